@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"troveler/db"
+	"troveler/internal/update"
+)
+
+var changelogSince string
+var changelogLimit int
+var changelogFormat string
+
+// ChangelogCmd lists past updates and what each changed in the catalog.
+var ChangelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Show what past updates changed in the catalog",
+	Long: `Show every recorded update, newest first, with what it changed compared to
+the catalog before it: tools added, removed upstream or renamed, changed
+taglines and descriptions, and install methods added, removed or changed per
+platform.
+
+--since takes a date (2006-01-02) or a time (RFC 3339).`,
+	Args: cobra.NoArgs,
+	Example: "  troveler changelog\n" +
+		"  troveler changelog --since 2026-10-01\n" +
+		"  troveler changelog -f json",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		since, err := parseSince(changelogSince)
+		if err != nil {
+			return err
+		}
+
+		return WithDB(cmd, func(ctx context.Context, database *db.SQLiteDB) error {
+			runs, err := database.ListUpdateRuns(ctx, since, changelogLimit)
+			if err != nil {
+				return fmt.Errorf("failed to read the changelog: %w", err)
+			}
+
+			switch changelogFormat {
+			case "json":
+				if runs == nil {
+					runs = []db.UpdateRun{}
+				}
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+
+				return encoder.Encode(runs)
+			default:
+				printChangelog(os.Stdout, runs)
+
+				return nil
+			}
+		})
+	},
+}
+
+func init() {
+	ChangelogCmd.Flags().StringVar(&changelogSince, "since", "", "Only show updates since this date")
+	ChangelogCmd.Flags().IntVarP(&changelogLimit, "limit", "n", 10, "Maximum number of updates (0 = all)")
+	ChangelogCmd.Flags().StringVarP(&changelogFormat, "format", "f", "pretty", "Output format (pretty, json)")
+}
+
+// parseSince parses a --since value: a local date or an RFC 3339 time. The
+// empty string means no bound.
+func parseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: want a date like 2006-01-02 or an RFC 3339 time", value)
+	}
+
+	return t, nil
+}
+
+func printChangelog(w io.Writer, runs []db.UpdateRun) {
+	if len(runs) == 0 {
+		_, _ = fmt.Fprintln(w, "No updates recorded")
+
+		return
+	}
+
+	headStyle := lipgloss.NewStyle().Bold(true)
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	for _, run := range runs {
+		counts := fmt.Sprintf("%d saved", run.Saved)
+		if run.Failed > 0 {
+			counts += ", " + failStyle.Render(fmt.Sprintf("%d failed", run.Failed))
+		}
+		_, _ = fmt.Fprintf(w, "%s  %s  %s\n",
+			headStyle.Render(run.RanAt.Local().Format("2006-01-02 15:04")), counts,
+			mutedStyle.Render(update.SummarizeChanges(run.Changes)))
+		for _, c := range run.Changes {
+			_, _ = fmt.Fprintf(w, "    %s\n", update.DescribeChange(c))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"troveler/db"
+)
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "", want: time.Time{}},
+		{value: "2026-10-01", want: time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+		{value: "2026-10-01T12:00:00Z", want: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)},
+		{value: "last week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSince(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPrintChangelog(t *testing.T) {
+	runs := []db.UpdateRun{
+		{RanAt: time.Now(), Saved: 2, Failed: 1, Changes: []db.CatalogChange{
+			{Slug: "fd", Kind: db.ChangeAdded, New: "fd"},
+			{Slug: "bat", Kind: db.ChangeInstallAdded, Platform: "brew", New: "brew install bat"},
+		}},
+		{RanAt: time.Now().Add(-time.Hour), Saved: 40},
+	}
+
+	var out bytes.Buffer
+	printChangelog(&out, runs)
+	got := out.String()
+	for _, want := range []string{
+		"2 saved", "1 failed", "1 added, 1 install method added", "fd: added",
+		"bat: brew install added: brew install bat", "40 saved", "no changes",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("changelog output missing %q:\n%s", want, got)
+		}
+	}
+
+	out.Reset()
+	printChangelog(&out, nil)
+	if !strings.Contains(out.String(), "No updates recorded") {
+		t.Errorf("unexpected output for an empty changelog: %q", out.String())
+	}
+}
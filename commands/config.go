@@ -4,11 +4,14 @@ package commands
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync"
 
 	"github.com/spf13/cobra"
 
 	"troveler/config"
 	"troveler/db"
+	"troveler/internal/install"
 )
 
 type contextKey struct{}
@@ -27,11 +30,35 @@ func GetConfig(ctx context.Context) *config.Config {
 	return nil
 }
 
-// LoadConfig loads the application config from the given path.
+// LoadConfig loads the application config from the given path and checks
+// its install risk policy.
 func LoadConfig(path string) (*config.Config, error) {
-	return config.Load(path)
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := riskPolicy(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
 }
 
+// riskPolicy returns the install risk policy of cfg. Invalid entries are an
+// error rather than no policy, which would allow every command.
+func riskPolicy(cfg *config.Config) (*install.RiskPolicy, error) {
+	policy, err := install.NewRiskPolicy(cfg.Install.BlockRisks, cfg.Install.ConfirmRisks)
+	if err != nil {
+		return nil, fmt.Errorf("[install] %w", err)
+	}
+
+	return policy, nil
+}
+
+// warnNoFTS reports once per process that search cannot use the FTS5 index.
+var warnNoFTS sync.Once
+
 // WithDB opens a database connection and calls fn, closing it when done.
 func WithDB(cmd *cobra.Command, fn func(ctx context.Context, database *db.SQLiteDB) error) error {
 	cfg := GetConfig(cmd.Context())
@@ -45,5 +72,12 @@ func WithDB(cmd *cobra.Command, fn func(ctx context.Context, database *db.SQLite
 	}
 	defer func() { _ = database.Close() }()
 
+	if !database.FullTextSearch() {
+		warnNoFTS.Do(func() {
+			fmt.Fprintln(os.Stderr,
+				"warning: built without the sqlite_fts5 tag; search falls back to substring matching")
+		})
+	}
+
 	return fn(cmd.Context(), database)
 }
@@ -2,6 +2,9 @@ package commands
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"troveler/config"
@@ -77,3 +80,37 @@ func testWithDBHelper(cfg *config.Config, fn func(context.Context, *db.SQLiteDB)
 
 	return fn(context.Background(), database)
 }
+
+func TestLoadConfigRiskPolicy(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+
+	//nolint:gosec // G306: test file
+	_ = os.WriteFile(configPath, []byte(`[install]
+block_risks = ["pipe-to-shell"]
+confirm_risks = ["medium"]`), 0644)
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if policy, err := riskPolicy(cfg); err != nil || policy == nil {
+		t.Errorf("expected a risk policy, got %v (%v)", policy, err)
+	}
+
+	//nolint:gosec // G306: test file
+	_ = os.WriteFile(configPath, []byte(`[install]
+confirm_risks = ["curl"]`), 0644)
+	if _, err := LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "confirm_risks") {
+		t.Errorf("LoadConfig error = %v, want an invalid confirm_risks error", err)
+	}
+
+	// A typo must not turn the policy off for configs built elsewhere.
+	cfg.Install.BlockRisks = []string{"pipe-to-shel"}
+	if _, err := riskPolicy(cfg); err == nil {
+		t.Error("expected an error for an invalid block_risks entry")
+	}
+	plan := installPlan{Slug: "bat", Action: planRun, Command: "brew install bat"}
+	plan.assessRisk(cfg)
+	if plan.Action != planFail || !strings.Contains(plan.Reason, "block_risks") {
+		t.Errorf("plan with an invalid policy = %s (%q), want fail", plan.Action, plan.Reason)
+	}
+}
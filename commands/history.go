@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"troveler/db"
+)
+
+var historyFailed bool
+var historyLimit int
+var historyJSON bool
+var historyVerbose bool
+
+// HistoryCmd lists the install, uninstall and upgrade commands troveler has run on this machine.
+var HistoryCmd = &cobra.Command{
+	Use:   "history [slug]",
+	Short: "Show the install history",
+	Long: `Show the install history: every install and uninstall command troveler ran
+on this machine, from the CLI or the TUI, newest first.
+
+Each entry records the tool, platform, the exact command (after sudo and mise
+rewriting), exit code, duration, user and the tail of the command's output.`,
+	Args: cobra.MaximumNArgs(1),
+	Example: "  troveler history\n" +
+		"  troveler history ripgrep\n" +
+		"  troveler history --failed -v",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter := db.HistoryFilter{Failed: historyFailed, Limit: historyLimit}
+		if len(args) == 1 {
+			filter.Slug = args[0]
+		}
+
+		return WithDB(cmd, func(_ context.Context, database *db.SQLiteDB) error {
+			records, err := database.ListInstallHistory(filter)
+			if err != nil {
+				return fmt.Errorf("failed to read install history: %w", err)
+			}
+
+			if historyJSON {
+				if records == nil {
+					records = []db.InstallRecord{}
+				}
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+
+				return encoder.Encode(records)
+			}
+
+			printHistory(os.Stdout, records, historyVerbose)
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	HistoryCmd.Flags().BoolVar(&historyFailed, "failed", false, "Only show failed installs")
+	HistoryCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Maximum number of entries (0 = all)")
+	HistoryCmd.Flags().BoolVarP(&historyJSON, "json", "j", false, "Output in JSON format")
+	HistoryCmd.Flags().BoolVarP(&historyVerbose, "verbose", "v", false, "Include the captured output of each install")
+}
+
+func printHistory(w io.Writer, records []db.InstallRecord, verbose bool) {
+	if len(records) == 0 {
+		_, _ = fmt.Fprintln(w, "No installs recorded")
+
+		return
+	}
+
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	for _, rec := range records {
+		status := okStyle.Render("✓")
+		if !rec.Succeeded() {
+			status = failStyle.Render(fmt.Sprintf("✗ exit %d", rec.ExitCode))
+		}
+
+		_, _ = fmt.Fprintf(w, "%s  %-9s %-20s %-10s %s  %s\n",
+			rec.InstalledAt.Local().Format("2006-01-02 15:04"), rec.Action, rec.Slug, rec.Platform, status,
+			mutedStyle.Render(fmt.Sprintf("%s by %s", rec.Duration.Round(time.Second/10), userOrUnknown(rec.User))))
+		_, _ = fmt.Fprintf(w, "    $ %s\n", rec.Command)
+
+		if verbose && strings.TrimSpace(rec.Output) != "" {
+			for _, line := range strings.Split(strings.TrimRight(rec.Output, "\n"), "\n") {
+				_, _ = fmt.Fprintf(w, "    %s\n", mutedStyle.Render(line))
+			}
+		}
+	}
+}
+
+func userOrUnknown(name string) string {
+	if name == "" {
+		return "unknown"
+	}
+
+	return name
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"troveler/db"
+)
+
+func TestPrintHistory(t *testing.T) {
+	records := []db.InstallRecord{
+		{Action: db.ActionInstall, Slug: "fd", Platform: "apt", Command: "sudo apt install fd-find", ExitCode: 100,
+			Output: "E: Could not get lock\n", User: "alice", InstalledAt: time.Now()},
+		{Slug: "ripgrep", Platform: "brew", Command: "brew install ripgrep", Duration: 2 * time.Second},
+	}
+
+	var out bytes.Buffer
+	printHistory(&out, records, false)
+	got := out.String()
+
+	for _, want := range []string{"sudo apt install fd-find", "exit 100", "by alice", "brew install ripgrep", "by unknown"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("history output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Could not get lock") {
+		t.Errorf("output tail should only be shown with verbose:\n%s", got)
+	}
+
+	out.Reset()
+	printHistory(&out, records, true)
+	if !strings.Contains(out.String(), "Could not get lock") {
+		t.Errorf("verbose history should include the output tail:\n%s", out.String())
+	}
+
+	out.Reset()
+	printHistory(&out, nil, false)
+	if !strings.Contains(out.String(), "No installs recorded") {
+		t.Errorf("unexpected output for empty history: %q", out.String())
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"troveler/db"
+	"troveler/internal/info"
 	"troveler/internal/install"
 	"troveler/pkg/ui"
 )
@@ -73,6 +74,13 @@ func runInfo(database *db.SQLiteDB, slug string) error {
 		rows = append(rows, []string{"Published", tool.DatePublished})
 	}
 	rows = append(rows, []string{"Slug", tool.Slug})
+	if tool.Source != "" {
+		rows = append(rows, []string{"Source", tool.Source})
+	}
+	if tool.Removed {
+		rows = append(rows, []string{"Status", "removed upstream"})
+	}
+	rows = append(rows, ownerRows(database, &tool, installs)...)
 
 	if len(rows) > 0 {
 		fmt.Println(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00FF00")).Render("Info:"))
@@ -105,6 +113,17 @@ func runInfo(database *db.SQLiteDB, slug string) error {
 	return nil
 }
 
+// ownerRows describes which package manager owns the installed executable,
+// or nothing when the tool is not on PATH.
+func ownerRows(database *db.SQLiteDB, tool *db.Tool, installs []db.InstallInstruction) [][]string {
+	owner, err := database.ResolveToolOwner(context.Background(), tool, installs)
+	if err != nil || owner == nil {
+		return nil
+	}
+
+	return [][]string{{"Installed", info.FormatOwner(owner)}}
+}
+
 func wrapText(text string, width int) string {
 	var result strings.Builder
 	words := strings.Fields(text)
@@ -2,9 +2,9 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -23,6 +23,10 @@ var override string
 var mise bool
 var reuseConfig string
 var skipIfBlind bool
+var dryRun bool
+var planFormat string
+var installJobs int
+var resume bool
 
 // InstallCmd shows or executes install commands for one or more tools.
 var InstallCmd = &cobra.Command{
@@ -38,21 +42,67 @@ Use --mise to output mise use commands for language-based installations.
 For multiple tools:
   --reuse-config: true (use same config for all), ask (prompt), false (configure each)
   --sudo-only-system: use sudo only for system package managers (apt, dnf, etc.)
-  --skip-if-blind: skip tools without a compatible install method`,
-	Args: cobra.MinimumNArgs(1),
+  --skip-if-blind: skip tools without a compatible install method
+  --jobs: how many installs --run executes at once (default: jobs in
+          [install], 4). Managers holding a global lock (apt, dnf, pacman,
+          brew, mise, ...) still install one tool at a time; the prompts are
+          asked before anything runs and output lines are prefixed with the
+          slug.
+
+A batch run with --run is remembered until it finishes. If it is
+interrupted, --resume installs the tools it did not get to (including the
+ones cut off mid-install) with the choices made when it started.
+
+Use --dry-run (or --plan) to print what --run would execute for each tool,
+without prompting or executing anything: the resolved platform, whether
+fallback_platform or language matching picked it, the mise rewrite and the
+sudo decision. For several tools the batch prompts are answered from the
+flags. Use -f json for machine-readable output.`,
+	Example: `  troveler install ripgrep --dry-run
+  troveler install ripgrep bat fd --plan --skip-if-blind --sudo-only-system -f json
+  troveler install --resume`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if resume {
+			return cobra.NoArgs(cmd, args)
+		}
+
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return WithDB(cmd, func(ctx context.Context, database *db.SQLiteDB) error {
 			cfg := GetConfig(ctx)
 
+			if resume {
+				return resumeBatchInstall(database, batchJobs(installJobs, cfg), cfg)
+			}
+
+			if dryRun {
+				plans := planInstalls(database, args, installPlanFlags{
+					Override:       override,
+					Sudo:           sudo,
+					SudoOnlySystem: sudoOnlySystem,
+					SkipIfBlind:    skipIfBlind,
+					Mise:           mise,
+				}, cfg, detectOSID())
+
+				return printInstallPlans(os.Stdout, plans, planFormat)
+			}
+
 			if len(args) == 1 {
+				policy, err := riskPolicy(cfg)
+				if err != nil {
+					return err
+				}
+
 				return runInstall(
 					database, args[0], all, run, sudo, override,
 					cfg.Install.PlatformOverride, cfg.Install.FallbackPlatform,
-					cfg.Install.AlwaysRun, cfg.Install.UseSudo,
+					cfg.Install.AlwaysRun, cfg.Install.UseSudo, policy,
 				)
 			}
 
-			return runBatchInstall(database, args, run, sudo, sudoOnlySystem, skipIfBlind, reuseConfig, cfg)
+			return runBatchInstall(database, batchTools(args), run, sudo, sudoOnlySystem, skipIfBlind, reuseConfig,
+				batchJobs(installJobs, cfg), cfg)
 		})
 	},
 }
@@ -68,29 +118,22 @@ func init() {
 	InstallCmd.Flags().StringVar(&reuseConfig, "reuse-config", "ask", "Reuse config for all tools: true, ask, false")
 	InstallCmd.Flags().BoolVar(&sudoOnlySystem, "sudo-only-system", false, "Use sudo only for system package managers")
 	InstallCmd.Flags().BoolVar(&skipIfBlind, "skip-if-blind", false, "Skip tools without compatible install method")
+	InstallCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print what --run would execute without executing")
+	InstallCmd.Flags().BoolVar(&dryRun, "plan", false, "Same as --dry-run")
+	InstallCmd.Flags().IntVarP(&installJobs, "jobs", "j", 0,
+		"Installs to run at once in a batch (default: jobs in [install])")
+	InstallCmd.Flags().BoolVar(&resume, "resume", false, "Continue the last interrupted batch install")
+	InstallCmd.Flags().StringVarP(&planFormat, "format", "f", "pretty", "Plan output format (pretty, json)")
 }
 
 func runInstall(
 	database *db.SQLiteDB, slug string, showAll bool, runFlag bool, sudoFlag bool,
 	cliOverride string, configOverride string, fallbackPlatform string,
-	alwaysRun bool, useSudo string,
+	alwaysRun bool, useSudo string, policy *install.RiskPolicy,
 ) error {
-	tools, err := database.GetToolBySlug(slug)
+	tool, installs, err := lookupInstall(database, slug)
 	if err != nil {
-		return fmt.Errorf("tool not found: %s", slug)
-	}
-	if len(tools) == 0 {
-		return fmt.Errorf("tool not found: %s", slug)
-	}
-
-	tool := tools[0]
-	installs, err := database.GetInstallInstructions(tool.ID)
-	if err != nil {
-		installs = []db.InstallInstruction{}
-	}
-
-	if len(installs) == 0 {
-		return fmt.Errorf("no install instructions available for %s", slug)
+		return err
 	}
 
 	if showAll {
@@ -102,42 +145,17 @@ func runInstall(
 		cliOverride = PlatformMiseLang
 	}
 
-	selector := platform.NewSelector(cliOverride, configOverride, fallbackPlatform, tool.Language)
-
-	osInfo, _ := platform.DetectOS()
-	detectedOS := ""
-	if osInfo != nil {
-		detectedOS = osInfo.ID
-	}
-
-	platformID := selector.Select(detectedOS)
-
-	// Check for explicit virtual platform requests (e.g., "mise:github")
-	// before resolving the virtual prefix
-	if strings.HasPrefix(platformID, "mise:") {
-		virtuals := install.GenerateVirtualInstallInstructions(installs)
-		requestedBackend := strings.TrimPrefix(platformID, "mise:")
-
-		for _, v := range virtuals {
-			if v.Platform == platformID {
-				displayInstallCommands(platformID, []db.InstallInstruction{
-					{Platform: v.Platform, Command: v.Command},
-				})
-
-				if runFlag {
-					return executeInstall(v.Command, sudoFlag, useSudo, alwaysRun)
-				}
-
-				return nil
-			}
-		}
+	res := resolveSingleInstall(tool, installs, cliOverride, configOverride, fallbackPlatform, detectOSID())
 
+	switch {
+	case res.virtual && len(res.matched) == 0:
 		// Virtual platform not found - show helpful error
-		fmt.Printf("No install command found for %s (backend: %s).\n\n", platformID, requestedBackend)
+		fmt.Printf("No install command found for %s (backend: %s).\n\n",
+			res.requested, strings.TrimPrefix(res.requested, "mise:"))
 
-		if len(virtuals) > 0 {
+		if len(res.virtuals) > 0 {
 			fmt.Println("Available virtual platforms:")
-			for _, v := range virtuals {
+			for _, v := range res.virtuals {
 				fmt.Printf("  - %s\n", v.Platform)
 			}
 			fmt.Println()
@@ -146,34 +164,16 @@ func runInstall(
 		fmt.Println("Available commands:")
 
 		return showAllInstalls(tool.Name, installs)
-	}
 
-	platformID = platform.ResolveVirtual(platformID)
+	case res.noMethod:
+		displayNoInstallMethod(tool.Name, res.requested, installs)
 
-	// Use ResolvePlatform to try fallback_platform when detected OS yields no matches
-	result := install.ResolvePlatform(selector, installs, detectedOS, tool.Language)
-	resolvedID := platform.ResolveVirtual(result.PlatformID)
-
-	var matched []db.InstallInstruction
-
-	if result.UsedFallback && resolvedID == platformID {
-		synthMatched, synthPlatform := install.TryResolveLangFallback(installs, platformID)
-		if len(synthMatched) > 0 {
-			matched = synthMatched
-			resolvedID = platform.ResolveVirtual(synthPlatform)
-		} else {
-			displayNoInstallMethod(tool.Name, platformID, installs)
-
-			return nil
-		}
-	} else {
-		matched = result.Installs
-	}
+		return nil
 
-	if len(matched) == 0 {
-		fmt.Printf("No install command found for %s.\n\n", platformID)
+	case len(res.matched) == 0:
+		fmt.Printf("No install command found for %s.\n\n", res.requested)
 
-		if !platform.IsLangPlatform(platformID) && platformID != tool.Language {
+		if !platform.IsLangPlatform(res.requested) && res.requested != tool.Language {
 			var langMatched []db.InstallInstruction
 			langMatched, _ = platform.FilterDBInstalls(installs, PlatformLang, tool.Language)
 			if len(langMatched) > 0 {
@@ -188,24 +188,105 @@ func runInstall(
 		return showAllInstalls(tool.Name, installs)
 	}
 
-	displayInstallCommands(resolvedID, matched)
+	displayInstallCommands(res.platformID, res.matched)
 
-	if runFlag {
-		cmd := matched[0].Command
-		effectiveUseSudo := useSudo
-		if platform.IsMiseLangPlatform(resolvedID) {
-			cmd = install.TransformToMise(cmd)
-			// Mise commands don't need sudo; don't prompt unless --sudo is explicit
-			if !sudoFlag {
-				effectiveUseSudo = ""
+	if !runFlag {
+		return nil
+	}
+
+	cmd, effectiveUseSudo := res.command(sudoFlag, useSudo)
+	if res.virtual {
+		return executeInstall(database, tool.Slug, res.platformID, cmd, sudoFlag, effectiveUseSudo, alwaysRun, policy)
+	}
+
+	// --run flag implies execute without confirmation
+	return executeInstall(database, tool.Slug, res.platformID, cmd, sudoFlag, effectiveUseSudo, true, policy)
+}
+
+// singleResolution is the outcome of platform resolution for a single-tool
+// install.
+type singleResolution struct {
+	requested        string                   // the selected platform, virtual prefixes resolved
+	platformID       string                   // platform of matched
+	matched          []db.InstallInstruction  // candidate instructions, the first one runs
+	usedFallback     bool                     // PlatformResult.UsedFallback: nothing matched the platform
+	fallbackPlatform bool                     // fallback_platform supplied matched
+	langMatch        bool                     // matched by the tool's language (lang, mise_lang)
+	langFallback     bool                     // matched came from TryResolveLangFallback
+	noMethod         bool                     // nothing matched, not even through the fallbacks
+	virtual          bool                     // a mise:<backend> platform was requested
+	virtuals         []install.VirtualInstall // the generated virtual instructions, for virtual requests
+}
+
+// resolveSingleInstall picks the install instructions for tool the way a
+// single-tool install does: explicit mise:<backend> platforms from the
+// generated virtual instructions, everything else through ResolvePlatform
+// with the language fallback for lang and mise_lang.
+func resolveSingleInstall(
+	tool *db.Tool, installs []db.InstallInstruction, cliOverride, configOverride, fallbackPlatform,
+	detectedOS string,
+) singleResolution {
+	selector := platform.NewSelector(cliOverride, configOverride, fallbackPlatform, tool.Language)
+	platformID := selector.Select(detectedOS)
+
+	// Check for explicit virtual platform requests (e.g., "mise:github")
+	// before resolving the virtual prefix
+	if strings.HasPrefix(platformID, "mise:") {
+		res := singleResolution{requested: platformID, platformID: platformID, virtual: true}
+		res.virtuals = install.GenerateVirtualInstallInstructions(installs)
+		for _, v := range res.virtuals {
+			if v.Platform == platformID {
+				res.matched = []db.InstallInstruction{{Platform: v.Platform, Command: v.Command}}
+
+				break
 			}
 		}
 
-		// --run flag implies execute without confirmation
-		return executeInstall(cmd, sudoFlag, effectiveUseSudo, true)
+		return res
 	}
 
-	return nil
+	platformID = platform.ResolveVirtual(platformID)
+	res := singleResolution{requested: platformID}
+
+	// Use ResolvePlatform to try fallback_platform when detected OS yields no matches
+	result := install.ResolvePlatform(selector, installs, detectedOS, tool.Language)
+	res.platformID = platform.ResolveVirtual(result.PlatformID)
+	res.usedFallback = result.UsedFallback
+	res.fallbackPlatform = !result.UsedFallback && res.platformID != platformID
+	res.langMatch = platform.IsLangPlatform(res.platformID)
+
+	if result.UsedFallback && res.platformID == platformID {
+		synthMatched, synthPlatform := install.TryResolveLangFallback(installs, platformID)
+		if len(synthMatched) == 0 {
+			res.noMethod = true
+
+			return res
+		}
+		res.matched = synthMatched
+		res.platformID = platform.ResolveVirtual(synthPlatform)
+		res.langFallback = true
+
+		return res
+	}
+
+	res.matched = result.Installs
+
+	return res
+}
+
+// command returns the command a run executes and the use_sudo setting it
+// runs under. mise_lang commands are rewritten to mise use and get sudo
+// only when --sudo asks for it.
+func (r singleResolution) command(sudoFlag bool, useSudo string) (string, string) {
+	cmd := r.matched[0].Command
+	if platform.IsMiseLangPlatform(r.platformID) {
+		cmd = install.TransformToMise(cmd)
+		if !sudoFlag {
+			useSudo = ""
+		}
+	}
+
+	return cmd, useSudo
 }
 
 // BatchConfig holds user preferences for batch install mode.
@@ -217,105 +298,141 @@ type BatchConfig struct {
 	AlwaysRun      bool
 }
 
+// batchTool is one tool in a batch install, with optional per-tool pins
+// (used by sync to honor the manifest).
+type batchTool struct {
+	Slug     string
+	Platform string // platform override; "" uses config and OS detection
+	Mise     bool   // install through mise regardless of the batch config
+}
+
+func batchTools(slugs []string) []batchTool {
+	tools := make([]batchTool, len(slugs))
+	for i, slug := range slugs {
+		tools[i] = batchTool{Slug: slug}
+	}
+
+	return tools
+}
+
+// runBatchInstall installs tools one after another, or with --run and jobs
+// above one through runParallelBatch. With --run the batch is persisted so
+// install --resume can continue it after an interruption. It fails when any
+// tool failed, so scripts see a failing exit code.
 func runBatchInstall(
-	database *db.SQLiteDB, slugs []string, runFlag, sudoFlag, sudoOnlySystemFlag, skipIfBlindFlag bool,
-	reuseConfig string, cfg *config.Config,
+	database *db.SQLiteDB, tools []batchTool, runFlag, sudoFlag, sudoOnlySystemFlag, skipIfBlindFlag bool,
+	reuseConfig string, jobs int, cfg *config.Config,
 ) error {
-	fmt.Printf("\n🔧 Batch Install: %d tools\n\n", len(slugs))
+	fmt.Printf("\n🔧 Batch Install: %d tools\n\n", len(tools))
 
 	batchCfg := promptBatchConfig(
 		sudoFlag, sudoOnlySystemFlag, skipIfBlindFlag,
 		reuseConfig, cfg.Install.AlwaysRun,
 	)
 
-	var completed, failed, skipped []string
-
-	for i, slug := range slugs {
-		fmt.Printf("\n[%d/%d] %s\n", i+1, len(slugs), slug)
-		fmt.Println(strings.Repeat("─", 40))
-
-		err := installSingleTool(database, slug, batchCfg, runFlag, cfg)
-		if err != nil {
-			if strings.Contains(err.Error(), "skipped") {
-				skipped = append(skipped, slug)
-				fmt.Printf("○ Skipped: %s\n", slug)
-			} else {
-				failed = append(failed, slug)
-				fmt.Printf("✗ Failed: %s - %v\n", slug, err)
-			}
-		} else {
-			completed = append(completed, slug)
-			fmt.Printf("✓ Completed: %s\n", slug)
-		}
-	}
-
-	fmt.Printf("\n%s\n", strings.Repeat("═", 40))
-	fmt.Printf("Batch Install Summary:\n")
-	if len(completed) > 0 {
-		fmt.Printf("  ✓ Completed: %d\n", len(completed))
-	}
-	if len(failed) > 0 {
-		fmt.Printf("  ✗ Failed: %d\n", len(failed))
-	}
-	if len(skipped) > 0 {
-		fmt.Printf("  ○ Skipped: %d\n", len(skipped))
+	var rec *batchRecorder
+	if runFlag {
+		rec = startBatchRun(database, tools, batchCfg)
 	}
 
-	return nil
+	return installBatch(database, tools, batchCfg, runFlag, jobs, cfg, rec)
 }
 
-func installSingleTool(
-	database *db.SQLiteDB, slug string, batchCfg *BatchConfig, runFlag bool, cfg *config.Config,
+// installBatch installs tools with the settled batch config and prints a
+// summary, recording each outcome in rec. Skipped tools do not count as
+// failures.
+func installBatch(
+	database *db.SQLiteDB, tools []batchTool, batchCfg *BatchConfig, runFlag bool, jobs int, cfg *config.Config,
+	rec *batchRecorder,
 ) error {
-	tools, err := database.GetToolBySlug(slug)
-	if err != nil || len(tools) == 0 {
-		return fmt.Errorf("tool not found: %s", slug)
-	}
+	summary := &batchSummary{rec: rec}
+	if runFlag && jobs > 1 && len(tools) > 1 {
+		runParallelBatch(database, tools, batchCfg, jobs, cfg, summary)
+	} else {
+		for i, bt := range tools {
+			fmt.Printf("\n[%d/%d] %s\n", i+1, len(tools), bt.Slug)
+			fmt.Println(strings.Repeat("─", 40))
 
-	tool := tools[0]
-	installs, err := database.GetInstallInstructions(tool.ID)
-	if err != nil || len(installs) == 0 {
-		if batchCfg != nil && batchCfg.SkipIfBlind {
-			return fmt.Errorf("skipped: no install instructions")
+			rec.running(i)
+			summary.record(i, bt.Slug, installSingleTool(database, bt, batchCfg, runFlag, cfg))
 		}
-
-		return fmt.Errorf("no install instructions available")
 	}
 
-	osInfo, _ := platform.DetectOS()
-	detectedOS := ""
-	if osInfo != nil {
-		detectedOS = osInfo.ID
-	}
+	summary.print()
+	rec.finish()
 
-	selector := platform.NewSelector("", cfg.Install.PlatformOverride, cfg.Install.FallbackPlatform, tool.Language)
+	if len(summary.failed) > 0 {
+		return fmt.Errorf("%d of %d tools failed to install", len(summary.failed), len(tools))
+	}
 
-	result := install.ResolvePlatform(selector, installs, detectedOS, tool.Language)
-	matched := result.Installs
+	return nil
+}
 
-	if result.UsedFallback || len(matched) == 0 {
-		if batchCfg != nil && batchCfg.SkipIfBlind {
-			return fmt.Errorf("skipped: no compatible install method")
-		}
+// batchSummary collects the outcome of each tool of a batch install.
+type batchSummary struct {
+	completed, failed, skipped []string
+	rec                        *batchRecorder
+}
 
-		return fmt.Errorf("no compatible install method for %s", result.PlatformID)
+// record prints and counts the outcome of installing the i-th tool. Errors
+// starting with "skipped" count as skips.
+func (s *batchSummary) record(i int, slug string, err error) {
+	s.rec.finished(i, err)
+
+	switch {
+	case err == nil:
+		s.completed = append(s.completed, slug)
+		fmt.Printf("✓ Completed: %s\n", slug)
+	case isSkip(err):
+		s.skipped = append(s.skipped, slug)
+		fmt.Printf("○ Skipped: %s\n", slug)
+	default:
+		s.failed = append(s.failed, slug)
+		fmt.Printf("✗ Failed: %s - %v\n", slug, err)
 	}
+}
 
-	cmd := matched[0].Command
+func isSkip(err error) bool {
+	return strings.Contains(err.Error(), "skipped")
+}
 
-	if batchCfg != nil && batchCfg.UseMise {
-		cmd = install.TransformToMise(cmd)
+func (s *batchSummary) print() {
+	fmt.Printf("\n%s\n", strings.Repeat("═", 40))
+	fmt.Printf("Batch Install Summary:\n")
+	if len(s.completed) > 0 {
+		fmt.Printf("  ✓ Completed: %d\n", len(s.completed))
+	}
+	if len(s.failed) > 0 {
+		fmt.Printf("  ✗ Failed: %d\n", len(s.failed))
 	}
+	if len(s.skipped) > 0 {
+		fmt.Printf("  ○ Skipped: %d\n", len(s.skipped))
+	}
+}
 
-	if batchCfg != nil {
-		if batchCfg.UseSudo {
-			cmd = "sudo " + cmd
-		} else if batchCfg.SudoOnlySystem && isSystemPM(matched[0].Platform) {
-			cmd = "sudo " + cmd
-		}
+func installSingleTool(
+	database *db.SQLiteDB, bt batchTool, batchCfg *BatchConfig, runFlag bool, cfg *config.Config,
+) error {
+	plan := planBatchTool(database, bt, batchCfg, cfg, detectOSID())
+	if err := reviewPlan(plan, batchCfg, runFlag, cfg); err != nil || !runFlag {
+		return err
 	}
 
-	fmt.Printf("Command: %s\n", cmd)
+	return runAndRecord(database, db.ActionInstall, plan.Slug, plan.Platform, plan.Command)
+}
+
+// reviewPlan prints the command plan runs and, with --run, asks for it
+// unless always_run is on and the risk policy does not require a
+// confirmation. The error tells why the tool is not to be installed.
+func reviewPlan(plan installPlan, batchCfg *BatchConfig, runFlag bool, cfg *config.Config) error {
+	switch plan.Action {
+	case planSkip:
+		return fmt.Errorf("skipped: %s", plan.Reason)
+	case planFail, planBlock:
+		return errors.New(plan.Reason)
+	}
+
+	fmt.Printf("Command: %s%s\n", plan.Command, riskBadge(install.AnalyzeCommand(plan.Command)))
 
 	if !runFlag {
 		return nil
@@ -326,17 +443,48 @@ func installSingleTool(
 		alwaysRun = batchCfg.AlwaysRun
 	}
 
-	if !alwaysRun {
-		if err := promptExecute(); err != nil {
-			return err
-		}
+	if plan.Confirm {
+		fmt.Printf("⚠ The install risk policy requires confirmation for: %s\n", strings.Join(plan.RiskClasses, ", "))
+	}
+	if !alwaysRun || plan.Confirm {
+		return promptExecute()
+	}
+
+	return nil
+}
+
+// resolveBatchInstall picks the install instruction a batch install runs for
+// tool: the first match for the pinned, configured or detected platform.
+func resolveBatchInstall(
+	tool *db.Tool, installs []db.InstallInstruction, bt batchTool, cfg *config.Config, detectedOS string,
+) (db.InstallInstruction, error) {
+	result, _ := resolveBatchPlatform(tool, installs, bt, cfg, detectedOS)
+	if result.UsedFallback || len(result.Installs) == 0 {
+		return db.InstallInstruction{}, fmt.Errorf("no compatible install method for %s", result.PlatformID)
 	}
 
-	execCmd := exec.Command("sh", "-c", cmd) //nolint:noctx,gosec // G204: user-requested install command
-	execCmd.Stdout = os.Stdout
-	execCmd.Stderr = os.Stderr
+	return result.Installs[0], nil
+}
+
+// resolveBatchPlatform resolves the platform of a batch install: the tool's
+// pin, then the configured override, the detected OS and fallback_platform.
+// It also returns the platform selected before fallback_platform was tried.
+func resolveBatchPlatform(
+	tool *db.Tool, installs []db.InstallInstruction, bt batchTool, cfg *config.Config, detectedOS string,
+) (install.PlatformResult, string) {
+	selector := platform.NewSelector(bt.Platform, cfg.Install.PlatformOverride, cfg.Install.FallbackPlatform,
+		tool.Language)
+
+	return install.ResolvePlatform(selector, installs, detectedOS, tool.Language), selector.Select(detectedOS)
+}
+
+func detectOSID() string {
+	osInfo, _ := platform.DetectOS()
+	if osInfo == nil {
+		return ""
+	}
 
-	return execCmd.Run()
+	return osInfo.ID
 }
 
 func isSystemPM(platformID string) bool {
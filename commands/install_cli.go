@@ -2,28 +2,54 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+
+	"troveler/db"
+	"troveler/internal/install"
 )
 
-func executeInstall(command string, sudoFlag bool, useSudo string, alwaysRun bool) error {
-	shouldSudo := sudoFlag
+// errAborted is returned by executeCommand when the user declines to run it.
+var errAborted = errors.New("aborted")
+
+func executeInstall(
+	database *db.SQLiteDB, slug, platformID, command string, sudoFlag bool, useSudo string, alwaysRun bool,
+	policy *install.RiskPolicy,
+) error {
+	err := executeCommand(database, db.ActionInstall, slug, platformID, command, sudoFlag, useSudo, alwaysRun, policy)
+	if errors.Is(err, errAborted) {
+		return nil
+	}
+
+	return err
+}
 
-	if !sudoFlag && useSudo == "ask" {
+// executeCommand settles sudo (flag, then the use_sudo setting), applies the
+// install risk policy, confirms unless alwaysRun, and runs command, recording
+// it in the install history.
+func executeCommand(
+	database *db.SQLiteDB, action, slug, platformID, command string, sudoFlag bool, useSudo string, alwaysRun bool,
+	policy *install.RiskPolicy,
+) error {
+	shouldSudo := false
+	switch sudoDecision(sudoFlag, useSudo) {
+	case sudoYes:
+		shouldSudo = true
+	case sudoAsk:
 		fmt.Print("Use sudo? [y/N] ")
 		var confirm string
-		if _, err := fmt.Scanln(&confirm); err != nil {
-			shouldSudo = false
-		} else {
+		if _, err := fmt.Scanln(&confirm); err == nil {
 			shouldSudo = confirm == "y" || confirm == "Y"
 		}
-	} else if !sudoFlag && useSudo == "true" {
-		shouldSudo = true
 	}
 
-	if shouldSudo {
-		command = "sudo " + command
+	command = withSudo(command, shouldSudo)
+
+	alwaysRun, err := applyRiskPolicy(policy, command, alwaysRun)
+	if err != nil {
+		return err
 	}
 
 	if !alwaysRun {
@@ -36,18 +62,53 @@ func executeInstall(command string, sudoFlag bool, useSudo string, alwaysRun boo
 		if confirm != "y" && confirm != "Y" {
 			fmt.Println("Aborted.")
 
-			return nil
+			return errAborted
 		}
 	}
 
 	fmt.Printf("\nExecuting: %s\n\n", command)
 
-	//nolint:gosec // G204: user-requested install
-	cmd := exec.CommandContext(context.Background(), "sh", "-c", command)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	return runAndRecord(database, action, slug, platformID, command)
+}
+
+// applyRiskPolicy fails for commands the policy blocks. For classes that need
+// confirmation it turns alwaysRun off, so the user is asked even under --run
+// or always_run.
+func applyRiskPolicy(policy *install.RiskPolicy, command string, alwaysRun bool) (bool, error) {
+	decision, classes := policy.Decide(install.AnalyzeCommand(command))
+	switch decision {
+	case install.RiskBlock:
+		return false, &install.RiskBlockedError{Classes: classes}
+	case install.RiskConfirm:
+		fmt.Printf("⚠ The install risk policy requires confirmation for: %s\n", install.FormatRiskClasses(classes))
+
+		return false, nil
+	}
+
+	return alwaysRun, nil
+}
+
+// runAndRecord runs command on the terminal, appends the outcome to the
+// install history and rescans the tool's installed state. Failing to write
+// either does not fail the install.
+func runAndRecord(database *db.SQLiteDB, action, slug, platformID, command string) error {
+	return runAndRecordTo(database, action, slug, platformID, command, os.Stdout, os.Stderr)
+}
+
+// runAndRecordTo is runAndRecord with the command's output copied to stdout
+// and stderr.
+func runAndRecordTo(database *db.SQLiteDB, action, slug, platformID, command string, stdout, stderr io.Writer) error {
+	result := install.Run(context.Background(), command, stdout, stderr)
+	rec := result.Record(slug, platformID)
+	rec.Action = action
+	if err := database.RecordInstall(rec); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if err := database.RefreshInstalled(context.Background(), slug); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
 
-	return cmd.Run()
+	return result.Err
 }
 
 func promptBatchConfig(
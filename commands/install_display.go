@@ -23,17 +23,17 @@ func showAllInstalls(name string, installs []db.InstallInstruction) error {
 
 	virtuals := install.GenerateVirtualInstallInstructions(installs)
 
-	headers := []string{"Platform", "Command"}
+	headers := []string{"Platform", "Command", "Risk"}
 
 	totalRows := len(installs) + len(virtuals)
 	rows := make([][]string, 0, totalRows)
 
 	for _, inst := range installs {
-		rows = append(rows, []string{inst.Platform, inst.Command})
+		rows = append(rows, []string{inst.Platform, inst.Command, install.AnalyzeCommand(inst.Command).Badge()})
 	}
 
 	for _, v := range virtuals {
-		rows = append(rows, []string{v.Platform, v.Command})
+		rows = append(rows, []string{v.Platform, v.Command, install.AnalyzeCommand(v.Command).Badge()})
 	}
 
 	tableConfig := ui.TableConfig{
@@ -70,11 +70,28 @@ func displayInstallCommands(platformID string, matched []db.InstallInstruction)
 		if platform.IsMiseLangPlatform(platformID) {
 			cmd = install.TransformToMise(cmd)
 		}
-		fmt.Println(lipgloss.NewStyle().Bold(true).Render(cmd))
+		fmt.Println(lipgloss.NewStyle().Bold(true).Render(cmd) + riskBadge(install.AnalyzeCommand(cmd)))
 	}
 	fmt.Println()
 }
 
+// riskBadgeColors color risk badges by level.
+var riskBadgeColors = map[install.RiskLevel]string{
+	install.RiskLow:    "#87CEEB",
+	install.RiskMedium: "#FFA500",
+	install.RiskHigh:   "#FF5555",
+}
+
+// riskBadge renders the risk of a command as "  [level: classes]", or "" when
+// the analyzer found nothing.
+func riskBadge(risk install.Risk) string {
+	if risk.Level == install.RiskNone {
+		return ""
+	}
+
+	return "  " + lipgloss.NewStyle().Foreground(lipgloss.Color(riskBadgeColors[risk.Level])).Render(risk.Badge())
+}
+
 func displayLanguageFallback(toolLanguage string, langMatched []db.InstallInstruction) {
 	fmt.Printf("Trying language (%s):\n", toolLanguage)
 	for _, inst := range langMatched {
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"troveler/config"
+	"troveler/db"
+	"troveler/internal/install"
+)
+
+// batchJobs is the number of installs a batch runs at once: --jobs, then
+// jobs in [install].
+func batchJobs(flag int, cfg *config.Config) int {
+	if flag > 0 {
+		return flag
+	}
+
+	return cfg.Install.Jobs
+}
+
+// runParallelBatch installs tools up to jobs at a time. Every tool is planned
+// and confirmed first, since prompts cannot interleave with running
+// installs; the approved installs then run through an install.Scheduler,
+// which keeps managers holding a global lock to one install at a time.
+func runParallelBatch(
+	database *db.SQLiteDB, tools []batchTool, batchCfg *BatchConfig, jobs int, cfg *config.Config,
+	summary *batchSummary,
+) {
+	detectedOS := detectOSID()
+	var approved []installPlan
+	var indexes []int // index in tools of each approved plan
+	for i, bt := range tools {
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(tools), bt.Slug)
+		plan := planBatchTool(database, bt, batchCfg, cfg, detectedOS)
+		if err := reviewPlan(plan, batchCfg, true, cfg); err != nil {
+			summary.record(i, bt.Slug, err)
+
+			continue
+		}
+		approved = append(approved, plan)
+		indexes = append(indexes, i)
+	}
+	if len(approved) == 0 {
+		return
+	}
+
+	primeSudo(approved)
+
+	sched := install.NewScheduler(jobs)
+	for _, plan := range approved {
+		sched.Add(install.ManagerLocks(plan.Command))
+	}
+	fmt.Printf("\n▶ Installing %d tools, up to %d at a time\n\n", len(approved), min(jobs, len(approved)))
+
+	type jobResult struct {
+		job int
+		err error
+	}
+	var mu sync.Mutex // serializes writes to the terminal
+	done := make(chan jobResult)
+	for {
+		for _, job := range sched.Next() {
+			plan := approved[job]
+			mu.Lock()
+			summary.rec.running(indexes[job])
+			fmt.Printf("[%s] $ %s\n", plan.Slug, plan.Command)
+			mu.Unlock()
+
+			go func() {
+				stdout := &prefixWriter{mu: &mu, w: os.Stdout, prefix: "[" + plan.Slug + "] "}
+				stderr := &prefixWriter{mu: &mu, w: os.Stderr, prefix: "[" + plan.Slug + "] "}
+				err := runAndRecordTo(database, db.ActionInstall, plan.Slug, plan.Platform, plan.Command, stdout, stderr)
+				stdout.Flush()
+				stderr.Flush()
+				done <- jobResult{job: job, err: err}
+			}()
+		}
+		if sched.Idle() {
+			return
+		}
+
+		result := <-done
+		sched.Done(result.job)
+		mu.Lock()
+		summary.record(indexes[result.job], approved[result.job].Slug, result.err)
+		mu.Unlock()
+	}
+}
+
+// primeSudo asks for the sudo password once before parallel installs start,
+// so concurrent sudo commands do not prompt over each other.
+func primeSudo(plans []installPlan) {
+	for _, plan := range plans {
+		if !install.AnalyzeCommand(plan.Command).Has(install.RiskSudo) {
+			continue
+		}
+
+		cmd := exec.Command("sudo", "-v")
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: sudo -v: %v\n", err)
+		}
+
+		return
+	}
+}
+
+// prefixWriter writes each complete line written to it to w, prefixed, so
+// the output of concurrent installs stays attributable. mu is shared by all
+// writers to the terminal.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		p.writeLine(p.buf[:i])
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes a trailing line that did not end in a newline.
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(p.buf)
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, _ = fmt.Fprintf(p.w, "%s%s\n", p.prefix, bytes.TrimRight(line, "\r"))
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"troveler/config"
+	"troveler/db"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{mu: &sync.Mutex{}, w: &out, prefix: "[fd] "}
+
+	_, _ = w.Write([]byte("Downloading\r\nCompil"))
+	_, _ = w.Write([]byte("ing fd\npartial"))
+	w.Flush()
+
+	if want := "[fd] Downloading\n[fd] Compiling fd\n[fd] partial\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+// setupEchoTestDB seeds tools one, two and three whose linux install
+// commands echo; two's exits 3.
+func setupEchoTestDB(t *testing.T) *db.SQLiteDB {
+	t.Helper()
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	for _, slug := range []string{"one", "two", "three"} {
+		tool := &db.Tool{ID: "tool-" + slug, Slug: slug, Name: slug}
+		if err := database.UpsertTool(context.Background(), tool); err != nil {
+			t.Fatalf("Failed to seed tool: %v", err)
+		}
+		command := "echo installed " + slug
+		if slug == "two" {
+			command = "echo broken >&2; exit 3"
+		}
+		err := database.UpsertInstallInstruction(context.Background(), &db.InstallInstruction{
+			ID: "inst-" + slug, ToolID: tool.ID, Platform: "linux", Command: command,
+		})
+		if err != nil {
+			t.Fatalf("Failed to seed install: %v", err)
+		}
+	}
+
+	return database
+}
+
+func TestRunParallelBatch(t *testing.T) {
+	database := setupEchoTestDB(t)
+
+	cfg := &config.Config{Install: config.InstallConfig{PlatformOverride: "linux"}}
+	summary := &batchSummary{}
+	runParallelBatch(database, batchTools([]string{"one", "two", "three", "nope"}),
+		&BatchConfig{AlwaysRun: true}, 2, cfg, summary)
+
+	sort.Strings(summary.completed)
+	if strings.Join(summary.completed, ",") != "one,three" {
+		t.Errorf("completed = %v, want one and three", summary.completed)
+	}
+	if strings.Join(summary.failed, ",") != "nope,two" {
+		t.Errorf("failed = %v, want nope (planning) then two", summary.failed)
+	}
+
+	records, err := database.ListInstallHistory(db.HistoryFilter{})
+	if err != nil {
+		t.Fatalf("ListInstallHistory: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d history records, want 3", len(records))
+	}
+	for _, rec := range records {
+		if rec.Slug == "two" && (rec.ExitCode != 3 || !strings.Contains(rec.Output, "broken")) {
+			t.Errorf("record of two = exit %d %q", rec.ExitCode, rec.Output)
+		}
+	}
+}
+
+func TestInstallBatchFailsOnFailedTools(t *testing.T) {
+	database := setupEchoTestDB(t)
+	cfg := &config.Config{Install: config.InstallConfig{PlatformOverride: "linux"}}
+
+	for _, jobs := range []int{1, 2} {
+		err := installBatch(database, batchTools([]string{"one", "two", "three"}),
+			&BatchConfig{AlwaysRun: true}, true, jobs, cfg, nil)
+		if err == nil || err.Error() != "1 of 3 tools failed to install" {
+			t.Errorf("jobs=%d: error = %v, want 1 of 3 tools failed", jobs, err)
+		}
+	}
+
+	if err := installBatch(database, batchTools([]string{"one", "three"}),
+		&BatchConfig{AlwaysRun: true}, true, 2, cfg, nil); err != nil {
+		t.Errorf("successful batch failed: %v", err)
+	}
+}
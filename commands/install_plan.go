@@ -0,0 +1,309 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"troveler/config"
+	"troveler/db"
+	"troveler/internal/install"
+	"troveler/internal/platform"
+)
+
+// Install plan actions.
+const (
+	planRun   = "run"   // the command would be executed
+	planSkip  = "skip"  // --skip-if-blind skips the tool
+	planFail  = "fail"  // no command could be determined
+	planBlock = "block" // the install risk policy forbids the command
+)
+
+// Sudo decisions of an install plan.
+const (
+	sudoYes = "yes"
+	sudoNo  = "no"
+	sudoAsk = "ask" // install prompts for it (use_sudo = "ask")
+)
+
+// installPlan is what install --run would execute for one slug, as reported
+// by --dry-run and --plan.
+type installPlan struct {
+	Slug             string   `json:"slug"`
+	Action           string   `json:"action"`
+	Requested        string   `json:"requested_platform,omitempty"` // from the override, config or OS
+	Platform         string   `json:"platform,omitempty"`           // platform the command was taken from
+	UsedFallback     bool     `json:"used_fallback"`                // no instruction matched the requested platform
+	FallbackPlatform bool     `json:"fallback_platform"`            // fallback_platform supplied the command
+	LangMatch        bool     `json:"lang_match"`                   // matched by the tool's language (lang, mise_lang)
+	LangFallback     bool     `json:"lang_fallback"`                // synthesized for a tool without a language match
+	Mise             bool     `json:"mise"`                         // the catalog command was rewritten to mise use
+	Sudo             string   `json:"sudo"`
+	Source           string   `json:"source_command,omitempty"` // the catalog command
+	Command          string   `json:"command,omitempty"`        // what would be executed
+	Risk             string   `json:"risk,omitempty"`           // risk level of command
+	RiskClasses      []string `json:"risk_classes,omitempty"`
+	Confirm          bool     `json:"confirm"`          // the risk policy requires a confirmation
+	Reason           string   `json:"reason,omitempty"` // why nothing would be executed
+}
+
+// installPlanFlags are the install flags a plan depends on. In a batch the
+// prompts for sudo, skipping and mise are answered from them.
+type installPlanFlags struct {
+	Override       string
+	Sudo           bool
+	SudoOnlySystem bool
+	SkipIfBlind    bool
+	Mise           bool
+}
+
+// planInstalls resolves what install would execute for slugs without
+// executing or prompting. A single slug is planned like runInstall, several
+// like runBatchInstall.
+func planInstalls(
+	database *db.SQLiteDB, slugs []string, flags installPlanFlags, cfg *config.Config, detectedOS string,
+) []installPlan {
+	if len(slugs) == 1 {
+		cliOverride := flags.Override
+		if flags.Mise && cliOverride == "" {
+			cliOverride = PlatformMiseLang
+		}
+
+		return []installPlan{planSingleInstall(database, slugs[0], cliOverride, flags.Sudo, cfg, detectedOS)}
+	}
+
+	batchCfg := &BatchConfig{
+		UseSudo:        flags.Sudo,
+		SudoOnlySystem: flags.SudoOnlySystem,
+		SkipIfBlind:    flags.SkipIfBlind,
+		UseMise:        flags.Mise,
+		AlwaysRun:      cfg.Install.AlwaysRun,
+	}
+	plans := make([]installPlan, 0, len(slugs))
+	for _, bt := range batchTools(slugs) {
+		plans = append(plans, planBatchTool(database, bt, batchCfg, cfg, detectedOS))
+	}
+
+	return plans
+}
+
+// planSingleInstall plans a single-tool install --run.
+func planSingleInstall(
+	database *db.SQLiteDB, slug, cliOverride string, sudoFlag bool, cfg *config.Config, detectedOS string,
+) installPlan {
+	plan := installPlan{Slug: slug, Action: planFail, Sudo: sudoNo}
+
+	tool, installs, err := lookupInstall(database, slug)
+	if err != nil {
+		plan.Reason = err.Error()
+
+		return plan
+	}
+
+	res := resolveSingleInstall(tool, installs, cliOverride, cfg.Install.PlatformOverride,
+		cfg.Install.FallbackPlatform, detectedOS)
+	plan.Requested = res.requested
+	plan.UsedFallback = res.usedFallback
+	plan.FallbackPlatform = res.fallbackPlatform
+	plan.LangMatch = res.langMatch
+	plan.LangFallback = res.langFallback
+	if len(res.matched) == 0 {
+		plan.Reason = "no install command found for " + res.requested
+
+		return plan
+	}
+
+	cmd, useSudo := res.command(sudoFlag, cfg.Install.UseSudo)
+	plan.Action, plan.Platform = planRun, res.platformID
+	plan.Source = res.matched[0].Command
+	plan.Mise = cmd != plan.Source
+	plan.Sudo = sudoDecision(sudoFlag, useSudo)
+	plan.Command = withSudo(cmd, plan.Sudo == sudoYes)
+	plan.assessRisk(cfg)
+
+	return plan
+}
+
+// planBatchTool plans one tool of a batch install. installSingleTool runs
+// what it returns.
+func planBatchTool(
+	database *db.SQLiteDB, bt batchTool, batchCfg *BatchConfig, cfg *config.Config, detectedOS string,
+) installPlan {
+	plan := installPlan{Slug: bt.Slug, Action: planFail, Sudo: sudoNo}
+	blind := func(reason string) installPlan {
+		plan.Reason = reason
+		if batchCfg != nil && batchCfg.SkipIfBlind {
+			plan.Action = planSkip
+		}
+
+		return plan
+	}
+
+	tools, err := database.GetToolBySlug(bt.Slug)
+	if err != nil || len(tools) == 0 {
+		plan.Reason = "tool not found: " + bt.Slug
+
+		return plan
+	}
+
+	tool := tools[0]
+	installs, err := database.GetInstallInstructions(tool.ID)
+	if err != nil || len(installs) == 0 {
+		return blind("no install instructions available")
+	}
+
+	result, requested := resolveBatchPlatform(&tool, installs, bt, cfg, detectedOS)
+	plan.Requested = requested
+	plan.UsedFallback = result.UsedFallback
+	plan.FallbackPlatform = !result.UsedFallback && result.PlatformID != requested
+	plan.LangMatch = platform.IsLangPlatform(result.PlatformID)
+	if result.UsedFallback || len(result.Installs) == 0 {
+		return blind("no compatible install method for " + result.PlatformID)
+	}
+
+	matched := result.Installs[0]
+	cmd := matched.Command
+	if bt.Mise || (batchCfg != nil && batchCfg.UseMise) {
+		cmd = install.TransformToMise(cmd)
+	}
+
+	plan.Action, plan.Platform = planRun, matched.Platform
+	plan.Source = matched.Command
+	plan.Mise = cmd != plan.Source
+	if batchCfg != nil && (batchCfg.UseSudo || (batchCfg.SudoOnlySystem && isSystemPM(matched.Platform))) {
+		plan.Sudo = sudoYes
+	}
+	plan.Command = withSudo(cmd, plan.Sudo == sudoYes)
+	plan.assessRisk(cfg)
+
+	return plan
+}
+
+// assessRisk records the risk of the planned command and applies the risk
+// policy of cfg to it: blocked commands turn the plan into planBlock, and an
+// invalid policy fails it.
+func (p *installPlan) assessRisk(cfg *config.Config) {
+	risk := install.AnalyzeCommand(p.Command)
+	p.Risk = risk.Level.String()
+	for _, c := range risk.Classes {
+		p.RiskClasses = append(p.RiskClasses, string(c))
+	}
+
+	policy, err := riskPolicy(cfg)
+	if err != nil {
+		p.Action, p.Reason = planFail, err.Error()
+
+		return
+	}
+
+	decision, classes := policy.Decide(risk)
+	switch decision {
+	case install.RiskBlock:
+		p.Action = planBlock
+		p.Reason = (&install.RiskBlockedError{Classes: classes}).Error()
+	case install.RiskConfirm:
+		p.Confirm = true
+	}
+}
+
+// sudoDecision settles sudo for a single-tool install: --sudo, then the
+// use_sudo setting.
+func sudoDecision(sudoFlag bool, useSudo string) string {
+	switch {
+	case sudoFlag || useSudo == "true":
+		return sudoYes
+	case useSudo == "ask":
+		return sudoAsk
+	default:
+		return sudoNo
+	}
+}
+
+func withSudo(command string, useSudo bool) string {
+	if useSudo {
+		return "sudo " + command
+	}
+
+	return command
+}
+
+func printInstallPlans(w io.Writer, plans []installPlan, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(plans)
+	}
+
+	runStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	skipStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500"))
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
+
+	for _, plan := range plans {
+		switch plan.Action {
+		case planRun:
+			_, _ = fmt.Fprintf(w, "%s %s (%s)\n", runStyle.Render("✓"), plan.Slug, plan.Platform)
+			_, _ = fmt.Fprintf(w, "    %s\n", plan.Command)
+			if notes := planNotes(plan); len(notes) > 0 {
+				_, _ = fmt.Fprintf(w, "    %s\n", strings.Join(notes, ", "))
+			}
+		case planSkip:
+			_, _ = fmt.Fprintf(w, "%s %s skipped: %s\n", skipStyle.Render("○"), plan.Slug, plan.Reason)
+		case planBlock:
+			_, _ = fmt.Fprintf(w, "%s %s: %s\n", failStyle.Render("⊘"), plan.Slug, plan.Reason)
+			_, _ = fmt.Fprintf(w, "    %s\n", plan.Command)
+		default:
+			_, _ = fmt.Fprintf(w, "%s %s: %s\n", failStyle.Render("✗"), plan.Slug, plan.Reason)
+		}
+	}
+
+	return nil
+}
+
+// planNotes lists how a planned command deviates from a plain match of the
+// requested platform.
+func planNotes(plan installPlan) []string {
+	var notes []string
+	if plan.FallbackPlatform {
+		notes = append(notes, fmt.Sprintf("fallback_platform (nothing for %s)", plan.Requested))
+	}
+	if plan.LangMatch {
+		notes = append(notes, "matched by language")
+	}
+	if plan.LangFallback {
+		notes = append(notes, "language fallback")
+	}
+	if plan.Mise {
+		notes = append(notes, "mise from: "+plan.Source)
+	}
+	if plan.Sudo == sudoAsk {
+		notes = append(notes, "sudo: ask")
+	}
+	if len(plan.RiskClasses) > 0 {
+		notes = append(notes, fmt.Sprintf("risk %s: %s", plan.Risk, strings.Join(plan.RiskClasses, ", ")))
+	}
+	if plan.Confirm {
+		notes = append(notes, "needs confirmation")
+	}
+
+	return notes
+}
+
+// lookupInstall loads the tool with slug and its install instructions.
+func lookupInstall(database *db.SQLiteDB, slug string) (*db.Tool, []db.InstallInstruction, error) {
+	tools, err := database.GetToolBySlug(slug)
+	if err != nil || len(tools) == 0 {
+		return nil, nil, fmt.Errorf("tool not found: %s", slug)
+	}
+
+	tool := tools[0]
+	installs, err := database.GetInstallInstructions(tool.ID)
+	if err != nil || len(installs) == 0 {
+		return nil, nil, fmt.Errorf("no install instructions available for %s", slug)
+	}
+
+	return &tool, installs, nil
+}
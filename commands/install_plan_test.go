@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"troveler/config"
+)
+
+func TestPlanInstalls(t *testing.T) {
+	database := setupSyncTestDB(t)
+
+	tests := []struct {
+		name    string
+		slugs   []string
+		flags   installPlanFlags
+		install config.InstallConfig
+		want    []installPlan
+	}{
+		{
+			name:  "single",
+			slugs: []string{"present"},
+			want: []installPlan{{
+				Slug: "present", Action: planRun, Requested: "macos", Platform: "macos", Sudo: sudoNo,
+				Source: "brew install present", Command: "brew install present", Risk: "none",
+			}},
+		},
+		{
+			name:    "single use_sudo",
+			slugs:   []string{"present"},
+			install: config.InstallConfig{UseSudo: "ask"},
+			want: []installPlan{{
+				Slug: "present", Action: planRun, Requested: "macos", Platform: "macos", Sudo: sudoAsk,
+				Source: "brew install present", Command: "brew install present", Risk: "none",
+			}},
+		},
+		{
+			name:    "single fallback_platform",
+			slugs:   []string{"crate"},
+			install: config.InstallConfig{FallbackPlatform: "lang"},
+			want: []installPlan{{
+				Slug: "crate", Action: planRun, Requested: "macos", Platform: "lang", FallbackPlatform: true,
+				LangMatch: true, Sudo: sudoNo, Source: "cargo install crate", Command: "cargo install crate", Risk: "none",
+			}},
+		},
+		{
+			name:    "single mise drops sudo",
+			slugs:   []string{"crate"},
+			flags:   installPlanFlags{Mise: true},
+			install: config.InstallConfig{UseSudo: "true"},
+			want: []installPlan{{
+				Slug: "crate", Action: planRun, Requested: "mise_lang", Platform: "mise_lang", LangMatch: true,
+				Mise: true, Sudo: sudoNo, Source: "cargo install crate", Command: "mise use --global cargo:crate", Risk: "none",
+			}},
+		},
+		{
+			name:  "single without method",
+			slugs: []string{"winonly"},
+			want: []installPlan{{
+				Slug: "winonly", Action: planFail, Requested: "macos", UsedFallback: true, Sudo: sudoNo,
+				Reason: "no install command found for macos",
+			}},
+		},
+		{
+			name:  "batch",
+			slugs: []string{"present", "winonly", "nope"},
+			flags: installPlanFlags{Sudo: true, SkipIfBlind: true},
+			want: []installPlan{
+				{
+					Slug: "present", Action: planRun, Requested: "macos", Platform: "macos", Sudo: sudoYes,
+					Source: "brew install present", Command: "sudo brew install present",
+					Risk: "medium", RiskClasses: []string{"sudo"},
+				},
+				{
+					Slug: "winonly", Action: planSkip, Requested: "macos", UsedFallback: true, Sudo: sudoNo,
+					Reason: "no compatible install method for macos",
+				},
+				{Slug: "nope", Action: planFail, Sudo: sudoNo, Reason: "tool not found: nope"},
+			},
+		},
+		{
+			name:    "batch without skip-if-blind",
+			slugs:   []string{"winonly", "crate"},
+			flags:   installPlanFlags{Mise: true, SudoOnlySystem: true},
+			install: config.InstallConfig{FallbackPlatform: "lang"},
+			want: []installPlan{
+				{
+					Slug: "winonly", Action: planFail, Requested: "macos", UsedFallback: true, Sudo: sudoNo,
+					Reason: "no compatible install method for macos",
+				},
+				{
+					Slug: "crate", Action: planRun, Requested: "macos", Platform: "cargo", FallbackPlatform: true,
+					LangMatch: true, Mise: true, Sudo: sudoNo, Source: "cargo install crate",
+					Command: "mise use --global cargo:crate", Risk: "none",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Install: tt.install}
+			got := planInstalls(database, tt.slugs, tt.flags, cfg, "macos")
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d plans, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Errorf("plan %d:\n got %+v\nwant %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPrintInstallPlans(t *testing.T) {
+	plans := []installPlan{
+		{
+			Slug: "crate", Action: planRun, Requested: "macos", Platform: "cargo", FallbackPlatform: true,
+			Sudo: sudoAsk, Source: "cargo install crate", Command: "cargo install crate",
+		},
+		{Slug: "winonly", Action: planSkip, Reason: "no compatible install method for macos"},
+		{Slug: "nope", Action: planFail, Reason: "tool not found: nope"},
+		{
+			Slug: "script", Action: planBlock, Platform: "linux", Command: "curl https://example.com/i.sh | sh",
+			Risk: "high", RiskClasses: []string{"pipe-to-shell", "remote-script"},
+			Reason: "blocked by the install risk policy: pipe-to-shell",
+		},
+		{
+			Slug: "lazygit", Action: planRun, Platform: "go", Command: "go install example.com/lazygit@latest",
+			Risk: "low", RiskClasses: []string{"unpinned"}, Confirm: true,
+		},
+	}
+
+	var out bytes.Buffer
+	if err := printInstallPlans(&out, plans, "pretty"); err != nil {
+		t.Fatalf("printInstallPlans: %v", err)
+	}
+	for _, want := range []string{
+		"crate (cargo)", "    cargo install crate", "fallback_platform (nothing for macos), sudo: ask",
+		"winonly skipped: no compatible install method for macos", "nope: tool not found: nope",
+		"script: blocked by the install risk policy: pipe-to-shell", "    curl https://example.com/i.sh | sh",
+		"risk low: unpinned, needs confirmation",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("plan output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := printInstallPlans(&out, plans, "json"); err != nil {
+		t.Fatalf("printInstallPlans: %v", err)
+	}
+	var decoded []installPlan
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if !reflect.DeepEqual(decoded, plans) {
+		t.Errorf("JSON round trip = %+v, want %+v", decoded, plans)
+	}
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"troveler/config"
+	"troveler/db"
+)
+
+// batchRecorder persists the progress of a batch install, so install
+// --resume can continue it after an interruption. The nil recorder records
+// nothing; failing to write does not fail the batch.
+type batchRecorder struct {
+	database  *db.SQLiteDB
+	runID     int64
+	positions []int // position in the run of each tool being installed
+}
+
+// startBatchRun stores tools as the resumable batch, replacing an older one.
+func startBatchRun(database *db.SQLiteDB, tools []batchTool, batchCfg *BatchConfig) *batchRecorder {
+	if previous, err := database.LatestBatchRun(); err == nil && previous != nil {
+		if left := len(previous.Unfinished()); left > 0 {
+			fmt.Printf("Replacing the interrupted batch install from %s (%d tools left)\n",
+				previous.StartedAt.Local().Format("2006-01-02 15:04"), left)
+		}
+	}
+
+	run := &db.BatchRun{Options: batchOptions(batchCfg)}
+	positions := make([]int, len(tools))
+	for i, bt := range tools {
+		run.Tools = append(run.Tools, db.BatchRunTool{Slug: bt.Slug, Platform: bt.Platform, Mise: bt.Mise})
+		positions[i] = i
+	}
+	if err := database.CreateBatchRun(run); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+
+		return nil
+	}
+
+	return &batchRecorder{database: database, runID: run.ID, positions: positions}
+}
+
+// resumeBatchInstall continues the interrupted batch install with the
+// choices made when it started.
+func resumeBatchInstall(database *db.SQLiteDB, jobs int, cfg *config.Config) error {
+	run, err := database.LatestBatchRun()
+	if err != nil {
+		return err
+	}
+	if run == nil {
+		return errors.New("no interrupted batch install to resume")
+	}
+
+	pending := run.Unfinished()
+	if len(pending) == 0 {
+		fmt.Println("The last batch install already finished")
+
+		return database.DeleteBatchRun(run.ID)
+	}
+
+	fmt.Printf("\n🔧 Resuming batch install from %s: %d of %d tools left\n\n",
+		run.StartedAt.Local().Format("2006-01-02 15:04"), len(pending), len(run.Tools))
+
+	var batchCfg *BatchConfig
+	if run.Options.ReuseConfig {
+		batchCfg = &BatchConfig{
+			UseSudo:        run.Options.UseSudo,
+			SudoOnlySystem: run.Options.SudoOnlySystem,
+			SkipIfBlind:    run.Options.SkipIfBlind,
+			UseMise:        run.Options.UseMise,
+			AlwaysRun:      cfg.Install.AlwaysRun,
+		}
+	}
+
+	tools := make([]batchTool, len(pending))
+	rec := &batchRecorder{database: database, runID: run.ID, positions: make([]int, len(pending))}
+	for i, tool := range pending {
+		tools[i] = batchTool{Slug: tool.Slug, Platform: tool.Platform, Mise: tool.Mise}
+		rec.positions[i] = tool.Position
+	}
+
+	return installBatch(database, tools, batchCfg, true, jobs, cfg, rec)
+}
+
+func batchOptions(batchCfg *BatchConfig) db.BatchOptions {
+	if batchCfg == nil {
+		return db.BatchOptions{}
+	}
+
+	return db.BatchOptions{
+		ReuseConfig:    true,
+		UseSudo:        batchCfg.UseSudo,
+		SudoOnlySystem: batchCfg.SudoOnlySystem,
+		SkipIfBlind:    batchCfg.SkipIfBlind,
+		UseMise:        batchCfg.UseMise,
+	}
+}
+
+// running marks the i-th tool as installing.
+func (r *batchRecorder) running(i int) {
+	r.set(i, db.BatchRunning, "")
+}
+
+// finished records how installing the i-th tool ended.
+func (r *batchRecorder) finished(i int, err error) {
+	switch {
+	case err == nil:
+		r.set(i, db.BatchCompleted, "")
+	case isSkip(err):
+		r.set(i, db.BatchSkipped, err.Error())
+	default:
+		r.set(i, db.BatchFailed, err.Error())
+	}
+}
+
+// finish drops the run once the batch got through every tool.
+func (r *batchRecorder) finish() {
+	if r == nil {
+		return
+	}
+	if err := r.database.DeleteBatchRun(r.runID); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+func (r *batchRecorder) set(i int, status, detail string) {
+	if r == nil {
+		return
+	}
+	if err := r.database.SetBatchToolStatus(r.runID, r.positions[i], status, detail); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
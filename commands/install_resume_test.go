@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"troveler/config"
+	"troveler/db"
+)
+
+func TestResumeBatchInstall(t *testing.T) {
+	database := setupEchoTestDB(t)
+	cfg := &config.Config{Install: config.InstallConfig{PlatformOverride: "linux", AlwaysRun: true}}
+
+	if err := resumeBatchInstall(database, 1, cfg); err == nil {
+		t.Fatal("expected an error without an interrupted batch")
+	}
+
+	// one finished and three was cut off mid-install before the interruption
+	rec := startBatchRun(database, batchTools([]string{"one", "two", "three"}), &BatchConfig{SkipIfBlind: true})
+	if rec == nil {
+		t.Fatal("startBatchRun did not record the run")
+	}
+	rec.finished(0, nil)
+	rec.running(2)
+
+	// two exits 3, which fails the resumed batch.
+	if err := resumeBatchInstall(database, 2, cfg); err == nil || err.Error() != "1 of 2 tools failed to install" {
+		t.Fatalf("resumeBatchInstall error = %v, want 1 of 2 tools failed", err)
+	}
+
+	records, err := database.ListInstallHistory(db.HistoryFilter{})
+	if err != nil {
+		t.Fatalf("ListInstallHistory: %v", err)
+	}
+	installed := map[string]bool{}
+	for _, r := range records {
+		installed[r.Slug] = true
+	}
+	if len(records) != 2 || !installed["two"] || !installed["three"] {
+		t.Errorf("expected two and three to be installed on resume, got %+v", records)
+	}
+
+	if run, err := database.LatestBatchRun(); err != nil || run != nil {
+		t.Errorf("expected the finished batch to be dropped, got %+v (%v)", run, err)
+	}
+}
+
+func TestBatchRecorderStatuses(t *testing.T) {
+	database := setupEchoTestDB(t)
+
+	rec := startBatchRun(database, []batchTool{{Slug: "one"}, {Slug: "two", Platform: "brew", Mise: true}}, nil)
+	rec.finished(0, errAborted)
+	rec.finished(1, errors.New("skipped: user declined"))
+
+	run, err := database.LatestBatchRun()
+	if err != nil || run == nil {
+		t.Fatalf("LatestBatchRun: %+v (%v)", run, err)
+	}
+	if run.Options.ReuseConfig {
+		t.Error("expected a per-tool batch to be stored without reuse_config")
+	}
+	if run.Tools[0].Status != db.BatchFailed || run.Tools[0].Detail != "aborted" {
+		t.Errorf("tool one = %+v, want failed", run.Tools[0])
+	}
+	if run.Tools[1].Status != db.BatchSkipped || run.Tools[1].Platform != "brew" || !run.Tools[1].Mise {
+		t.Errorf("tool two = %+v, want skipped with its pins", run.Tools[1])
+	}
+
+	var none *batchRecorder
+	none.running(0)
+	none.finish()
+}
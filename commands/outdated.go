@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"troveler/config"
+	"troveler/db"
+	"troveler/internal/install"
+	"troveler/internal/upgrade"
+)
+
+var outdatedJSON bool
+var outdatedRefresh bool
+var outdatedAll bool
+
+// OutdatedCmd reports installed tools with a newer upstream release.
+var OutdatedCmd = &cobra.Command{
+	Use:   "outdated [slug...]",
+	Short: "List installed tools with a newer release",
+	Long: `List installed tools with a newer upstream release.
+
+The installed version is asked from the package manager that owns the binary
+(cargo, brew, npm, pipx, dpkg, ...), judged by where it lives on disk, and
+otherwise read from "<exe> --version". The latest version is the newest GitHub
+release of the tool's code repository; set GITHUB_TOKEN to raise the API rate
+limit.
+
+Checks are stored and reused for a day; --refresh checks again. The TUI marks
+outdated tools in the tools panel.`,
+	Example: "  troveler outdated\n" +
+		"  troveler outdated --all --refresh\n" +
+		"  troveler outdated ripgrep fd --json",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return WithDB(cmd, func(ctx context.Context, database *db.SQLiteDB) error {
+			targets, err := upgradeTargets(ctx, database, args, GetConfig(ctx), detectOSID())
+			if err != nil {
+				return err
+			}
+			if len(targets) == 0 {
+				fmt.Println("No installed tools to check")
+
+				return nil
+			}
+
+			results := upgrade.NewChecker().CheckAll(ctx, database, targets, outdatedRefresh)
+
+			if outdatedJSON {
+				return outputOutdatedJSON(os.Stdout, results, outdatedAll)
+			}
+			printOutdated(os.Stdout, results, outdatedAll)
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	OutdatedCmd.Flags().BoolVarP(&outdatedJSON, "json", "j", false, "Output in JSON format")
+	OutdatedCmd.Flags().BoolVar(&outdatedRefresh, "refresh", false, "Ignore stored checks and check again")
+	OutdatedCmd.Flags().BoolVarP(&outdatedAll, "all", "a", false, "Also list tools that are up to date or unknown")
+}
+
+// upgradeTargets returns the installed tools among slugs (every installed
+// tool when slugs is empty) with the command each was installed with.
+func upgradeTargets(
+	ctx context.Context, database *db.SQLiteDB, slugs []string, cfg *config.Config, detectedOS string,
+) ([]upgrade.Target, error) {
+	var tools []db.Tool
+	if len(slugs) == 0 {
+		all, err := database.GetAllTools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tools: %w", err)
+		}
+		tools = all
+	} else {
+		for _, slug := range slugs {
+			found, err := database.GetToolBySlug(slug)
+			if err != nil || len(found) == 0 {
+				return nil, fmt.Errorf("tool not found: %s", slug)
+			}
+			tools = append(tools, found[0])
+		}
+	}
+
+	ids := make([]string, len(tools))
+	for i, t := range tools {
+		ids[i] = t.ID
+	}
+	installsByTool, err := database.GetInstallInstructionsBatch(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load install instructions: %w", err)
+	}
+	pathCache := db.BuildLookPathCache(installsByTool)
+
+	var targets []upgrade.Target
+	for i := range tools {
+		tool := tools[i]
+		installs := installsByTool[tool.ID]
+		if !db.IsInstalledCached(&tool, installs, pathCache) {
+			continue
+		}
+
+		target := upgrade.Target{Tool: tool, Installs: installs}
+		if plan, _ := planUpgrade(database, &tool, installs, cfg, detectedOS); plan != nil {
+			target.InstallCommand = plan.InstallCommand
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// planUpgrade derives the upgrade command for tool like planUninstall does
+// the uninstall command.
+func planUpgrade(
+	database *db.SQLiteDB, tool *db.Tool, installs []db.InstallInstruction, cfg *config.Config, detectedOS string,
+) (*install.UninstallPlan, error) {
+	var fallback *db.InstallInstruction
+	if matched, err := resolveBatchInstall(tool, installs, batchTool{Slug: tool.Slug}, cfg, detectedOS); err == nil {
+		fallback = &matched
+	}
+
+	return install.PlanUpgrade(database, tool.Slug, fallback)
+}
+
+func outputOutdatedJSON(w io.Writer, results []upgrade.Result, all bool) error {
+	versions := []db.ToolVersion{}
+	for _, r := range results {
+		if all || r.Outdated {
+			versions = append(versions, r.ToolVersion)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(versions)
+}
+
+func printOutdated(w io.Writer, results []upgrade.Result, all bool) {
+	outdatedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500"))
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	outdated := 0
+	for _, r := range results {
+		if r.Outdated {
+			outdated++
+		}
+		if !all && !r.Outdated {
+			continue
+		}
+
+		status := mutedStyle.Render("unknown")
+		switch {
+		case r.Outdated:
+			status = outdatedStyle.Render("outdated")
+		case r.Err != nil:
+			status = mutedStyle.Render(r.Err.Error())
+		case r.Installed != "" && r.Latest != "":
+			status = okStyle.Render("up to date")
+		}
+
+		_, _ = fmt.Fprintf(w, "%-20s %-14s → %-14s %s\n",
+			r.Slug, versionOrUnknown(r.Installed), versionOrUnknown(r.Latest), status)
+	}
+
+	if outdated == 0 {
+		_, _ = fmt.Fprintf(w, "All %d installed tools are up to date (or have no known release)\n", len(results))
+
+		return
+	}
+	_, _ = fmt.Fprintf(w, "\n%d of %d installed tools are outdated; run troveler upgrade\n", outdated, len(results))
+}
+
+func versionOrUnknown(version string) string {
+	if version == "" {
+		return "?"
+	}
+
+	return version
+}
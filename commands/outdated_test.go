@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"troveler/config"
+	"troveler/db"
+	"troveler/internal/upgrade"
+)
+
+func TestUpgradeTargets(t *testing.T) {
+	database := setupSyncTestDB(t)
+	cfg := &config.Config{}
+
+	targets, err := upgradeTargets(context.Background(), database, nil, cfg, "macos")
+	if err != nil {
+		t.Fatalf("upgradeTargets failed: %v", err)
+	}
+	if len(targets) != 1 || targets[0].Tool.Slug != "present" || targets[0].InstallCommand != "brew install present" {
+		t.Fatalf("expected only the installed tool with its catalog command, got %+v", targets)
+	}
+
+	if _, err := upgradeTargets(context.Background(), database, []string{"missing"}, cfg, "macos"); err == nil {
+		t.Error("expected error for an unknown slug")
+	}
+
+	// A recorded install wins; the upgrades it went through do not replace it.
+	for _, rec := range []*db.InstallRecord{
+		{Action: db.ActionInstall, Slug: "present", Platform: "cargo", Command: "cargo install --locked present"},
+		{Action: db.ActionUpgrade, Slug: "present", Platform: "cargo", Command: "cargo install --locked present"},
+	} {
+		if err := database.RecordInstall(rec); err != nil {
+			t.Fatalf("RecordInstall failed: %v", err)
+		}
+	}
+
+	tool := targets[0].Tool
+	plan, err := planUpgrade(database, &tool, targets[0].Installs, cfg, "macos")
+	if err != nil {
+		t.Fatalf("planUpgrade failed: %v", err)
+	}
+	if !plan.FromHistory || plan.Command != "cargo install --locked present" || plan.Platform != "cargo" {
+		t.Errorf("unexpected upgrade plan: %+v", plan)
+	}
+}
+
+func TestPrintOutdated(t *testing.T) {
+	results := []upgrade.Result{
+		{ToolVersion: db.ToolVersion{Slug: "ripgrep", Installed: "13.0.0", Latest: "14.1.0", Outdated: true}},
+		{ToolVersion: db.ToolVersion{Slug: "fd", Installed: "10.1.0", Latest: "10.1.0"}},
+		{ToolVersion: db.ToolVersion{Slug: "mytool"}},
+	}
+
+	var buf bytes.Buffer
+	printOutdated(&buf, results, false)
+	out := buf.String()
+	if !strings.Contains(out, "ripgrep") || strings.Contains(out, "fd ") || !strings.Contains(out, "1 of 3") {
+		t.Errorf("expected only ripgrep listed, got:\n%s", out)
+	}
+
+	buf.Reset()
+	printOutdated(&buf, results, true)
+	out = buf.String()
+	if !strings.Contains(out, "up to date") || !strings.Contains(out, "mytool") {
+		t.Errorf("expected every tool listed with --all, got:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := outputOutdatedJSON(&buf, results[1:], false); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("expected an empty JSON list, got %q (%v)", buf.String(), err)
+	}
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"troveler/db"
+	"troveler/internal/search"
+)
+
+// QueryCmd manages saved search expressions.
+var QueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Manage saved searches",
+	Long: `Manage saved searches.
+
+A saved query is a named search expression. Run it with "troveler search @name",
+or pick it from the TUI with Ctrl+R in the search panel.`,
+}
+
+var querySaveCmd = &cobra.Command{
+	Use:   "save <name> <expression>",
+	Short: "Save a search expression under a name",
+	Args:  cobra.MinimumNArgs(2),
+	Example: "  troveler query save rusty-todo \"(language=go|language=rust)&!installed=true\"\n" +
+		"  troveler search @rusty-todo",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		expression := strings.Join(args[1:], " ")
+
+		warning, err := validateQueryExpression(expression)
+		if err != nil {
+			return err
+		}
+
+		return WithDB(cmd, func(_ context.Context, database *db.SQLiteDB) error {
+			if err := database.SaveQuery(name, expression); err != nil {
+				return fmt.Errorf("failed to save query: %w", err)
+			}
+			fmt.Printf("Saved query '@%s': %s\n", strings.TrimPrefix(name, "@"), expression)
+			if warning != "" {
+				fmt.Println(lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#87CEEB")).
+					Render(warning))
+			}
+
+			return nil
+		})
+	},
+}
+
+var queryListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List saved queries",
+	Args:    cobra.NoArgs,
+	Example: "  troveler query list",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		return WithDB(cmd, func(_ context.Context, database *db.SQLiteDB) error {
+			return listSavedQueries(database, jsonOutput)
+		})
+	},
+}
+
+var queryRemoveCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove a saved query",
+	Args:    cobra.ExactArgs(1),
+	Example: "  troveler query rm rusty-todo",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		return WithDB(cmd, func(_ context.Context, database *db.SQLiteDB) error {
+			if err := database.DeleteSavedQuery(name); err != nil {
+				return fmt.Errorf("failed to remove query: %w", err)
+			}
+			fmt.Printf("Removed query '@%s'\n", strings.TrimPrefix(name, "@"))
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	queryListCmd.Flags().BoolP("json", "j", false, "Output in JSON format")
+	QueryCmd.AddCommand(querySaveCmd)
+	QueryCmd.AddCommand(queryListCmd)
+	QueryCmd.AddCommand(queryRemoveCmd)
+}
+
+// validateQueryExpression rejects expressions that would fail every time
+// they run, and returns the parser's warning for ones that degrade to a
+// plain search term.
+func validateQueryExpression(expression string) (string, error) {
+	if refs := search.SavedQueryRefs(expression); len(refs) > 0 {
+		return "", fmt.Errorf("saved queries cannot reference other saved queries (@%s)", refs[0])
+	}
+
+	_, _, warning, err := search.ParseFilters(expression)
+	if err != nil {
+		return "", fmt.Errorf("invalid query: %w", err)
+	}
+
+	return warning, nil
+}
+
+func listSavedQueries(database *db.SQLiteDB, jsonOutput bool) error {
+	queries, err := database.ListSavedQueries()
+	if err != nil {
+		return fmt.Errorf("failed to list queries: %w", err)
+	}
+
+	if len(queries) == 0 {
+		if jsonOutput {
+			fmt.Println("[]")
+		} else {
+			fmt.Println("No saved queries")
+		}
+
+		return nil
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(queries)
+	}
+
+	fmt.Println()
+	title := fmt.Sprintf("Saved queries (%d)", len(queries))
+	fmt.Println(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#00FFFF")).
+		Render(title))
+	fmt.Println(strings.Repeat("─", len(title)))
+	fmt.Println()
+
+	for _, q := range queries {
+		fmt.Printf("  @%-20s %s\n", q.Name, q.Expression)
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package commands
+
+import "testing"
+
+func TestValidateQueryExpression(t *testing.T) {
+	tests := []struct {
+		expression  string
+		wantErr     bool
+		wantWarning bool
+	}{
+		{expression: "(language=go|language=rust)&!installed=true"},
+		{expression: "json tag=cli"},
+		{expression: "lang=go", wantErr: true},
+		{expression: "@other language=go", wantErr: true},
+		{expression: "(@other)", wantErr: true},
+		{expression: "!@other", wantErr: true},
+		{expression: "language=go&(@other|tag=cli)", wantErr: true},
+		{expression: "name=foo@bar"},
+		{expression: "model &", wantWarning: true},
+	}
+
+	for _, tt := range tests {
+		warning, err := validateQueryExpression(tt.expression)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateQueryExpression(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+		}
+		if (warning != "") != tt.wantWarning {
+			t.Errorf("validateQueryExpression(%q) warning = %q, wantWarning %v", tt.expression, warning, tt.wantWarning)
+		}
+	}
+}
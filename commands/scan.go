@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"troveler/db"
+)
+
+var scanJSON bool
+
+// ScanCmd rescans PATH for installed tools.
+var ScanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Rescan PATH for installed tools",
+	Long: `Look up every tool's executable on PATH and store what was found, along
+with the package manager that owns it.
+
+Searches read installed= from this stored state and never rescan; they warn
+when it is older than 15 minutes. Updates, and installs and uninstalls made
+through troveler, refresh the tools they touch. Run scan after installing or
+removing tools by other means.`,
+	Args:    cobra.NoArgs,
+	Example: "  troveler scan\n  troveler scan --json",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return WithDB(cmd, func(ctx context.Context, database *db.SQLiteDB) error {
+			result, err := database.ScanInstalled(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to scan installed tools: %w", err)
+			}
+
+			if scanJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+
+				return encoder.Encode(result)
+			}
+
+			printScan(os.Stdout, result)
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	ScanCmd.Flags().BoolVarP(&scanJSON, "json", "j", false, "Output in JSON format")
+}
+
+func printScan(w io.Writer, result *db.ScanResult) {
+	addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	removedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5555"))
+
+	for _, slug := range result.Added {
+		_, _ = fmt.Fprintf(w, "  %s %s\n", addedStyle.Render("+"), slug)
+	}
+	for _, slug := range result.Removed {
+		_, _ = fmt.Fprintf(w, "  %s %s\n", removedStyle.Render("-"), slug)
+	}
+
+	_, _ = fmt.Fprintf(w, "%d of %d tools installed (%d new, %d gone)\n",
+		result.Installed, result.Tools, len(result.Added), len(result.Removed))
+}
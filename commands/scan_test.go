@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"troveler/db"
+)
+
+func TestPrintScan(t *testing.T) {
+	var out bytes.Buffer
+	printScan(&out, &db.ScanResult{Tools: 10, Installed: 3, Added: []string{"ripgrep"}, Removed: []string{"fd"}})
+	got := out.String()
+
+	for _, want := range []string{"+ ripgrep", "- fd", "3 of 10 tools installed (1 new, 1 gone)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("scan output missing %q:\n%s", want, got)
+		}
+	}
+}
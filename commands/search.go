@@ -23,7 +23,11 @@ var SearchCmd = &cobra.Command{
 	Args:  cobra.MinimumNArgs(1),
 	Example: "troveler search go-cli --limit 10 --sort language --desc --width 40\n\n" +
 		"troveler search tagline=cli\n" +
+		"troveler search ~ripgerp\n" +
+		"troveler search \"published>2024-01-01&name~/^rip/\"\n" +
 		"troveler search installed=true\n" +
+		"troveler search installed=cargo\n" +
+		"troveler search @rusty-todo\n" +
 		"troveler search \"name=bat | name=batcat\"\n" +
 		"troveler search \"(name=git|tagline=git)&language=go\"",
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -64,7 +68,8 @@ var SearchCmd = &cobra.Command{
 
 func init() {
 	SearchCmd.Flags().IntP("limit", "l", 0, "Limit number of results to display (0 for default: 50)")
-	SearchCmd.Flags().StringP("sort", "s", "name", "Sort field (name, tagline, language)")
+	SearchCmd.Flags().StringP("sort", "s", "",
+		"Sort field (relevance, name, tagline, language; default: relevance for free text, else name)")
 	SearchCmd.Flags().BoolP("desc", "d", false, "Sort in descending order")
 	SearchCmd.Flags().IntP("width", "w", 0, "Tagline column width in characters (0 for config default)")
 	SearchCmd.Flags().StringP("format", "f", "pretty", "Output format (pretty, json)")
@@ -79,6 +84,7 @@ var searchColumns = []searchColumn{
 	{"Name", "name"},
 	{"Tagline", "tagline"},
 	{"Language", "language"},
+	{"Source", "source"},
 	{"Installed", "installed"},
 }
 
@@ -100,6 +106,16 @@ func runSearch(
 
 	results := result.Tools
 	filterWarning := result.FilterWarning
+	opts.SortField = result.SortField
+	opts.SortOrder = result.SortOrder
+	if result.Fuzzy {
+		filterWarning = joinWarnings(filterWarning,
+			fmt.Sprintf("Showing fuzzy matches for '%s'", result.Query))
+	}
+	if result.InstalledStale {
+		filterWarning = joinWarnings(filterWarning,
+			"Installed status may be out of date; run 'troveler scan' to refresh it")
+	}
 
 	if len(results) == 0 {
 		if format == "json" {
@@ -134,8 +150,11 @@ func outputJSON(results []db.SearchResult) error {
 
 func outputPretty(results []db.SearchResult, opts db.SearchOptions, taglineWidth int, filterWarning string) error {
 	fmt.Println()
-	title := fmt.Sprintf("Found %d results for '%s' (sorted by %s %s)",
-		len(results), opts.Query, opts.SortField, opts.SortOrder)
+	sortDesc := opts.SortField + " " + opts.SortOrder
+	if opts.SortField == db.SortFieldRelevance || opts.SortField == search.SortFieldCloseness {
+		sortDesc = opts.SortField
+	}
+	title := fmt.Sprintf("Found %d results for '%s' (sorted by %s)", len(results), opts.Query, sortDesc)
 	fmt.Println(lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("#00FFFF")).
@@ -164,6 +183,8 @@ func outputPretty(results []db.SearchResult, opts db.SearchOptions, taglineWidth
 				}
 			case "language":
 				val = r.Language
+			case "source":
+				val = r.Source
 			case "installed":
 				if r.Installed {
 					val = "✓"
@@ -191,3 +212,14 @@ func outputPretty(results []db.SearchResult, opts db.SearchOptions, taglineWidth
 
 	return nil
 }
+
+func joinWarnings(warnings ...string) string {
+	var nonEmpty []string
+	for _, w := range warnings {
+		if w != "" {
+			nonEmpty = append(nonEmpty, w)
+		}
+	}
+
+	return strings.Join(nonEmpty, "\n")
+}
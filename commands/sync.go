@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"troveler/config"
+	"troveler/db"
+	"troveler/internal/install"
+	"troveler/internal/manifest"
+)
+
+var syncFile string
+var syncCheck bool
+var syncDryRun bool
+var syncSudo bool
+var syncSudoOnlySystem bool
+var syncReuseConfig string
+var syncJobs int
+
+// SyncCmd installs whatever a troveler.toml manifest lists but the machine lacks.
+var SyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Install the tools listed in a troveler.toml manifest",
+	Long: `Install the tools listed in a troveler.toml manifest.
+
+Each manifest entry is resolved to an install command for this machine and
+checked against PATH. Sync shows the plan and installs only what is missing.
+Use --check to exit non-zero when the machine drifts from the manifest.
+
+Manifest format:
+
+  [[tools]]
+  slug = "ripgrep"
+
+  [[tools]]
+  slug = "fd"
+  platform = "brew"   # pin the install platform, like install --override
+
+  [[groups]]
+  tag = "essentials"  # every tool carrying this tag
+  method = "mise"     # install through mise`,
+	Args: cobra.NoArgs,
+	Example: "  troveler sync\n" +
+		"  troveler sync --check\n" +
+		"  troveler sync -f team/troveler.toml --dry-run\n" +
+		"  troveler sync --jobs 8",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		m, err := manifest.Load(syncFile)
+		if err != nil {
+			return err
+		}
+
+		return WithDB(cmd, func(ctx context.Context, database *db.SQLiteDB) error {
+			return runSync(database, m, GetConfig(ctx))
+		})
+	},
+}
+
+func init() {
+	SyncCmd.Flags().StringVarP(&syncFile, "file", "f", manifest.DefaultPath, "Manifest file")
+	SyncCmd.Flags().BoolVar(&syncCheck, "check", false, "Only report drift; exit non-zero if anything is missing")
+	SyncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show the plan without installing")
+	SyncCmd.Flags().BoolVarP(&syncSudo, "sudo", "s", false, "Prepend sudo to install commands")
+	SyncCmd.Flags().BoolVar(&syncSudoOnlySystem, "sudo-only-system", false, "Use sudo only for system package managers")
+	SyncCmd.Flags().StringVar(&syncReuseConfig, "reuse-config", "ask", "Reuse config for all tools: true, ask, false")
+	SyncCmd.Flags().IntVarP(&syncJobs, "jobs", "j", 0, "Installs to run at once (default: jobs in [install])")
+}
+
+// syncStatus is the state of a manifest entry on this machine.
+type syncStatus int
+
+const (
+	syncInstalled syncStatus = iota
+	syncMissing
+	syncUnresolvable // no install command for this machine
+	syncUnknown      // not in the local database
+)
+
+type syncItem struct {
+	entry   manifest.Entry
+	status  syncStatus
+	command string // install command for syncMissing
+	reason  string // why the entry cannot be installed
+}
+
+func runSync(database *db.SQLiteDB, m *manifest.Manifest, cfg *config.Config) error {
+	entries, err := m.Entries(database)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Printf("%s lists no tools\n", syncFile)
+
+		return nil
+	}
+
+	items := planSync(database, entries, cfg, detectOSID())
+	printSyncPlan(items)
+
+	var missing []batchTool
+	drift := 0
+	for _, item := range items {
+		if item.status != syncInstalled {
+			drift++
+		}
+		if item.status == syncMissing {
+			missing = append(missing, batchTool{
+				Slug:     item.entry.Slug,
+				Platform: item.entry.Platform,
+				Mise:     item.entry.Method == manifest.MethodMise,
+			})
+		}
+	}
+
+	if syncCheck {
+		if drift > 0 {
+			return fmt.Errorf("%d of %d tools in %s are not installed", drift, len(items), syncFile)
+		}
+		fmt.Printf("All %d tools in %s are installed\n", len(items), syncFile)
+
+		return nil
+	}
+
+	if drift == 0 {
+		fmt.Printf("All %d tools in %s are installed\n", len(items), syncFile)
+
+		return nil
+	}
+	if skipped := drift - len(missing); skipped > 0 {
+		fmt.Printf("%d tools cannot be installed automatically\n", skipped)
+	}
+
+	if len(missing) == 0 || syncDryRun {
+		return nil
+	}
+
+	return runBatchInstall(database, missing, true, syncSudo, syncSudoOnlySystem, false, syncReuseConfig,
+		batchJobs(syncJobs, cfg), cfg)
+}
+
+// planSync resolves every entry to its install state and, for missing tools,
+// the command a batch install would run.
+func planSync(database *db.SQLiteDB, entries []manifest.Entry, cfg *config.Config, detectedOS string) []syncItem {
+	items := make([]syncItem, 0, len(entries))
+
+	for _, entry := range entries {
+		item := syncItem{entry: entry}
+
+		tools, err := database.GetToolBySlug(entry.Slug)
+		if err != nil || len(tools) == 0 {
+			item.status = syncUnknown
+			item.reason = "not in the local database (run troveler update)"
+			items = append(items, item)
+
+			continue
+		}
+
+		tool := tools[0]
+		installs, err := database.GetInstallInstructions(tool.ID)
+		if err != nil {
+			installs = nil
+		}
+
+		if db.IsInstalled(&tool, installs) {
+			item.status = syncInstalled
+			items = append(items, item)
+
+			continue
+		}
+
+		bt := batchTool{Slug: entry.Slug, Platform: entry.Platform}
+		matched, err := resolveBatchInstall(&tool, installs, bt, cfg, detectedOS)
+		if err != nil {
+			item.status = syncUnresolvable
+			item.reason = err.Error()
+			items = append(items, item)
+
+			continue
+		}
+
+		item.status = syncMissing
+		item.command = matched.Command
+		if entry.Method == manifest.MethodMise {
+			item.command = install.TransformToMise(item.command)
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func printSyncPlan(items []syncItem) {
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	installStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500"))
+
+	fmt.Println()
+	for _, item := range items {
+		switch item.status {
+		case syncInstalled:
+			fmt.Printf("  %s %s\n", okStyle.Render("✓"), item.entry.Slug)
+		case syncMissing:
+			fmt.Printf("  %s %-20s %s\n", installStyle.Render("+"), item.entry.Slug, item.command)
+		case syncUnresolvable, syncUnknown:
+			fmt.Printf("  %s %-20s %s\n", warnStyle.Render("✗"), item.entry.Slug, item.reason)
+		}
+	}
+	fmt.Println()
+}
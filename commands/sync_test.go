@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"troveler/config"
+	"troveler/db"
+	"troveler/internal/manifest"
+)
+
+func setupSyncTestDB(t *testing.T) *db.SQLiteDB {
+	t.Helper()
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	seed := []struct {
+		slug, platform, command, executable string
+	}{
+		{"present", "macos", "brew install present", "sh"},
+		{"absent", "macos", "brew install absent", "troveler-test-absent"},
+		{"crate", "cargo", "cargo install crate", "troveler-test-crate"},
+		{"winonly", "windows", "scoop install winonly", "troveler-test-winonly"},
+	}
+	for _, s := range seed {
+		tool := &db.Tool{ID: "tool-" + s.slug, Slug: s.slug, Name: s.slug, Language: "rust"}
+		if err := database.UpsertTool(context.Background(), tool); err != nil {
+			t.Fatalf("Failed to seed tool: %v", err)
+		}
+		err := database.UpsertInstallInstruction(context.Background(), &db.InstallInstruction{
+			ID: "inst-" + s.slug, ToolID: tool.ID, Platform: s.platform, Command: s.command, ExecutableName: s.executable,
+		})
+		if err != nil {
+			t.Fatalf("Failed to seed install: %v", err)
+		}
+	}
+
+	return database
+}
+
+func TestPlanSync(t *testing.T) {
+	database := setupSyncTestDB(t)
+	cfg := &config.Config{}
+
+	entries := []manifest.Entry{
+		{Slug: "present", Method: manifest.MethodAuto},
+		{Slug: "absent", Method: manifest.MethodAuto},
+		{Slug: "crate", Platform: "lang", Method: manifest.MethodMise},
+		{Slug: "winonly", Method: manifest.MethodAuto},
+		{Slug: "nowhere", Method: manifest.MethodAuto},
+	}
+
+	items := planSync(database, entries, cfg, "macos")
+	if len(items) != len(entries) {
+		t.Fatalf("expected %d plan items, got %d", len(entries), len(items))
+	}
+
+	want := []struct {
+		status  syncStatus
+		command string
+	}{
+		{syncInstalled, ""},
+		{syncMissing, "brew install absent"},
+		{syncMissing, "mise use --global cargo:crate"},
+		{syncUnresolvable, ""},
+		{syncUnknown, ""},
+	}
+	for i, w := range want {
+		if items[i].status != w.status || items[i].command != w.command {
+			t.Errorf("%s: got status %d command %q, want %d %q",
+				items[i].entry.Slug, items[i].status, items[i].command, w.status, w.command)
+		}
+	}
+	if items[3].reason == "" || items[4].reason == "" {
+		t.Error("expected a reason for entries that cannot be installed")
+	}
+}
+
+func TestRunSyncCheck(t *testing.T) {
+	database := setupSyncTestDB(t)
+	cfg := &config.Config{Install: config.InstallConfig{PlatformOverride: "macos"}}
+
+	syncCheck = true
+	t.Cleanup(func() { syncCheck = false })
+
+	inSync := &manifest.Manifest{Tools: []manifest.Tool{{Slug: "present"}}}
+	if err := runSync(database, inSync, cfg); err != nil {
+		t.Errorf("expected no drift, got %v", err)
+	}
+
+	drifted := &manifest.Manifest{Tools: []manifest.Tool{{Slug: "present"}, {Slug: "absent"}}}
+	if err := runSync(database, drifted, cfg); err == nil {
+		t.Error("expected --check to fail when a tool is missing")
+	}
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"troveler/config"
+	"troveler/db"
+	"troveler/internal/install"
+)
+
+var uninstallSudo bool
+var uninstallYes bool
+
+// UninstallCmd removes a tool with the inverse of the command that installed it.
+var UninstallCmd = &cobra.Command{
+	Use:   "uninstall <slug>",
+	Short: "Remove an installed tool",
+	Long: `Remove an installed tool.
+
+The uninstall command is derived from the command the tool was installed with:
+the last successful install in the install history, or, when troveler did not
+install it, the command "troveler install" would pick on this machine.
+"apt install" becomes "apt remove", "cargo install" becomes "cargo uninstall",
+and so on. Install scripts and other methods without an inverse are reported
+instead of guessed at.
+
+The command is shown and confirmed before it runs. Sudo follows the install:
+a tool installed with sudo is removed with sudo, otherwise --sudo and the
+use_sudo setting apply as for install.`,
+	Args: cobra.ExactArgs(1),
+	Example: "  troveler uninstall ripgrep\n" +
+		"  troveler uninstall fd --sudo --yes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return WithDB(cmd, func(ctx context.Context, database *db.SQLiteDB) error {
+			return runUninstall(database, args[0], GetConfig(ctx))
+		})
+	},
+}
+
+func init() {
+	UninstallCmd.Flags().BoolVarP(&uninstallSudo, "sudo", "s", false, "Prepend sudo to the uninstall command")
+	UninstallCmd.Flags().BoolVarP(&uninstallYes, "yes", "y", false, "Run without asking for confirmation")
+}
+
+func runUninstall(database *db.SQLiteDB, slug string, cfg *config.Config) error {
+	tools, err := database.GetToolBySlug(slug)
+	if err != nil || len(tools) == 0 {
+		return fmt.Errorf("tool not found: %s", slug)
+	}
+
+	tool := tools[0]
+	installs, err := database.GetInstallInstructions(tool.ID)
+	if err != nil {
+		installs = nil
+	}
+
+	plan, err := planUninstall(database, &tool, installs, cfg, detectOSID())
+	if plan != nil {
+		source := "catalog, no install recorded"
+		if plan.FromHistory {
+			source = "install history"
+		}
+		fmt.Printf("Installed with: %s (%s)\n", plan.InstallCommand, source)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot uninstall %s: %w", slug, err)
+	}
+
+	if !plan.FromHistory && !db.IsInstalled(&tool, installs) {
+		fmt.Printf("%s does not appear to be installed\n", slug)
+
+		return nil
+	}
+
+	fmt.Printf("Uninstall with: %s\n", plan.Command)
+
+	policy, err := riskPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	err = executeCommand(database, db.ActionUninstall, tool.Slug, plan.Platform, plan.Command,
+		uninstallSudo || plan.Sudo, cfg.Install.UseSudo, uninstallYes, policy)
+	if errors.Is(err, errAborted) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if db.IsInstalled(&tool, installs) {
+		fmt.Printf("\n%s is still on PATH; another install may provide it\n", slug)
+	} else {
+		_ = database.DeleteToolVersion(tool.ID)
+		fmt.Printf("\n✓ Removed %s\n", slug)
+	}
+
+	return nil
+}
+
+// planUninstall derives the uninstall command for tool, falling back to the
+// instruction a batch install would pick when the history has no install.
+func planUninstall(
+	database *db.SQLiteDB, tool *db.Tool, installs []db.InstallInstruction, cfg *config.Config, detectedOS string,
+) (*install.UninstallPlan, error) {
+	var fallback *db.InstallInstruction
+	if matched, err := resolveBatchInstall(tool, installs, batchTool{Slug: tool.Slug}, cfg, detectedOS); err == nil {
+		fallback = &matched
+	}
+
+	return install.PlanUninstall(database, tool.Slug, fallback)
+}
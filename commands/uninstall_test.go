@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"troveler/config"
+	"troveler/db"
+	"troveler/internal/platform"
+)
+
+func TestPlanUninstall(t *testing.T) {
+	database := setupSyncTestDB(t)
+	cfg := &config.Config{}
+
+	tools, err := database.GetToolBySlug("absent")
+	if err != nil || len(tools) == 0 {
+		t.Fatalf("GetToolBySlug failed: %v", err)
+	}
+	tool := tools[0]
+	installs, err := database.GetInstallInstructions(tool.ID)
+	if err != nil {
+		t.Fatalf("GetInstallInstructions failed: %v", err)
+	}
+
+	// Nothing recorded: assume the catalog command for this platform.
+	plan, err := planUninstall(database, &tool, installs, cfg, "macos")
+	if err != nil {
+		t.Fatalf("planUninstall failed: %v", err)
+	}
+	if plan.FromHistory || plan.Command != "brew uninstall absent" {
+		t.Errorf("unexpected catalog plan: %+v", plan)
+	}
+
+	// A recorded install wins over the catalog, including its sudo.
+	record := func(action, command string, exitCode int) {
+		t.Helper()
+		rec := &db.InstallRecord{Action: action, Slug: "absent", Platform: "apt", Command: command, ExitCode: exitCode}
+		if err := database.RecordInstall(rec); err != nil {
+			t.Fatalf("RecordInstall failed: %v", err)
+		}
+	}
+	record(db.ActionInstall, "sudo apt install absent", 0)
+	record(db.ActionInstall, "cargo install absent", 101) // failed installs are ignored
+
+	plan, err = planUninstall(database, &tool, installs, cfg, "macos")
+	if err != nil {
+		t.Fatalf("planUninstall failed: %v", err)
+	}
+	if !plan.FromHistory || !plan.Sudo || plan.Command != "apt remove absent" || plan.Platform != "apt" {
+		t.Errorf("unexpected history plan: %+v", plan)
+	}
+
+	// Once uninstalled, the history no longer says how the tool got there.
+	record(db.ActionUninstall, "sudo apt remove absent", 0)
+	plan, err = planUninstall(database, &tool, installs, cfg, "macos")
+	if err != nil || plan.FromHistory {
+		t.Errorf("expected catalog plan after uninstall, got %+v (%v)", plan, err)
+	}
+
+	record(db.ActionInstall, "curl -fsSL https://absent.sh | sh", 0)
+	if _, err := planUninstall(database, &tool, installs, cfg, "macos"); !errors.Is(err, platform.ErrNotInvertible) {
+		t.Errorf("expected ErrNotInvertible for an install script, got %v", err)
+	}
+}
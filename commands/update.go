@@ -2,8 +2,11 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand/v2"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -14,31 +17,104 @@ import (
 
 	"troveler/crawler"
 	"troveler/db"
+	"troveler/internal/source"
+	"troveler/internal/update"
 	"troveler/pkg/ui"
 )
 
 var limit int
 var logOutput bool
-
-// UpdateCmd crawls terminaltrove.com and updates the local database.
+var incremental bool
+var noCache bool
+var recordDir string
+var fromDir string
+var sourceName string
+var retryFailed bool
+var progressFormat string
+var quiet bool
+
+// UpdateCmd crawls terminaltrove.com and configured catalogs and updates the local database.
 var UpdateCmd = &cobra.Command{
-	Use:   "update",
+	Use:   "update [slug]...",
 	Short: "Crawl terminaltrove.com and update local database",
 	Long: `Fetches all tools from terminaltrove.com and stores them in the local SQLite database.
-Use --log to show detailed logging output.`,
-	RunE: func(cmd *cobra.Command, _ []string) error {
+Local catalog files listed under [[catalogs]] in the config are refreshed too;
+use --source to refresh a single source.
+Use --log to show detailed logging output.
+Use --incremental to only fetch detail pages for new or changed tools and
+mark tools that disappeared upstream as removed.
+
+Responses are cached on disk (cache_dir in the config) and revalidated with
+ETag/Last-Modified on later runs; entries unused for 30 days are dropped.
+Use --no-cache to bypass the cache.
+
+Use --record-dir to save every raw search page and detail page as fixtures,
+and --from-dir to run the update from such a directory without network access.
+
+Nothing is written until every tool is fetched; the refresh is then saved in
+a single transaction, so an interrupted update leaves the database unchanged.
+Tools that could not be fetched or saved keep their previous data and are
+listed at the end with the class of failure (http, timeout, network,
+no_json_ld, parse, database); they are remembered until a later update
+refreshes them.
+
+Give slugs to refetch just those tools, and --retry-failed to refetch the
+tools earlier updates failed to refresh; --limit and --incremental do not
+apply then.
+
+For scripts, --progress=jsonl writes every progress event (start, slug,
+progress, error, complete with counts, failed tools, changes and elapsed
+time) as a JSON line on stdout, and --quiet prints nothing unless tools
+failed. In both modes, update exits non-zero when some tools failed.`,
+	Example: "  troveler update\n" +
+		"  troveler update --incremental\n" +
+		"  troveler update --retry-failed\n" +
+		"  troveler update ripgrep fd\n" +
+		"  troveler update --progress=jsonl\n" +
+		"  troveler update --quiet",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := update.Options{Limit: limit, Incremental: incremental, Slugs: args, RetryFailed: retryFailed}
+		if (len(args) > 0 || retryFailed) && (limit > 0 || incremental) {
+			return errors.New("--limit and --incremental cannot be combined with slugs or --retry-failed")
+		}
+		if progressFormat != "" && progressFormat != progressJSONL {
+			return fmt.Errorf("invalid --progress %q: want %s", progressFormat, progressJSONL)
+		}
+
 		return WithDB(cmd, func(ctx context.Context, database *db.SQLiteDB) error {
 			currentCount, err := database.ToolCount(context.Background())
 			if err != nil {
 				return fmt.Errorf("count tools: %w", err)
 			}
 
-			fetcher := crawler.NewFetcher()
+			cacheDir := GetConfig(ctx).CacheDir
+			if noCache || fromDir != "" {
+				cacheDir = ""
+			}
+			if fromDir != "" {
+				if _, err := os.Stat(fromDir); err != nil {
+					return fmt.Errorf("fixture dir: %w", err)
+				}
+			}
+			fetcher := crawler.NewFetcher(
+				crawler.WithCacheDir(cacheDir),
+				crawler.WithRecordDir(recordDir),
+				crawler.WithReplayDir(fromDir),
+			)
+
+			sources, err := source.Select(source.Configured(fetcher, GetConfig(ctx).Catalogs), sourceName)
+			if err != nil {
+				return err
+			}
 
 			updateCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
 			defer cancel()
 
-			return runUpdate(updateCtx, database, fetcher, limit, logOutput, currentCount)
+			if quiet || progressFormat != "" {
+				return streamUpdate(updateCtx, database, sources, opts, streamOutput(progressFormat))
+			}
+
+			return showUpdate(updateCtx, database, sources, opts, logOutput, currentCount)
 		})
 	},
 }
@@ -46,6 +122,21 @@ Use --log to show detailed logging output.`,
 func init() {
 	UpdateCmd.Flags().IntVarP(&limit, "limit", "l", 0, "Limit number of tools to fetch (0 for all)")
 	UpdateCmd.Flags().BoolVarP(&logOutput, "log", "v", false, "Show verbose logging output")
+	UpdateCmd.Flags().BoolVarP(&incremental, "incremental", "i", false,
+		"Only fetch new or changed tools and mark vanished ones as removed")
+	UpdateCmd.Flags().BoolVar(&noCache, "no-cache", false, "Bypass the on-disk HTTP cache")
+	UpdateCmd.Flags().StringVar(&recordDir, "record-dir", "", "Write raw search and detail pages to this directory")
+	UpdateCmd.Flags().StringVar(&fromDir, "from-dir", "", "Read search and detail pages from a recorded directory")
+	UpdateCmd.MarkFlagsMutuallyExclusive("record-dir", "from-dir")
+	UpdateCmd.Flags().StringVar(&sourceName, "source", "",
+		"Only refresh this source (terminaltrove or a configured catalog name)")
+	UpdateCmd.Flags().BoolVar(&retryFailed, "retry-failed", false,
+		"Only refetch the tools earlier updates failed to refresh")
+	UpdateCmd.Flags().StringVar(&progressFormat, "progress", "",
+		"Stream progress events to stdout in this format (jsonl)")
+	UpdateCmd.Flags().BoolVarP(&quiet, "quiet", "q", false,
+		"Print nothing unless tools failed, for cron")
+	UpdateCmd.MarkFlagsMutuallyExclusive("progress", "quiet", "log")
 }
 
 const (
@@ -53,6 +144,9 @@ const (
 	streamWidth      = 60
 	streamHeight     = 4
 	totalLines       = 1 + streamHeight + 1
+
+	maxReportedFailures = 20
+	maxReportedChanges  = 20
 )
 
 var runePalette = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789~!@#$%^&*()_+-=")
@@ -191,154 +285,166 @@ func abs(x int) int {
 	return x
 }
 
-func fetchAndParseSlugs(
-	ctx context.Context, fetcher *crawler.Fetcher, limit int,
-) ([]string, map[string]bool, int, error) {
-	initialData, err := fetcher.FetchSearchPage(ctx, 1)
+// runUpdate runs the update through update.Service, passing every progress
+// event to emit, and returns the last one. Tags are snapshotted before and
+// reapplied after the refresh.
+func runUpdate(
+	ctx context.Context, database *db.SQLiteDB, sources []source.Source, opts update.Options,
+	emit func(update.ProgressUpdate),
+) (update.ProgressUpdate, error) {
+	var last update.ProgressUpdate
+	preservedTags, err := database.GetAllTagsBySlug()
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("initial fetch: %w", err)
+		return last, fmt.Errorf("snapshot tags before update: %w", err)
 	}
 
-	initialResp, err := crawler.ParseSearchResponse(initialData)
-	if err != nil {
-		return nil, nil, 0, fmt.Errorf("parse initial: %w", err)
+	progress := make(chan update.ProgressUpdate, 100)
+	opts.Progress = progress
+	done := make(chan error, 1)
+	go func() {
+		done <- update.NewServiceWithSources(database, sources...).FetchAndUpdate(ctx, opts)
+	}()
+
+	// FetchAndUpdate closes progress when it returns.
+	for upd := range progress {
+		last = upd
+		emit(upd)
 	}
+	if err := <-done; err != nil {
+		if ctx.Err() != nil {
+			return last, fmt.Errorf("update interrupted, database left unchanged: %w", err)
+		}
 
-	totalTools := int(initialResp.Found)
-	if limit > 0 && limit < totalTools {
-		totalTools = limit
+		return last, err
 	}
 
-	pageResults, err := fetcher.FetchSearchPagesConcurrently(ctx, (totalTools+99)/100)
-	if err != nil {
-		return nil, nil, 0, fmt.Errorf("fetch pages: %w", err)
+	if len(preservedTags) > 0 {
+		if err := database.ReapplyTags(preservedTags); err != nil {
+			return last, fmt.Errorf("restore tags after update: %w", err)
+		}
 	}
 
-	allSlugs := make([]string, 0, totalTools)
-	slugToToolOfTheWeek := make(map[string]bool)
+	return last, nil
+}
 
-	for i := 1; i <= (totalTools+99)/100; i++ {
-		data, ok := pageResults[i]
-		if !ok {
-			continue
-		}
+// updateDisplay shows the progress events of an update on the terminal: the
+// animated progress display or, with logOutput, plain log lines.
+type updateDisplay struct {
+	logOutput bool
+	planned   int // tools to fetch, -1 until the update has listed its sources
+	ui        *UpdateUI
+	done      chan struct{}
+	rendering sync.WaitGroup
+}
 
-		resp, err := crawler.ParseSearchResponse(data)
-		if err != nil {
-			continue
-		}
+func newUpdateDisplay(logOutput bool) *updateDisplay {
+	return &updateDisplay{logOutput: logOutput, planned: -1, done: make(chan struct{})}
+}
 
-		for _, item := range resp.Hits {
-			if item.Document.Slug != "" {
-				allSlugs = append(allSlugs, item.Document.Slug)
-				slugToToolOfTheWeek[item.Document.Slug] = item.Document.ToolOfTheWeek
-				if limit > 0 && len(allSlugs) >= limit {
-					break
-				}
-			}
+// handle shows upd. The first "progress" event carries the number of tools
+// to fetch and starts the animation.
+func (d *updateDisplay) handle(ctx context.Context, upd update.ProgressUpdate) {
+	switch upd.Type {
+	case "start":
+		if d.logOutput {
+			fmt.Println(upd.Message)
+
+			return
 		}
-		if limit > 0 && len(allSlugs) >= limit {
-			break
+		fmt.Println()
+		fmt.Println(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00FFFF")).Render(upd.Message))
+		fmt.Println()
+	case "progress":
+		if d.planned >= 0 {
+			return
+		}
+		d.planned = upd.Total
+		fmt.Println(upd.Message)
+		if d.logOutput {
+			fmt.Printf("Fetching details for %d tools...\n", upd.Total)
+		} else if upd.Total > 0 {
+			d.ui = NewUpdateUI(upd.Total)
+			d.rendering.Add(1)
+			go func() {
+				defer d.rendering.Done()
+				runUpdateUI(ctx, d.ui, d.done)
+			}()
+		}
+	case "slug":
+		if d.ui != nil {
+			d.ui.IncProcessed()
+			d.ui.AddSlug(upd.Slug)
 		}
 	}
-
-	return allSlugs, slugToToolOfTheWeek, int(initialResp.Found), nil
 }
 
-func processDetailsConcurrently(
-	ctx context.Context, fetcher *crawler.Fetcher, slugs []string, slugToToolOfTheWeek map[string]bool, ui *UpdateUI,
-) (<-chan crawler.DetailPage, <-chan error) {
-	detailChan := make(chan crawler.DetailPage, 100)
-	errChan := make(chan error, 1)
-
-	slugChan := make(chan string, len(slugs))
-	for _, s := range slugs {
-		slugChan <- s
+// stop ends the animation, waiting for its last frame.
+func (d *updateDisplay) stop() {
+	close(d.done)
+	d.rendering.Wait()
+	if d.ui != nil {
+		fmt.Println()
 	}
-	close(slugChan)
-
-	workerCount := 5
-	var detailWg sync.WaitGroup
-
-	for i := 0; i < workerCount; i++ {
-		detailWg.Add(1)
-		go func() {
-			defer detailWg.Done()
-			for slug := range slugChan {
-				data, err := fetcher.FetchDetailPage(ctx, slug)
-				if err != nil {
-					continue
-				}
-
-				detail, err := crawler.ParseDetailPage(data)
-				if err != nil {
-					continue
-				}
-
-				// Set tool of the week from search hit
-				if totw, ok := slugToToolOfTheWeek[slug]; ok {
-					detail.Tool.ToolOfTheWeek = totw
-				}
-
-				ui.IncProcessed()
-				ui.AddSlug(slug)
+}
 
-				select {
-				case detailChan <- *detail:
-				case <-ctx.Done():
-					return
-				}
-			}
-		}()
+// showUpdate runs the update on the terminal and reports what it changed and
+// the tools that failed.
+func showUpdate(
+	ctx context.Context, database *db.SQLiteDB, sources []source.Source, opts update.Options,
+	logOutput bool, currentCount int,
+) error {
+	display := newUpdateDisplay(logOutput)
+	complete, err := runUpdate(ctx, database, sources, opts, func(upd update.ProgressUpdate) {
+		display.handle(ctx, upd)
+	})
+	display.stop()
+	if err != nil {
+		return err
 	}
 
-	go func() {
-		detailWg.Wait()
-		close(detailChan)
-	}()
-
-	return detailChan, errChan
-}
+	if display.planned <= 0 {
+		if len(complete.Changes) > 0 {
+			reportChanges(complete.Changes)
+		}
+		switch {
+		case opts.RetryFailed:
+			fmt.Println("No failed tools to retry.")
+		case opts.Incremental:
+			fmt.Println("Database is up to date.")
+		default:
+			fmt.Println("No tools found.")
+		}
 
-func handleDatabaseWrites(ctx context.Context, database *db.SQLiteDB, detailChan <-chan crawler.DetailPage) {
-	writeChan := make(chan db.InstallInstruction, 200)
-	var writeWg sync.WaitGroup
-
-	dbWorkerCount := 3
-	for i := 0; i < dbWorkerCount; i++ {
-		writeWg.Add(1)
-		go func() {
-			defer writeWg.Done()
-			for inst := range writeChan {
-				_ = database.UpsertInstallInstruction(ctx, &inst)
-			}
-		}()
+		return nil
 	}
 
-	for detail := range detailChan {
-		_ = database.UpsertTool(ctx, detail.ToTool())
+	if logOutput {
+		fmt.Println(complete.Message)
+	}
+	reportFailedRows(complete.Failed)
+	reportChanges(complete.Changes)
 
-		for _, inst := range detail.ToInstallInstructions() {
-			select {
-			case writeChan <- inst:
-			case <-ctx.Done():
-				return
-			}
-		}
+	finalCount, _ := database.ToolCount(context.Background())
+	if logOutput {
+		fmt.Printf("Update complete! Database now has %d tools\n", finalCount)
+	} else {
+		fmt.Println(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00FF00")).Render("✓ ") +
+			fmt.Sprintf("Update complete! Database has %d tools (+%d new)",
+				finalCount, finalCount-currentCount))
+		fmt.Println()
 	}
 
-	close(writeChan)
-	writeWg.Wait()
+	return nil
 }
 
-func runUpdateUI(ctx context.Context, ui *UpdateUI, detailDone <-chan struct{}, logOutput bool) {
+func runUpdateUI(ctx context.Context, ui *UpdateUI, done <-chan struct{}) {
 	ticker := time.NewTicker(33 * time.Millisecond)
 	defer ticker.Stop()
 
 	frame := 0
 	for {
 		select {
-		case <-detailDone:
+		case <-done:
 			return
 
 		case <-ticker.C:
@@ -350,9 +456,7 @@ func runUpdateUI(ctx context.Context, ui *UpdateUI, detailDone <-chan struct{},
 			ui.step = frame
 			ui.bufferMu.Unlock()
 
-			if !logOutput {
-				fmt.Print(ui.Render())
-			}
+			fmt.Print(ui.Render())
 
 		case <-ctx.Done():
 			return
@@ -360,98 +464,37 @@ func runUpdateUI(ctx context.Context, ui *UpdateUI, detailDone <-chan struct{},
 	}
 }
 
-func runUpdate(
-	ctx context.Context, database *db.SQLiteDB, fetcher *crawler.Fetcher,
-	limit int, logOutput bool, currentCount int,
-) error {
-	if !logOutput {
-		fmt.Println()
-		fmt.Println(lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#00FFFF")).
-			Render("Fetching tools from terminaltrove.com..."))
-		fmt.Println()
-	}
-
-	slugs, slugToToolOfTheWeek, total, err := fetchAndParseSlugs(ctx, fetcher, limit)
-	if err != nil {
-		return err
-	}
-
-	if len(slugs) == 0 {
-		if logOutput {
-			fmt.Println("No tools found in search pages")
-		} else {
-			fmt.Println("No tools found.")
-		}
-
-		return nil
-	}
-
-	if logOutput {
-		fmt.Printf("Found %d tools (limited to %d)\n", total, len(slugs))
-	} else {
-		fmt.Printf("Found %d tools\n", len(slugs))
-	}
-
-	preservedTags, err := database.GetAllTagsBySlug()
-	if err != nil {
-		return fmt.Errorf("snapshot tags before update: %w", err)
-	}
-	if logOutput && len(preservedTags) > 0 {
-		fmt.Printf("Preserving %d tagged tools\n", len(preservedTags))
+// reportChanges summarizes what an update changed in the catalog.
+func reportChanges(changes []db.CatalogChange) {
+	fmt.Printf("Changes: %s\n", update.SummarizeChanges(changes))
+	fmt.Print(update.DescribeChanges(changes, maxReportedChanges))
+	if len(changes) > maxReportedChanges {
+		fmt.Println("Run 'troveler changelog' for the full list.")
 	}
+}
 
-	if logOutput {
-		fmt.Printf("Fetching details for %d tools...\n", len(slugs))
+// reportFailedRows lists the tools an update could not refresh. Their stored
+// versions, if any, were kept.
+func reportFailedRows(failed []db.FailedRow) {
+	if len(failed) == 0 {
+		return
 	}
 
-	ui := NewUpdateUI(len(slugs))
-
-	detailChan, errChan := processDetailsConcurrently(ctx, fetcher, slugs, slugToToolOfTheWeek, ui)
-
-	detailDone := make(chan struct{})
-	go func() {
-		handleDatabaseWrites(ctx, database, detailChan)
-		close(detailDone)
-	}()
-
-	go runUpdateUI(ctx, ui, detailDone, logOutput)
-
-	select {
-	case err := <-errChan:
-		if err != nil {
-			return err
-		}
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-detailDone:
-		if !logOutput {
-			fmt.Println()
-		}
-
-		if len(preservedTags) > 0 {
-			if err := database.ReapplyTags(preservedTags); err != nil {
-				return fmt.Errorf("restore tags after update: %w", err)
-			}
-			if logOutput {
-				fmt.Printf("Restored tags for %d tools\n", len(preservedTags))
-			}
-		}
+	fmt.Fprintf(os.Stderr, "\n%d tools could not be refreshed and kept their previous data:\n", len(failed))
+	printFailureTable(os.Stderr, failed, maxReportedFailures)
+	fmt.Fprintln(os.Stderr, "Run 'troveler update --retry-failed' to refetch them.")
+}
 
-		finalCount, _ := database.ToolCount(context.Background())
+// printFailureTable lists failed tools with their source, class of failure
+// and error, at most limit of them.
+func printFailureTable(w io.Writer, failed []db.FailedRow, limit int) {
+	_, _ = fmt.Fprintf(w, "  %-24s %-14s %-11s %s\n", "SLUG", "SOURCE", "CLASS", "ERROR")
+	for i, row := range failed {
+		if i == limit {
+			_, _ = fmt.Fprintf(w, "  ... and %d more\n", len(failed)-limit)
 
-		if logOutput {
-			fmt.Printf("Update complete! Database now has %d tools\n", finalCount)
-		} else {
-			fmt.Println(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00FF00")).Render("✓ ") +
-				fmt.Sprintf("Update complete! Database has %d tools (+%d new)",
-					finalCount, finalCount-currentCount))
-			fmt.Println()
+			break
 		}
-
-		return nil
+		_, _ = fmt.Fprintf(w, "  %-24s %-14s %-11s %v\n", row.Slug, row.Source, row.Class, row.Err)
 	}
-
-	return nil
 }
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"troveler/db"
+	"troveler/internal/source"
+	"troveler/internal/update"
+)
+
+// progressJSONL is the --progress value that streams progress events as JSON
+// lines.
+const progressJSONL = "jsonl"
+
+// streamUpdate runs the update for scripts and cron: with w set, every
+// progress event is written to it as a JSON line; otherwise nothing is
+// printed unless some tools failed, which are then listed on stderr. Either
+// way, a partial update is an error, so the exit code reports it.
+func streamUpdate(
+	ctx context.Context, database *db.SQLiteDB, sources []source.Source, opts update.Options, w io.Writer,
+) error {
+	var encoder *json.Encoder
+	if w != nil {
+		encoder = json.NewEncoder(w)
+	}
+	emit := func(upd update.ProgressUpdate) {
+		if encoder != nil {
+			_ = encoder.Encode(upd)
+		}
+	}
+
+	last, err := runUpdate(ctx, database, sources, opts, emit)
+	if err != nil {
+		if last.Type != "error" {
+			emit(update.ProgressUpdate{Type: "error", Error: err})
+		}
+
+		return err
+	}
+
+	if failed := last.Failed; last.Type == "complete" && len(failed) > 0 {
+		if encoder == nil {
+			reportFailedRows(failed)
+		}
+
+		return fmt.Errorf("%d of %d tools failed to refresh", len(failed), last.Total)
+	}
+
+	return nil
+}
+
+// streamOutput is where streamUpdate writes progress events for the
+// --progress value: stdout for JSON lines, nowhere in quiet mode.
+func streamOutput(format string) io.Writer {
+	if format == progressJSONL {
+		return os.Stdout
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"troveler/crawler"
+	"troveler/db"
+	"troveler/internal/source"
+	"troveler/internal/update"
+)
+
+// flakySource lists slugs and fails to fetch those in broken with HTTP 503.
+type flakySource struct {
+	slugs  []string
+	broken map[string]bool
+}
+
+func (s *flakySource) Name() string { return "flaky" }
+
+func (s *flakySource) List(_ context.Context, _ int) ([]source.Ref, int, error) {
+	refs := make([]source.Ref, len(s.slugs))
+	for i, slug := range s.slugs {
+		refs[i] = source.Ref{Slug: slug, Fingerprint: slug}
+	}
+
+	return refs, len(refs), nil
+}
+
+func (s *flakySource) Fetch(_ context.Context, slug string) (*source.Entry, error) {
+	if s.broken[slug] {
+		return nil, &crawler.StatusError{StatusCode: 503}
+	}
+
+	return &source.Entry{Tool: &db.Tool{ID: "tool-" + slug, Slug: slug, Name: slug, Source: "flaky"}}, nil
+}
+
+func TestStreamUpdateJSONL(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	src := &flakySource{slugs: []string{"bat", "fd"}, broken: map[string]bool{"fd": true}}
+	var out bytes.Buffer
+	err = streamUpdate(context.Background(), database, []source.Source{src}, update.Options{}, &out)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 tools failed") {
+		t.Errorf("expected a partial failure error, got %v", err)
+	}
+
+	var types []string
+	var complete map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		types = append(types, event["type"].(string))
+		if event["type"] == "complete" {
+			complete = event
+		}
+	}
+	if types[0] != "start" || types[len(types)-1] != "complete" {
+		t.Errorf("event types = %v, want start first and complete last", types)
+	}
+	if complete["processed"] != 1.0 || complete["total"] != 2.0 {
+		t.Errorf("unexpected counts on complete: %v", complete)
+	}
+	if _, ok := complete["elapsed_ms"]; !ok {
+		t.Errorf("complete lacks elapsed_ms: %v", complete)
+	}
+	failed, _ := complete["failed"].([]any)
+	if len(failed) != 1 || failed[0].(map[string]any)["class"] != db.FailureHTTP {
+		t.Errorf("expected fd reported as an http failure, got %v", complete["failed"])
+	}
+}
+
+func TestStreamUpdateQuietSucceeds(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	src := &flakySource{slugs: []string{"bat", "fd"}}
+	if err := streamUpdate(context.Background(), database, []source.Source{src}, update.Options{}, nil); err != nil {
+		t.Fatalf("streamUpdate failed: %v", err)
+	}
+	if count, err := database.ToolCount(context.Background()); err != nil || count != 2 {
+		t.Errorf("expected 2 tools saved, got %d (%v)", count, err)
+	}
+}
+
+func TestShowUpdateLogKeepsTags(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	ctx := context.Background()
+
+	if err := database.UpsertTool(ctx, &db.Tool{ID: "old-bat", Slug: "bat", Name: "bat", Source: "flaky"}); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+	if err := database.AddTag("bat", "favorite"); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	src := &flakySource{slugs: []string{"bat", "fd"}, broken: map[string]bool{"fd": true}}
+	err = showUpdate(ctx, database, []source.Source{src}, update.Options{}, true, 1)
+	_ = w.Close()
+	os.Stdout = old
+	if err != nil {
+		t.Fatalf("showUpdate failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	for _, want := range []string{
+		"Fetching tools from flaky...", "Found 2 tools", "Fetching details for 2 tools...",
+		"Update complete! Saved 1 tools, 1 failed.", "Changes: no changes", "Database now has 1 tools",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, buf.String())
+		}
+	}
+
+	if tags, err := database.GetTags("bat"); err != nil || len(tags) != 1 || tags[0] != "favorite" {
+		t.Errorf("tags of bat = %v (%v), want favorite", tags, err)
+	}
+}
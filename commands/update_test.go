@@ -5,19 +5,20 @@ import (
 	"testing"
 
 	"troveler/crawler"
+	"troveler/internal/source"
 )
 
 func TestFetchAndParseSlugs(t *testing.T) {
 	ctx := context.Background()
 
-	fetcher := crawler.NewFetcher()
+	src := source.NewTerminalTrove(crawler.NewFetcher())
 
-	slugs, _, _, err := fetchAndParseSlugs(ctx, fetcher, 10)
+	refs, _, err := src.List(ctx, 10)
 	if err != nil {
-		t.Fatalf("fetchAndParseSlugs failed: %v", err)
+		t.Fatalf("List failed: %v", err)
 	}
 
-	if len(slugs) == 0 {
+	if len(refs) == 0 {
 		t.Logf("No slugs fetched from real API (expected - running without network)")
 	}
 }
@@ -25,14 +26,14 @@ func TestFetchAndParseSlugs(t *testing.T) {
 func TestFetchAndParseSlugsNoLimit(t *testing.T) {
 	ctx := context.Background()
 
-	fetcher := crawler.NewFetcher()
+	src := source.NewTerminalTrove(crawler.NewFetcher())
 
-	slugs, _, _, err := fetchAndParseSlugs(ctx, fetcher, 0)
+	refs, _, err := src.List(ctx, 0)
 	if err != nil {
-		t.Fatalf("fetchAndParseSlugs failed: %v", err)
+		t.Fatalf("List failed: %v", err)
 	}
 
-	if len(slugs) == 0 {
+	if len(refs) == 0 {
 		t.Logf("No slugs fetched from real API (expected - running without network)")
 	}
 }
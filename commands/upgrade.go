@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"troveler/config"
+	"troveler/db"
+	"troveler/internal/upgrade"
+)
+
+var upgradeSudo bool
+var upgradeYes bool
+var upgradeDryRun bool
+var upgradeRefresh bool
+
+// UpgradeCmd upgrades installed tools through the method that installed them.
+var UpgradeCmd = &cobra.Command{
+	Use:   "upgrade [slug...]",
+	Short: "Upgrade outdated tools",
+	Long: `Upgrade installed tools with the package manager that installed them.
+
+Without arguments every tool "troveler outdated" reports is upgraded; named
+tools are upgraded whether or not a newer release is known.
+
+The upgrade command is derived from the install command, as for uninstall:
+"apt install" becomes "apt install --only-upgrade", "brew install" becomes
+"brew upgrade", "go install pkg@v1" becomes "go install pkg@latest", and
+install scripts are simply run again. Each command is shown and confirmed
+before it runs and recorded in the install history.`,
+	Example: "  troveler upgrade\n" +
+		"  troveler upgrade ripgrep --yes\n" +
+		"  troveler upgrade --dry-run",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return WithDB(cmd, func(ctx context.Context, database *db.SQLiteDB) error {
+			return runUpgrade(ctx, database, args, GetConfig(ctx))
+		})
+	},
+}
+
+func init() {
+	UpgradeCmd.Flags().BoolVarP(&upgradeSudo, "sudo", "s", false, "Prepend sudo to upgrade commands")
+	UpgradeCmd.Flags().BoolVarP(&upgradeYes, "yes", "y", false, "Run without asking for confirmation")
+	UpgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "Show the upgrade commands without running them")
+	UpgradeCmd.Flags().BoolVar(&upgradeRefresh, "refresh", false, "Ignore stored version checks and check again")
+}
+
+func runUpgrade(ctx context.Context, database *db.SQLiteDB, slugs []string, cfg *config.Config) error {
+	detectedOS := detectOSID()
+	targets, err := upgradeTargets(ctx, database, slugs, cfg, detectedOS)
+	if err != nil {
+		return err
+	}
+	if len(slugs) > 0 && len(targets) < len(slugs) {
+		return fmt.Errorf("only installed tools can be upgraded (%d of %d are installed)", len(targets), len(slugs))
+	}
+
+	checker := upgrade.NewChecker()
+	if len(slugs) == 0 {
+		var outdated []upgrade.Target
+		for i, r := range checker.CheckAll(ctx, database, targets, upgradeRefresh) {
+			if r.Outdated {
+				outdated = append(outdated, targets[i])
+			}
+		}
+		targets = outdated
+		if len(targets) == 0 {
+			fmt.Println("All installed tools are up to date")
+
+			return nil
+		}
+	}
+
+	var failed []string
+	for i := range targets {
+		if err := upgradeTool(ctx, database, checker, &targets[i], cfg, detectedOS); err != nil {
+			fmt.Printf("✗ %s: %v\n", targets[i].Tool.Slug, err)
+			failed = append(failed, targets[i].Tool.Slug)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d upgrades failed", len(failed), len(targets))
+	}
+
+	return nil
+}
+
+// upgradeTool runs the upgrade command of one tool and stores its new
+// version.
+func upgradeTool(
+	ctx context.Context, database *db.SQLiteDB, checker *upgrade.Checker, target *upgrade.Target,
+	cfg *config.Config, detectedOS string,
+) error {
+	tool := &target.Tool
+	plan, err := planUpgrade(database, tool, target.Installs, cfg, detectedOS)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s: %s\n", tool.Slug, plan.Command)
+	if upgradeDryRun {
+		return nil
+	}
+
+	policy, err := riskPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	err = executeCommand(database, db.ActionUpgrade, tool.Slug, plan.Platform, plan.Command,
+		upgradeSudo || plan.Sudo, cfg.Install.UseSudo, upgradeYes, policy)
+	if errors.Is(err, errAborted) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	result := checker.Check(ctx, *target)
+	if result.Err == nil {
+		_ = database.SaveToolVersion(&result.ToolVersion)
+	}
+	if result.Installed != "" {
+		fmt.Printf("\n✓ %s is now at %s\n", tool.Slug, result.Installed)
+	}
+
+	return nil
+}
@@ -5,17 +5,27 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
 // Config holds all application configuration.
 type Config struct {
-	DSN          string        `toml:"dsn"`
-	DefaultToTUI bool          `toml:"default_to_tui"`
-	Install      InstallConfig `toml:"install"`
-	Search       SearchConfig  `toml:"search"`
-	TUI          TUIConfig     `toml:"tui"`
+	DSN          string          `toml:"dsn"`
+	CacheDir     string          `toml:"cache_dir"`
+	DefaultToTUI bool            `toml:"default_to_tui"`
+	Install      InstallConfig   `toml:"install"`
+	Search       SearchConfig    `toml:"search"`
+	TUI          TUIConfig       `toml:"tui"`
+	Catalogs     []CatalogConfig `toml:"catalogs"`
+}
+
+// CatalogConfig describes a local TOML catalog file that update reads as an
+// additional tool source next to terminaltrove.com.
+type CatalogConfig struct {
+	Name string `toml:"name"`
+	Path string `toml:"path"`
 }
 
 // InstallConfig holds install-related settings.
@@ -24,6 +34,11 @@ type InstallConfig struct {
 	PlatformOverride string `toml:"platform_override"`
 	AlwaysRun        bool   `toml:"always_run"`
 	UseSudo          string `toml:"use_sudo"`
+	Jobs             int    `toml:"jobs"` // installs a batch runs at once
+	// Risk classes (or levels: low, medium, high) of install commands that
+	// are never run, or run only after an explicit confirmation.
+	BlockRisks   []string `toml:"block_risks"`
+	ConfirmRisks []string `toml:"confirm_risks"`
 }
 
 // SearchConfig holds search-related settings.
@@ -60,10 +75,22 @@ func Load(configPath string) (*Config, error) {
 		cfg.DSN = dsn
 	}
 
+	if cfg.CacheDir == "" {
+		cfg.CacheDir = defaultCacheDir()
+	}
+
+	if dir := os.Getenv("TROVELER_CACHE_DIR"); dir != "" {
+		cfg.CacheDir = dir
+	}
+
 	if cfg.Search.TaglineWidth == 0 {
 		cfg.Search.TaglineWidth = 50
 	}
 
+	if cfg.Install.Jobs == 0 {
+		cfg.Install.Jobs = 4
+	}
+
 	// TUI defaults
 	if cfg.TUI.Theme == "" {
 		cfg.TUI.Theme = "gradient"
@@ -73,9 +100,40 @@ func Load(configPath string) (*Config, error) {
 		cfg.TUI.TaglineMaxWidth = 40
 	}
 
+	seen := map[string]bool{"terminaltrove": true}
+	for i := range cfg.Catalogs {
+		cat := &cfg.Catalogs[i]
+		if cat.Path == "" {
+			return nil, fmt.Errorf("catalog #%d: path is required", i+1)
+		}
+		cat.Path = resolvePath(cat.Path, filepath.Dir(configPath))
+		if cat.Name == "" {
+			cat.Name = strings.TrimSuffix(filepath.Base(cat.Path), filepath.Ext(cat.Path))
+		}
+		if seen[cat.Name] {
+			return nil, fmt.Errorf("catalog %q: source name already in use", cat.Name)
+		}
+		seen[cat.Name] = true
+	}
+
 	return cfg, nil
 }
 
+// resolvePath expands a leading ~/ and makes relative paths relative to base
+// (the directory holding the config file).
+func resolvePath(path, base string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(base, path)
+}
+
 func defaultConfigPath() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -85,12 +143,26 @@ func defaultConfigPath() string {
 	return filepath.Join(home, ".config", "troveler", "config.toml")
 }
 
-func defaultDSN() string {
+func defaultDataDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".local", "share", "troveler")
+}
+
+func defaultDSN() string {
+	dataDir := defaultDataDir()
+	if dataDir == "" {
 		return "file:troveler.db?cache=shared&mode=rwc"
 	}
-	dbPath := filepath.Join(home, ".local", "share", "troveler", "troveler.db")
+	dbPath := filepath.Join(dataDir, "troveler.db")
 
 	return "file:" + dbPath + "?cache=shared&mode=rwc"
 }
+
+// defaultCacheDir is where the crawler keeps its persistent HTTP cache.
+func defaultCacheDir() string {
+	return filepath.Join(defaultDataDir(), "http-cache")
+}
@@ -30,6 +30,10 @@ fallback_platform = "lang"`), 0644)
 	if cfg.Search.TaglineWidth != 50 {
 		t.Errorf("Expected default TaglineWidth 50, got %d", cfg.Search.TaglineWidth)
 	}
+
+	if cfg.Install.Jobs != 4 {
+		t.Errorf("Expected default install jobs 4, got %d", cfg.Install.Jobs)
+	}
 }
 
 func TestLoadConfigWithCustomTaglineWidth(t *testing.T) {
@@ -67,3 +71,77 @@ tagline_width = 0`), 0644)
 		t.Errorf("Expected default TaglineWidth 50 when set to 0, got %d", cfg.Search.TaglineWidth)
 	}
 }
+
+func TestLoadConfigCacheDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load config failed: %v", err)
+	}
+	if cfg.CacheDir == "" {
+		t.Error("Expected CacheDir to have a default value")
+	}
+
+	t.Setenv("TROVELER_CACHE_DIR", filepath.Join(tmpDir, "cache"))
+
+	cfg, err = Load(configPath)
+	if err != nil {
+		t.Fatalf("Load config failed: %v", err)
+	}
+	if cfg.CacheDir != filepath.Join(tmpDir, "cache") {
+		t.Errorf("Expected TROVELER_CACHE_DIR override, got %q", cfg.CacheDir)
+	}
+}
+
+func TestLoadConfigCatalogs(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	content := `[[catalogs]]
+path = "team-tools.toml"
+
+[[catalogs]]
+name = "ops"
+path = "/srv/catalogs/ops.toml"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load config failed: %v", err)
+	}
+
+	if len(cfg.Catalogs) != 2 {
+		t.Fatalf("Expected 2 catalogs, got %d", len(cfg.Catalogs))
+	}
+	if cfg.Catalogs[0].Name != "team-tools" {
+		t.Errorf("Expected name derived from file name, got %q", cfg.Catalogs[0].Name)
+	}
+	if cfg.Catalogs[0].Path != filepath.Join(tmpDir, "team-tools.toml") {
+		t.Errorf("Expected path relative to config dir, got %q", cfg.Catalogs[0].Path)
+	}
+	if cfg.Catalogs[1].Name != "ops" || cfg.Catalogs[1].Path != "/srv/catalogs/ops.toml" {
+		t.Errorf("Unexpected second catalog: %+v", cfg.Catalogs[1])
+	}
+}
+
+func TestLoadConfigCatalogNameClash(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+
+	content := `[[catalogs]]
+name = "terminaltrove"
+path = "tools.toml"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := Load(configPath); err == nil {
+		t.Error("Expected error for catalog named after the built-in source")
+	}
+}
@@ -0,0 +1,159 @@
+package crawler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheEntry holds a cached response body together with its HTTP validators.
+type CacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	Body         []byte    `json:"-"`
+}
+
+// CacheMaxAge is how long a cache entry is kept without being used. Pages
+// of tools removed upstream are never requested again and go after it.
+const CacheMaxAge = 30 * 24 * time.Hour
+
+// DiskCache stores HTTP response bodies and their validators on disk so later
+// runs can revalidate with If-None-Match / If-Modified-Since instead of
+// downloading everything again. Each URL maps to a <hash>.body file holding
+// the raw response and a <hash>.json file holding the metadata. The files'
+// modification time is when the entry was last stored or revalidated.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates (if needed) and returns a cache rooted at dir.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+
+	return &DiskCache{dir: dir}, nil
+}
+
+// Dir returns the cache's root directory.
+func (c *DiskCache) Dir() string {
+	return c.dir
+}
+
+func (c *DiskCache) paths(url string) (string, string) {
+	sum := sha256.Sum256([]byte(url))
+	base := filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+
+	return base + ".body", base + ".json"
+}
+
+// Get returns the cached entry for url, if both body and metadata are present.
+func (c *DiskCache) Get(url string) (*CacheEntry, bool) {
+	bodyPath, metaPath := c.paths(url)
+
+	meta, err := os.ReadFile(metaPath) //nolint:gosec // G304: path derived from URL hash
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(meta, &entry); err != nil || entry.URL != url {
+		return nil, false
+	}
+
+	body, err := os.ReadFile(bodyPath) //nolint:gosec // G304: path derived from URL hash
+	if err != nil {
+		return nil, false
+	}
+	entry.Body = body
+
+	return &entry, true
+}
+
+// Put stores entry, replacing any previous entry for the same URL.
+// Files are written to a temp name and renamed so readers never observe a
+// partially written body.
+func (c *DiskCache) Put(entry *CacheEntry) error {
+	bodyPath, metaPath := c.paths(entry.URL)
+
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode cache metadata: %w", err)
+	}
+
+	if err := writeFileAtomic(bodyPath, entry.Body); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(metaPath, meta)
+}
+
+// Touch marks the entry for url as used now, after a revalidation found it
+// current, so Prune keeps it.
+func (c *DiskCache) Touch(url string) error {
+	bodyPath, metaPath := c.paths(url)
+	now := time.Now()
+	for _, path := range []string{bodyPath, metaPath} {
+		if err := os.Chtimes(path, now, now); err != nil {
+			return fmt.Errorf("touch cache file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Prune removes the entries not stored or revalidated within maxAge, along
+// with temp files left behind by interrupted writes, and returns how many
+// entries it removed.
+func (c *DiskCache) Prune(maxAge time.Duration) (int, error) {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return 0, fmt.Errorf("read cache dir: %w", err)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil || file.IsDir() || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, file.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("remove cache file: %w", err)
+		}
+		if filepath.Ext(file.Name()) == ".json" {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename cache file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDiskCache_PutGet(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+
+	if _, ok := cache.Get("https://example.com/a"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	err = cache.Put(&CacheEntry{URL: "https://example.com/a", ETag: `"v1"`, Body: []byte("hello")})
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	entry, ok := cache.Get("https://example.com/a")
+	if !ok {
+		t.Fatal("expected hit after Put")
+	}
+	if string(entry.Body) != "hello" || entry.ETag != `"v1"` {
+		t.Errorf("unexpected entry: body=%q etag=%q", entry.Body, entry.ETag)
+	}
+}
+
+func TestDiskCache_Prune(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache failed: %v", err)
+	}
+
+	for _, url := range []string{"https://example.com/old", "https://example.com/used", "https://example.com/new"} {
+		if err := cache.Put(&CacheEntry{URL: url, Body: []byte(url)}); err != nil {
+			t.Fatalf("Put(%s) failed: %v", url, err)
+		}
+	}
+	// Age two entries past the limit, then revalidate one of them.
+	old := time.Now().Add(-2 * CacheMaxAge)
+	for _, url := range []string{"https://example.com/old", "https://example.com/used"} {
+		bodyPath, metaPath := cache.paths(url)
+		for _, path := range []string{bodyPath, metaPath} {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatalf("Chtimes failed: %v", err)
+			}
+		}
+	}
+	if err := cache.Touch("https://example.com/used"); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	removed, err := cache.Prune(CacheMaxAge)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Prune removed %d entries, want 1", removed)
+	}
+	if _, ok := cache.Get("https://example.com/old"); ok {
+		t.Error("expected the unused entry pruned")
+	}
+	for _, url := range []string{"https://example.com/used", "https://example.com/new"} {
+		if _, ok := cache.Get(url); !ok {
+			t.Errorf("expected %s kept", url)
+		}
+	}
+	files, err := os.ReadDir(cache.Dir())
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(files) != 4 {
+		t.Errorf("expected the files of 2 entries left, got %d", len(files))
+	}
+}
+
+func TestFetcher_RevalidatesWithETag(t *testing.T) {
+	var requests, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	first := NewFetcher(WithCacheDir(dir))
+	body, err := first.Fetch(ctx, srv.URL)
+	if err != nil || string(body) != "payload" {
+		t.Fatalf("first fetch: body=%q err=%v", body, err)
+	}
+
+	// Same run: served from the validated cache without another request.
+	if _, err := first.Fetch(ctx, srv.URL); err != nil {
+		t.Fatalf("repeat fetch failed: %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("expected 1 request within a run, got %d", got)
+	}
+
+	// Next run: revalidated with If-None-Match, 304 served from disk.
+	second := NewFetcher(WithCacheDir(dir))
+	body, err = second.Fetch(ctx, srv.URL)
+	if err != nil || string(body) != "payload" {
+		t.Fatalf("second fetch: body=%q err=%v", body, err)
+	}
+	if got := notModified.Load(); got != 1 {
+		t.Errorf("expected one 304 revalidation, got %d", got)
+	}
+}
@@ -22,10 +22,14 @@ const (
 
 // Fetcher handles HTTP requests to terminaltrove.com with rate limiting and caching.
 type Fetcher struct {
-	client  *http.Client
-	limiter *rate.Limiter
-	cache   map[string][]byte
-	mu      sync.RWMutex
+	client    *http.Client
+	limiter   *rate.Limiter
+	cache     map[string][]byte
+	disk      *DiskCache
+	validated map[string]bool
+	recordDir string
+	replayDir string
+	mu        sync.RWMutex
 }
 
 // FetchResult holds the outcome of a single fetch operation.
@@ -35,15 +39,60 @@ type FetchResult struct {
 	Err  error
 }
 
+// StatusError reports a response with an unexpected HTTP status, after
+// retrying.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d", e.StatusCode)
+}
+
+// FetcherOption configures a Fetcher.
+type FetcherOption func(*Fetcher) error
+
+// WithCacheDir enables the persistent on-disk HTTP cache rooted at dir.
+// Cached responses are revalidated with ETag / Last-Modified once per run,
+// and entries unused for CacheMaxAge are pruned when the cache is opened;
+// an empty dir leaves the cache disabled.
+func WithCacheDir(dir string) FetcherOption {
+	return func(f *Fetcher) error {
+		if dir == "" {
+			return nil
+		}
+
+		disk, err := NewDiskCache(dir)
+		if err != nil {
+			return err
+		}
+		// A failed prune only leaves old entries behind.
+		_, _ = disk.Prune(CacheMaxAge)
+		f.disk = disk
+
+		return nil
+	}
+}
+
 // NewFetcher creates a new Fetcher with default rate limiting and caching.
-func NewFetcher() *Fetcher {
-	return &Fetcher{
+// Without options responses are only cached in memory for the lifetime of
+// the Fetcher. Options that fail to apply are ignored so a broken cache
+// directory degrades to plain fetching instead of aborting the crawl.
+func NewFetcher(opts ...FetcherOption) *Fetcher {
+	f := &Fetcher{
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		limiter: rate.NewLimiter(rate.Limit(globalRate), burstRate),
-		cache:   make(map[string][]byte),
+		limiter:   rate.NewLimiter(rate.Limit(globalRate), burstRate),
+		cache:     make(map[string][]byte),
+		validated: make(map[string]bool),
 	}
+
+	for _, opt := range opts {
+		_ = opt(f)
+	}
+
+	return f
 }
 
 // FetchSearchPage fetches a single search results page.
@@ -60,20 +109,42 @@ func (f *Fetcher) FetchDetailPage(ctx context.Context, slug string) ([]byte, err
 	return f.Fetch(ctx, url)
 }
 
-// Fetch retrieves a URL with caching, rate limiting, and retries.
+// Fetch retrieves a URL with caching, rate limiting, and retries. In replay
+// mode the body is read from the fixture directory instead; in record mode
+// every returned body is also written to the record directory.
 func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
-	f.mu.RLock()
-	if data, ok := f.cache[url]; ok {
-		f.mu.RUnlock()
+	if f.replayDir != "" {
+		return f.replay(url)
+	}
+
+	body, err := f.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.recordDir != "" {
+		if err := f.record(url, body); err != nil {
+			return nil, err
+		}
+	}
 
+	return body, nil
+}
+
+func (f *Fetcher) fetch(ctx context.Context, url string) ([]byte, error) {
+	if data, ok := f.cached(url); ok {
 		return data, nil
 	}
-	f.mu.RUnlock()
 
 	if err := f.limiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limit exceeded: %w", err)
 	}
 
+	var stale *CacheEntry
+	if f.disk != nil {
+		stale, _ = f.disk.Get(url)
+	}
+
 	var body []byte
 	var fetchErr error
 
@@ -85,6 +156,14 @@ func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
 
 		req.Header.Set("User-Agent",
 			"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+		if stale != nil {
+			if stale.ETag != "" {
+				req.Header.Set("If-None-Match", stale.ETag)
+			}
+			if stale.LastModified != "" {
+				req.Header.Set("If-Modified-Since", stale.LastModified)
+			}
+		}
 
 		resp, err := f.client.Do(req)
 		if err != nil {
@@ -102,6 +181,13 @@ func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
 			return nil, fmt.Errorf("fetch failed after %d attempts: %w", maxRetries, fetchErr)
 		}
 
+		if resp.StatusCode == http.StatusNotModified && stale != nil {
+			_ = resp.Body.Close()
+			f.remember(url, stale.Body, nil)
+
+			return stale.Body, nil
+		}
+
 		if resp.StatusCode != http.StatusOK {
 			_ = resp.Body.Close()
 			if attempt < maxRetries {
@@ -114,7 +200,7 @@ func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
 				}
 			}
 
-			return nil, fmt.Errorf("status %d", resp.StatusCode)
+			return nil, &StatusError{StatusCode: resp.StatusCode}
 		}
 
 		body, err = io.ReadAll(resp.Body)
@@ -133,14 +219,67 @@ func (f *Fetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
 			return nil, fmt.Errorf("read failed: %w", err)
 		}
 
+		f.remember(url, body, &CacheEntry{
+			URL:          url,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+			Body:         body,
+		})
+
 		break
 	}
 
+	return body, nil
+}
+
+// cached returns a body that is already known to be fresh for this run.
+// With a disk cache, only URLs revalidated during this run count as fresh and
+// their bodies are read back from disk rather than held in memory.
+func (f *Fetcher) cached(url string) ([]byte, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.disk == nil {
+		data, ok := f.cache[url]
+
+		return data, ok
+	}
+
+	if !f.validated[url] {
+		return nil, false
+	}
+
+	entry, ok := f.disk.Get(url)
+	if !ok {
+		return nil, false
+	}
+
+	return entry.Body, true
+}
+
+// remember records a fresh response. entry is persisted to the disk cache
+// when one is configured; a nil entry means the disk copy is already current.
+func (f *Fetcher) remember(url string, body []byte, entry *CacheEntry) {
 	f.mu.Lock()
-	f.cache[url] = body
-	f.mu.Unlock()
+	defer f.mu.Unlock()
 
-	return body, nil
+	if f.disk == nil {
+		f.cache[url] = body
+
+		return
+	}
+
+	if entry != nil {
+		if err := f.disk.Put(entry); err != nil {
+			// The response is still usable; it just won't be revalidated next run.
+			return
+		}
+	} else {
+		// Revalidated: keep the entry from being pruned.
+		_ = f.disk.Touch(url)
+	}
+	f.validated[url] = true
 }
 
 // FetchSearchPagesConcurrently fetches all search pages in parallel.
@@ -0,0 +1,101 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Fixture directories mirror the two kinds of pages the crawler fetches:
+//
+//	search/page-<n>.json  raw search API responses
+//	detail/<slug>.html    raw tool detail pages
+//
+// A directory written with WithRecordDir can be replayed with WithReplayDir,
+// which lets update run without network access and makes parser bugs
+// reproducible from the exact bytes that triggered them.
+const (
+	searchFixtureDir = "search"
+	detailFixtureDir = "detail"
+)
+
+// WithRecordDir writes every response body returned by Fetch into dir using
+// the fixture layout, including bodies served from the cache. An empty dir
+// disables recording.
+func WithRecordDir(dir string) FetcherOption {
+	return func(f *Fetcher) error {
+		f.recordDir = dir
+
+		return nil
+	}
+}
+
+// WithReplayDir makes Fetch read responses from a fixture directory instead of
+// the network. URLs without a recorded fixture fail rather than falling back
+// to a live request. An empty dir disables replay.
+func WithReplayDir(dir string) FetcherOption {
+	return func(f *Fetcher) error {
+		f.replayDir = dir
+
+		return nil
+	}
+}
+
+// fixturePath maps a terminaltrove URL to its path relative to a fixture dir.
+func fixturePath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parse url: %w", err)
+	}
+
+	path := strings.Trim(u.Path, "/")
+	if path == "search" {
+		page, err := strconv.Atoi(u.Query().Get("page"))
+		if err != nil || page < 1 {
+			return "", fmt.Errorf("no fixture for %s: invalid page", rawURL)
+		}
+
+		return filepath.Join(searchFixtureDir, fmt.Sprintf("page-%d.json", page)), nil
+	}
+
+	if path == "" || path == "." || path == ".." || strings.Contains(path, "/") {
+		return "", fmt.Errorf("no fixture for %s", rawURL)
+	}
+
+	return filepath.Join(detailFixtureDir, path+".html"), nil
+}
+
+func (f *Fetcher) replay(rawURL string) ([]byte, error) {
+	rel, err := fixturePath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(f.replayDir, rel)) //nolint:gosec // G304: path built by fixturePath
+	if err != nil {
+		return nil, fmt.Errorf("replay %s: %w", rawURL, err)
+	}
+
+	return data, nil
+}
+
+func (f *Fetcher) record(rawURL string, body []byte) error {
+	rel, err := fixturePath(rawURL)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(f.recordDir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create record dir: %w", err)
+	}
+
+	if err := writeFileAtomic(path, body); err != nil {
+		return fmt.Errorf("record %s: %w", rawURL, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixturePath(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "https://terminaltrove.com/search?q=*&page=3&per_page=100", want: "search/page-3.json"},
+		{url: "https://terminaltrove.com/bat/", want: "detail/bat.html"},
+		{url: "https://terminaltrove.com/search?q=*", wantErr: true},
+		{url: "https://terminaltrove.com/", wantErr: true},
+		{url: "https://terminaltrove.com/../etc/", wantErr: true},
+		{url: "https://terminaltrove.com/a/b/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := fixturePath(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fixturePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != filepath.FromSlash(tt.want) {
+				t.Errorf("fixturePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetcher_RecordThenReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search" {
+			_, _ = w.Write([]byte(`{"found":1,"page":1,"hits":[]}`))
+
+			return
+		}
+		_, _ = w.Write([]byte("<html>" + r.URL.Path + "</html>"))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	recorder := NewFetcher(WithRecordDir(dir))
+	ctx := context.Background()
+
+	if _, err := recorder.Fetch(ctx, srv.URL+"/search?q=*&page=1&per_page=100"); err != nil {
+		t.Fatalf("record search page: %v", err)
+	}
+	if _, err := recorder.Fetch(ctx, srv.URL+"/bat/"); err != nil {
+		t.Fatalf("record detail page: %v", err)
+	}
+
+	for _, rel := range []string{"search/page-1.json", "detail/bat.html"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+			t.Errorf("expected fixture %s: %v", rel, err)
+		}
+	}
+
+	srv.Close()
+	replayer := NewFetcher(WithReplayDir(dir))
+
+	data, err := replayer.FetchDetailPage(ctx, "bat")
+	if err != nil {
+		t.Fatalf("replay detail page: %v", err)
+	}
+	if string(data) != "<html>/bat/</html>" {
+		t.Errorf("replayed body = %q", data)
+	}
+
+	if _, err := replayer.FetchSearchPage(ctx, 1); err != nil {
+		t.Errorf("replay search page: %v", err)
+	}
+
+	if _, err := replayer.FetchDetailPage(ctx, "missing"); err == nil {
+		t.Error("expected error for unrecorded page")
+	}
+}
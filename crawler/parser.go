@@ -1,7 +1,10 @@
 package crawler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"regexp"
@@ -46,6 +49,25 @@ type HitDocument struct {
 	Language      string   `json:"language"`
 	License       []string `json:"license"`
 	ToolOfTheWeek bool     `json:"tool_of_the_week"`
+	// DateCreated is kept raw: it only feeds Fingerprint, so its exact
+	// encoding upstream (number or string) does not matter.
+	DateCreated json.RawMessage `json:"date_create_ts,omitempty"`
+}
+
+// Fingerprint returns a stable hash of the search-hit fields that change when
+// a tool's detail page changes. Incremental updates compare it against the
+// stored hash to decide whether a detail page must be re-fetched.
+func (d HitDocument) Fingerprint() string {
+	h := sha256.New()
+	for _, part := range []string{
+		d.Slug, d.Name, d.Tagline, d.Description, d.Language,
+		strings.Join(d.License, ","), fmt.Sprintf("%t", d.ToolOfTheWeek), string(d.DateCreated),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // ParseSearchResponse parses the raw JSON search response.
@@ -87,12 +109,12 @@ func ParseDetailPage(data []byte) (*DetailPage, error) {
 
 	jsonLDMatch := jsonLDRegex.FindStringSubmatch(string(data))
 	if len(jsonLDMatch) < 2 {
-		return nil, fmt.Errorf("no JSON-LD found")
+		return nil, &ParseError{Inner: ErrNoJSONLD}
 	}
 
 	var jsonLD JSONLD
 	if err := json.Unmarshal([]byte(jsonLDMatch[1]), &jsonLD); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON-LD: %w", err)
+		return nil, NewParseError("failed to parse JSON-LD: %w", err)
 	}
 
 	var softwareApp map[string]any
@@ -105,7 +127,7 @@ func ParseDetailPage(data []byte) (*DetailPage, error) {
 	}
 
 	if softwareApp == nil {
-		return nil, fmt.Errorf("no SoftwareApplication in JSON-LD")
+		return nil, NewParseError("no SoftwareApplication in JSON-LD")
 	}
 
 	page.Tool.ID = uuid.New().String()
@@ -242,7 +264,12 @@ func mapLanguageSlug(slug string) string {
 	}
 }
 
-// ParseError wraps a parsing error with context.
+// ErrNoJSONLD reports a detail page without the JSON-LD block describing the
+// tool, e.g. an error page served with status 200.
+var ErrNoJSONLD = errors.New("no JSON-LD found")
+
+// ParseError wraps a parsing error with context. ParseDetailPage returns its
+// errors as *ParseError.
 type ParseError struct {
 	Inner error
 }
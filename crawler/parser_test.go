@@ -2,6 +2,7 @@
 package crawler
 
 import (
+	"errors"
 	"os"
 	"testing"
 )
@@ -112,6 +113,19 @@ func TestParseDetailPage(t *testing.T) {
 	}
 }
 
+func TestParseDetailPageErrors(t *testing.T) {
+	_, err := ParseDetailPage([]byte(`<!DOCTYPE html><html><body></body></html>`))
+	if !errors.Is(err, ErrNoJSONLD) {
+		t.Errorf("missing JSON-LD: got %v, want ErrNoJSONLD", err)
+	}
+
+	_, err = ParseDetailPage([]byte(`<script type="application/ld+json">{"@graph":[]}</script>`))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || errors.Is(err, ErrNoJSONLD) {
+		t.Errorf("missing SoftwareApplication: got %v, want a ParseError", err)
+	}
+}
+
 func TestDetailPageToTool(t *testing.T) {
 	jsonLD := `{"@context":"https://schema.org","@graph":[{"@type":"SoftwareApplication","@id":"https://terminaltrove.com/testtool/","name":"Test Tool","description":"A test tool","programmingLanguage":"rust","codeRepository":"https://github.com/test/test","datePublished":"2024-01-01"}]}`
 	input := `<!DOCTYPE html>
@@ -241,3 +255,23 @@ func TestParseDetailPageLanguageFallback(t *testing.T) {
 		})
 	}
 }
+
+func TestHitDocumentFingerprint(t *testing.T) {
+	base := HitDocument{Slug: "bat", Name: "bat", Tagline: "A cat clone", License: []string{"mit"}}
+
+	if base.Fingerprint() != base.Fingerprint() {
+		t.Fatal("Fingerprint() is not deterministic")
+	}
+
+	changed := base
+	changed.Tagline = "A cat clone with wings"
+	if changed.Fingerprint() == base.Fingerprint() {
+		t.Error("Fingerprint() should change when the tagline changes")
+	}
+
+	dated := base
+	dated.DateCreated = []byte("1700000000")
+	if dated.Fingerprint() == base.Fingerprint() {
+		t.Error("Fingerprint() should change when the date changes")
+	}
+}
@@ -0,0 +1,63 @@
+package db
+
+import "testing"
+
+func TestBatchRuns(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	run, err := database.LatestBatchRun()
+	if err != nil || run != nil {
+		t.Fatalf("expected no batch run in a new database, got %+v (%v)", run, err)
+	}
+
+	first := &BatchRun{Tools: []BatchRunTool{{Slug: "old"}}}
+	if err := database.CreateBatchRun(first); err != nil {
+		t.Fatalf("CreateBatchRun failed: %v", err)
+	}
+
+	run = &BatchRun{
+		Options: BatchOptions{ReuseConfig: true, SudoOnlySystem: true, UseMise: true},
+		Tools: []BatchRunTool{
+			{Slug: "ripgrep"},
+			{Slug: "fd", Platform: "brew"},
+			{Slug: "bat", Mise: true},
+			{Slug: "jq"},
+		},
+	}
+	if err := database.CreateBatchRun(run); err != nil {
+		t.Fatalf("CreateBatchRun failed: %v", err)
+	}
+	if run.ID == 0 || run.ID == first.ID {
+		t.Fatalf("expected a new run ID, got %d", run.ID)
+	}
+
+	for position, status := range []string{BatchCompleted, BatchRunning, BatchFailed} {
+		if err := database.SetBatchToolStatus(run.ID, position, status, ""); err != nil {
+			t.Fatalf("SetBatchToolStatus failed: %v", err)
+		}
+	}
+
+	got, err := database.LatestBatchRun()
+	if err != nil {
+		t.Fatalf("LatestBatchRun failed: %v", err)
+	}
+	if got.ID != run.ID || got.Options != run.Options || got.StartedAt.IsZero() || len(got.Tools) != 4 {
+		t.Fatalf("run not round-tripped: %+v", got)
+	}
+	if got.Tools[1].Platform != "brew" || !got.Tools[2].Mise {
+		t.Errorf("tool pins not round-tripped: %+v", got.Tools)
+	}
+
+	unfinished := got.Unfinished()
+	if len(unfinished) != 2 || unfinished[0].Slug != "fd" || unfinished[1].Slug != "jq" {
+		t.Errorf("expected the interrupted fd and pending jq unfinished, got %+v", unfinished)
+	}
+
+	if err := database.DeleteBatchRun(run.ID); err != nil {
+		t.Fatalf("DeleteBatchRun failed: %v", err)
+	}
+	if got, err := database.LatestBatchRun(); err != nil || got != nil {
+		t.Errorf("expected no batch run after delete, got %+v (%v)", got, err)
+	}
+}
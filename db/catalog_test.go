@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUpsertTool_StableIDBySlug(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	ctx := context.Background()
+
+	seedTool(t, database, "bat", "bat")
+	if err := database.AddTag("bat", testTagCLI); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+
+	refreshed := &Tool{ID: "fresh-uuid", Slug: "bat", Name: "bat", Tagline: "new tagline", SourceHash: "abc"}
+	if err := database.UpsertTool(ctx, refreshed); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+
+	if refreshed.ID != "tool-bat" {
+		t.Errorf("expected ID to be rewritten to stored ID, got %q", refreshed.ID)
+	}
+
+	tools, err := database.GetToolBySlug("bat")
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("GetToolBySlug: %v (%d tools)", err, len(tools))
+	}
+	if tools[0].Tagline != "new tagline" {
+		t.Errorf("expected tagline to be updated, got %q", tools[0].Tagline)
+	}
+
+	tags, err := database.GetTags("bat")
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != testTagCLI {
+		t.Errorf("expected tags to survive the update, got %v", tags)
+	}
+
+	state, err := database.GetCatalogState(ctx, DefaultSource)
+	if err != nil {
+		t.Fatalf("GetCatalogState failed: %v", err)
+	}
+	if state["bat"].SourceHash != "abc" {
+		t.Errorf("expected source hash 'abc', got %q", state["bat"].SourceHash)
+	}
+}
+
+func TestReplaceInstallInstructions(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	ctx := context.Background()
+
+	seedToolWithInstall(t, database, "tool-bat", "bat", "apt install bat")
+
+	err := database.ReplaceInstallInstructions(ctx, "tool-bat", []InstallInstruction{
+		{ID: "i1", Platform: "brew", Command: "brew install bat"},
+		{ID: "i2", Platform: "cargo", Command: "cargo install bat"},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceInstallInstructions failed: %v", err)
+	}
+
+	insts, err := database.GetInstallInstructions("tool-bat")
+	if err != nil {
+		t.Fatalf("GetInstallInstructions failed: %v", err)
+	}
+	if len(insts) != 2 {
+		t.Fatalf("expected 2 install instructions, got %d", len(insts))
+	}
+	for _, inst := range insts {
+		if inst.Command == "apt install bat" {
+			t.Error("stale install instruction was not removed")
+		}
+	}
+}
+
+func TestMarkRemoved_HidesFromSearchUntilUpserted(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	ctx := context.Background()
+
+	seedTool(t, database, "bat", "bat")
+	seedTool(t, database, "fzf", "fzf")
+
+	if err := database.MarkRemoved(ctx, DefaultSource, []string{"bat"}); err != nil {
+		t.Fatalf("MarkRemoved failed: %v", err)
+	}
+
+	results, err := database.Search(ctx, SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Slug != "fzf" {
+		t.Fatalf("expected only fzf in results, got %v", results)
+	}
+
+	tools, _ := database.GetToolBySlug("bat")
+	if len(tools) != 1 || !tools[0].Removed {
+		t.Fatal("expected bat to be flagged as removed")
+	}
+
+	seedTool(t, database, "bat", "bat")
+
+	results, err = database.Search(ctx, SearchOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected bat to reappear after upsert, got %d results", len(results))
+	}
+}
+
+func TestGetCatalogState_ScopedBySource(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	ctx := context.Background()
+
+	seedTool(t, database, "bat", "bat")
+	internal := &Tool{ID: "tool-deployctl", Slug: "deployctl", Name: "deployctl", Source: "team"}
+	if err := database.UpsertTool(ctx, internal); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+
+	state, err := database.GetCatalogState(ctx, "team")
+	if err != nil {
+		t.Fatalf("GetCatalogState failed: %v", err)
+	}
+	if len(state) != 1 || state["deployctl"].ID != "tool-deployctl" {
+		t.Errorf("expected only deployctl in team state, got %v", state)
+	}
+
+	tools, err := database.GetToolBySlug("bat")
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("GetToolBySlug: %v (%d tools)", err, len(tools))
+	}
+	if tools[0].Source != DefaultSource {
+		t.Errorf("expected default source %q, got %q", DefaultSource, tools[0].Source)
+	}
+}
+
+func TestApplyCatalogRefresh(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	ctx := context.Background()
+
+	seedToolWithInstall(t, database, "tool-bat", "bat", "apt install bat")
+	seedToolWithInstall(t, database, "tool-fzf", "fzf", "apt install fzf")
+	seedTool(t, database, "jq", "jq")
+	if err := database.AddTag("bat", testTagCLI); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+
+	refresh := &CatalogRefresh{
+		Tools: []StagedTool{
+			{
+				Tool:     &Tool{ID: "fresh-bat", Slug: "bat", Name: "bat", Tagline: "new tagline"},
+				Installs: []InstallInstruction{{ID: "bat-brew", Platform: "brew", Command: "brew install bat"}},
+			},
+			{
+				// Duplicate instruction IDs make this row fail.
+				Tool: &Tool{ID: "fresh-fzf", Slug: "fzf", Name: "fzf", Tagline: "half written"},
+				Installs: []InstallInstruction{
+					{ID: "dup", Platform: "brew", Command: "brew install fzf"},
+					{ID: "dup", Platform: "cargo", Command: "cargo install fzf"},
+				},
+			},
+		},
+		Removed: map[string][]string{DefaultSource: {"jq"}},
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := database.ApplyCatalogRefresh(canceled, refresh); err == nil {
+		t.Fatal("expected a canceled refresh to fail")
+	}
+	if tools, _ := database.GetToolBySlug("jq"); len(tools) != 1 || tools[0].Removed {
+		t.Fatal("canceled refresh must leave the catalog untouched")
+	}
+
+	_, failed, err := database.ApplyCatalogRefresh(ctx, refresh)
+	if err != nil {
+		t.Fatalf("ApplyCatalogRefresh failed: %v", err)
+	}
+	if len(failed) != 1 || failed[0].Slug != "fzf" || failed[0].Err == nil {
+		t.Fatalf("expected only fzf to fail, got %+v", failed)
+	}
+
+	tools, err := database.GetToolBySlug("bat")
+	if err != nil || len(tools) != 1 || tools[0].Tagline != "new tagline" {
+		t.Fatalf("bat not refreshed: %+v (%v)", tools, err)
+	}
+	if tags, _ := database.GetTags("bat"); len(tags) != 1 || tags[0] != testTagCLI {
+		t.Errorf("expected bat's tags to survive, got %v", tags)
+	}
+	insts, err := database.GetInstallInstructions("tool-bat")
+	if err != nil || len(insts) != 1 || insts[0].Command != "brew install bat" {
+		t.Errorf("expected only the new bat install, got %+v (%v)", insts, err)
+	}
+
+	tools, _ = database.GetToolBySlug("fzf")
+	if len(tools) != 1 || tools[0].Tagline != "" {
+		t.Errorf("failed fzf row must keep its stored version, got %+v", tools)
+	}
+	insts, err = database.GetInstallInstructions("tool-fzf")
+	if err != nil || len(insts) != 1 || insts[0].Command != "apt install fzf" {
+		t.Errorf("failed fzf row must keep its install, got %+v (%v)", insts, err)
+	}
+
+	if tools, _ := database.GetToolBySlug("jq"); len(tools) != 1 || !tools[0].Removed {
+		t.Error("expected jq to be marked removed")
+	}
+}
+
+func TestCatalogSourcesSharingSlug(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	ctx := context.Background()
+
+	if err := database.UpsertTool(ctx, &Tool{ID: "tt-bat", Slug: "bat", Name: "bat"}); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+	local := &Tool{ID: "local-bat", Slug: "bat", Name: "bat (local)", Source: "local"}
+	if err := database.UpsertTool(ctx, local); !errors.Is(err, ErrSlugTaken) {
+		t.Fatalf("expected ErrSlugTaken for another source's slug, got %v", err)
+	}
+
+	// Removing bat from the other source must leave terminaltrove's row.
+	if err := database.MarkRemoved(ctx, "local", []string{"bat"}); err != nil {
+		t.Fatalf("MarkRemoved failed: %v", err)
+	}
+	tools, err := database.GetToolBySlug("bat")
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("GetToolBySlug: got %d tools, err %v", len(tools), err)
+	}
+	if tools[0].Source != DefaultSource || tools[0].Name != "bat" || tools[0].Removed {
+		t.Errorf("bat changed by the other source: %+v", tools[0])
+	}
+	state, err := database.GetCatalogState(ctx, DefaultSource)
+	if err != nil {
+		t.Fatalf("GetCatalogState failed: %v", err)
+	}
+	if _, ok := state["bat"]; !ok {
+		t.Error("bat no longer in terminaltrove's catalog state")
+	}
+
+	// Once terminaltrove removed it, the slug may move.
+	if err := database.MarkRemoved(ctx, DefaultSource, []string{"bat"}); err != nil {
+		t.Fatalf("MarkRemoved failed: %v", err)
+	}
+	if err := database.UpsertTool(ctx, local); err != nil {
+		t.Fatalf("UpsertTool of a removed slug failed: %v", err)
+	}
+	if local.ID != "tt-bat" {
+		t.Errorf("moved tool got ID %q, want the stored tt-bat", local.ID)
+	}
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApplyCatalogRefresh_RecordsChanges(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	ctx := context.Background()
+
+	seedToolWithInstall(t, database, "tool-bat", "bat", "apt install bat")
+	seedTool(t, database, "jq", "jq")
+	seedTool(t, database, "gone", "gone")
+	if err := database.MarkRemoved(ctx, DefaultSource, []string{"gone"}); err != nil {
+		t.Fatalf("MarkRemoved failed: %v", err)
+	}
+
+	run, _, err := database.ApplyCatalogRefresh(ctx, &CatalogRefresh{
+		Tools: []StagedTool{
+			{
+				Tool: &Tool{Slug: "bat", Name: "bat-cat", Tagline: "A cat clone"},
+				Installs: []InstallInstruction{
+					{ID: "bat-brew", Platform: "brew", Command: "brew install bat"},
+				},
+			},
+			{Tool: &Tool{ID: "tool-fd", Slug: "fd", Name: "fd"}},
+			{Tool: &Tool{ID: "tool-gone", Slug: "gone", Name: "gone"}},
+		},
+		Removed: map[string][]string{DefaultSource: {"jq", "gone-already"}},
+		Failed:  []FailedRow{{Slug: "broken"}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyCatalogRefresh failed: %v", err)
+	}
+
+	want := []CatalogChange{
+		{Slug: "bat", Kind: ChangeRenamed, Old: "bat", New: "bat-cat"},
+		{Slug: "bat", Kind: ChangeTagline, New: "A cat clone"},
+		{Slug: "bat", Kind: ChangeInstallAdded, Platform: "brew", New: "brew install bat"},
+		{Slug: "bat", Kind: ChangeInstallRemoved, Platform: "linux", Old: "apt install bat"},
+		{Slug: "fd", Kind: ChangeAdded, New: "fd"},
+		{Slug: "gone", Kind: ChangeAdded, New: "gone"},
+		{Slug: "jq", Kind: ChangeRemoved, Old: "jq"},
+	}
+	if run.ID == 0 || run.RanAt.IsZero() || run.Saved != 3 || run.Failed != 1 {
+		t.Errorf("unexpected run: %+v", run)
+	}
+	if len(run.Changes) != len(want) {
+		t.Fatalf("got changes %+v, want %+v", run.Changes, want)
+	}
+	for i := range want {
+		if run.Changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, run.Changes[i], want[i])
+		}
+	}
+
+	if _, _, err := database.ApplyCatalogRefresh(ctx, &CatalogRefresh{}); err != nil {
+		t.Fatalf("second ApplyCatalogRefresh failed: %v", err)
+	}
+
+	runs, err := database.ListUpdateRuns(ctx, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("ListUpdateRuns failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID <= runs[1].ID || len(runs[0].Changes) != 0 {
+		t.Fatalf("expected the empty run first, got %+v", runs)
+	}
+	if runs[1].ID != run.ID || runs[1].Failed != 1 || len(runs[1].Changes) != len(want) || runs[1].Changes[2] != want[2] {
+		t.Errorf("run not round-tripped: %+v", runs[1])
+	}
+
+	if runs, err := database.ListUpdateRuns(ctx, time.Time{}, 1); err != nil || len(runs) != 1 {
+		t.Errorf("expected the limit to apply, got %d runs (%v)", len(runs), err)
+	}
+	if runs, err := database.ListUpdateRuns(ctx, time.Now().Add(time.Hour), 0); err != nil || len(runs) != 0 {
+		t.Errorf("expected no runs since the future, got %d (%v)", len(runs), err)
+	}
+	if runs, err := database.ListUpdateRuns(ctx, time.Now().Add(-time.Hour), 0); err != nil || len(runs) != 2 {
+		t.Errorf("expected both runs since an hour ago, got %d (%v)", len(runs), err)
+	}
+}
+
+func TestApplyCatalogRefresh_TracksFailures(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	ctx := context.Background()
+
+	failed := &CatalogRefresh{Failed: []FailedRow{
+		{Slug: "bat", Source: "terminaltrove", Class: FailureHTTP, Err: errors.New("status 503")},
+		{Slug: "fd", Source: "terminaltrove"},
+	}}
+	for range 2 {
+		if _, _, err := database.ApplyCatalogRefresh(ctx, failed); err != nil {
+			t.Fatalf("ApplyCatalogRefresh failed: %v", err)
+		}
+	}
+
+	failures, err := database.ListUpdateFailures(ctx)
+	if err != nil {
+		t.Fatalf("ListUpdateFailures failed: %v", err)
+	}
+	if len(failures) != 2 {
+		t.Fatalf("got failures %+v, want bat and fd", failures)
+	}
+	bat := failures[0]
+	if bat.Slug != "bat" || bat.Class != FailureHTTP || bat.Message != "status 503" || bat.Attempts != 2 ||
+		bat.FailedAt.IsZero() {
+		t.Errorf("unexpected failure of bat: %+v", bat)
+	}
+	if failures[1].Class != FailureOther {
+		t.Errorf("unclassified failure stored as %q, want %q", failures[1].Class, FailureOther)
+	}
+
+	if _, _, err := database.ApplyCatalogRefresh(ctx, &CatalogRefresh{
+		Tools:   []StagedTool{{Tool: &Tool{ID: "tool-bat", Slug: "bat", Name: "bat"}}},
+		Removed: map[string][]string{DefaultSource: {"fd"}},
+	}); err != nil {
+		t.Fatalf("ApplyCatalogRefresh failed: %v", err)
+	}
+	if failures, err = database.ListUpdateFailures(ctx); err != nil || len(failures) != 0 {
+		t.Errorf("saved and removed tools must clear their failures, got %+v (%v)", failures, err)
+	}
+}
+
+func TestUpdateFailuresPerSource(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	ctx := context.Background()
+
+	if _, _, err := database.ApplyCatalogRefresh(ctx, &CatalogRefresh{Failed: []FailedRow{
+		{Slug: "bat", Source: "alpha", Class: FailureHTTP},
+		{Slug: "bat", Source: "beta", Class: FailureParse},
+	}}); err != nil {
+		t.Fatalf("ApplyCatalogRefresh failed: %v", err)
+	}
+	failures, err := database.ListUpdateFailures(ctx)
+	if err != nil || len(failures) != 2 || failures[0].Source != "alpha" || failures[1].Source != "beta" {
+		t.Fatalf("expected bat failed in alpha and beta, got %+v (%v)", failures, err)
+	}
+
+	// Saving bat from alpha clears only alpha's failure.
+	if _, _, err := database.ApplyCatalogRefresh(ctx, &CatalogRefresh{
+		Tools: []StagedTool{{Tool: &Tool{ID: "tool-bat", Slug: "bat", Name: "bat", Source: "alpha"}}},
+	}); err != nil {
+		t.Fatalf("ApplyCatalogRefresh failed: %v", err)
+	}
+	failures, err = database.ListUpdateFailures(ctx)
+	if err != nil || len(failures) != 1 || failures[0].Source != "beta" || failures[0].Class != FailureParse {
+		t.Errorf("expected only beta's failure left, got %+v (%v)", failures, err)
+	}
+}
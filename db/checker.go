@@ -26,6 +26,22 @@ func IsInstalled(tool *Tool, installs []InstallInstruction) bool {
 	return false
 }
 
+// InstalledExecutable returns the PATH location of the first executable of
+// installs that is installed, or "" when none is.
+func InstalledExecutable(installs []InstallInstruction) string {
+	for _, inst := range installs {
+		name := resolveExecutableName(inst)
+		if name == "" {
+			continue
+		}
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
 // resolveExecutableName returns the executable name for an install instruction.
 // It prefers the explicit ExecutableName field; falls back to parsing the command.
 func resolveExecutableName(inst InstallInstruction) string {
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func seedFilterTools(t *testing.T, database *SQLiteDB) {
+	t.Helper()
+	tools := []*Tool{
+		{ID: "t1", Slug: "ripgrep", Name: "ripgrep", Language: "rust", License: "MIT",
+			CodeRepository: "https://github.com/BurntSushi/ripgrep", DatePublished: "2023-05-10", ToolOfTheWeek: true},
+		{ID: "t2", Slug: "ripgrep-all", Name: "ripgrep-all", Language: "rust", License: "AGPL-3.0",
+			CodeRepository: "https://github.com/phiresky/ripgrep-all", DatePublished: "2024-02-01"},
+		{ID: "t3", Slug: "fd", Name: "fd", Language: "rust", License: "Apache-2.0",
+			CodeRepository: "https://github.com/sharkdp/fd", DatePublished: "2024-08-20"},
+		{ID: "t4", Slug: "deployctl", Name: "deployctl", Language: "go", Source: "internal",
+			CodeRepository: "https://git.example.com/platform/deployctl"},
+	}
+	for _, tool := range tools {
+		if err := database.UpsertTool(context.Background(), tool); err != nil {
+			t.Fatalf("UpsertTool failed: %v", err)
+		}
+	}
+}
+
+func TestSearchFilterOperators(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	seedFilterTools(t, database)
+
+	tests := []struct {
+		name  string
+		field string
+		op    string
+		value string
+		want  []string
+	}{
+		{"contains", "name", OpContains, "ripgrep", []string{"ripgrep", "ripgrep-all"}},
+		{"exact", "name", OpEquals, "RIPGREP", []string{"ripgrep"}},
+		{"regex", "name", OpMatch, "^(fd|deploy)", []string{"deployctl", "fd"}},
+		{"regex is case-insensitive", "repo", OpMatch, "burntsushi", []string{"ripgrep"}},
+		{"license", "license", OpContains, "mit", []string{"ripgrep"}},
+		{"repo", "repo", OpContains, "github.com/sharkdp", []string{"fd"}},
+		{"source", "source", OpEquals, "internal", []string{"deployctl"}},
+		{"totw", "totw", OpContains, "true", []string{"ripgrep"}},
+		{"published after", "published", OpAfter, "2024-01-01", []string{"fd", "ripgrep-all"}},
+		{"published before skips undated", "published", OpBefore, "2024", []string{"ripgrep"}},
+		{"published on or after month", "published", OpOnAfter, "2024-08", []string{"fd"}},
+		{"published in year", "published", OpContains, "2024", []string{"fd", "ripgrep-all"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFilterField(tt.field, tt.op, tt.value); err != nil {
+				t.Fatalf("ValidateFilterField(%s %s %s) failed: %v", tt.field, tt.op, tt.value, err)
+			}
+
+			results, err := database.Search(context.Background(), SearchOptions{
+				Limit:  10,
+				Filter: &Filter{Type: FilterField, Field: tt.field, Op: tt.op, Value: tt.value},
+			})
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if got := resultSlugs(results); !slices.Equal(got, tt.want) {
+				t.Errorf("%s%s%s = %v, want %v", tt.field, tt.op, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFilterField(t *testing.T) {
+	tests := []struct {
+		field   string
+		op      string
+		value   string
+		wantErr bool
+	}{
+		{"name", "", "bat", false},
+		{"Language", OpEquals, "go", false},
+		{"tag", OpMatch, "^cli$", false},
+		{"installed", OpContains, "true", false},
+		{"installed", OpContains, "Cargo", false},
+		{"installed", OpContains, "foo", true},
+		{"published", OpOnBefore, "2024-06-30", false},
+		{"lang", OpContains, "go", true},
+		{"name", OpAfter, "bat", true},
+		{"name", OpMatch, "(", true},
+		{"totw", OpContains, "maybe", true},
+		{"published", OpAfter, "last week", true},
+		{"published", OpMatch, "2024", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateFilterField(tt.field, tt.op, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateFilterField(%q, %q, %q) error = %v, wantErr %v", tt.field, tt.op, tt.value, err, tt.wantErr)
+		}
+	}
+
+	if err := ValidateFilterField("lang", OpContains, "go"); !errors.Is(err, ErrUnknownFilterField) {
+		t.Errorf("expected ErrUnknownFilterField, got %v", err)
+	}
+}
+
+func TestBuildFieldFilterUnknownMatchesNothing(t *testing.T) {
+	clause, args := buildFieldFilter("lang", OpContains, "go")
+	if clause != "0=1" || args != nil {
+		t.Errorf("expected unknown field to match nothing, got %q %v", clause, args)
+	}
+}
@@ -1,7 +1,11 @@
 package db
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -36,7 +40,6 @@ func BuildWhereClause(filter *Filter, searchTerm string) (string, []interface{})
 	}
 
 	// If no clauses (no search term and no non-empty filter), add default clause
-	// This ensures we still query the DB for filtering in Go code (e.g., installed filter)
 	if len(clauses) == 0 {
 		clauses = append(clauses, "1=1")
 	}
@@ -73,96 +76,214 @@ func buildFilterSQL(filter *Filter) (string, []interface{}) {
 		return fmt.Sprintf("NOT (%s)", innerClause), innerArgs
 
 	case FilterField:
-		return buildFieldFilter(filter.Field, filter.Value)
+		return buildFieldFilter(filter.Field, filter.Op, filter.Value)
 
 	default:
 		return "", nil
 	}
 }
 
-// buildFieldFilter creates SQL for a single field filter
-func buildFieldFilter(field, value string) (string, []interface{}) {
-	switch strings.ToLower(field) {
-	case filterFieldName:
-		return "name LIKE ?", []interface{}{"%" + value + "%"}
-	case "tagline":
-		return "tagline LIKE ?", []interface{}{"%" + value + "%"}
-	case "language":
-		return "language LIKE ?", []interface{}{"%" + value + "%"}
-	case "tag":
+// buildFieldFilter creates SQL for a single field filter. Filters are
+// expected to have passed ValidateFilterField; anything else matches nothing.
+func buildFieldFilter(field, op, value string) (string, []interface{}) {
+	spec, ok := filterFields[strings.ToLower(field)]
+	if !ok {
+		return "0=1", nil
+	}
+
+	switch spec.kind {
+	case fieldText:
+		switch op {
+		case OpEquals:
+			return spec.column + " = ? COLLATE NOCASE", []interface{}{value}
+		case OpMatch:
+			return "COALESCE(" + spec.column + ", '') REGEXP ?", []interface{}{regexpPattern(value)}
+		default:
+			return spec.column + " LIKE ?", []interface{}{"%" + value + "%"}
+		}
+
+	case fieldTag:
+		if op == OpMatch {
+			return "EXISTS (SELECT 1 FROM tool_tags WHERE tool_id = tools.id AND tag_name REGEXP ?)",
+				[]interface{}{regexpPattern(value)}
+		}
+
 		return "EXISTS (SELECT 1 FROM tool_tags WHERE tool_id = tools.id AND tag_name = ?)",
 			[]interface{}{strings.ToLower(value)}
-	case filterFieldInstalled:
-		// Special case: handled in Go after query, but needs to return a clause
-		// for AND/OR combinations to work properly
-		return "1=1", nil
+
+	case fieldBool:
+		return spec.column + " = ?", []interface{}{isTrue(value)}
+
+	case fieldDate:
+		// Compare only as many characters as given, so published>2024 means
+		// "after 2024" rather than "after 2024-00-00".
+		sqlOp := op
+		if op == "" || op == OpContains || op == OpEquals {
+			sqlOp = "="
+		}
+
+		return fmt.Sprintf("(%[1]s != '' AND substr(%[1]s, 1, %[2]d) %[3]s ?)", spec.column, len(value), sqlOp),
+			[]interface{}{value}
+
 	default:
-		// Unknown field - return always true to not filter out results
-		return "1=1", nil
+		return installedSQL(value)
 	}
 }
 
-// hasInstalledFilter checks if the filter AST contains an installed field filter
-func hasInstalledFilter(filter *Filter) bool {
-	if filter == nil {
-		return false
+// installedSQL matches tools by the state the last PATH scan stored in
+// installed_state, and for a package manager by the owner stored for the
+// executable at the scanned location.
+func installedSQL(value string) (string, []interface{}) {
+	if IsOwner(value) {
+		return `EXISTS (SELECT 1 FROM installed_state st
+			JOIN tool_owners o ON o.tool_id = st.tool_id AND o.lookup_path = st.lookup_path
+			WHERE st.tool_id = tools.id AND st.installed AND o.owner = ?)`, []interface{}{strings.ToLower(value)}
 	}
 
-	if filter.Type == FilterField && filter.Field == filterFieldInstalled {
-		return true
+	clause := "EXISTS (SELECT 1 FROM installed_state WHERE tool_id = tools.id AND installed)"
+	if !isTrue(value) {
+		clause = "NOT " + clause
 	}
 
-	if filter.Left != nil && hasInstalledFilter(filter.Left) {
-		return true
-	}
-	if filter.Right != nil && hasInstalledFilter(filter.Right) {
-		return true
+	return clause, nil
+}
+
+// Filter operators.
+const (
+	OpContains = "="  // case-insensitive substring
+	OpEquals   = "==" // case-insensitive exact match
+	OpMatch    = "~"  // case-insensitive regular expression
+	OpBefore   = "<"
+	OpAfter    = ">"
+	OpOnBefore = "<="
+	OpOnAfter  = ">="
+)
+
+type fieldKind int
+
+const (
+	fieldText fieldKind = iota
+	fieldTag
+	fieldBool
+	fieldDate
+	fieldInstalled
+)
+
+type filterField struct {
+	column string
+	kind   fieldKind
+}
+
+var filterFields = map[string]filterField{
+	filterFieldName:      {"name", fieldText},
+	"slug":               {"slug", fieldText},
+	"tagline":            {"tagline", fieldText},
+	"description":        {"description", fieldText},
+	"language":           {"language", fieldText},
+	"license":            {"license", fieldText},
+	"repo":               {"code_repository", fieldText},
+	"source":             {"source", fieldText},
+	"tag":                {"", fieldTag},
+	"totw":               {"tool_of_the_week", fieldBool},
+	"published":          {"date_published", fieldDate},
+	filterFieldInstalled: {"", fieldInstalled},
+}
+
+// ErrUnknownFilterField is returned by ValidateFilterField for fields that
+// cannot be filtered on.
+var ErrUnknownFilterField = errors.New("unknown filter field")
+
+var datePrefix = regexp.MustCompile(`^\d{4}(-\d{2}(-\d{2})?)?$`)
+
+// FilterFields returns the names of all fields that can be filtered on.
+func FilterFields() []string {
+	names := make([]string, 0, len(filterFields))
+	for name := range filterFields {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	return false
+	return names
 }
 
-// hasInstalledInOrContext checks whether the installed field filter appears
-// inside an OR node. When installed is OR-combined with other conditions
-// (e.g., OR(installed=true, language=go)), the SQL WHERE already handles the
-// non-installed branch (language=go), and a Go-side installed filter would
-// incorrectly exclude those results. In that case the Go-side filter must be
-// skipped entirely.
-func hasInstalledInOrContext(filter *Filter) bool {
-	if filter == nil {
-		return false
+// ValidateFilterField checks that field exists and that op and value make
+// sense for it, so a typo fails loudly instead of silently matching
+// everything or nothing.
+func ValidateFilterField(field, op, value string) error {
+	spec, ok := filterFields[strings.ToLower(field)]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownFilterField, field)
+	}
+	if op == "" {
+		op = OpContains
 	}
 
-	if filter.Type == FilterOr {
-		if hasInstalledFilter(filter.Left) || hasInstalledFilter(filter.Right) {
-			return true
+	var ops []string
+	switch spec.kind {
+	case fieldText, fieldTag:
+		ops = []string{OpContains, OpEquals, OpMatch}
+	case fieldBool:
+		ops = []string{OpContains, OpEquals}
+		if !isBool(value) {
+			return fmt.Errorf("%s expects true or false, got %q", field, value)
+		}
+	case fieldInstalled:
+		ops = []string{OpContains, OpEquals}
+		if !isBool(value) && !IsOwner(value) {
+			return fmt.Errorf("%s expects true, false or a package manager (%s), got %q",
+				field, strings.Join(owners, ", "), value)
+		}
+	case fieldDate:
+		ops = []string{OpContains, OpEquals, OpBefore, OpAfter, OpOnBefore, OpOnAfter}
+		if !datePrefix.MatchString(value) {
+			return fmt.Errorf("%s expects a date like 2024, 2024-06 or 2024-06-30, got %q", field, value)
 		}
 	}
 
-	if hasInstalledInOrContext(filter.Left) {
-		return true
+	if !slices.Contains(ops, op) {
+		return fmt.Errorf("operator %q is not supported for %s (use %s)", op, field, strings.Join(ops, " "))
+	}
+
+	if op == OpMatch {
+		if _, err := regexp.Compile(regexpPattern(value)); err != nil {
+			return fmt.Errorf("invalid regular expression for %s: %w", field, err)
+		}
 	}
 
-	return hasInstalledInOrContext(filter.Right)
+	return nil
 }
 
-// getInstalledFilterValue extracts the value from an installed filter
-func getInstalledFilterInfo(filter *Filter, negated bool) (string, bool) {
-	if filter == nil {
-		return "", negated
-	}
+// regexpPattern makes a filter regex case-insensitive like the other
+// operators; a leading (?-i) turns that back off.
+func regexpPattern(value string) string {
+	return "(?i)" + value
+}
 
-	if filter.Type == FilterNot {
-		return getInstalledFilterInfo(filter.Left, !negated)
+func isBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "false", "1", "0":
+		return true
 	}
 
-	if filter.Type == FilterField && filter.Field == filterFieldInstalled {
-		return filter.Value, negated
+	return false
+}
+
+func isTrue(value string) bool {
+	v := strings.ToLower(value)
+
+	return v == "true" || v == "1"
+}
+
+// FilterUsesInstalled reports whether the filter AST has an installed=
+// predicate, which is answered from the stored installed state.
+func FilterUsesInstalled(filter *Filter) bool {
+	if filter == nil {
+		return false
 	}
 
-	if value, n := getInstalledFilterInfo(filter.Left, negated); value != "" {
-		return value, n
+	if filter.Type == FilterField && strings.EqualFold(filter.Field, filterFieldInstalled) {
+		return true
 	}
 
-	return getInstalledFilterInfo(filter.Right, negated)
+	return FilterUsesInstalled(filter.Left) || FilterUsesInstalled(filter.Right)
 }
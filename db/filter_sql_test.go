@@ -208,142 +208,57 @@ func TestBuildFilterSQLNotWithOr(t *testing.T) {
 	}
 }
 
-func TestGetInstalledFilterInfoNotNegated(t *testing.T) {
-	filter := &Filter{
-		Type: FilterNot,
-		Left: &Filter{
-			Type:  FilterField,
-			Field: "installed",
-			Value: "true",
-		},
-	}
-
-	value, negated := getInstalledFilterInfo(filter, false)
-
-	if value != "true" {
-		t.Errorf("expected value 'true', got '%s'", value)
-	}
-	if !negated {
-		t.Errorf("expected negated=true")
-	}
-}
-
-func TestGetInstalledFilterInfoDoubleNegation(t *testing.T) {
-	filter := &Filter{
-		Type: FilterNot,
-		Left: &Filter{
-			Type: FilterNot,
-			Left: &Filter{
-				Type:  FilterField,
-				Field: "installed",
-				Value: "true",
-			},
-		},
-	}
-
-	value, negated := getInstalledFilterInfo(filter, false)
-
-	if value != "true" {
-		t.Errorf("expected value 'true', got '%s'", value)
-	}
-	if negated {
-		t.Errorf("expected negated=false (double negation)")
+func TestInstalledSQL(t *testing.T) {
+	tests := []struct {
+		value    string
+		contains string
+		negated  bool
+		args     int
+	}{
+		{"true", "installed_state", false, 0},
+		{"1", "installed_state", false, 0},
+		{"false", "installed_state", true, 0},
+		{"Cargo", "tool_owners", false, 1},
+	}
+
+	for _, tt := range tests {
+		clause, args := installedSQL(tt.value)
+		if !strings.Contains(clause, tt.contains) || strings.HasPrefix(clause, "NOT") != tt.negated {
+			t.Errorf("installedSQL(%q) = %s", tt.value, clause)
+		}
+		if len(args) != tt.args {
+			t.Errorf("installedSQL(%q) args = %v, want %d", tt.value, args, tt.args)
+		}
+	}
+
+	if _, args := installedSQL("Cargo"); args[0] != "cargo" {
+		t.Errorf("expected the owner lower-cased, got %v", args)
 	}
 }
 
-func TestInstalledFilterValueNot(t *testing.T) {
-	filter := &Filter{
-		Type: FilterNot,
-		Left: &Filter{
-			Type:  FilterField,
-			Field: "installed",
-			Value: "true",
-		},
-	}
-
-	wantInstalled, negated := installedFilterValue(filter)
-
-	if !wantInstalled {
-		t.Errorf("expected wantInstalled=true, got false")
-	}
-	if !negated {
-		t.Errorf("expected negated=true")
-	}
-}
-
-func TestHasInstalledInOrContextTrue(t *testing.T) {
-	// OR(installed=true, language=go) — installed in OR context
-	filter := &Filter{
-		Type: FilterOr,
-		Left: &Filter{
-			Type:  FilterField,
-			Field: "installed",
-			Value: "true",
-		},
-		Right: &Filter{
-			Type:  FilterField,
-			Field: "language",
-			Value: "go",
-		},
-	}
-
-	if !hasInstalledInOrContext(filter) {
-		t.Errorf("expected hasInstalledInOrContext=true for OR(installed, language)")
-	}
-}
-
-func TestHasInstalledInOrContextFalse(t *testing.T) {
-	// AND(installed=true, language=go) — installed NOT in OR context
-	filter := &Filter{
-		Type: FilterAnd,
-		Left: &Filter{
-			Type:  FilterField,
-			Field: "installed",
-			Value: "true",
-		},
-		Right: &Filter{
-			Type:  FilterField,
-			Field: "language",
-			Value: "go",
-		},
-	}
-
-	if hasInstalledInOrContext(filter) {
-		t.Errorf("expected hasInstalledInOrContext=false for AND(installed, language)")
-	}
-}
-
-func TestHasInstalledInOrContextNil(t *testing.T) {
-	if hasInstalledInOrContext(nil) {
-		t.Errorf("expected hasInstalledInOrContext=false for nil")
-	}
-}
-
-func TestHasInstalledInOrContextNestedOr(t *testing.T) {
-	// AND(OR(installed=true, language=go), tag=cli) — installed in nested OR
-	filter := &Filter{
-		Type: FilterAnd,
-		Left: &Filter{
+func TestFilterUsesInstalled(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"nil", nil, false},
+		{"installed=true", &Filter{Type: FilterField, Field: "installed", Value: "true"}, true},
+		{"installed=cargo", &Filter{Type: FilterField, Field: "installed", Value: "cargo"}, true},
+		{"nested", &Filter{
 			Type: FilterOr,
-			Left: &Filter{
-				Type:  FilterField,
-				Field: "installed",
-				Value: "true",
-			},
+			Left: &Filter{Type: FilterField, Field: "language", Value: "go"},
 			Right: &Filter{
-				Type:  FilterField,
-				Field: "language",
-				Value: "go",
+				Type: FilterNot,
+				Left: &Filter{Type: FilterField, Field: "installed", Value: "brew"},
 			},
-		},
-		Right: &Filter{
-			Type:  FilterField,
-			Field: "tag",
-			Value: "cli",
-		},
+		}, true},
+		{"other field", &Filter{Type: FilterField, Field: "name", Value: "cargo"}, false},
 	}
 
-	if !hasInstalledInOrContext(filter) {
-		t.Errorf("expected hasInstalledInOrContext=true for nested OR")
+	for _, tt := range tests {
+		if got := FilterUsesInstalled(tt.filter); got != tt.want {
+			t.Errorf("%s: FilterUsesInstalled = %v, want %v", tt.name, got, tt.want)
+		}
 	}
 }
@@ -0,0 +1,14 @@
+//go:build sqlite_fts5
+
+package db
+
+import "testing"
+
+// With the sqlite_fts5 tag the FTS tests must run rather than skip.
+func TestFTSEnabledWithTag(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	if !database.FullTextSearch() {
+		t.Fatal("built with sqlite_fts5 but SQLite reports FTS5 unavailable")
+	}
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+func seedSearchTools(t *testing.T, database *SQLiteDB) {
+	t.Helper()
+	tools := []*Tool{
+		{ID: "t1", Slug: "aardvark", Name: "aardvark", Tagline: "Terminal file manager",
+			Description: "Can preview json files among many others"},
+		{ID: "t2", Slug: "jq", Name: "jq", Tagline: "Command-line JSON processor"},
+		{ID: "t3", Slug: "json", Name: "json", Tagline: "Pretty print structured data"},
+		{ID: "t4", Slug: "mysql-cli", Name: "mysql-cli", Tagline: "Database shell"},
+	}
+	for _, tool := range tools {
+		if err := database.UpsertTool(context.Background(), tool); err != nil {
+			t.Fatalf("UpsertTool failed: %v", err)
+		}
+	}
+}
+
+func resultSlugs(results []SearchResult) []string {
+	slugs := make([]string, len(results))
+	for i, r := range results {
+		slugs[i] = r.Slug
+	}
+
+	return slugs
+}
+
+func TestSearchRelevanceOrder(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	seedSearchTools(t, database)
+
+	results, err := database.Search(context.Background(), SearchOptions{
+		Query: "json", SortField: SortFieldRelevance, Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	got := resultSlugs(results)
+	want := []string{"json", "jq", "aardvark"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v (fts=%v)", got, want, database.fts)
+		}
+	}
+}
+
+func TestSearchSubstringStillMatches(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	seedSearchTools(t, database)
+
+	results, err := database.Search(context.Background(), SearchOptions{
+		Query: "sql", SortField: SortFieldRelevance, Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := resultSlugs(results); len(got) != 1 || got[0] != "mysql-cli" {
+		t.Errorf("expected substring match mysql-cli, got %v", got)
+	}
+}
+
+func TestSearchFTSIndexesTagsAndUpdates(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	if !database.fts {
+		t.Skip("SQLite built without FTS5; run with -tags sqlite_fts5")
+	}
+	seedSearchTools(t, database)
+	ctx := context.Background()
+
+	if err := database.AddTag("aardvark", "filemanager"); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+	results, err := database.Search(ctx, SearchOptions{Query: "filemanager", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := resultSlugs(results); len(got) != 1 || got[0] != "aardvark" {
+		t.Errorf("expected tag hit aardvark, got %v", got)
+	}
+
+	if err := database.RemoveTag("aardvark", "filemanager"); err != nil {
+		t.Fatalf("RemoveTag failed: %v", err)
+	}
+	results, err = database.Search(ctx, SearchOptions{Query: "filemanager", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no hits after tag removal, got %v", resultSlugs(results))
+	}
+
+	updated := &Tool{ID: "t2", Slug: "jq", Name: "jq", Tagline: "Slice and filter streams"}
+	if err := database.UpsertTool(ctx, updated); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+	results, err = database.Search(ctx, SearchOptions{Query: "streams", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := resultSlugs(results); len(got) != 1 || got[0] != "jq" {
+		t.Errorf("expected updated tagline to be indexed, got %v", got)
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"json", `"json"*`},
+		{"json parser", `"json"* "parser"*`},
+		{`*bat* "x`, `"bat"* """x"*`},
+		{"- *", ""},
+	}
+	for _, tt := range tests {
+		if got := ftsQuery(tt.in); got != tt.want {
+			t.Errorf("ftsQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSetupFTSRebuildsWhenTriggersMissing(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+	if !database.fts {
+		t.Skip("SQLite built without FTS5; run with -tags sqlite_fts5")
+	}
+	ctx := context.Background()
+
+	// Simulate a write by a binary built without FTS5: triggers gone, index stale.
+	for name := range ftsTriggers {
+		if _, err := database.db.ExecContext(ctx, `DROP TRIGGER `+name); err != nil {
+			t.Fatalf("drop trigger: %v", err)
+		}
+	}
+	seedSearchTools(t, database)
+	if err := database.AddTag("jq", "streaming"); err != nil {
+		t.Fatalf("AddTag failed: %v", err)
+	}
+
+	if err := database.setupFTS(); err != nil {
+		t.Fatalf("setupFTS failed: %v", err)
+	}
+
+	// Tags are only searchable through the index, so a hit proves the rebuild.
+	results, err := database.Search(ctx, SearchOptions{Query: "streaming", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := resultSlugs(results); len(got) != 1 || got[0] != "jq" {
+		t.Errorf("expected rebuilt index to find jq, got %v", got)
+	}
+}
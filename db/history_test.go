@@ -0,0 +1,110 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInstallHistory(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	records := []*InstallRecord{
+		{Slug: "ripgrep", Platform: "brew", Command: "brew install ripgrep", Duration: 1500 * time.Millisecond},
+		{Slug: "fd", Platform: "apt", Command: "sudo apt install fd-find", ExitCode: 100, Output: "E: locked"},
+		{Slug: "ripgrep", Platform: "cargo", Command: "cargo install ripgrep", ExitCode: 101},
+	}
+	for _, rec := range records {
+		if err := database.RecordInstall(rec); err != nil {
+			t.Fatalf("RecordInstall failed: %v", err)
+		}
+		if rec.ID == 0 {
+			t.Errorf("RecordInstall did not set the ID of %s", rec.Command)
+		}
+	}
+
+	all, err := database.ListInstallHistory(HistoryFilter{})
+	if err != nil {
+		t.Fatalf("ListInstallHistory failed: %v", err)
+	}
+	if len(all) != 3 || all[0].Command != "cargo install ripgrep" {
+		t.Fatalf("expected 3 records newest first, got %+v", all)
+	}
+	if all[2].Duration != 1500*time.Millisecond || all[2].InstalledAt.IsZero() {
+		t.Errorf("duration or timestamp not round-tripped: %+v", all[2])
+	}
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string
+	}{
+		{"by slug", HistoryFilter{Slug: "ripgrep"}, []string{"cargo install ripgrep", "brew install ripgrep"}},
+		{"failed", HistoryFilter{Failed: true}, []string{"cargo install ripgrep", "sudo apt install fd-find"}},
+		{"failed slug", HistoryFilter{Slug: "fd", Failed: true}, []string{"sudo apt install fd-find"}},
+		{"limit", HistoryFilter{Limit: 1}, []string{"cargo install ripgrep"}},
+		{"succeeded", HistoryFilter{Succeeded: true}, []string{"brew install ripgrep"}},
+		{"action", HistoryFilter{Action: ActionUninstall}, nil},
+		{"no match", HistoryFilter{Slug: "bat"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := database.ListInstallHistory(tt.filter)
+			if err != nil {
+				t.Fatalf("ListInstallHistory failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d records, got %+v", len(tt.want), got)
+			}
+			for i, rec := range got {
+				if rec.Command != tt.want[i] {
+					t.Errorf("record %d: expected %q, got %q", i, tt.want[i], rec.Command)
+				}
+			}
+		})
+	}
+
+	if err := database.RecordInstall(&InstallRecord{Command: "true"}); err == nil {
+		t.Error("expected error for a record without slug")
+	}
+}
+
+func TestLastSuccessfulInstall(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	rec, err := database.LastSuccessfulInstall("ripgrep")
+	if err != nil || rec != nil {
+		t.Fatalf("expected no install for empty history, got %+v (%v)", rec, err)
+	}
+
+	for _, r := range []*InstallRecord{
+		{Slug: "ripgrep", Command: "brew install ripgrep"},
+		{Slug: "ripgrep", Command: "cargo install ripgrep", ExitCode: 101},
+	} {
+		if err := database.RecordInstall(r); err != nil {
+			t.Fatalf("RecordInstall failed: %v", err)
+		}
+	}
+
+	rec, err = database.LastSuccessfulInstall("ripgrep")
+	if err != nil || rec == nil || rec.Command != "brew install ripgrep" || rec.Action != ActionInstall {
+		t.Fatalf("expected the brew install, got %+v (%v)", rec, err)
+	}
+
+	err = database.RecordInstall(&InstallRecord{Action: ActionUpgrade, Slug: "ripgrep", Command: "brew upgrade ripgrep"})
+	if err != nil {
+		t.Fatalf("RecordInstall failed: %v", err)
+	}
+	if rec, err := database.LastSuccessfulInstall("ripgrep"); err != nil || rec == nil || rec.Command != "brew install ripgrep" {
+		t.Errorf("expected the brew install to survive an upgrade, got %+v (%v)", rec, err)
+	}
+
+	err = database.RecordInstall(&InstallRecord{Action: ActionUninstall, Slug: "ripgrep", Command: "brew uninstall ripgrep"})
+	if err != nil {
+		t.Fatalf("RecordInstall failed: %v", err)
+	}
+	if rec, err := database.LastSuccessfulInstall("ripgrep"); err != nil || rec != nil {
+		t.Errorf("expected no install after a successful uninstall, got %+v (%v)", rec, err)
+	}
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("migration %q has version %d, want %d", m.name, m.version, i+1)
+		}
+	}
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	version, err := database.schemaVersion(context.Background())
+	if err != nil || version != len(migrations) {
+		t.Errorf("schema version = %d (%v), want %d", version, err, len(migrations))
+	}
+}
+
+func TestMigrateUnversionedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "troveler.db")
+	legacy, err := sql.Open(driverName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// tools as created before the source columns were added.
+	if _, err := legacy.ExecContext(context.Background(), `
+		CREATE TABLE tools (id TEXT PRIMARY KEY, slug TEXT UNIQUE, name TEXT, tagline TEXT, description TEXT,
+			language TEXT, license TEXT, date_published TEXT, code_repository TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO tools VALUES ('t1', 'ripgrep', 'ripgrep', '', '', 'rust', 'MIT', '', '',
+			CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	database, err := New(path)
+	if err != nil {
+		t.Fatalf("New failed on an unversioned database: %v", err)
+	}
+	defer checkClose(t, database)
+
+	tools, err := database.GetToolBySlug("ripgrep")
+	if err != nil || len(tools) != 1 || tools[0].Source != DefaultSource {
+		t.Errorf("expected ripgrep with the default source after migrating, got %+v (%v)", tools, err)
+	}
+
+	backup, err := sql.Open(driverName, path+".bak-v0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = backup.Close() }()
+	var columns int
+	if err := backup.QueryRowContext(context.Background(),
+		"SELECT COUNT(*) FROM pragma_table_info('tools') WHERE name = 'source'").Scan(&columns); err != nil {
+		t.Fatalf("expected a backup of the unmigrated database: %v", err)
+	}
+	if columns != 0 {
+		t.Error("expected the backup to hold the schema from before migrating")
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "troveler.db")
+	database, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.db.ExecContext(context.Background(), "PRAGMA user_version = 999"); err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, database)
+
+	_, err = New(path)
+	var tooNew *SchemaTooNewError
+	if !errors.As(err, &tooNew) || tooNew.Version != 999 || tooNew.Supported != len(migrations) {
+		t.Fatalf("expected a SchemaTooNewError, got %v", err)
+	}
+	if _, err := os.Stat(path + ".bak-v999"); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected no backup of a database that was not migrated")
+	}
+}
+
+func TestMigrateRollsBackFailedMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "troveler.db")
+	database, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkClose(t, database)
+
+	saved := migrations
+	t.Cleanup(func() { migrations = saved })
+	migrations = append(append([]migration(nil), saved...), migration{
+		version: len(saved) + 1,
+		name:    "broken",
+		up: func(ctx context.Context, tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, "CREATE TABLE half_done (id INTEGER)"); err != nil {
+				return err
+			}
+
+			return errors.New("boom")
+		},
+	})
+
+	if _, err := New(path); err == nil {
+		t.Fatal("expected the broken migration to fail")
+	}
+
+	migrations = saved
+	database, err = New(path)
+	if err != nil {
+		t.Fatalf("expected the database to open at its old version: %v", err)
+	}
+	defer checkClose(t, database)
+
+	var tables int
+	if err := database.db.QueryRowContext(context.Background(),
+		"SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_done'").Scan(&tables); err != nil || tables != 0 {
+		t.Errorf("expected the failed migration to be rolled back, found %d tables (%v)", tables, err)
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s.bak-v%d", path, len(saved))); err != nil {
+		t.Errorf("expected a backup from before the failed migration: %v", err)
+	}
+}
@@ -15,11 +15,102 @@ type Tool struct {
 	DatePublished  string    `json:"date_published" db:"date_published"`
 	CodeRepository string    `json:"code_repository" db:"code_repository"`
 	ToolOfTheWeek  bool      `json:"tool_of_the_week" db:"tool_of_the_week"`
+	SourceHash     string    `json:"-" db:"source_hash"`
+	Removed        bool      `json:"removed" db:"removed"`
+	Source         string    `json:"source" db:"source"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 	Installed      bool      `json:"installed" db:"-"`
 }
 
+// DefaultSource is the source recorded for tools crawled from terminaltrove.com,
+// including every tool stored before sources were tracked.
+const DefaultSource = "terminaltrove"
+
+// CatalogEntry is the per-slug state incremental updates compare against.
+type CatalogEntry struct {
+	ID         string
+	SourceHash string
+	Removed    bool
+}
+
+// StagedTool is a fetched tool waiting in a CatalogRefresh, together with
+// the install instructions that replace its stored ones.
+type StagedTool struct {
+	Tool     *Tool
+	Installs []InstallInstruction
+}
+
+// CatalogRefresh is the staged result of an update, written by
+// ApplyCatalogRefresh in a single transaction.
+type CatalogRefresh struct {
+	Tools   []StagedTool
+	Removed map[string][]string // slugs that vanished upstream, by source
+	Failed  []FailedRow         // tools that could not be fetched
+}
+
+// Classes of update failures.
+const (
+	FailureHTTP     = "http"       // the server answered with an error status
+	FailureTimeout  = "timeout"    // the request timed out
+	FailureNetwork  = "network"    // the server could not be reached
+	FailureNoJSONLD = "no_json_ld" // the detail page lacks the JSON-LD describing the tool
+	FailureParse    = "parse"      // the detail page could not be parsed
+	FailureDatabase = "database"   // the fetched tool could not be saved
+	FailureOther    = "other"
+)
+
+// FailedRow is a tool an update could not refresh, and why.
+type FailedRow struct {
+	Slug   string
+	Source string
+	Class  string // one of the Failure* constants
+	Err    error
+}
+
+// UpdateFailure is a tool that failed to refresh the last time an update
+// tried it. A later successful refresh, or its removal upstream, clears it.
+type UpdateFailure struct {
+	Slug     string    `json:"slug"`
+	Source   string    `json:"source"`
+	Class    string    `json:"class"` // one of the Failure* constants
+	Message  string    `json:"message"`
+	Attempts int       `json:"attempts"` // updates in a row that failed to refresh it
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// Kinds of CatalogChange.
+const (
+	ChangeAdded          = "added"
+	ChangeRemoved        = "removed"
+	ChangeRenamed        = "renamed"
+	ChangeTagline        = "tagline"
+	ChangeDescription    = "description"
+	ChangeInstallAdded   = "install_added"
+	ChangeInstallRemoved = "install_removed"
+	ChangeInstallChanged = "install_changed"
+)
+
+// CatalogChange is one difference an update made to the catalog. Old and New
+// hold the name, tagline, description or install command before and after.
+type CatalogChange struct {
+	Slug     string `json:"slug"`
+	Kind     string `json:"kind"`               // one of the Change* constants
+	Platform string `json:"platform,omitempty"` // for install changes
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new,omitempty"`
+}
+
+// UpdateRun is a recorded update: when it ran, how many tools it saved or
+// failed to refresh, and what it changed.
+type UpdateRun struct {
+	ID      int64           `json:"id"`
+	RanAt   time.Time       `json:"ran_at"`
+	Saved   int             `json:"saved"`
+	Failed  int             `json:"failed"`
+	Changes []CatalogChange `json:"changes"`
+}
+
 // InstallInstruction represents a single install command for a platform.
 type InstallInstruction struct {
 	ID             string    `json:"id" db:"id"`
@@ -43,6 +134,7 @@ type SearchOptions struct {
 	SortField string
 	SortOrder string
 	Filter    *Filter
+	Slugs     []string // restrict results to these slugs; nil means no restriction
 }
 
 // FilterType enumerates the kinds of filter tree nodes.
@@ -55,7 +147,7 @@ const (
 	FilterOr
 	// FilterNot is a logical NOT node.
 	FilterNot
-	// FilterField is a field=value leaf node.
+	// FilterField is a field comparison leaf node, e.g. name=bat.
 	FilterField
 )
 
@@ -63,6 +155,7 @@ const (
 type Filter struct {
 	Type  FilterType
 	Field string
+	Op    string // one of the Op* constants; "" means OpContains
 	Value string
 	Left  *Filter
 	Right *Filter
@@ -74,6 +167,131 @@ type Tag struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// SavedQuery is a named search expression, run with "search @name".
+type SavedQuery struct {
+	Name       string    `json:"name"`
+	Expression string    `json:"expression"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Install history actions.
+const (
+	ActionInstall   = "install"
+	ActionUninstall = "uninstall"
+	ActionUpgrade   = "upgrade"
+)
+
+// InstallRecord is one entry of the install history: a command troveler ran
+// to install, uninstall or upgrade a tool, and how it went.
+type InstallRecord struct {
+	ID          int64         `json:"id"`
+	Action      string        `json:"action"` // ActionInstall, ActionUninstall or ActionUpgrade; "" records an install
+	Slug        string        `json:"slug"`
+	Platform    string        `json:"platform"`
+	Command     string        `json:"command"` // as executed, after sudo and mise rewriting
+	ExitCode    int           `json:"exit_code"`
+	Duration    time.Duration `json:"duration"`
+	Output      string        `json:"output"` // tail of combined stdout and stderr
+	User        string        `json:"user"`
+	InstalledAt time.Time     `json:"installed_at"`
+}
+
+// Succeeded reports whether the install command exited cleanly.
+func (r *InstallRecord) Succeeded() bool {
+	return r.ExitCode == 0
+}
+
+// HistoryFilter narrows ListInstallHistory.
+type HistoryFilter struct {
+	Slug      string
+	Action    string // ActionInstall, ActionUninstall or ActionUpgrade; "" = all
+	Failed    bool   // only records with a non-zero exit code
+	Succeeded bool   // only records with a zero exit code
+	Limit     int    // 0 = no limit
+}
+
+// Batch run tool statuses.
+const (
+	BatchPending   = "pending"
+	BatchRunning   = "running" // left behind when the batch was interrupted
+	BatchCompleted = "completed"
+	BatchFailed    = "failed"
+	BatchSkipped   = "skipped"
+)
+
+// BatchRun is a batch install in progress, kept so an interrupted one can be
+// resumed. Runs are deleted once every tool has finished.
+type BatchRun struct {
+	ID        int64
+	Options   BatchOptions
+	Tools     []BatchRunTool // in install order
+	StartedAt time.Time
+}
+
+// BatchOptions are the batch install choices made when the run started.
+type BatchOptions struct {
+	ReuseConfig    bool // false: the CLI asks for every tool
+	UseSudo        bool
+	SudoOnlySystem bool
+	SkipIfBlind    bool
+	UseMise        bool
+}
+
+// BatchRunTool is one tool of a batch run.
+type BatchRunTool struct {
+	Position int
+	Slug     string
+	Platform string // platform pin, e.g. from a sync manifest
+	Mise     bool   // install through mise regardless of the options
+	Status   string // BatchPending, BatchRunning, ...
+	Detail   string // why the tool failed or was skipped
+}
+
+// Unfinished returns the tools that still need installing: those never
+// started and those interrupted while running.
+func (r *BatchRun) Unfinished() []BatchRunTool {
+	var tools []BatchRunTool
+	for _, tool := range r.Tools {
+		if tool.Status == BatchPending || tool.Status == BatchRunning {
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools
+}
+
+// ToolVersion is the last version check of an installed tool: the version
+// found on this machine against the newest upstream release.
+type ToolVersion struct {
+	ToolID    string    `json:"tool_id"`
+	Slug      string    `json:"slug"`
+	Installed string    `json:"installed"` // "" when the version could not be determined
+	Latest    string    `json:"latest"`    // "" when there is no known upstream release
+	Outdated  bool      `json:"outdated"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// ToolOwner records which package manager installed a tool's executable on
+// this machine.
+type ToolOwner struct {
+	ToolID    string    `json:"tool_id"`
+	Owner     string    `json:"owner"`   // one of the Owner* constants, "" when unknown
+	Package   string    `json:"package"` // the owner's package name, "" when unknown
+	Path      string    `json:"path"`    // the executable, symlinks resolved
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// InstalledState is where the last PATH scan found a tool's executable.
+type InstalledState struct {
+	ToolID     string    `json:"tool_id"`
+	Executable string    `json:"executable"`  // the name looked up on PATH
+	LookupPath string    `json:"lookup_path"` // where PATH lookup found it, "" when not installed
+	Path       string    `json:"path"`        // LookupPath with symlinks resolved
+	ModTime    time.Time `json:"mtime"`
+	Installed  bool      `json:"installed"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
 // TagCount pairs a tag name with its usage count.
 type TagCount struct {
 	Name  string `json:"name"`
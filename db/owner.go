@@ -0,0 +1,270 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Package managers an installed executable can belong to. The names match
+// the install method families of the platform package, so installed=cargo
+// reads the same as the install command it came from.
+const (
+	OwnerApt    = "apt" // dpkg
+	OwnerRPM    = "rpm" // dnf, yum and zypper
+	OwnerPacman = "pacman"
+	OwnerApk    = "apk"
+	OwnerBrew   = "brew"
+	OwnerCargo  = "cargo"
+	OwnerGo     = "go"
+	OwnerMise   = "mise"
+	OwnerPipx   = "pipx"
+	OwnerUv     = "uv"
+	OwnerNpm    = "npm"
+	OwnerNix    = "nix"
+	OwnerSnap   = "snap"
+	OwnerScoop  = "scoop"
+)
+
+var owners = []string{
+	OwnerApt, OwnerRPM, OwnerPacman, OwnerApk, OwnerBrew, OwnerCargo, OwnerGo, OwnerMise, OwnerPipx, OwnerUv,
+	OwnerNpm, OwnerNix, OwnerSnap, OwnerScoop,
+}
+
+// Owners returns the package manager names DetectOwner can report.
+func Owners() []string {
+	return append([]string(nil), owners...)
+}
+
+// IsOwner reports whether name is one of Owners.
+func IsOwner(name string) bool {
+	for _, o := range owners {
+		if strings.EqualFold(o, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CommandRunner runs a command and returns its combined output.
+type CommandRunner func(ctx context.Context, name string, args ...string) (string, error)
+
+// ownerProbeTimeout bounds each package manager query.
+const ownerProbeTimeout = 5 * time.Second
+
+// ExecCommandRunner runs commands with os/exec.
+func ExecCommandRunner(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ownerProbeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+
+	return string(out), err
+}
+
+// ownerMarkers map path fragments to the package manager that installs
+// there. The package name, where there is one, is the path segment right
+// after the fragment.
+var ownerMarkers = []struct {
+	fragment string
+	owner    string
+	named    bool
+}{
+	{"/.cargo/bin/", OwnerCargo, false},
+	{"/Cellar/", OwnerBrew, true},
+	{"/homebrew/", OwnerBrew, false},
+	{"/linuxbrew/", OwnerBrew, false},
+	{"/mise/installs/", OwnerMise, true},
+	{"/pipx/venvs/", OwnerPipx, true},
+	{"/uv/tools/", OwnerUv, true},
+	{"/node_modules/", OwnerNpm, true},
+	{"/nix/store/", OwnerNix, true},
+	{"/snap/bin/", OwnerSnap, false},
+	{"/scoop/apps/", OwnerScoop, true},
+}
+
+// DetectOwner finds the package manager that installed the executable at
+// path. Locations only one manager writes to (~/.cargo/bin, GOBIN, pipx
+// venvs, mise shims, the Homebrew cellar, ...) are recognized from the
+// resolved path; anything else is asked of the system package database
+// (dpkg -S, rpm -qf, pacman -Qo, apk --who-owns) and finally npm's global
+// prefix. Owner is empty when nobody claims the file.
+func DetectOwner(ctx context.Context, path string, run CommandRunner) ToolOwner {
+	result := ToolOwner{Path: path}
+
+	if strings.Contains(filepath.ToSlash(path), "/mise/shims/") {
+		result.Owner, result.Package = OwnerMise, filepath.Base(path)
+		if out, err := run(ctx, "mise", "which", filepath.Base(path)); err == nil && strings.TrimSpace(out) != "" {
+			result.Path = firstOutputLine(out)
+		}
+
+		return result
+	}
+
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		result.Path = resolved
+	}
+
+	if owner, pkg := ownerFromPath(result.Path); owner != "" {
+		result.Owner, result.Package = owner, pkg
+
+		return result
+	}
+	if owner := ownerFromEnvDirs(result.Path); owner != "" {
+		result.Owner = owner
+
+		return result
+	}
+
+	if !isHomePath(result.Path) {
+		if owner, pkg := systemOwner(ctx, path, result.Path, run); owner != "" {
+			result.Owner, result.Package = owner, pkg
+
+			return result
+		}
+	}
+
+	if npmGlobalBin(ctx, path, run) {
+		result.Owner, result.Package = OwnerNpm, filepath.Base(path)
+	}
+
+	return result
+}
+
+// npmGlobalBin reports whether path is a launcher npm put into its global
+// prefix for a package of the same name. Symlinked launchers already resolve
+// into node_modules; this catches the copied shims npm writes on Windows.
+func npmGlobalBin(ctx context.Context, path string, run CommandRunner) bool {
+	out, err := run(ctx, "npm", "prefix", "-g")
+	if err != nil {
+		return false
+	}
+	prefix := firstOutputLine(out)
+	if dir := filepath.Dir(path); prefix == "" || (dir != filepath.Join(prefix, "bin") && dir != prefix) {
+		return false
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, modules := range []string{filepath.Join(prefix, "lib", "node_modules"), filepath.Join(prefix, "node_modules")} {
+		if info, err := os.Stat(filepath.Join(modules, name)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ownerFromPath(path string) (string, string) {
+	slashed := filepath.ToSlash(path)
+	for _, m := range ownerMarkers {
+		_, rest, ok := strings.Cut(slashed, m.fragment)
+		if !ok {
+			continue
+		}
+		if !m.named {
+			return m.owner, ""
+		}
+
+		return m.owner, markerPackage(m.owner, rest)
+	}
+
+	return "", ""
+}
+
+// nixStoreName strips the hash and version from a store entry:
+// "abc123-ripgrep-14.1.0" -> "ripgrep".
+var nixStoreName = regexp.MustCompile(`^[a-z0-9]{32}-(.+?)(?:-\d[^/]*)?$`)
+
+func markerPackage(owner, rest string) string {
+	parts := strings.Split(rest, "/")
+	pkg := parts[0]
+	switch owner {
+	case OwnerNpm:
+		if strings.HasPrefix(pkg, "@") && len(parts) > 1 {
+			pkg += "/" + parts[1]
+		}
+	case OwnerNix:
+		if m := nixStoreName.FindStringSubmatch(pkg); m != nil {
+			pkg = m[1]
+		}
+	}
+
+	return pkg
+}
+
+// ownerFromEnvDirs recognizes the install directories cargo and go take
+// from the environment.
+func ownerFromEnvDirs(path string) string {
+	dir := filepath.Dir(path)
+	if cargoHome := os.Getenv("CARGO_HOME"); cargoHome != "" && dir == filepath.Join(cargoHome, "bin") {
+		return OwnerCargo
+	}
+	if gobin := os.Getenv("GOBIN"); gobin != "" && dir == filepath.Clean(gobin) {
+		return OwnerGo
+	}
+	gopath := os.Getenv("GOPATH")
+	if home, err := os.UserHomeDir(); gopath == "" && err == nil {
+		gopath = filepath.Join(home, "go")
+	}
+	if gopath != "" && dir == filepath.Join(gopath, "bin") {
+		return OwnerGo
+	}
+
+	return ""
+}
+
+func isHomePath(path string) bool {
+	home, err := os.UserHomeDir()
+
+	return err == nil && home != "" && strings.HasPrefix(path, home+string(filepath.Separator))
+}
+
+// apkOwnedBy extracts the package from apk's "... is owned by jq-1.7.1-r0".
+var apkOwnedBy = regexp.MustCompile(`is owned by (\S+?)-\d`)
+
+// systemOwner asks the system package databases who owns the file. Both the
+// PATH entry and the resolved file are tried since dpkg only knows the path
+// the package shipped (e.g. /bin vs /usr/bin on merged-usr systems).
+func systemOwner(ctx context.Context, path, resolved string, run CommandRunner) (string, string) {
+	candidates := []string{resolved}
+	if path != resolved {
+		candidates = append(candidates, path)
+	}
+
+	for _, file := range candidates {
+		if out, err := run(ctx, "dpkg", "-S", file); err == nil {
+			// "fd-find: /usr/bin/fdfind" or "bat:amd64: /usr/bin/bat"
+			if pkg, _, ok := strings.Cut(firstOutputLine(out), ":"); ok {
+				return OwnerApt, pkg
+			}
+		}
+		if out, err := run(ctx, "rpm", "-qf", "--qf", "%{NAME}", file); err == nil {
+			return OwnerRPM, firstOutputLine(out)
+		}
+		if out, err := run(ctx, "pacman", "-Qqo", file); err == nil {
+			return OwnerPacman, firstOutputLine(out)
+		}
+		if out, err := run(ctx, "apk", "info", "--who-owns", file); err == nil {
+			if m := apkOwnedBy.FindStringSubmatch(out); m != nil {
+				return OwnerApk, m[1]
+			}
+		}
+	}
+
+	return "", ""
+}
+
+func firstOutputLine(out string) string {
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	if scanner.Scan() {
+		return strings.TrimSpace(scanner.Text())
+	}
+
+	return ""
+}
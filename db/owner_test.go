@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeOwnerRunner answers commands from a table keyed by the full command
+// line and counts the calls.
+func fakeOwnerRunner(outputs map[string]string, calls *int) CommandRunner {
+	return func(_ context.Context, name string, args ...string) (string, error) {
+		if calls != nil {
+			*calls++
+		}
+		out, ok := outputs[strings.Join(append([]string{name}, args...), " ")]
+		if !ok {
+			return "", errors.New("command not found")
+		}
+
+		return out, nil
+	}
+}
+
+func TestDetectOwner(t *testing.T) {
+	t.Setenv("CARGO_HOME", "/opt/cargo")
+	t.Setenv("GOBIN", "/opt/gobin")
+	run := fakeOwnerRunner(map[string]string{
+		"dpkg -S /usr/bin/fdfind":           "fd-find: /usr/bin/fdfind",
+		"dpkg -S /usr/bin/bat":              "bat:amd64: /usr/bin/bat",
+		"rpm -qf --qf %{NAME} /usr/bin/jq":  "jq",
+		"pacman -Qqo /usr/bin/rg":           "ripgrep\n",
+		"apk info --who-owns /usr/bin/htop": "/usr/bin/htop is owned by htop-3.3.0-r0\n",
+		"mise which node":                   "/home/me/.local/share/mise/installs/node/20.11.0/bin/node\n",
+		"npm prefix -g":                     "/usr/local\n",
+	}, nil)
+
+	tests := []struct {
+		path  string
+		owner string
+		pkg   string
+	}{
+		{"/home/me/.cargo/bin/rg", OwnerCargo, ""},
+		{"/opt/cargo/bin/rg", OwnerCargo, ""},
+		{"/opt/gobin/glow", OwnerGo, ""},
+		{"/opt/homebrew/Cellar/ripgrep/14.1.0/bin/rg", OwnerBrew, "ripgrep"},
+		{"/home/me/.local/pipx/venvs/poetry/bin/poetry", OwnerPipx, "poetry"},
+		{"/usr/lib/node_modules/@ast-grep/cli/sg", OwnerNpm, "@ast-grep/cli"},
+		{"/nix/store/0123456789abcdefghijklmnopqrstuv-ripgrep-14.1.0/bin/rg", OwnerNix, "ripgrep"},
+		{"/home/me/.local/share/mise/shims/node", OwnerMise, "node"},
+		{"/usr/bin/fdfind", OwnerApt, "fd-find"},
+		{"/usr/bin/bat", OwnerApt, "bat"},
+		{"/usr/bin/jq", OwnerRPM, "jq"},
+		{"/usr/bin/rg", OwnerPacman, "ripgrep"},
+		{"/usr/bin/htop", OwnerApk, "htop"},
+		{"/usr/local/bin/unknown", "", ""},
+	}
+
+	for _, tt := range tests {
+		got := DetectOwner(context.Background(), tt.path, run)
+		if got.Owner != tt.owner || got.Package != tt.pkg {
+			t.Errorf("DetectOwner(%q) = %q/%q, want %q/%q", tt.path, got.Owner, got.Package, tt.owner, tt.pkg)
+		}
+	}
+
+	if got := DetectOwner(context.Background(), "/home/me/.local/share/mise/shims/node", run); !strings.Contains(
+		got.Path, "/mise/installs/node/20.11.0/") {
+		t.Errorf("expected the shim resolved through mise, got %q", got.Path)
+	}
+}
+
+func TestDetectOwnerNpmShim(t *testing.T) {
+	prefix := t.TempDir()
+	for _, dir := range []string{"bin", filepath.Join("lib", "node_modules", "prettier")} {
+		if err := os.MkdirAll(filepath.Join(prefix, dir), 0o755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+	}
+	run := fakeOwnerRunner(map[string]string{"npm prefix -g": prefix + "\n"}, nil)
+
+	got := DetectOwner(context.Background(), filepath.Join(prefix, "bin", "prettier"), run)
+	if got.Owner != OwnerNpm || got.Package != "prettier" {
+		t.Errorf("expected npm/prettier, got %+v", got)
+	}
+
+	if got := DetectOwner(context.Background(), filepath.Join(prefix, "bin", "other"), run); got.Owner != "" {
+		t.Errorf("expected no owner for a file without an npm package, got %+v", got)
+	}
+}
+
+func TestResolveToolOwner(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "mytool")
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("CARGO_HOME", filepath.Dir(dir))
+
+	calls := 0
+	saved := ownerRunner
+	ownerRunner = fakeOwnerRunner(nil, &calls)
+	defer func() { ownerRunner = saved }()
+
+	tool := &Tool{ID: "tool-mytool", Slug: "mytool"}
+	installs := []InstallInstruction{{Platform: "linux", Command: "cargo install mytool"}}
+	ctx := context.Background()
+
+	owner, err := database.ResolveToolOwner(ctx, tool, nil)
+	if err != nil || owner != nil {
+		t.Fatalf("expected no owner without installs, got %+v (%v)", owner, err)
+	}
+
+	first, err := database.ResolveToolOwner(ctx, tool, installs)
+	if err != nil || first == nil {
+		t.Fatalf("ResolveToolOwner failed: %+v (%v)", first, err)
+	}
+	probes := calls
+
+	second, err := database.ResolveToolOwner(ctx, tool, installs)
+	if err != nil || second == nil {
+		t.Fatalf("ResolveToolOwner failed: %+v (%v)", second, err)
+	}
+	if calls != probes {
+		t.Errorf("expected the stored owner to be reused, ran %d more commands", calls-probes)
+	}
+	if second.Owner != first.Owner || second.Path != first.Path {
+		t.Errorf("stored owner %+v differs from probed %+v", second, first)
+	}
+}
@@ -0,0 +1,67 @@
+package db
+
+import "testing"
+
+func TestSavedQueries(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	if err := database.SaveQuery("rusty", "language=rust"); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+	if err := database.SaveQuery("@go-todo", "language=go&!installed=true"); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+
+	q, err := database.GetSavedQuery("@Rusty")
+	if err != nil {
+		t.Fatalf("GetSavedQuery failed: %v", err)
+	}
+	if q.Name != "rusty" || q.Expression != "language=rust" {
+		t.Errorf("unexpected query: %+v", q)
+	}
+
+	// Saving again replaces the expression.
+	if err := database.SaveQuery("rusty", "language=rust&tag=cli"); err != nil {
+		t.Fatalf("SaveQuery (replace) failed: %v", err)
+	}
+
+	queries, err := database.ListSavedQueries()
+	if err != nil {
+		t.Fatalf("ListSavedQueries failed: %v", err)
+	}
+	if len(queries) != 2 || queries[0].Name != "go-todo" || queries[1].Expression != "language=rust&tag=cli" {
+		t.Errorf("unexpected queries: %+v", queries)
+	}
+
+	if err := database.DeleteSavedQuery("go-todo"); err != nil {
+		t.Fatalf("DeleteSavedQuery failed: %v", err)
+	}
+	if err := database.DeleteSavedQuery("go-todo"); err == nil {
+		t.Error("expected error deleting a missing query")
+	}
+	if _, err := database.GetSavedQuery("go-todo"); err == nil {
+		t.Error("expected error getting a deleted query")
+	}
+}
+
+func TestSaveQueryValidation(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{"", "language=go"},
+		{"@", "language=go"},
+		{"two words", "language=go"},
+		{"ok", "  "},
+	}
+
+	for _, tt := range tests {
+		if err := database.SaveQuery(tt.name, tt.expression); err == nil {
+			t.Errorf("SaveQuery(%q, %q): expected error", tt.name, tt.expression)
+		}
+	}
+}
@@ -13,6 +13,7 @@ func TestSearchWithInstalledFilterAndLimit(t *testing.T) {
 	seedToolWithInstall(t, database, "tool-bat", "bat", "bat")
 	seedToolWithInstall(t, database, "tool-fzf", "fzf", "fzf")
 	seedToolWithInstall(t, database, "tool-notinstalled", "notinstalled", "this-command-does-not-exist-12345")
+	scanForTest(t, database)
 
 	results, err := database.Search(context.Background(), SearchOptions{
 		Query:     "",
@@ -48,6 +49,7 @@ func TestSearchWithInstalledFilterAndSmallLimit(t *testing.T) {
 	seedToolWithInstall(t, database, "tool-bat", "bat", "bat")
 	seedToolWithInstall(t, database, "tool-fzf", "fzf", "fzf")
 	seedToolWithInstall(t, database, "tool-notinstalled", "notinstalled", "this-command-does-not-exist-12345")
+	scanForTest(t, database)
 
 	results, err := database.Search(context.Background(), SearchOptions{
 		Query:     "",
@@ -85,6 +87,7 @@ func TestSearchWithInstalledFilterAndLanguageFilter(t *testing.T) {
 	seedToolWithInstallAndLanguage(t, database, "tool-fzf-rs", "fzf-rs", "fzf", "rust")
 	seedToolWithInstallAndLanguage(t, database, "tool-notinstalled", "notinstalled",
 		"this-command-does-not-exist-12345", "go")
+	scanForTest(t, database)
 
 	filter := &Filter{
 		Type: FilterAnd,
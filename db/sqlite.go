@@ -5,18 +5,19 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-
-	_ "github.com/mattn/go-sqlite3" // sqlite3 driver
 )
 
 // SQLiteDB wraps a *sql.DB for SQLite operations.
 type SQLiteDB struct {
-	db *sql.DB
+	db  *sql.DB
+	fts bool // tools_fts full-text index is available
 }
 
-// New opens (or creates) a SQLite database at dbPath and runs migrations.
+// New opens (or creates) a SQLite database at dbPath and migrates its schema
+// to the current version. A database with a newer schema is refused with a
+// *SchemaTooNewError.
 func New(dbPath string) (*SQLiteDB, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open(driverName, dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db: %w", err)
 	}
@@ -32,11 +33,9 @@ func New(dbPath string) (*SQLiteDB, error) {
 	}
 
 	sqlite := &SQLiteDB{db: db}
-	if err := sqlite.createTables(); err != nil {
-		return nil, fmt.Errorf("failed to create tables: %w", err)
-	}
-
 	if err := sqlite.runMigrations(); err != nil {
+		_ = db.Close()
+
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
@@ -48,6 +47,12 @@ func (s *SQLiteDB) Close() error {
 	return s.db.Close()
 }
 
+// FullTextSearch reports whether search uses the FTS5 index. It is false when
+// troveler was built without the sqlite_fts5 tag.
+func (s *SQLiteDB) FullTextSearch() bool {
+	return s.fts
+}
+
 func (s *SQLiteDB) getDB() *sql.DB {
 	return s.db
 }
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// CreateBatchRun stores run with its tools as the resumable batch and sets
+// its ID. Only the newest batch can be resumed, so older runs are dropped.
+func (s *SQLiteDB) CreateBatchRun(run *BatchRun) error {
+	ctx := context.Background()
+	tx, err := s.getDB().BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM batch_run_tools"); err != nil {
+		return fmt.Errorf("drop previous batch run: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM batch_runs"); err != nil {
+		return fmt.Errorf("drop previous batch run: %w", err)
+	}
+
+	opts := run.Options
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO batch_runs (reuse_config, use_sudo, sudo_only_system, skip_if_blind, use_mise)
+		VALUES (?, ?, ?, ?, ?)`,
+		opts.ReuseConfig, opts.UseSudo, opts.SudoOnlySystem, opts.SkipIfBlind, opts.UseMise)
+	if err != nil {
+		return fmt.Errorf("create batch run: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for i := range run.Tools {
+		tool := &run.Tools[i]
+		tool.Position = i
+		if tool.Status == "" {
+			tool.Status = BatchPending
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO batch_run_tools (run_id, position, slug, platform, mise, status, detail)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, tool.Position, tool.Slug, tool.Platform, tool.Mise, tool.Status, tool.Detail); err != nil {
+			return fmt.Errorf("add %s to batch run: %w", tool.Slug, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	run.ID = id
+
+	return nil
+}
+
+// SetBatchToolStatus records the status of the tool at position in run.
+func (s *SQLiteDB) SetBatchToolStatus(runID int64, position int, status, detail string) error {
+	_, err := s.getDB().ExecContext(context.Background(),
+		"UPDATE batch_run_tools SET status = ?, detail = ? WHERE run_id = ? AND position = ?",
+		status, detail, runID, position)
+	if err != nil {
+		return fmt.Errorf("update batch run: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBatchRun drops a finished or discarded batch run.
+func (s *SQLiteDB) DeleteBatchRun(runID int64) error {
+	ctx := context.Background()
+	if _, err := s.getDB().ExecContext(ctx, "DELETE FROM batch_run_tools WHERE run_id = ?", runID); err != nil {
+		return fmt.Errorf("delete batch run: %w", err)
+	}
+	if _, err := s.getDB().ExecContext(ctx, "DELETE FROM batch_runs WHERE id = ?", runID); err != nil {
+		return fmt.Errorf("delete batch run: %w", err)
+	}
+
+	return nil
+}
+
+// LatestBatchRun returns the batch run that can be resumed, or nil when
+// there is none.
+func (s *SQLiteDB) LatestBatchRun() (*BatchRun, error) {
+	ctx := context.Background()
+	run := &BatchRun{}
+	opts := &run.Options
+	err := s.getDB().QueryRowContext(ctx, `
+		SELECT id, reuse_config, use_sudo, sudo_only_system, skip_if_blind, use_mise, started_at
+		FROM batch_runs ORDER BY id DESC LIMIT 1`).Scan(
+		&run.ID, &opts.ReuseConfig, &opts.UseSudo, &opts.SudoOnlySystem, &opts.SkipIfBlind, &opts.UseMise,
+		&run.StartedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load batch run: %w", err)
+	}
+
+	rows, err := s.getDB().QueryContext(ctx, `
+		SELECT position, slug, platform, mise, status, detail FROM batch_run_tools
+		WHERE run_id = ? ORDER BY position`, run.ID)
+	if err != nil {
+		return nil, fmt.Errorf("load batch run: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var tool BatchRunTool
+		if err := rows.Scan(&tool.Position, &tool.Slug, &tool.Platform, &tool.Mise, &tool.Status,
+			&tool.Detail); err != nil {
+			return nil, err
+		}
+		run.Tools = append(run.Tools, tool)
+	}
+
+	return run, rows.Err()
+}
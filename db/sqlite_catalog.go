@@ -0,0 +1,225 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// execer is the part of *sql.DB and *sql.Tx that writes need, so they can
+// run on their own or inside a catalog refresh.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// GetCatalogState returns the stored ID, source hash and removed flag of every
+// tool from the given source, keyed by slug. Incremental updates diff a
+// source's listing against it.
+func (s *SQLiteDB) GetCatalogState(ctx context.Context, source string) (map[string]CatalogEntry, error) {
+	rows, err := s.getDB().QueryContext(ctx,
+		`SELECT id, slug, source_hash, removed FROM tools WHERE source = ?`, source)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	state := make(map[string]CatalogEntry)
+	for rows.Next() {
+		var slug string
+		var entry CatalogEntry
+		if err := rows.Scan(&entry.ID, &slug, &entry.SourceHash, &entry.Removed); err != nil {
+			return nil, err
+		}
+		state[slug] = entry
+	}
+
+	return state, rows.Err()
+}
+
+// MarkRemoved flags the tools of source with the given slugs as removed
+// upstream. Removed tools stay in the database (tags and history survive) but
+// are hidden from search until they reappear in a later update.
+func (s *SQLiteDB) MarkRemoved(ctx context.Context, source string, slugs []string) error {
+	return markRemoved(ctx, s.getDB(), source, slugs)
+}
+
+func markRemoved(ctx context.Context, q execer, source string, slugs []string) error {
+	for i := 0; i < len(slugs); i += sqliteVarLimit {
+		end := min(i+sqliteVarLimit, len(slugs))
+		chunk := slugs[i:end]
+
+		placeholders := strings.Repeat("?,", len(chunk))
+		placeholders = placeholders[:len(placeholders)-1]
+
+		args := make([]interface{}, 0, len(chunk)+1)
+		args = append(args, source)
+		for _, slug := range chunk {
+			args = append(args, slug)
+		}
+
+		query := fmt.Sprintf(`UPDATE tools SET removed = true, updated_at = CURRENT_TIMESTAMP
+			WHERE source = ? AND slug IN (%s)`, placeholders)
+		if _, err := q.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("mark removed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReplaceInstallInstructions atomically swaps the install instructions of a
+// tool for insts, so methods dropped upstream do not linger.
+func (s *SQLiteDB) ReplaceInstallInstructions(ctx context.Context, toolID string, insts []InstallInstruction) error {
+	tx, err := s.getDB().BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := replaceInstallInstructions(ctx, tx, toolID, insts); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceInstallInstructions(ctx context.Context, q execer, toolID string, insts []InstallInstruction) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM install_instructions WHERE tool_id = ?`, toolID); err != nil {
+		return fmt.Errorf("clear install instructions: %w", err)
+	}
+
+	for _, inst := range insts {
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO install_instructions (id, tool_id, platform, command, executable_name) VALUES (?, ?, ?, ?, ?)`,
+			inst.ID, toolID, inst.Platform, inst.Command, inst.ExecutableName,
+		)
+		if err != nil {
+			return fmt.Errorf("insert install instruction %s: %w", inst.Platform, err)
+		}
+	}
+
+	return nil
+}
+
+// ApplyCatalogRefresh writes a staged update in one transaction: every staged
+// tool is upserted with its install instructions replaced, and the removed
+// slugs are marked removed. Readers see the old catalog until the commit, and
+// an error or a canceled ctx leaves it untouched.
+//
+// The update is recorded as an UpdateRun together with what it changed
+// compared to the stored catalog; ListUpdateRuns returns it later. Failed
+// tools, staged or at write time, are recorded for ListUpdateFailures, and
+// saved or removed ones are cleared from it.
+//
+// A staged tool that cannot be written is rolled back on its own and returned
+// as a FailedRow; its stored version, if any, is kept. Tool IDs are stable per
+// slug, so tags, install history and other user data stay attached.
+func (s *SQLiteDB) ApplyCatalogRefresh(ctx context.Context, refresh *CatalogRefresh) (*UpdateRun, []FailedRow, error) {
+	tx, err := s.getDB().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin catalog refresh: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	run := &UpdateRun{}
+	var failed []FailedRow
+	for _, staged := range refresh.Tools {
+		changes, err := applyStagedTool(ctx, tx, staged)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			failed = append(failed, FailedRow{
+				Slug: staged.Tool.Slug, Source: staged.Tool.Source, Class: FailureDatabase, Err: err,
+			})
+
+			continue
+		}
+		run.Saved++
+		run.Changes = append(run.Changes, changes...)
+		if err := clearUpdateFailure(ctx, tx, staged.Tool.Source, staged.Tool.Slug); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, row := range append(append([]FailedRow(nil), refresh.Failed...), failed...) {
+		if err := recordUpdateFailure(ctx, tx, row); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := applyRemoved(ctx, tx, refresh.Removed, run); err != nil {
+		return nil, nil, err
+	}
+
+	run.Failed = len(refresh.Failed) + len(failed)
+	if err := recordUpdateRun(ctx, tx, run); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("commit catalog refresh: %w", err)
+	}
+
+	return run, failed, nil
+}
+
+// applyRemoved marks the removed tools of each source, clearing their
+// failures and adding their removals to run's changes.
+func applyRemoved(ctx context.Context, tx *sql.Tx, removed map[string][]string, run *UpdateRun) error {
+	sources := make([]string, 0, len(removed))
+	for source := range removed {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		slugs := removed[source]
+		for _, slug := range slugs {
+			if err := clearUpdateFailure(ctx, tx, source, slug); err != nil {
+				return err
+			}
+		}
+
+		changes, err := diffRemoved(ctx, tx, source, slugs)
+		if err != nil {
+			return err
+		}
+		run.Changes = append(run.Changes, changes...)
+		if err := markRemoved(ctx, tx, source, slugs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyStagedTool writes one staged tool inside a savepoint, so a failure
+// undoes only that tool, and returns how it changed.
+func applyStagedTool(ctx context.Context, tx *sql.Tx, staged StagedTool) ([]CatalogChange, error) {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT staged_tool"); err != nil {
+		return nil, err
+	}
+
+	changes, err := diffStagedTool(ctx, tx, staged)
+	if err == nil {
+		err = upsertTool(ctx, tx, staged.Tool)
+	}
+	if err == nil {
+		err = replaceInstallInstructions(ctx, tx, staged.Tool.ID, staged.Installs)
+	}
+	if err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO staged_tool"); rbErr != nil {
+			return nil, fmt.Errorf("%w (rollback: %w)", err, rbErr)
+		}
+	}
+	if _, relErr := tx.ExecContext(ctx, "RELEASE staged_tool"); relErr != nil && err == nil {
+		return nil, relErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
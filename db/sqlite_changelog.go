@@ -0,0 +1,277 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// diffStagedTool compares a staged tool with its stored version. A tool that
+// is new, or was removed upstream before, counts as added.
+func diffStagedTool(ctx context.Context, tx *sql.Tx, staged StagedTool) ([]CatalogChange, error) {
+	tool := staged.Tool
+	var id, name, tagline, description string
+	var removed bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, COALESCE(name, ''), COALESCE(tagline, ''), COALESCE(description, ''), removed
+		FROM tools WHERE slug = ?`, tool.Slug).Scan(&id, &name, &tagline, &description, &removed)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) || (err == nil && removed):
+		return []CatalogChange{{Slug: tool.Slug, Kind: ChangeAdded, New: tool.Name}}, nil
+	case err != nil:
+		return nil, fmt.Errorf("load stored tool: %w", err)
+	}
+
+	var changes []CatalogChange
+	fields := []struct{ kind, old, new string }{
+		{ChangeRenamed, name, tool.Name},
+		{ChangeTagline, tagline, tool.Tagline},
+		{ChangeDescription, description, tool.Description},
+	}
+	for _, f := range fields {
+		if f.old != f.new {
+			changes = append(changes, CatalogChange{Slug: tool.Slug, Kind: f.kind, Old: f.old, New: f.new})
+		}
+	}
+
+	installChanges, err := diffInstalls(ctx, tx, id, tool.Slug, staged.Installs)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(changes, installChanges...), nil
+}
+
+// diffInstalls compares the install commands stored for toolID with insts,
+// per platform.
+func diffInstalls(
+	ctx context.Context, tx *sql.Tx, toolID, slug string, insts []InstallInstruction,
+) ([]CatalogChange, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT platform, command FROM install_instructions WHERE tool_id = ?`, toolID)
+	if err != nil {
+		return nil, fmt.Errorf("load stored installs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	old := make(map[string]string)
+	for rows.Next() {
+		var platform, command string
+		if err := rows.Scan(&platform, &command); err != nil {
+			return nil, err
+		}
+		old[platform] = command
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]string, len(insts))
+	platforms := make([]string, 0, len(old)+len(insts))
+	for platform := range old {
+		platforms = append(platforms, platform)
+	}
+	for _, inst := range insts {
+		if _, ok := old[inst.Platform]; !ok {
+			if _, seen := current[inst.Platform]; !seen {
+				platforms = append(platforms, inst.Platform)
+			}
+		}
+		current[inst.Platform] = inst.Command
+	}
+	sort.Strings(platforms)
+
+	var changes []CatalogChange
+	for _, platform := range platforms {
+		before, had := old[platform]
+		after, has := current[platform]
+		change := CatalogChange{Slug: slug, Platform: platform, Old: before, New: after}
+		switch {
+		case !had:
+			change.Kind = ChangeInstallAdded
+		case !has:
+			change.Kind = ChangeInstallRemoved
+		case before != after:
+			change.Kind = ChangeInstallChanged
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// diffRemoved returns a removal for every slug of source that is still
+// listed.
+func diffRemoved(ctx context.Context, tx *sql.Tx, source string, slugs []string) ([]CatalogChange, error) {
+	var changes []CatalogChange
+	for _, slug := range slugs {
+		var name string
+		err := tx.QueryRowContext(ctx,
+			`SELECT COALESCE(name, '') FROM tools WHERE slug = ? AND source = ? AND NOT removed`,
+			slug, source).Scan(&name)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load removed tool %s: %w", slug, err)
+		}
+		changes = append(changes, CatalogChange{Slug: slug, Kind: ChangeRemoved, Old: name})
+	}
+
+	return changes, nil
+}
+
+// recordUpdateRun stores run and its changes and sets its ID and time.
+func recordUpdateRun(ctx context.Context, tx *sql.Tx, run *UpdateRun) error {
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO update_runs (saved, failed) VALUES (?, ?)`, run.Saved, run.Failed)
+	if err != nil {
+		return fmt.Errorf("record update run: %w", err)
+	}
+	if run.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	if err := tx.QueryRowContext(ctx,
+		`SELECT ran_at FROM update_runs WHERE id = ?`, run.ID).Scan(&run.RanAt); err != nil {
+		return fmt.Errorf("record update run: %w", err)
+	}
+
+	for i, c := range run.Changes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO update_changes (run_id, position, slug, kind, platform, old_value, new_value)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			run.ID, i, c.Slug, c.Kind, c.Platform, c.Old, c.New); err != nil {
+			return fmt.Errorf("record change of %s: %w", c.Slug, err)
+		}
+	}
+
+	return nil
+}
+
+// ListUpdateRuns returns recorded updates with their changes, newest first:
+// those that ran at or after since (the zero time for all), at most limit
+// of them (0 = all).
+func (s *SQLiteDB) ListUpdateRuns(ctx context.Context, since time.Time, limit int) ([]UpdateRun, error) {
+	query := `SELECT id, ran_at, saved, failed FROM update_runs`
+	var args []any
+	if !since.IsZero() {
+		// ran_at is stored by SQLite as UTC "YYYY-MM-DD HH:MM:SS".
+		query += ` WHERE ran_at >= ?`
+		args = append(args, since.UTC().Format(time.DateTime))
+	}
+	query += ` ORDER BY id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.getDB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list update runs: %w", err)
+	}
+	var runs []UpdateRun
+	for rows.Next() {
+		var run UpdateRun
+		if err := rows.Scan(&run.ID, &run.RanAt, &run.Saved, &run.Failed); err != nil {
+			_ = rows.Close()
+
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range runs {
+		if runs[i].Changes, err = s.updateChanges(ctx, runs[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return runs, nil
+}
+
+func (s *SQLiteDB) updateChanges(ctx context.Context, runID int64) ([]CatalogChange, error) {
+	rows, err := s.getDB().QueryContext(ctx, `
+		SELECT slug, kind, platform, old_value, new_value FROM update_changes
+		WHERE run_id = ? ORDER BY position`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("list update changes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	changes := []CatalogChange{}
+	for rows.Next() {
+		var c CatalogChange
+		if err := rows.Scan(&c.Slug, &c.Kind, &c.Platform, &c.Old, &c.New); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+// recordUpdateFailure stores why row failed, counting the updates in a row
+// that failed to refresh it.
+func recordUpdateFailure(ctx context.Context, tx *sql.Tx, row FailedRow) error {
+	class := row.Class
+	if class == "" {
+		class = FailureOther
+	}
+	var message string
+	if row.Err != nil {
+		message = row.Err.Error()
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO update_failures (slug, source, class, message) VALUES (?, ?, ?, ?)
+		ON CONFLICT(slug, source) DO UPDATE SET
+			class = excluded.class,
+			message = excluded.message,
+			attempts = attempts + 1,
+			failed_at = CURRENT_TIMESTAMP`,
+		row.Slug, row.Source, class, message)
+	if err != nil {
+		return fmt.Errorf("record failure of %s: %w", row.Slug, err)
+	}
+
+	return nil
+}
+
+func clearUpdateFailure(ctx context.Context, tx *sql.Tx, source, slug string) error {
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM update_failures WHERE slug = ? AND source = ?`, slug, source); err != nil {
+		return fmt.Errorf("clear failure of %s: %w", slug, err)
+	}
+
+	return nil
+}
+
+// ListUpdateFailures returns the tools that failed to refresh the last time an
+// update tried them, by slug and source. A slug fails once per source.
+func (s *SQLiteDB) ListUpdateFailures(ctx context.Context) ([]UpdateFailure, error) {
+	rows, err := s.getDB().QueryContext(ctx, `
+		SELECT slug, source, class, message, attempts, failed_at FROM update_failures ORDER BY slug, source`)
+	if err != nil {
+		return nil, fmt.Errorf("list update failures: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var failures []UpdateFailure
+	for rows.Next() {
+		var f UpdateFailure
+		if err := rows.Scan(&f.Slug, &f.Source, &f.Class, &f.Message, &f.Attempts, &f.FailedAt); err != nil {
+			return nil, err
+		}
+		failures = append(failures, f)
+	}
+
+	return failures, rows.Err()
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tools_fts is a full-text index over the searchable text of every tool plus
+// its tags, keyed by the tools rowid. Triggers on tools and tool_tags keep it
+// in sync. FTS5 is only compiled into go-sqlite3 with the sqlite_fts5 build
+// tag; without it search falls back to LIKE matching.
+const createFTSTable = `CREATE VIRTUAL TABLE IF NOT EXISTS tools_fts USING fts5(
+	name, tagline, description, tags,
+	tokenize = 'unicode61 remove_diacritics 2'
+)`
+
+// ftsTags is the tags column value for the tool with the given id.
+const ftsTags = `COALESCE((SELECT group_concat(tag_name, ' ') FROM tool_tags WHERE tool_id = %s), '')`
+
+var ftsTriggers = map[string]string{
+	"tools_fts_ai": `CREATE TRIGGER IF NOT EXISTS tools_fts_ai AFTER INSERT ON tools BEGIN
+		INSERT INTO tools_fts (rowid, name, tagline, description, tags)
+		VALUES (new.rowid, new.name, new.tagline, new.description, ` + fmt.Sprintf(ftsTags, "new.id") + `);
+	END`,
+	"tools_fts_au": `CREATE TRIGGER IF NOT EXISTS tools_fts_au AFTER UPDATE ON tools BEGIN
+		DELETE FROM tools_fts WHERE rowid = old.rowid;
+		INSERT INTO tools_fts (rowid, name, tagline, description, tags)
+		VALUES (new.rowid, new.name, new.tagline, new.description, ` + fmt.Sprintf(ftsTags, "new.id") + `);
+	END`,
+	"tools_fts_ad": `CREATE TRIGGER IF NOT EXISTS tools_fts_ad AFTER DELETE ON tools BEGIN
+		DELETE FROM tools_fts WHERE rowid = old.rowid;
+	END`,
+	"tool_tags_fts_ai": `CREATE TRIGGER IF NOT EXISTS tool_tags_fts_ai AFTER INSERT ON tool_tags BEGIN
+		UPDATE tools_fts SET tags = ` + fmt.Sprintf(ftsTags, "new.tool_id") + `
+		WHERE rowid = (SELECT rowid FROM tools WHERE id = new.tool_id);
+	END`,
+	"tool_tags_fts_ad": `CREATE TRIGGER IF NOT EXISTS tool_tags_fts_ad AFTER DELETE ON tool_tags BEGIN
+		UPDATE tools_fts SET tags = ` + fmt.Sprintf(ftsTags, "old.tool_id") + `
+		WHERE rowid = (SELECT rowid FROM tools WHERE id = old.tool_id);
+	END`,
+}
+
+// bm25 column weights for name, tagline, description and tags: a hit in the
+// name ranks well above the same hit buried in the description.
+const ftsRank = `bm25(tools_fts, 10.0, 4.0, 1.0, 4.0)`
+
+// setupFTS creates and, when needed, rebuilds the full-text index.
+//
+// A binary built without FTS5 cannot write through the sync triggers, so it
+// drops them; the next FTS5-enabled run sees the triggers missing, rebuilds
+// the (possibly stale) index from scratch and recreates them.
+func (s *SQLiteDB) setupFTS() error {
+	ctx := context.Background()
+
+	var enabled int
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&enabled); err != nil {
+		return err
+	}
+
+	if enabled == 0 {
+		for name := range ftsTriggers {
+			if _, err := s.db.ExecContext(ctx, `DROP TRIGGER IF EXISTS `+name); err != nil {
+				return fmt.Errorf("drop fts trigger %s: %w", name, err)
+			}
+		}
+
+		return nil
+	}
+
+	names := make([]string, 0, len(ftsTriggers))
+	for name := range ftsTriggers {
+		names = append(names, "'"+name+"'")
+	}
+
+	var present int
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN (%s)`,
+		strings.Join(names, ","))).Scan(&present)
+	if err != nil {
+		return err
+	}
+
+	if present < len(ftsTriggers) {
+		if err := s.rebuildFTS(ctx); err != nil {
+			return fmt.Errorf("build search index: %w", err)
+		}
+	}
+	s.fts = true
+
+	return nil
+}
+
+func (s *SQLiteDB) rebuildFTS(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	queries := []string{
+		createFTSTable,
+		`DELETE FROM tools_fts`,
+		`INSERT INTO tools_fts (rowid, name, tagline, description, tags)
+			SELECT rowid, name, tagline, description, ` + fmt.Sprintf(ftsTags, "tools.id") + ` FROM tools`,
+	}
+	for _, q := range queries {
+		if _, err := tx.ExecContext(ctx, q); err != nil {
+			return err
+		}
+	}
+
+	for _, trigger := range ftsTriggers {
+		if _, err := tx.ExecContext(ctx, trigger); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ftsQuery turns a free-text search term into an FTS5 MATCH expression: each
+// whitespace-separated word becomes a quoted prefix query and all words must
+// match. Returns "" when the term has no searchable words.
+func ftsQuery(term string) string {
+	var parts []string
+	for _, word := range strings.Fields(term) {
+		word = strings.Trim(word, "*")
+		if strings.IndexFunc(word, isWordRune) < 0 {
+			continue
+		}
+		parts = append(parts, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecordInstall appends rec to the install history and sets its ID.
+func (s *SQLiteDB) RecordInstall(rec *InstallRecord) error {
+	if rec.Slug == "" {
+		return fmt.Errorf("install record has no slug")
+	}
+
+	if rec.Action == "" {
+		rec.Action = ActionInstall
+	}
+
+	result, err := s.getDB().ExecContext(context.Background(), `
+		INSERT INTO install_history (action, slug, platform, command, exit_code, duration_ms, output, user)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		rec.Action, rec.Slug, rec.Platform, rec.Command, rec.ExitCode, rec.Duration.Milliseconds(),
+		rec.Output, rec.User)
+	if err != nil {
+		return fmt.Errorf("record install of %s: %w", rec.Slug, err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rec.ID = id
+
+	return nil
+}
+
+// LastSuccessfulInstall returns the install that put slug on this machine:
+// the newest successful install or uninstall record for slug, provided it is
+// an install. Upgrades keep the install in place and are skipped. Returns nil
+// when the history has no such record.
+func (s *SQLiteDB) LastSuccessfulInstall(slug string) (*InstallRecord, error) {
+	records, err := s.ListInstallHistory(HistoryFilter{Slug: slug, Succeeded: true})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		switch records[i].Action {
+		case ActionInstall:
+			return &records[i], nil
+		case ActionUninstall:
+			return nil, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ListInstallHistory returns install records matching filter, newest first.
+func (s *SQLiteDB) ListInstallHistory(filter HistoryFilter) ([]InstallRecord, error) {
+	var conditions []string
+	var args []any
+
+	if filter.Slug != "" {
+		conditions = append(conditions, "slug = ?")
+		args = append(args, filter.Slug)
+	}
+	if filter.Failed {
+		conditions = append(conditions, "exit_code != 0")
+	}
+	if filter.Succeeded {
+		conditions = append(conditions, "exit_code = 0")
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+
+	query := `SELECT id, action, slug, platform, command, exit_code, duration_ms, output, user, installed_at
+		FROM install_history`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY installed_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.getDB().QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var records []InstallRecord
+	for rows.Next() {
+		var rec InstallRecord
+		var durationMS int64
+		if err := rows.Scan(&rec.ID, &rec.Action, &rec.Slug, &rec.Platform, &rec.Command, &rec.ExitCode,
+			&durationMS, &rec.Output, &rec.User, &rec.InstalledAt); err != nil {
+			return nil, err
+		}
+		rec.Duration = time.Duration(durationMS) * time.Millisecond
+		records = append(records, rec)
+	}
+
+	return records, rows.Err()
+}
@@ -0,0 +1,306 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InstalledStateMaxAge is how old the stored installed state may get before
+// it counts as stale. Search never rescans: the TUI rescans in the
+// background well within this, and updates and installs made through
+// troveler refresh the tools they touch.
+const InstalledStateMaxAge = 15 * time.Minute
+
+// ScanResult summarizes a scan of PATH for installed tools.
+type ScanResult struct {
+	Tools     int      `json:"tools"`     // tools checked
+	Installed int      `json:"installed"` // tools found on PATH
+	Added     []string `json:"added"`     // slugs newly found on PATH
+	Removed   []string `json:"removed"`   // slugs no longer found on PATH
+}
+
+// Changed reports whether the scan found any tool installed or removed.
+func (r *ScanResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0
+}
+
+// ScanInstalled looks up the executables of every tool on PATH and stores
+// the result in installed_state, which the installed= filter queries. The
+// owners of installed executables are resolved too, so installed=<manager>
+// can be answered without probing package managers during a search.
+func (s *SQLiteDB) ScanInstalled(ctx context.Context) (*ScanResult, error) {
+	result, err := s.scanInstalled(ctx, "SELECT id, slug FROM tools WHERE NOT removed")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getDB().ExecContext(ctx, `
+		DELETE FROM installed_state WHERE tool_id NOT IN (SELECT id FROM tools WHERE NOT removed)`); err != nil {
+		return nil, fmt.Errorf("prune installed state: %w", err)
+	}
+
+	if err := s.resolveInstalledOwners(ctx); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RefreshInstalled rescans the tools with the given slugs, e.g. right after
+// troveler installed or removed them or an update saved them, and resolves
+// the owners of those found.
+func (s *SQLiteDB) RefreshInstalled(ctx context.Context, slugs ...string) error {
+	if len(slugs) == 0 {
+		return nil
+	}
+
+	for i := 0; i < len(slugs); i += sqliteVarLimit {
+		end := min(i+sqliteVarLimit, len(slugs))
+		chunk := slugs[i:end]
+
+		placeholders := strings.Repeat("?,", len(chunk))
+		placeholders = placeholders[:len(placeholders)-1]
+
+		args := make([]interface{}, len(chunk))
+		for j, slug := range chunk {
+			args[j] = slug
+		}
+
+		query := "SELECT id, slug FROM tools WHERE slug IN (" + placeholders + ")"
+		if _, err := s.scanInstalled(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	return s.resolveInstalledOwners(ctx)
+}
+
+// GetInstalledState returns the stored installed state keyed by tool ID.
+func (s *SQLiteDB) GetInstalledState(ctx context.Context) (map[string]InstalledState, error) {
+	rows, err := s.getDB().QueryContext(ctx, `
+		SELECT tool_id, executable, lookup_path, path, mtime, installed, checked_at FROM installed_state`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	states := make(map[string]InstalledState)
+	for rows.Next() {
+		var st InstalledState
+		var mtime sql.NullTime
+		if err := rows.Scan(
+			&st.ToolID, &st.Executable, &st.LookupPath, &st.Path, &mtime, &st.Installed, &st.CheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		st.ModTime = mtime.Time
+		states[st.ToolID] = st
+	}
+
+	return states, rows.Err()
+}
+
+// scanInstalled resolves the tools selected by query (id, slug) on PATH and
+// stores their state. One PATH lookup is made per distinct executable name.
+func (s *SQLiteDB) scanInstalled(ctx context.Context, query string, args ...interface{}) (*ScanResult, error) {
+	slugs, err := s.scanSlugs(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(slugs))
+	for id := range slugs {
+		ids = append(ids, id)
+	}
+	installsByTool, err := s.GetInstallInstructionsBatch(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.GetInstalledState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ScanResult{Tools: len(ids), Added: []string{}, Removed: []string{}}
+	lookups := make(map[string]string)
+	states := make([]InstalledState, 0, len(ids))
+	for _, id := range ids {
+		st := lookupInstalled(id, installsByTool[id], lookups)
+		states = append(states, st)
+
+		prev, seen := previous[id]
+		switch {
+		case st.Installed:
+			result.Installed++
+			if !prev.Installed {
+				result.Added = append(result.Added, slugs[id])
+			}
+		case seen && prev.Installed:
+			result.Removed = append(result.Removed, slugs[id])
+		}
+	}
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+
+	if err := s.saveInstalledState(ctx, states, previous); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *SQLiteDB) scanSlugs(ctx context.Context, query string, args ...interface{}) (map[string]string, error) {
+	rows, err := s.getDB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	slugs := make(map[string]string)
+	for rows.Next() {
+		var id, slug string
+		if err := rows.Scan(&id, &slug); err != nil {
+			return nil, err
+		}
+		slugs[id] = slug
+	}
+
+	return slugs, rows.Err()
+}
+
+// lookupInstalled finds the first executable of installs on PATH. lookups
+// caches PATH lookups by name across tools ("" for not found).
+func lookupInstalled(toolID string, installs []InstallInstruction, lookups map[string]string) InstalledState {
+	st := InstalledState{ToolID: toolID}
+	for _, inst := range installs {
+		name := resolveExecutableName(inst)
+		if name == "" {
+			continue
+		}
+		if st.Executable == "" {
+			st.Executable = name
+		}
+
+		path, ok := lookups[name]
+		if !ok {
+			path, _ = exec.LookPath(name)
+			lookups[name] = path
+		}
+		if path == "" {
+			continue
+		}
+
+		st.Executable, st.LookupPath, st.Path, st.Installed = name, path, path, true
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			st.Path = resolved
+		}
+		if info, err := os.Stat(st.Path); err == nil {
+			st.ModTime = info.ModTime()
+		}
+
+		break
+	}
+
+	return st
+}
+
+// saveInstalledState stores states in one transaction. A stored owner is
+// dropped when the executable was replaced in place (same path, new mtime),
+// since another package manager may have installed it this time.
+func (s *SQLiteDB) saveInstalledState(
+	ctx context.Context, states []InstalledState, previous map[string]InstalledState,
+) error {
+	tx, err := s.getDB().BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, st := range states {
+		var mtime interface{}
+		if !st.ModTime.IsZero() {
+			mtime = st.ModTime
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO installed_state (tool_id, executable, lookup_path, path, mtime, installed, checked_at)
+			VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(tool_id) DO UPDATE SET
+				executable = excluded.executable,
+				lookup_path = excluded.lookup_path,
+				path = excluded.path,
+				mtime = excluded.mtime,
+				installed = excluded.installed,
+				checked_at = excluded.checked_at`,
+			st.ToolID, st.Executable, st.LookupPath, st.Path, mtime, st.Installed); err != nil {
+			return fmt.Errorf("save installed state of %s: %w", st.ToolID, err)
+		}
+
+		prev, ok := previous[st.ToolID]
+		if ok && prev.Installed && st.Installed && prev.Path == st.Path && !prev.ModTime.Equal(st.ModTime) {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM tool_owners WHERE tool_id = ?", st.ToolID); err != nil {
+				return fmt.Errorf("drop owner of %s: %w", st.ToolID, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// resolveInstalledOwners probes the owner of every installed executable
+// whose owner is not stored for its current location.
+func (s *SQLiteDB) resolveInstalledOwners(ctx context.Context) error {
+	rows, err := s.getDB().QueryContext(ctx, `
+		SELECT st.tool_id, st.lookup_path FROM installed_state st
+		LEFT JOIN tool_owners o ON o.tool_id = st.tool_id AND o.lookup_path = st.lookup_path
+		WHERE st.installed AND o.tool_id IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	pending := make(map[string]string)
+	for rows.Next() {
+		var toolID, lookupPath string
+		if err := rows.Scan(&toolID, &lookupPath); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		pending[toolID] = lookupPath
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// The rows are read before probing: the single connection is needed to
+	// store each owner.
+	for toolID, lookupPath := range pending {
+		if _, err := s.ownerAt(ctx, toolID, lookupPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InstalledStateStale reports whether some tool was never scanned or the
+// oldest scan is older than maxAge, i.e. whether installed= filters may
+// answer from outdated data.
+func (s *SQLiteDB) InstalledStateStale(ctx context.Context, maxAge time.Duration) (bool, error) {
+	var stale bool
+	err := s.getDB().QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM tools WHERE NOT removed AND id NOT IN (SELECT tool_id FROM installed_state))
+			OR EXISTS (SELECT 1 FROM installed_state WHERE checked_at < datetime('now', ?))`,
+		fmt.Sprintf("-%d seconds", int(maxAge.Seconds()))).Scan(&stale)
+	if err != nil {
+		return false, fmt.Errorf("check installed state: %w", err)
+	}
+
+	return stale, nil
+}
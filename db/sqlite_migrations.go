@@ -2,20 +2,205 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
 )
 
+// migration upgrades the schema from version-1 to version. It runs in a
+// transaction together with recording the new version, so a failing
+// migration leaves the database as it was.
+type migration struct {
+	version int
+	name    string
+	up      func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations lists every schema upgrade in order. The schema version is kept
+// in PRAGMA user_version; a database created before versioning has version
+// 0. Append new migrations, never change released ones.
+var migrations = []migration{
+	{version: 1, name: "baseline schema", up: migrateBaseline},
+	{version: 2, name: "update changelog", up: migrateUpdateChangelog},
+	{version: 3, name: "update failures", up: migrateUpdateFailures},
+}
+
+// SchemaTooNewError reports a database written by a newer troveler, whose
+// schema this binary does not know.
+type SchemaTooNewError struct {
+	Version   int // schema version of the database
+	Supported int // newest schema version this binary knows
+}
+
+func (e *SchemaTooNewError) Error() string {
+	return fmt.Sprintf("database schema version %d is newer than the %d this troveler supports; "+
+		"upgrade troveler or point db_path at another database", e.Version, e.Supported)
+}
+
+// runMigrations brings the schema up to date, first copying an existing
+// database file aside, and sets up full-text search.
 func (s *SQLiteDB) runMigrations() error {
-	if err := s.addColumnIfNotExists("tools", "tool_of_the_week", "BOOLEAN DEFAULT false"); err != nil {
+	ctx := context.Background()
+
+	current, err := s.schemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	latest := migrations[len(migrations)-1].version
+	if current > latest {
+		return &SchemaTooNewError{Version: current, Supported: latest}
+	}
+
+	if current < latest {
+		if err := s.backupBeforeMigrating(ctx, current); err != nil {
+			return err
+		}
+		for _, m := range migrations[current:] {
+			if err := s.migrate(ctx, m); err != nil {
+				return fmt.Errorf("migrate to version %d (%s): %w", m.version, m.name, err)
+			}
+		}
+	}
+
+	return s.setupFTS()
+}
+
+// schemaVersion returns the version of the database's schema.
+func (s *SQLiteDB) schemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+func (s *SQLiteDB) migrate(ctx context.Context, m migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := m.up(ctx, tx); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", m.version)); err != nil {
+		return fmt.Errorf("record schema version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// backupBeforeMigrating copies the database file to <file>.bak-v<version>
+// before its schema changes, so a failed or unwanted upgrade can be undone
+// by putting the copy back. New and in-memory databases have nothing to
+// keep.
+func (s *SQLiteDB) backupBeforeMigrating(ctx context.Context, version int) error {
+	var file string
+	err := s.db.QueryRowContext(ctx, "SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&file)
+	if err != nil {
+		return fmt.Errorf("locate database file: %w", err)
+	}
+	if file == "" {
+		return nil
+	}
+
+	var tables int
+	if err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil {
+		return fmt.Errorf("inspect database: %w", err)
+	}
+	if tables == 0 {
+		return nil
+	}
+
+	backup := fmt.Sprintf("%s.bak-v%d", file, version)
+	if err := os.Remove(backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("replace database backup: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", backup); err != nil {
+		return fmt.Errorf("back up database before migrating: %w", err)
+	}
+
+	return nil
+}
+
+// migrateBaseline creates the schema of the first versioned database. It
+// also upgrades databases from before versioning, whose tables may lack
+// columns added since.
+func migrateBaseline(ctx context.Context, tx *sql.Tx) error {
+	if err := createTables(ctx, tx); err != nil {
 		return err
 	}
 
-	return s.addColumnIfNotExists("install_instructions", "executable_name", "TEXT DEFAULT ''")
+	columns := []struct{ table, column, definition string }{
+		{"tools", "tool_of_the_week", "BOOLEAN DEFAULT false"},
+		{"install_instructions", "executable_name", "TEXT DEFAULT ''"},
+		{"tools", "source_hash", "TEXT DEFAULT ''"},
+		{"tools", "removed", "BOOLEAN DEFAULT false"},
+		{"tools", "source", "TEXT DEFAULT '" + DefaultSource + "'"},
+		{"install_history", "action", "TEXT NOT NULL DEFAULT '" + ActionInstall + "'"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfNotExists(ctx, tx, c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateUpdateChangelog adds the tables recording every update and the
+// catalog changes it made.
+func migrateUpdateChangelog(ctx context.Context, tx *sql.Tx) error {
+	queries := []string{
+		`CREATE TABLE update_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ran_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			saved INTEGER NOT NULL DEFAULT 0,
+			failed INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE TABLE update_changes (
+			run_id INTEGER NOT NULL REFERENCES update_runs(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			slug TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			platform TEXT NOT NULL DEFAULT '',
+			old_value TEXT NOT NULL DEFAULT '',
+			new_value TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (run_id, position)
+		)`,
+		`CREATE INDEX idx_update_runs_ran_at ON update_runs(ran_at)`,
+	}
+	for _, q := range queries {
+		if _, err := tx.ExecContext(ctx, q); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateUpdateFailures adds the table of tools that failed to refresh.
+func migrateUpdateFailures(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `CREATE TABLE update_failures (
+		slug TEXT NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		class TEXT NOT NULL,
+		message TEXT NOT NULL DEFAULT '',
+		attempts INTEGER NOT NULL DEFAULT 1,
+		failed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (slug, source)
+	)`)
+
+	return err
 }
 
-func (s *SQLiteDB) addColumnIfNotExists(table, column, definition string) error {
+func addColumnIfNotExists(ctx context.Context, tx *sql.Tx, table, column, definition string) error {
 	var exists int
-	err := s.db.QueryRowContext(
-		context.Background(),
+	err := tx.QueryRowContext(ctx,
 		`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`,
 		table, column,
 	).Scan(&exists)
@@ -27,10 +212,7 @@ func (s *SQLiteDB) addColumnIfNotExists(table, column, definition string) error
 		return nil
 	}
 
-	_, err = s.db.ExecContext(
-		context.Background(),
-		`ALTER TABLE `+table+` ADD COLUMN `+column+` `+definition,
-	)
+	_, err = tx.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN `+column+` `+definition)
 
 	return err
 }
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ownerRunner runs the package manager queries of DetectOwner; tests swap it
+// for canned output.
+var ownerRunner CommandRunner = ExecCommandRunner
+
+// ResolveToolOwner returns the owner of tool's installed executable, or nil
+// when none of installs is on PATH. A stored result is reused while the
+// executable is found at the same PATH location; otherwise the owner is
+// probed again and stored.
+func (s *SQLiteDB) ResolveToolOwner(
+	ctx context.Context, tool *Tool, installs []InstallInstruction,
+) (*ToolOwner, error) {
+	lookupPath := InstalledExecutable(installs)
+	if lookupPath == "" {
+		return nil, nil
+	}
+
+	return s.ownerAt(ctx, tool.ID, lookupPath)
+}
+
+// ownerAt returns the owner of toolID's executable found at lookupPath,
+// probing and storing it unless it was already stored for that location.
+func (s *SQLiteDB) ownerAt(ctx context.Context, toolID, lookupPath string) (*ToolOwner, error) {
+	stored, err := s.getToolOwner(ctx, toolID, lookupPath)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		return stored, nil
+	}
+
+	owner := DetectOwner(ctx, lookupPath, ownerRunner)
+	owner.ToolID = toolID
+	owner.CheckedAt = time.Now()
+	if err := s.saveToolOwner(ctx, &owner, lookupPath); err != nil {
+		return nil, err
+	}
+
+	return &owner, nil
+}
+
+// getToolOwner returns the stored owner of toolID if it was probed for the
+// executable at lookupPath.
+func (s *SQLiteDB) getToolOwner(ctx context.Context, toolID, lookupPath string) (*ToolOwner, error) {
+	var owner ToolOwner
+	err := s.getDB().QueryRowContext(ctx, `
+		SELECT tool_id, owner, package, path, checked_at FROM tool_owners WHERE tool_id = ? AND lookup_path = ?`,
+		toolID, lookupPath).Scan(&owner.ToolID, &owner.Owner, &owner.Package, &owner.Path, &owner.CheckedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &owner, nil
+}
+
+func (s *SQLiteDB) saveToolOwner(ctx context.Context, owner *ToolOwner, lookupPath string) error {
+	_, err := s.getDB().ExecContext(ctx, `
+		INSERT INTO tool_owners (tool_id, lookup_path, owner, package, path, checked_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(tool_id) DO UPDATE SET
+			lookup_path = excluded.lookup_path,
+			owner = excluded.owner,
+			package = excluded.package,
+			path = excluded.path,
+			checked_at = excluded.checked_at`,
+		owner.ToolID, lookupPath, owner.Owner, owner.Package, owner.Path)
+	if err != nil {
+		return fmt.Errorf("save owner of %s: %w", owner.ToolID, err)
+	}
+
+	return nil
+}
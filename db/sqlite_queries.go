@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+func normalizeQueryName(name string) (string, error) {
+	normalized := strings.TrimPrefix(strings.TrimSpace(name), "@")
+	if normalized == "" {
+		return "", fmt.Errorf("query name cannot be empty")
+	}
+	if strings.IndexFunc(normalized, unicode.IsSpace) >= 0 {
+		return "", fmt.Errorf("query name cannot contain spaces: %q", normalized)
+	}
+
+	return normalized, nil
+}
+
+// SaveQuery stores expression under name, replacing any query of that name.
+// A leading "@" on name is ignored.
+func (s *SQLiteDB) SaveQuery(name, expression string) error {
+	normalized, err := normalizeQueryName(name)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(expression) == "" {
+		return fmt.Errorf("query expression cannot be empty")
+	}
+
+	_, err = s.getDB().ExecContext(context.Background(), `
+		INSERT INTO saved_queries (name, expression) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET expression = excluded.expression, updated_at = CURRENT_TIMESTAMP`,
+		normalized, expression)
+
+	return err
+}
+
+// GetSavedQuery returns the query called name.
+func (s *SQLiteDB) GetSavedQuery(name string) (*SavedQuery, error) {
+	normalized, err := normalizeQueryName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var q SavedQuery
+	err = s.getDB().QueryRowContext(context.Background(),
+		"SELECT name, expression, updated_at FROM saved_queries WHERE name = ?", normalized).
+		Scan(&q.Name, &q.Expression, &q.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("saved query not found: %s", normalized)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// ListSavedQueries returns every saved query ordered by name.
+func (s *SQLiteDB) ListSavedQueries() ([]SavedQuery, error) {
+	rows, err := s.getDB().QueryContext(context.Background(),
+		"SELECT name, expression, updated_at FROM saved_queries ORDER BY name COLLATE NOCASE")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var queries []SavedQuery
+	for rows.Next() {
+		var q SavedQuery
+		if err := rows.Scan(&q.Name, &q.Expression, &q.UpdatedAt); err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+
+	return queries, rows.Err()
+}
+
+// DeleteSavedQuery removes the query called name.
+func (s *SQLiteDB) DeleteSavedQuery(name string) error {
+	normalized, err := normalizeQueryName(name)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.getDB().ExecContext(context.Background(),
+		"DELETE FROM saved_queries WHERE name = ?", normalized)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("saved query not found: %s", normalized)
+	}
+
+	return nil
+}
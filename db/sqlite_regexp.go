@@ -0,0 +1,41 @@
+package db
+
+import (
+	"database/sql"
+	"regexp"
+	"sync"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName is go-sqlite3 with a REGEXP implementation, which SQLite leaves
+// to the application. Filters use it for field~/pattern/.
+const driverName = "sqlite3_troveler"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("regexp", regexpMatch, true)
+		},
+	})
+}
+
+// regexpCache holds compiled patterns: SQLite calls regexp once per row.
+var regexpCache sync.Map
+
+// regexpMatch implements "value REGEXP pattern".
+func regexpMatch(pattern, value string) (bool, error) {
+	if cached, ok := regexpCache.Load(pattern); ok {
+		if re, ok := cached.(*regexp.Regexp); ok {
+			return re.MatchString(value), nil
+		}
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	regexpCache.Store(pattern, re)
+
+	return re.MatchString(value), nil
+}
@@ -1,8 +1,13 @@
 package db
 
-import "context"
+import (
+	"context"
+	"database/sql"
+)
 
-func (s *SQLiteDB) createTables() error {
+// createTables creates the tables and indexes of the baseline schema, the
+// schema of the first versioned database.
+func createTables(ctx context.Context, tx *sql.Tx) error {
 	queries := []string{
 		`CREATE TABLE IF NOT EXISTS tools (
 			id TEXT PRIMARY KEY,
@@ -35,15 +40,77 @@ func (s *SQLiteDB) createTables() error {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			PRIMARY KEY (tool_id, tag_name)
 		)`,
+		`CREATE TABLE IF NOT EXISTS saved_queries (
+			name TEXT PRIMARY KEY COLLATE NOCASE,
+			expression TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS install_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			slug TEXT NOT NULL,
+			platform TEXT NOT NULL DEFAULT '',
+			command TEXT NOT NULL,
+			exit_code INTEGER NOT NULL,
+			duration_ms INTEGER NOT NULL DEFAULT 0,
+			output TEXT NOT NULL DEFAULT '',
+			user TEXT NOT NULL DEFAULT '',
+			installed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS tool_versions (
+			tool_id TEXT PRIMARY KEY,
+			slug TEXT NOT NULL,
+			installed_version TEXT NOT NULL DEFAULT '',
+			latest_version TEXT NOT NULL DEFAULT '',
+			outdated BOOLEAN NOT NULL DEFAULT false,
+			checked_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS tool_owners (
+			tool_id TEXT PRIMARY KEY,
+			lookup_path TEXT NOT NULL,
+			owner TEXT NOT NULL DEFAULT '',
+			package TEXT NOT NULL DEFAULT '',
+			path TEXT NOT NULL DEFAULT '',
+			checked_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS installed_state (
+			tool_id TEXT PRIMARY KEY,
+			executable TEXT NOT NULL DEFAULT '',
+			lookup_path TEXT NOT NULL DEFAULT '',
+			path TEXT NOT NULL DEFAULT '',
+			mtime DATETIME,
+			installed BOOLEAN NOT NULL DEFAULT false,
+			checked_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS batch_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			reuse_config BOOLEAN NOT NULL DEFAULT true,
+			use_sudo BOOLEAN NOT NULL DEFAULT false,
+			sudo_only_system BOOLEAN NOT NULL DEFAULT false,
+			skip_if_blind BOOLEAN NOT NULL DEFAULT false,
+			use_mise BOOLEAN NOT NULL DEFAULT false,
+			started_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS batch_run_tools (
+			run_id INTEGER NOT NULL REFERENCES batch_runs(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			slug TEXT NOT NULL,
+			platform TEXT NOT NULL DEFAULT '',
+			mise BOOLEAN NOT NULL DEFAULT false,
+			status TEXT NOT NULL DEFAULT 'pending',
+			detail TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (run_id, position)
+		)`,
 		`CREATE INDEX IF NOT EXISTS idx_tools_name ON tools(name)`,
 		`CREATE INDEX IF NOT EXISTS idx_tools_language ON tools(language)`,
 		`CREATE INDEX IF NOT EXISTS idx_tools_slug ON tools(slug)`,
 		`CREATE INDEX IF NOT EXISTS idx_install_tool_id ON install_instructions(tool_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_tool_tags_tag_name ON tool_tags(tag_name)`,
+		`CREATE INDEX IF NOT EXISTS idx_install_history_slug ON install_history(slug)`,
 	}
 
 	for _, q := range queries {
-		if _, err := s.getDB().ExecContext(context.Background(), q); err != nil {
+		if _, err := tx.ExecContext(ctx, q); err != nil {
 			return err
 		}
 	}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
 const (
@@ -11,28 +12,21 @@ const (
 	sortOrderAsc       = "ASC"
 	sortFieldName      = "name"
 
-	// installedOverfetchFactor controls how many extra rows we fetch when
-	// the installed filter is active. Since we can't filter by "installed"
-	// in SQL (it requires a runtime LookPath check), we over-fetch by this
-	// multiplier and then trim in Go. A factor of 4 means we fetch 4x the
-	// requested limit, which is sufficient for most real-world installed ratios.
-	installedOverfetchFactor = 4
-
-	// installedOverfetchMax caps the over-fetch to avoid pulling excessive rows
-	// even with large limits. Only applies when the caller specifies a limit > 0.
-	installedOverfetchMax = 500
-
-	// installedNoLimitFallback is used when the caller requests limit=0 (no limit)
-	// and the installed filter is active. We must fetch all rows since we can't
-	// predict how many will be filtered out.
-	installedNoLimitFallback = 100000
+	// SortFieldRelevance ranks free-text matches best first: by bm25 over the
+	// full-text index when available, otherwise name hits before tagline hits
+	// before description hits. Without a free-text term it sorts by name.
+	SortFieldRelevance = "relevance"
 )
 
 // Search queries tools matching opts, applying filters and sorting.
+// Tools marked as removed upstream are never returned.
+// The free-text term is matched through the tools_fts index when SQLite has
+// FTS5, with LIKE substring matching as a fallback and for words the index
+// cannot find (e.g. "sql" inside "mysql").
 // Sorting and limiting are always pushed to SQLite via ORDER BY / LIMIT.
-// The "installed" filter is resolved in Go (requires exec.LookPath) using
-// an over-fetch strategy: we fetch more rows than requested, resolve
-// installed status, filter, and trim to the actual limit.
+// Installed status is only read from the installed_state table (see
+// ScanInstalled and InstalledStateStale), so the installed filter is an
+// ordinary SQL predicate and searching never rescans PATH.
 func (s *SQLiteDB) Search(ctx context.Context, opts SearchOptions) ([]SearchResult, error) {
 	allowedFields := map[string]string{
 		"name":           "name",
@@ -55,31 +49,62 @@ func (s *SQLiteDB) Search(ctx context.Context, opts SearchOptions) ([]SearchResu
 	// in-memory compareASC provided via strings.ToLower.
 	orderByClause := sortField + " COLLATE NOCASE " + sortOrder
 
-	whereClause, args := BuildWhereClause(opts.Filter, opts.Query)
+	match := ""
+	if s.fts {
+		match = ftsQuery(opts.Query)
+	}
+
+	fromClause := "tools"
+	var args []interface{}
+	var whereClause string
+
+	if match == "" {
+		whereClause, args = BuildWhereClause(opts.Filter, opts.Query)
+	} else {
+		fromClause = `tools LEFT JOIN (
+			SELECT rowid AS fts_rowid, ` + ftsRank + ` AS fts_rank FROM tools_fts WHERE tools_fts MATCH ?
+		) ON fts_rowid = tools.rowid`
+		args = append(args, match)
 
-	if whereClause == "" {
-		whereClause = "name LIKE ? OR tagline LIKE ? OR description LIKE ?"
+		filterClause, filterArgs := BuildWhereClause(opts.Filter, "")
 		likeQuery := "%" + opts.Query + "%"
-		args = []interface{}{likeQuery, likeQuery, likeQuery}
+		whereClause = "(fts_rowid IS NOT NULL OR name LIKE ? OR tagline LIKE ? OR description LIKE ?) AND (" +
+			filterClause + ")"
+		args = append(args, likeQuery, likeQuery, likeQuery)
+		args = append(args, filterArgs...)
 	}
 
-	// Determine SQL LIMIT: over-fetch when installed filter is active
-	// since we can't filter by installed in SQL.
-	sqlLimit := opts.Limit
-	hasInstalled := hasInstalledFilter(opts.Filter)
-	if hasInstalled {
-		sqlLimit = overfetchLimit(opts.Limit)
+	if len(opts.Slugs) > 0 {
+		placeholders := strings.Repeat("?,", len(opts.Slugs))
+		whereClause = "(" + whereClause + ") AND slug IN (" + placeholders[:len(placeholders)-1] + ")"
+		for _, slug := range opts.Slugs {
+			args = append(args, slug)
+		}
+	}
+
+	if opts.SortField == SortFieldRelevance && opts.Query != "" {
+		rank, rankArgs := likeRank(opts.Query)
+		orderByClause = rank + " " + sortOrder + ", name COLLATE NOCASE"
+		if match != "" {
+			orderByClause = "fts_rank IS NULL, fts_rank " + sortOrder + ", " + orderByClause
+		}
+		args = append(args, rankArgs...)
 	}
 
 	sqlQuery := fmt.Sprintf(`
-		SELECT id, slug, name, tagline, description, language, license, date_published, code_repository, tool_of_the_week
-		FROM tools
-		WHERE %s
+		SELECT id, slug, name, tagline, description, language, license, date_published, code_repository, tool_of_the_week,
+			source, EXISTS (SELECT 1 FROM installed_state WHERE tool_id = tools.id AND installed)
+		FROM %s
+		WHERE NOT removed AND (%s)
 		ORDER BY %s
 		LIMIT ?
-	`, whereClause, orderByClause)
+	`, fromClause, whereClause, orderByClause)
 
-	args = append(args, sqlLimit)
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	args = append(args, limit)
 
 	rows, err := s.getDB().QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
@@ -87,100 +112,53 @@ func (s *SQLiteDB) Search(ctx context.Context, opts SearchOptions) ([]SearchResu
 	}
 	defer func() { _ = rows.Close() }()
 
-	var tools []Tool
+	var results []SearchResult
 	for rows.Next() {
 		var t Tool
 		err := rows.Scan(
 			&t.ID, &t.Slug, &t.Name, &t.Tagline, &t.Description,
 			&t.Language, &t.License, &t.DatePublished, &t.CodeRepository, &t.ToolOfTheWeek,
+			&t.Source, &t.Installed,
 		)
 		if err != nil {
 			return nil, err
 		}
-		tools = append(tools, t)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		results = append(results, SearchResult{Tool: t})
 	}
 
-	// Batch-fetch install instructions (1 query instead of N)
-	toolIDs := make([]string, len(tools))
-	for i, t := range tools {
-		toolIDs[i] = t.ID
-	}
-	installsByTool, err := s.GetInstallInstructionsBatch(ctx, toolIDs)
+	return results, rows.Err()
+}
+
+// likeRank returns an ORDER BY expression ranking exact name matches first,
+// then name prefixes, name substrings, tagline hits and everything else.
+func likeRank(term string) (string, []interface{}) {
+	contains := "%" + term + "%"
+
+	return `CASE
+		WHEN name LIKE ? THEN 0
+		WHEN name LIKE ? THEN 1
+		WHEN name LIKE ? THEN 2
+		WHEN tagline LIKE ? THEN 3
+		ELSE 4 END`, []interface{}{term, term + "%", contains, contains}
+}
+
+// ListToolNames returns the name of every tool not removed upstream, keyed by
+// slug. Fuzzy search ranks these in Go since SQLite has no edit distance.
+func (s *SQLiteDB) ListToolNames(ctx context.Context) (map[string]string, error) {
+	rows, err := s.getDB().QueryContext(ctx, `SELECT slug, name FROM tools WHERE NOT removed`)
 	if err != nil {
 		return nil, err
 	}
+	defer func() { _ = rows.Close() }()
 
-	// Build LookPath cache (deduplicated — one stat per unique executable name)
-	pathCache := BuildLookPathCache(installsByTool)
-
-	// Resolve installed status and apply installed filter in one pass.
-	// Results are already sorted by SQLite, so we just need to filter
-	// by installed status and trim to the requested limit.
-	//
-	// When installed appears in an OR context (e.g., OR(installed=true, language=go)),
-	// the SQL WHERE already handles the non-installed branch. Applying a Go-side
-	// filter would incorrectly exclude those results, so we skip it.
-	skipGoFilter := hasInstalled && hasInstalledInOrContext(opts.Filter)
-	wantInstalled, negated := false, false
-	if hasInstalled && !skipGoFilter {
-		wantInstalled, negated = installedFilterValue(opts.Filter)
-	}
-
-	var results []SearchResult
-	for _, t := range tools {
-		installs := installsByTool[t.ID]
-		t.Installed = IsInstalledCached(&t, installs, pathCache)
-
-		if hasInstalled && !skipGoFilter {
-			match := t.Installed == wantInstalled
-			if negated {
-				match = !match
-			}
-			if !match {
-				continue
-			}
-		}
-
-		results = append(results, SearchResult{Tool: t})
-
-		// Early exit: we have enough results after filtering
-		if hasInstalled && !skipGoFilter && opts.Limit > 0 && len(results) >= opts.Limit {
-			break
+	names := make(map[string]string)
+	for rows.Next() {
+		var slug, name string
+		if err := rows.Scan(&slug, &name); err != nil {
+			return nil, err
 		}
+		names[slug] = name
 	}
 
-	return results, nil
-}
-
-// overfetchLimit computes the SQL LIMIT when the installed filter is active.
-// We fetch more rows than requested to compensate for rows that will be
-// filtered out by the Go-side installed check.
-// When requested is 0 (no limit), we fall back to a large cap since we
-// can't predict how many rows the installed filter will discard.
-func overfetchLimit(requested int) int {
-	if requested <= 0 {
-		return installedNoLimitFallback
-	}
-	limit := requested * installedOverfetchFactor
-	if limit > installedOverfetchMax {
-		limit = installedOverfetchMax
-	}
-	if limit < requested {
-		limit = requested
-	}
-	return limit
-}
-
-// installedFilterValue extracts the desired installed state from the filter AST.
-// Returns (value, negated) where value is true if the filter wants installed=true.
-func installedFilterValue(filter *Filter) (bool, bool) {
-	if filter == nil {
-		return false, false
-	}
-	val, negated := getInstalledFilterInfo(filter, false)
-	wantInstalled := (val == "true" || val == "1")
-	return wantInstalled, negated
+	return names, rows.Err()
 }
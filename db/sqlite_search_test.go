@@ -1,110 +1,216 @@
 package db
 
-import "testing"
-
-func TestOverfetchLimitWithPositiveRequested(t *testing.T) {
-	// 10 * 4 = 40, under the cap
-	got := overfetchLimit(10)
-	if got != 40 {
-		t.Errorf("expected 40, got %d", got)
-	}
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakePath puts executables with the given names into a fresh PATH, the
+// directory bin of a temporary directory.
+func fakePath(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "bin")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	return dir
 }
 
-func TestOverfetchLimitCapped(t *testing.T) {
-	// 200 * 4 = 800, capped at 500
-	got := overfetchLimit(200)
-	if got != 500 {
-		t.Errorf("expected 500 (capped), got %d", got)
+// scanForTest stores the installed state Search reads.
+func scanForTest(t *testing.T, database *SQLiteDB) {
+	t.Helper()
+	if _, err := database.ScanInstalled(context.Background()); err != nil {
+		t.Fatalf("ScanInstalled failed: %v", err)
 	}
 }
 
-func TestOverfetchLimitAtCap(t *testing.T) {
-	// 125 * 4 = 500, exactly at cap
-	got := overfetchLimit(125)
-	if got != 500 {
-		t.Errorf("expected 500, got %d", got)
+func searchNames(t *testing.T, database *SQLiteDB, filter *Filter, limit int) []string {
+	t.Helper()
+	results, err := database.Search(context.Background(), SearchOptions{
+		Limit:     limit,
+		SortField: "name",
+		Filter:    filter,
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
 	}
-}
 
-func TestOverfetchLimitZeroReturnsFallback(t *testing.T) {
-	got := overfetchLimit(0)
-	if got != installedNoLimitFallback {
-		t.Errorf("expected %d (no-limit fallback), got %d", installedNoLimitFallback, got)
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
 	}
-}
 
-func TestOverfetchLimitNegativeReturnsFallback(t *testing.T) {
-	got := overfetchLimit(-1)
-	if got != installedNoLimitFallback {
-		t.Errorf("expected %d (no-limit fallback), got %d", installedNoLimitFallback, got)
-	}
+	return names
 }
 
-func TestOverfetchLimitOne(t *testing.T) {
-	got := overfetchLimit(1)
-	if got != 4 {
-		t.Errorf("expected 4, got %d", got)
+func TestSearchInstalledPredicate(t *testing.T) {
+	fakePath(t, "aa-tool", "cc-tool", "ee-tool")
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	seedToolWithInstallAndLanguage(t, database, "tool-a", "aa", "aa-tool", "go")
+	seedToolWithInstallAndLanguage(t, database, "tool-b", "bb", "bb-tool", "go")
+	seedToolWithInstallAndLanguage(t, database, "tool-c", "cc", "cc-tool", "rust")
+	seedToolWithInstallAndLanguage(t, database, "tool-d", "dd", "dd-tool", "rust")
+	seedToolWithInstallAndLanguage(t, database, "tool-e", "ee", "ee-tool", "rust")
+	scanForTest(t, database)
+
+	installed := &Filter{Type: FilterField, Field: "installed", Value: "true"}
+	tests := []struct {
+		name   string
+		filter *Filter
+		limit  int
+		want   []string
+	}{
+		{"installed", installed, 10, []string{"aa", "cc", "ee"}},
+		{"limit applies after the predicate", installed, 2, []string{"aa", "cc"}},
+		{"not installed", &Filter{Type: FilterNot, Left: installed}, 10, []string{"bb", "dd"}},
+		{"or branch", &Filter{
+			Type:  FilterOr,
+			Left:  installed,
+			Right: &Filter{Type: FilterField, Field: "language", Value: "go"},
+		}, 10, []string{"aa", "bb", "cc", "ee"}},
+		{"and", &Filter{
+			Type:  FilterAnd,
+			Left:  installed,
+			Right: &Filter{Type: FilterField, Field: "language", Value: "rust"},
+		}, 1, []string{"cc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := searchNames(t, database, tt.filter, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
 	}
 }
 
-func TestInstalledFilterValueTrue(t *testing.T) {
-	filter := &Filter{
-		Type:  FilterField,
-		Field: "installed",
-		Value: "true",
-	}
+func TestScanInstalled(t *testing.T) {
+	dir := fakePath(t, "aa-tool")
+	database := setupTestDB(t)
+	defer checkClose(t, database)
 
-	wantInstalled, negated := installedFilterValue(filter)
+	seedToolWithInstall(t, database, "tool-a", "aa", "aa-tool")
+	seedToolWithInstall(t, database, "tool-b", "bb", "bb-tool")
 
-	if !wantInstalled {
-		t.Errorf("expected wantInstalled=true")
+	saved := ownerRunner
+	ownerRunner = fakeOwnerRunner(nil, nil)
+	defer func() { ownerRunner = saved }()
+
+	ctx := context.Background()
+	result, err := database.ScanInstalled(ctx)
+	if err != nil {
+		t.Fatalf("ScanInstalled failed: %v", err)
 	}
-	if negated {
-		t.Errorf("expected negated=false")
+	if result.Tools != 2 || result.Installed != 1 || len(result.Added) != 1 || result.Added[0] != "aa" {
+		t.Errorf("unexpected first scan: %+v", result)
+	}
+
+	states, err := database.GetInstalledState(ctx)
+	if err != nil {
+		t.Fatalf("GetInstalledState failed: %v", err)
+	}
+	if st := states["tool-a"]; !st.Installed || st.LookupPath != filepath.Join(dir, "aa-tool") || st.ModTime.IsZero() {
+		t.Errorf("unexpected state for aa: %+v", st)
+	}
+	if st := states["tool-b"]; st.Installed || st.Executable != "bb-tool" {
+		t.Errorf("unexpected state for bb: %+v", st)
 	}
-}
 
-func TestInstalledFilterValueOne(t *testing.T) {
-	filter := &Filter{
-		Type:  FilterField,
-		Field: "installed",
-		Value: "1",
+	// State is only refreshed by a scan: Search reads what is stored.
+	if err := os.Rename(filepath.Join(dir, "aa-tool"), filepath.Join(dir, "bb-tool")); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if got := searchNames(t, database, &Filter{Type: FilterField, Field: "installed", Value: "true"}, 10); len(got) != 1 ||
+		got[0] != "aa" {
+		t.Errorf("expected the stored state before a rescan, got %v", got)
 	}
 
-	wantInstalled, negated := installedFilterValue(filter)
+	if err := database.RefreshInstalled(ctx, "aa", "bb"); err != nil {
+		t.Fatalf("RefreshInstalled failed: %v", err)
+	}
+	if got := searchNames(t, database, &Filter{Type: FilterField, Field: "installed", Value: "true"}, 10); len(got) != 1 ||
+		got[0] != "bb" {
+		t.Errorf("expected bb installed after the refresh, got %v", got)
+	}
 
-	if !wantInstalled {
-		t.Errorf("expected wantInstalled=true for value '1'")
+	result, err = database.ScanInstalled(ctx)
+	if err != nil {
+		t.Fatalf("ScanInstalled failed: %v", err)
 	}
-	if negated {
-		t.Errorf("expected negated=false")
+	if result.Changed() {
+		t.Errorf("expected no changes after the refresh, got %+v", result)
 	}
 }
 
-func TestInstalledFilterValueFalse(t *testing.T) {
-	filter := &Filter{
-		Type:  FilterField,
-		Field: "installed",
-		Value: "false",
-	}
+func TestRefreshInstalledManySlugs(t *testing.T) {
+	fakePath(t, "last-tool")
+	database := setupTestDB(t)
+	defer checkClose(t, database)
 
-	wantInstalled, negated := installedFilterValue(filter)
+	// More slugs than one statement may bind.
+	n := 2*sqliteVarLimit + 1
+	slugs := make([]string, n)
+	for i := range slugs {
+		slugs[i] = fmt.Sprintf("tool%d", i)
+		seedToolWithInstall(t, database, "id-"+slugs[i], slugs[i], slugs[i]+"-cmd")
+	}
+	seedToolWithInstall(t, database, "id-last", "last", "last-tool")
+	slugs = append(slugs, "last")
 
-	if wantInstalled {
-		t.Errorf("expected wantInstalled=false for value 'false'")
+	ctx := context.Background()
+	if err := database.RefreshInstalled(ctx, slugs...); err != nil {
+		t.Fatalf("RefreshInstalled failed: %v", err)
 	}
-	if negated {
-		t.Errorf("expected negated=false")
+	states, err := database.GetInstalledState(ctx)
+	if err != nil {
+		t.Fatalf("GetInstalledState failed: %v", err)
+	}
+	if len(states) != n+1 {
+		t.Errorf("refreshed %d tools, want %d", len(states), n+1)
+	}
+	if !states["id-last"].Installed {
+		t.Errorf("expected the tool in the last chunk installed, got %+v", states["id-last"])
 	}
 }
 
-func TestInstalledFilterValueNil(t *testing.T) {
-	wantInstalled, negated := installedFilterValue(nil)
+func TestSearchInstalledOwner(t *testing.T) {
+	t.Setenv("CARGO_HOME", filepath.Dir(fakePath(t, "aa-tool", "bb-tool")))
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	seedToolWithInstall(t, database, "tool-a", "aa", "aa-tool")
+	seedToolWithInstall(t, database, "tool-c", "cc", "cc-tool")
+
+	saved := ownerRunner
+	ownerRunner = fakeOwnerRunner(nil, nil)
+	defer func() { ownerRunner = saved }()
+
+	scanForTest(t, database)
 
-	if wantInstalled {
-		t.Errorf("expected wantInstalled=false for nil filter")
+	// The fake PATH directory is CARGO_HOME/bin, so cargo owns aa-tool.
+	if got := searchNames(t, database, &Filter{Type: FilterField, Field: "installed", Value: "cargo"}, 10); len(got) != 1 ||
+		got[0] != "aa" {
+		t.Errorf("expected aa owned by cargo, got %v", got)
 	}
-	if negated {
-		t.Errorf("expected negated=false for nil filter")
+	if got := searchNames(t, database, &Filter{Type: FilterField, Field: "installed", Value: "apt"}, 10); len(got) != 0 {
+		t.Errorf("expected nothing owned by apt, got %v", got)
 	}
 }
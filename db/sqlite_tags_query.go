@@ -75,7 +75,7 @@ func (s *SQLiteDB) GetToolsByTag(tagName string) ([]Tool, error) {
 
 	query := `
 		SELECT t.id, t.slug, t.name, t.tagline, t.description, t.language, t.license,
-			t.date_published, t.code_repository, t.created_at, t.updated_at
+			t.date_published, t.code_repository, t.source, t.created_at, t.updated_at
 		FROM tools t
 		JOIN tool_tags tt ON t.id = tt.tool_id
 		WHERE tt.tag_name = ?
@@ -93,7 +93,7 @@ func (s *SQLiteDB) GetToolsByTag(tagName string) ([]Tool, error) {
 		err := rows.Scan(
 			&t.ID, &t.Slug, &t.Name, &t.Tagline, &t.Description,
 			&t.Language, &t.License, &t.DatePublished, &t.CodeRepository,
-			&t.CreatedAt, &t.UpdatedAt,
+			&t.Source, &t.CreatedAt, &t.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -2,17 +2,50 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 )
 
+// ErrSlugTaken reports a tool whose slug another source already lists.
+var ErrSlugTaken = errors.New("slug belongs to another source")
+
 // UpsertTool inserts or updates a tool record.
+// Tool IDs are stable per slug: when a row with the same slug already exists,
+// tool.ID is rewritten to the stored ID so dependent rows (install
+// instructions, tags) keep pointing at the same tool across updates. A slug
+// stays with the source that listed it first, until that source removes it;
+// a tool of another source with the same slug fails with ErrSlugTaken.
 func (s *SQLiteDB) UpsertTool(ctx context.Context, tool *Tool) error {
+	return upsertTool(ctx, s.getDB(), tool)
+}
+
+func upsertTool(ctx context.Context, q execer, tool *Tool) error {
+	if tool.Source == "" {
+		tool.Source = DefaultSource
+	}
+
+	if tool.Slug != "" {
+		var existingID, existingSource string
+		var removed bool
+		err := q.QueryRowContext(ctx, "SELECT id, source, removed FROM tools WHERE slug = ?",
+			tool.Slug).Scan(&existingID, &existingSource, &removed)
+		switch {
+		case err == nil && existingSource != tool.Source && !removed:
+			return fmt.Errorf("%w: %s is listed by %s", ErrSlugTaken, tool.Slug, existingSource)
+		case err == nil:
+			tool.ID = existingID
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+	}
+
 	query := `
 		INSERT INTO tools (id, slug, name, tagline, description, language, license,
-			date_published, code_repository, tool_of_the_week, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			date_published, code_repository, tool_of_the_week, source_hash, removed, source, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, false, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			slug = excluded.slug,
 			name = excluded.name,
@@ -23,13 +56,18 @@ func (s *SQLiteDB) UpsertTool(ctx context.Context, tool *Tool) error {
 			date_published = excluded.date_published,
 			code_repository = excluded.code_repository,
 			tool_of_the_week = excluded.tool_of_the_week,
+			source_hash = excluded.source_hash,
+			removed = false,
+			source = excluded.source,
 			updated_at = excluded.updated_at
 	`
 
 	tool.UpdatedAt = time.Now()
-	_, err := s.getDB().ExecContext(ctx, query,
+	tool.Removed = false
+	_, err := q.ExecContext(ctx, query,
 		tool.ID, tool.Slug, tool.Name, tool.Tagline, tool.Description,
-		tool.Language, tool.License, tool.DatePublished, tool.CodeRepository, tool.ToolOfTheWeek, tool.UpdatedAt,
+		tool.Language, tool.License, tool.DatePublished, tool.CodeRepository, tool.ToolOfTheWeek,
+		tool.SourceHash, tool.Source, tool.UpdatedAt,
 	)
 
 	return err
@@ -55,7 +93,7 @@ func (s *SQLiteDB) UpsertInstallInstruction(ctx context.Context, inst *InstallIn
 // GetAllTools returns every tool in the database.
 func (s *SQLiteDB) GetAllTools(ctx context.Context) ([]Tool, error) {
 	query := `SELECT id, slug, name, tagline, description, language, license,
-		date_published, code_repository, tool_of_the_week, created_at, updated_at FROM tools`
+		date_published, code_repository, tool_of_the_week, removed, source, created_at, updated_at FROM tools`
 	rows, err := s.getDB().QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -68,7 +106,7 @@ func (s *SQLiteDB) GetAllTools(ctx context.Context) ([]Tool, error) {
 		err := rows.Scan(
 			&t.ID, &t.Slug, &t.Name, &t.Tagline, &t.Description,
 			&t.Language, &t.License, &t.DatePublished, &t.CodeRepository,
-			&t.ToolOfTheWeek, &t.CreatedAt, &t.UpdatedAt,
+			&t.ToolOfTheWeek, &t.Removed, &t.Source, &t.CreatedAt, &t.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -82,7 +120,7 @@ func (s *SQLiteDB) GetAllTools(ctx context.Context) ([]Tool, error) {
 // GetToolBySlug returns tools matching the given slug.
 func (s *SQLiteDB) GetToolBySlug(slug string) ([]Tool, error) {
 	query := `SELECT id, slug, name, tagline, description, language, license,
-		date_published, code_repository, tool_of_the_week, created_at, updated_at FROM tools WHERE slug = ?`
+		date_published, code_repository, tool_of_the_week, removed, source, created_at, updated_at FROM tools WHERE slug = ?`
 	rows, err := s.getDB().QueryContext(context.Background(), query, slug)
 	if err != nil {
 		return nil, err
@@ -95,7 +133,7 @@ func (s *SQLiteDB) GetToolBySlug(slug string) ([]Tool, error) {
 		err := rows.Scan(
 			&t.ID, &t.Slug, &t.Name, &t.Tagline, &t.Description,
 			&t.Language, &t.License, &t.DatePublished, &t.CodeRepository,
-			&t.ToolOfTheWeek, &t.CreatedAt, &t.UpdatedAt,
+			&t.ToolOfTheWeek, &t.Removed, &t.Source, &t.CreatedAt, &t.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
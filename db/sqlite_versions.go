@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// SaveToolVersion stores the result of a version check, replacing the
+// previous check of the same tool.
+func (s *SQLiteDB) SaveToolVersion(v *ToolVersion) error {
+	if v.ToolID == "" {
+		return fmt.Errorf("tool version has no tool id")
+	}
+
+	_, err := s.getDB().ExecContext(context.Background(), `
+		INSERT INTO tool_versions (tool_id, slug, installed_version, latest_version, outdated, checked_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(tool_id) DO UPDATE SET
+			slug = excluded.slug,
+			installed_version = excluded.installed_version,
+			latest_version = excluded.latest_version,
+			outdated = excluded.outdated,
+			checked_at = excluded.checked_at`,
+		v.ToolID, v.Slug, v.Installed, v.Latest, v.Outdated)
+	if err != nil {
+		return fmt.Errorf("save version of %s: %w", v.Slug, err)
+	}
+
+	return nil
+}
+
+// GetToolVersions returns the stored version checks keyed by tool ID.
+func (s *SQLiteDB) GetToolVersions() (map[string]ToolVersion, error) {
+	rows, err := s.getDB().QueryContext(context.Background(), `
+		SELECT tool_id, slug, installed_version, latest_version, outdated, checked_at FROM tool_versions`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	versions := make(map[string]ToolVersion)
+	for rows.Next() {
+		var v ToolVersion
+		if err := rows.Scan(&v.ToolID, &v.Slug, &v.Installed, &v.Latest, &v.Outdated, &v.CheckedAt); err != nil {
+			return nil, err
+		}
+		versions[v.ToolID] = v
+	}
+
+	return versions, rows.Err()
+}
+
+// OutdatedToolIDs returns the IDs of tools whose last check found a newer
+// upstream release.
+func (s *SQLiteDB) OutdatedToolIDs() (map[string]bool, error) {
+	rows, err := s.getDB().QueryContext(context.Background(),
+		"SELECT tool_id FROM tool_versions WHERE outdated")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
+
+// DeleteToolVersion forgets the version check of a tool, e.g. after it was
+// uninstalled.
+func (s *SQLiteDB) DeleteToolVersion(toolID string) error {
+	_, err := s.getDB().ExecContext(context.Background(), "DELETE FROM tool_versions WHERE tool_id = ?", toolID)
+
+	return err
+}
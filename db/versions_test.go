@@ -0,0 +1,53 @@
+package db
+
+import "testing"
+
+func TestToolVersions(t *testing.T) {
+	database := setupTestDB(t)
+	defer checkClose(t, database)
+
+	for _, v := range []*ToolVersion{
+		{ToolID: "1", Slug: "ripgrep", Installed: "13.0.0", Latest: "14.1.0", Outdated: true},
+		{ToolID: "2", Slug: "fd", Installed: "10.1.0", Latest: "10.1.0"},
+	} {
+		if err := database.SaveToolVersion(v); err != nil {
+			t.Fatalf("SaveToolVersion failed: %v", err)
+		}
+	}
+
+	outdated, err := database.OutdatedToolIDs()
+	if err != nil {
+		t.Fatalf("OutdatedToolIDs failed: %v", err)
+	}
+	if len(outdated) != 1 || !outdated["1"] {
+		t.Errorf("expected only ripgrep outdated, got %v", outdated)
+	}
+
+	// A newer check replaces the old one.
+	err = database.SaveToolVersion(&ToolVersion{ToolID: "1", Slug: "ripgrep", Installed: "14.1.0", Latest: "14.1.0"})
+	if err != nil {
+		t.Fatalf("SaveToolVersion failed: %v", err)
+	}
+
+	versions, err := database.GetToolVersions()
+	if err != nil {
+		t.Fatalf("GetToolVersions failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %+v", versions)
+	}
+	if v := versions["1"]; v.Installed != "14.1.0" || v.Outdated || v.CheckedAt.IsZero() {
+		t.Errorf("expected the replaced check, got %+v", v)
+	}
+
+	if err := database.DeleteToolVersion("2"); err != nil {
+		t.Fatalf("DeleteToolVersion failed: %v", err)
+	}
+	if versions, _ := database.GetToolVersions(); len(versions) != 1 {
+		t.Errorf("expected 1 version after delete, got %+v", versions)
+	}
+
+	if err := database.SaveToolVersion(&ToolVersion{Slug: "bat"}); err == nil {
+		t.Error("expected error for a version without tool id")
+	}
+}
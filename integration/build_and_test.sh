@@ -8,7 +8,7 @@ PROJECT_DIR="$(dirname "$SCRIPT_DIR")"
 
 echo "Building troveler binary..."
 cd "$PROJECT_DIR"
-CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -o troveler .
+CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -tags sqlite_fts5 -o troveler .
 
 echo "Copying database..."
 cp ~/.local/share/troveler/troveler.db "$SCRIPT_DIR/troveler.db"
@@ -18,6 +18,8 @@ type ToolInfo struct {
 	Repository     string
 	DatePublished  string
 	Slug           string
+	Source         string
+	Installed      string // owning package manager and path, "" when not installed
 	InstallOptions []InstallOption
 }
 
@@ -38,6 +40,7 @@ func FormatTool(tool *db.Tool, installs []db.InstallInstruction) *ToolInfo {
 		Repository:    tool.CodeRepository,
 		DatePublished: tool.DatePublished,
 		Slug:          tool.Slug,
+		Source:        tool.Source,
 	}
 
 	for _, inst := range installs {
@@ -50,6 +53,26 @@ func FormatTool(tool *db.Tool, installs []db.InstallInstruction) *ToolInfo {
 	return info
 }
 
+// SetOwner describes where the installed executable came from, e.g.
+// "cargo: ripgrep (/home/me/.cargo/bin/rg)". A nil owner clears it.
+func (ti *ToolInfo) SetOwner(owner *db.ToolOwner) {
+	ti.Installed = FormatOwner(owner)
+}
+
+// FormatOwner renders an installed executable's owner for display.
+func FormatOwner(owner *db.ToolOwner) string {
+	switch {
+	case owner == nil:
+		return ""
+	case owner.Owner == "":
+		return fmt.Sprintf("unknown manager (%s)", owner.Path)
+	case owner.Package == "":
+		return fmt.Sprintf("%s (%s)", owner.Owner, owner.Path)
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", owner.Owner, owner.Package, owner.Path)
+}
+
 // RenderPlainText renders tool info as plain text
 func (ti *ToolInfo) RenderPlainText() string {
 	var b strings.Builder
@@ -84,6 +107,12 @@ func (ti *ToolInfo) RenderPlainText() string {
 		fmt.Fprintf(&b, "  Published:  %s\n", ti.DatePublished)
 	}
 	fmt.Fprintf(&b, "  Slug:       %s\n", ti.Slug)
+	if ti.Source != "" {
+		fmt.Fprintf(&b, "  Source:     %s\n", ti.Source)
+	}
+	if ti.Installed != "" {
+		fmt.Fprintf(&b, "  Installed:  %s\n", ti.Installed)
+	}
 
 	if len(ti.InstallOptions) > 0 {
 		b.WriteString("\nInstall Instructions:\n")
@@ -137,6 +166,12 @@ func (ti *ToolInfo) GetKeyValuePairs() [][]string {
 		pairs = append(pairs, []string{"Published", ti.DatePublished})
 	}
 	pairs = append(pairs, []string{"Slug", ti.Slug})
+	if ti.Source != "" {
+		pairs = append(pairs, []string{"Source", ti.Source})
+	}
+	if ti.Installed != "" {
+		pairs = append(pairs, []string{"Installed", ti.Installed})
+	}
 
 	return pairs
 }
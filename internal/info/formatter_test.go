@@ -56,6 +56,7 @@ func TestFormatTool(t *testing.T) {
 		License:        "MIT",
 		CodeRepository: "https://github.com/test/tool",
 		DatePublished:  "2024-01-01",
+		Source:         "team",
 	}
 
 	installs := []db.InstallInstruction{
@@ -69,6 +70,10 @@ func TestFormatTool(t *testing.T) {
 		t.Errorf("Expected name 'Test Tool', got %s", info.Name)
 	}
 
+	if info.Source != "team" {
+		t.Errorf("Expected source 'team', got %s", info.Source)
+	}
+
 	if len(info.InstallOptions) != 2 {
 		t.Errorf("Expected 2 install options, got %d", len(info.InstallOptions))
 	}
@@ -132,3 +137,28 @@ func TestRenderPlainText(t *testing.T) {
 		t.Error("Output should contain language field")
 	}
 }
+
+func TestFormatOwner(t *testing.T) {
+	tests := []struct {
+		owner *db.ToolOwner
+		want  string
+	}{
+		{nil, ""},
+		{&db.ToolOwner{Path: "/usr/local/bin/rg"}, "unknown manager (/usr/local/bin/rg)"},
+		{&db.ToolOwner{Owner: "cargo", Path: "/home/me/.cargo/bin/rg"}, "cargo (/home/me/.cargo/bin/rg)"},
+		{&db.ToolOwner{Owner: "apt", Package: "fd-find", Path: "/usr/bin/fdfind"}, "apt: fd-find (/usr/bin/fdfind)"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatOwner(tt.owner); got != tt.want {
+			t.Errorf("FormatOwner(%+v) = %q, want %q", tt.owner, got, tt.want)
+		}
+	}
+
+	ti := &ToolInfo{Slug: "rg"}
+	ti.SetOwner(tests[2].owner)
+	pairs := ti.GetKeyValuePairs()
+	if last := pairs[len(pairs)-1]; last[0] != "Installed" || last[1] != tests[2].want {
+		t.Errorf("expected an Installed pair, got %v", pairs)
+	}
+}
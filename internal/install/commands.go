@@ -105,6 +105,11 @@ func TransformToMise(command string) string {
 	return platform.TransformToMise(command)
 }
 
+// UninstallCommand derives the command that undoes an install command.
+func UninstallCommand(installCommand string) (string, error) {
+	return platform.UninstallCommand(installCommand)
+}
+
 // VirtualInstall represents a synthetic install instruction.
 type VirtualInstall struct {
 	Platform string
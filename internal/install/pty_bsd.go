@@ -0,0 +1,42 @@
+//go:build darwin || freebsd
+
+package install
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal of the given size, returning its master
+// side and the terminal a command runs in.
+func openPTY(cols, rows uint16) (ptmx, tty *os.File, err error) {
+	ptmx, name, err := openPTM()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := unix.IoctlSetWinsize(int(ptmx.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Row: rows, Col: cols}); err != nil {
+		_ = ptmx.Close()
+
+		return nil, nil, fmt.Errorf("size pseudo-terminal: %w", err)
+	}
+
+	tty, err = os.OpenFile(name, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		_ = ptmx.Close()
+
+		return nil, nil, fmt.Errorf("open pseudo-terminal: %w", err)
+	}
+
+	return ptmx, tty, nil
+}
+
+// echoing reports whether the terminal echoes what is typed into it.
+func echoing(ptmx *os.File) bool {
+	termios, err := unix.IoctlGetTermios(int(ptmx.Fd()), unix.TIOCGETA)
+
+	return err != nil || termios.Lflag&unix.ECHO != 0
+}
@@ -0,0 +1,49 @@
+//go:build darwin
+
+package install
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTM opens a pseudo-terminal master the way posix_openpt, grantpt and
+// unlockpt do on macOS, returning it with the path of its terminal.
+func openPTM() (*os.File, string, error) {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, "", fmt.Errorf("open pseudo-terminal: %w", err)
+	}
+
+	fd := int(ptmx.Fd())
+	if err := unix.IoctlSetInt(fd, unix.TIOCPTYGRANT, 0); err != nil {
+		_ = ptmx.Close()
+
+		return nil, "", fmt.Errorf("grant pseudo-terminal: %w", err)
+	}
+	if err := unix.IoctlSetInt(fd, unix.TIOCPTYUNLK, 0); err != nil {
+		_ = ptmx.Close()
+
+		return nil, "", fmt.Errorf("unlock pseudo-terminal: %w", err)
+	}
+
+	// TIOCPTYGNAME fills a buffer of 128 bytes with the terminal's path.
+	var name [128]byte
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(unix.TIOCPTYGNAME),
+		uintptr(unsafe.Pointer(&name[0])))
+	if errno != 0 {
+		_ = ptmx.Close()
+
+		return nil, "", fmt.Errorf("name pseudo-terminal: %w", errno)
+	}
+	if i := bytes.IndexByte(name[:], 0); i >= 0 {
+		return ptmx, string(name[:i]), nil
+	}
+
+	return ptmx, string(name[:]), nil
+}
@@ -0,0 +1,30 @@
+//go:build freebsd
+
+package install
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTM opens a pseudo-terminal master with posix_openpt, returning it
+// with the path of its terminal. FreeBSD needs no grantpt or unlockpt.
+func openPTM() (*os.File, string, error) {
+	fd, _, errno := unix.Syscall(unix.SYS_POSIX_OPENPT, uintptr(unix.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC), 0, 0)
+	if errno != 0 {
+		return nil, "", fmt.Errorf("open pseudo-terminal: %w", errno)
+	}
+	ptmx := os.NewFile(fd, "/dev/ptmx")
+
+	n, err := unix.IoctlGetInt(int(fd), unix.TIOCGPTN)
+	if err != nil {
+		_ = ptmx.Close()
+
+		return nil, "", fmt.Errorf("name pseudo-terminal: %w", err)
+	}
+
+	return ptmx, "/dev/pts/" + strconv.Itoa(n), nil
+}
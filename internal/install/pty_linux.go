@@ -0,0 +1,55 @@
+//go:build linux
+
+package install
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal of the given size, returning its master
+// side and the terminal a command runs in.
+func openPTY(cols, rows uint16) (ptmx, tty *os.File, err error) {
+	ptmx, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open pseudo-terminal: %w", err)
+	}
+
+	fd := int(ptmx.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		_ = ptmx.Close()
+
+		return nil, nil, fmt.Errorf("unlock pseudo-terminal: %w", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		_ = ptmx.Close()
+
+		return nil, nil, fmt.Errorf("name pseudo-terminal: %w", err)
+	}
+	if err := unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: rows, Col: cols}); err != nil {
+		_ = ptmx.Close()
+
+		return nil, nil, fmt.Errorf("size pseudo-terminal: %w", err)
+	}
+
+	tty, err = os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		_ = ptmx.Close()
+
+		return nil, nil, fmt.Errorf("open pseudo-terminal: %w", err)
+	}
+
+	return ptmx, tty, nil
+}
+
+// echoing reports whether the terminal echoes what is typed into it.
+func echoing(ptmx *os.File) bool {
+	termios, err := unix.IoctlGetTermios(int(ptmx.Fd()), unix.TCGETS)
+
+	return err != nil || termios.Lflag&unix.ECHO != 0
+}
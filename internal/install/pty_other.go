@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !freebsd
+
+package install
+
+import (
+	"os"
+	"os/exec"
+)
+
+func openPTY(_, _ uint16) (ptmx, tty *os.File, err error) {
+	return nil, nil, errNoPTY
+}
+
+func echoing(_ *os.File) bool {
+	return true
+}
+
+func runInSession(_ *exec.Cmd) {}
@@ -0,0 +1,18 @@
+//go:build linux || darwin || freebsd
+
+package install
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// runInSession makes cmd lead a new session with its terminal as the
+// controlling one, and makes canceling it kill the whole session rather
+// than just sh.
+func runInSession(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,283 @@
+package install
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RiskClass is one kind of risk an install command carries.
+type RiskClass string
+
+// Risk classes reported by AnalyzeCommand.
+const (
+	RiskPipeToShell   RiskClass = "pipe-to-shell"  // downloaded code is fed to a shell or interpreter
+	RiskRemoteScript  RiskClass = "remote-script"  // fetches what it runs from a URL
+	RiskSudo          RiskClass = "sudo"           // runs as root
+	RiskUnknownBinary RiskClass = "unknown-binary" // runs a program that is not a known package manager
+	RiskUnpinned      RiskClass = "unpinned"       // installs whatever @latest is at the time
+)
+
+// RiskLevel orders how dangerous running a command is.
+type RiskLevel int
+
+// Risk levels, lowest first.
+const (
+	RiskNone RiskLevel = iota
+	RiskLow
+	RiskMedium
+	RiskHigh
+)
+
+var riskLevelNames = map[RiskLevel]string{RiskNone: "none", RiskLow: "low", RiskMedium: "medium", RiskHigh: "high"}
+
+func (l RiskLevel) String() string {
+	return riskLevelNames[l]
+}
+
+// riskClasses lists every class with its level, in the order reports name
+// them.
+var riskClasses = []struct {
+	class RiskClass
+	level RiskLevel
+}{
+	{RiskPipeToShell, RiskHigh},
+	{RiskRemoteScript, RiskHigh},
+	{RiskSudo, RiskMedium},
+	{RiskUnknownBinary, RiskMedium},
+	{RiskUnpinned, RiskLow},
+}
+
+// RiskClasses returns every class AnalyzeCommand can report.
+func RiskClasses() []RiskClass {
+	classes := make([]RiskClass, len(riskClasses))
+	for i, rc := range riskClasses {
+		classes[i] = rc.class
+	}
+
+	return classes
+}
+
+// Risk is the outcome of analyzing an install command.
+type Risk struct {
+	Level   RiskLevel
+	Classes []RiskClass // in RiskClasses order
+}
+
+// Has reports whether class was found.
+func (r Risk) Has(class RiskClass) bool {
+	for _, c := range r.Classes {
+		if c == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Badge renders the risk for display next to a command, e.g.
+// "[high: pipe-to-shell, remote-script]", or "" when none was found.
+func (r Risk) Badge() string {
+	if len(r.Classes) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("[%s: %s]", r.Level, FormatRiskClasses(r.Classes))
+}
+
+// FormatRiskClasses joins class names for messages: "pipe-to-shell, sudo".
+func FormatRiskClasses(classes []RiskClass) string {
+	names := make([]string, len(classes))
+	for i, c := range classes {
+		names[i] = string(c)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+var (
+	// "curl ... | sh", "| sudo bash -s --", "iex (irm ...)"
+	pipeToShell = regexp.MustCompile(
+		`(?i)\|\s*(?:sudo\s+(?:-\S+\s+)*)?(?:sh|bash|zsh|fish|dash|ksh|python\d*|perl|ruby|node|iex|pwsh|powershell)\b` +
+			`|\b(?:iex|invoke-expression)\b`)
+	// "sh -c "$(curl ...)"", "bash <(wget ...)"
+	shellSubstitution = regexp.MustCompile(`(?i)\b(?:sh|bash|zsh)\s+(?:-c\s+)?["']?(?:\$\(|<\()\s*(?:curl|wget)\b`)
+	remoteFetch       = regexp.MustCompile(
+		`(?i)\b(?:curl|wget|irm|iwr|invoke-webrequest|invoke-restmethod)\b[^|;&]*https?://`)
+	sudoCall       = regexp.MustCompile(`(?:^|[\s;&|(])(?:sudo|doas)\s`)
+	unpinned       = regexp.MustCompile(`@latest\b`)
+	envAssignment  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+	segmentPattern = regexp.MustCompile(`&&|\|\||[;|]`)
+)
+
+// knownPrograms are the programs install commands run that troveler
+// recognizes: package managers, downloaders and shell basics. Anything else
+// is reported as RiskUnknownBinary.
+var knownPrograms = map[string]bool{
+	"apt": true, "apt-get": true, "dnf": true, "yum": true, "zypper": true, "pacman": true, "yay": true,
+	"paru": true, "apk": true, "brew": true, "port": true, "snap": true, "flatpak": true, "scoop": true,
+	"choco": true, "winget": true, "nix-env": true, "nix": true, "pkg": true, "emerge": true, "xbps-install": true,
+	"cargo": true, "go": true, "npm": true, "pnpm": true, "yarn": true, "bun": true, "pip": true, "pip3": true,
+	"python": true, "python3": true, "pipx": true, "uv": true, "gem": true, "mise": true, "asdf": true,
+	"eget": true, "stack": true, "cabal": true, "opam": true, "conda": true, "docker": true,
+	"curl": true, "wget": true, "irm": true, "iwr": true, "git": true, "make": true, "tar": true, "unzip": true,
+	"chmod": true, "mv": true, "cp": true, "mkdir": true, "cd": true, "install": true, "echo": true,
+	"sh": true, "bash": true, "zsh": true, "fish": true, "iex": true, "pwsh": true, "powershell": true,
+	"sudo": true, "doas": true,
+}
+
+// AnalyzeCommand statically classifies the risk of running command. It only
+// looks at the text; nothing is executed or downloaded.
+func AnalyzeCommand(command string) Risk {
+	found := map[RiskClass]bool{
+		RiskPipeToShell:   pipeToShell.MatchString(command) || shellSubstitution.MatchString(command),
+		RiskRemoteScript:  remoteFetch.MatchString(command),
+		RiskSudo:          sudoCall.MatchString(command),
+		RiskUnknownBinary: hasUnknownProgram(command),
+		RiskUnpinned:      unpinned.MatchString(command),
+	}
+
+	var risk Risk
+	for _, rc := range riskClasses {
+		if found[rc.class] {
+			risk.Classes = append(risk.Classes, rc.class)
+			risk.Level = max(risk.Level, rc.level)
+		}
+	}
+
+	return risk
+}
+
+// hasUnknownProgram reports whether any segment of command runs a program
+// missing from knownPrograms.
+func hasUnknownProgram(command string) bool {
+	for _, program := range segmentPrograms(command) {
+		if !knownPrograms[program] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// segmentPrograms returns the program each segment of command runs, lower
+// cased. Leading sudo, env assignments and paths are looked through, so
+// "sudo ./install.sh" names install.sh.
+func segmentPrograms(command string) []string {
+	var programs []string
+	for _, segment := range segmentPattern.Split(command, -1) {
+		fields := strings.Fields(strings.Trim(strings.TrimSpace(segment), "()"))
+		for len(fields) > 0 && (fields[0] == "sudo" || fields[0] == "doas" || envAssignment.MatchString(fields[0])) {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "-") {
+			continue
+		}
+
+		program := fields[0]
+		if i := strings.LastIndexAny(program, `/\`); i >= 0 {
+			program = program[i+1:]
+		}
+		programs = append(programs, strings.ToLower(program))
+	}
+
+	return programs
+}
+
+// RiskDecision is what a RiskPolicy allows for a command.
+type RiskDecision int
+
+// Policy decisions, least restrictive first.
+const (
+	RiskAllow   RiskDecision = iota
+	RiskConfirm              // run only after an explicit confirmation
+	RiskBlock                // never run
+)
+
+// RiskPolicy blocks or demands confirmation for risk classes, configured by
+// block_risks and confirm_risks in [install]. The nil policy allows
+// everything.
+type RiskPolicy struct {
+	block   map[RiskClass]bool
+	confirm map[RiskClass]bool
+}
+
+// NewRiskPolicy builds a policy from class names. A level name ("low",
+// "medium", "high") stands for every class of that level or above.
+func NewRiskPolicy(block, confirm []string) (*RiskPolicy, error) {
+	blockSet, err := riskClassSet(block)
+	if err != nil {
+		return nil, fmt.Errorf("block_risks: %w", err)
+	}
+	confirmSet, err := riskClassSet(confirm)
+	if err != nil {
+		return nil, fmt.Errorf("confirm_risks: %w", err)
+	}
+
+	return &RiskPolicy{block: blockSet, confirm: confirmSet}, nil
+}
+
+func riskClassSet(names []string) (map[RiskClass]bool, error) {
+	set := make(map[RiskClass]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		matched := false
+		for _, rc := range riskClasses {
+			if string(rc.class) == name || levelAtLeast(rc.level, name) {
+				set[rc.class] = true
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unknown risk class %q (want a level or one of %s)", name,
+				FormatRiskClasses(RiskClasses()))
+		}
+	}
+
+	return set, nil
+}
+
+// levelAtLeast reports whether level is at or above the level named name.
+func levelAtLeast(level RiskLevel, name string) bool {
+	for l, n := range riskLevelNames {
+		if n == name && l != RiskNone {
+			return level >= l
+		}
+	}
+
+	return false
+}
+
+// Decide returns what the policy allows for a command with risk, and the
+// classes that caused a block or confirmation.
+func (p *RiskPolicy) Decide(risk Risk) (RiskDecision, []RiskClass) {
+	if p == nil {
+		return RiskAllow, nil
+	}
+
+	for _, set := range []struct {
+		decision RiskDecision
+		classes  map[RiskClass]bool
+	}{{RiskBlock, p.block}, {RiskConfirm, p.confirm}} {
+		var hits []RiskClass
+		for _, c := range risk.Classes {
+			if set.classes[c] {
+				hits = append(hits, c)
+			}
+		}
+		if len(hits) > 0 {
+			return set.decision, hits
+		}
+	}
+
+	return RiskAllow, nil
+}
+
+// RiskBlockedError is returned when the policy forbids running a command.
+type RiskBlockedError struct {
+	Classes []RiskClass
+}
+
+func (e *RiskBlockedError) Error() string {
+	return "blocked by the install risk policy: " + FormatRiskClasses(e.Classes)
+}
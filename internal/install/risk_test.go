@@ -0,0 +1,108 @@
+package install
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		level   RiskLevel
+		classes []RiskClass
+	}{
+		{"brew install ripgrep", RiskNone, nil},
+		{"cargo install --locked ripgrep", RiskNone, nil},
+		{"sudo apt install ripgrep", RiskMedium, []RiskClass{RiskSudo}},
+		{"sudo apt update && sudo apt install -y bat", RiskMedium, []RiskClass{RiskSudo}},
+		{"go install github.com/jesseduffield/lazygit@latest", RiskLow, []RiskClass{RiskUnpinned}},
+		{
+			"curl -fsSL https://example.com/install.sh | sh", RiskHigh,
+			[]RiskClass{RiskPipeToShell, RiskRemoteScript},
+		},
+		{
+			"curl -sS https://webi.sh/bat | sudo bash -s -- --yes", RiskHigh,
+			[]RiskClass{RiskPipeToShell, RiskRemoteScript, RiskSudo},
+		},
+		{
+			`sh -c "$(curl -fsSL https://example.com/install.sh)"`, RiskHigh,
+			[]RiskClass{RiskPipeToShell, RiskRemoteScript},
+		},
+		{
+			"iwr -useb https://get.scoop.sh | iex", RiskHigh,
+			[]RiskClass{RiskPipeToShell, RiskRemoteScript},
+		},
+		{"wget https://example.com/tool.tar.gz", RiskHigh, []RiskClass{RiskRemoteScript}},
+		{"curl -fsSL https://example.com/x.txt | shasum", RiskHigh, []RiskClass{RiskRemoteScript, RiskUnknownBinary}},
+		{"./install.sh --prefix ~/.local", RiskMedium, []RiskClass{RiskUnknownBinary}},
+		{"PREFIX=/opt sudo ./setup", RiskMedium, []RiskClass{RiskSudo, RiskUnknownBinary}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			risk := AnalyzeCommand(tt.command)
+			if risk.Level != tt.level {
+				t.Errorf("level = %s, want %s", risk.Level, tt.level)
+			}
+			if !reflect.DeepEqual(risk.Classes, tt.classes) {
+				t.Errorf("classes = %v, want %v", risk.Classes, tt.classes)
+			}
+		})
+	}
+}
+
+func TestRiskBadge(t *testing.T) {
+	if badge := AnalyzeCommand("brew install bat").Badge(); badge != "" {
+		t.Errorf("badge of a plain command = %q, want none", badge)
+	}
+
+	badge := AnalyzeCommand("curl https://example.com/i.sh | bash").Badge()
+	if want := "[high: pipe-to-shell, remote-script]"; badge != want {
+		t.Errorf("badge = %q, want %q", badge, want)
+	}
+}
+
+func TestRiskPolicy(t *testing.T) {
+	policy, err := NewRiskPolicy([]string{"pipe-to-shell"}, []string{"medium"})
+	if err != nil {
+		t.Fatalf("NewRiskPolicy: %v", err)
+	}
+
+	tests := []struct {
+		command  string
+		decision RiskDecision
+		classes  []RiskClass
+	}{
+		{"brew install bat", RiskAllow, nil},
+		{"go install example.com/x@latest", RiskAllow, nil},
+		{"sudo apt install bat", RiskConfirm, []RiskClass{RiskSudo}},
+		{"curl https://example.com/i.sh | sudo sh", RiskBlock, []RiskClass{RiskPipeToShell}},
+		// remote-script is high, so "medium" covers it
+		{"wget https://example.com/tool.tar.gz", RiskConfirm, []RiskClass{RiskRemoteScript}},
+	}
+	for _, tt := range tests {
+		decision, classes := policy.Decide(AnalyzeCommand(tt.command))
+		if decision != tt.decision || !reflect.DeepEqual(classes, tt.classes) {
+			t.Errorf("Decide(%q) = %v %v, want %v %v", tt.command, decision, classes, tt.decision, tt.classes)
+		}
+	}
+
+	var none *RiskPolicy
+	if decision, _ := none.Decide(AnalyzeCommand("curl https://x | sh")); decision != RiskAllow {
+		t.Errorf("nil policy decision = %v, want allow", decision)
+	}
+}
+
+func TestNewRiskPolicyRejectsUnknownClass(t *testing.T) {
+	if _, err := NewRiskPolicy(nil, []string{"curl"}); err == nil {
+		t.Error("expected an error for an unknown risk class")
+	}
+	if _, err := NewRiskPolicy([]string{"none"}, nil); err == nil {
+		t.Error(`expected an error for level "none"`)
+	}
+
+	err := &RiskBlockedError{Classes: []RiskClass{RiskPipeToShell, RiskSudo}}
+	if want := "blocked by the install risk policy: pipe-to-shell, sudo"; err.Error() != want {
+		t.Errorf("RiskBlockedError = %q, want %q", err.Error(), want)
+	}
+}
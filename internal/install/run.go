@@ -0,0 +1,127 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"sync"
+	"time"
+
+	"troveler/db"
+)
+
+// waitDelay bounds how long a canceled command is waited for when a process
+// it started keeps its output open.
+const waitDelay = 5 * time.Second
+
+// OutputTailSize is how many trailing bytes of an install command's output
+// are kept for the install history.
+const OutputTailSize = 4096
+
+// Execution is the outcome of running an install command.
+type Execution struct {
+	Command  string
+	ExitCode int // -1 when the command could not be started
+	Duration time.Duration
+	Output   string // last OutputTailSize bytes of combined stdout and stderr
+	Err      error
+}
+
+// Run executes command through sh, copying its stdout and stderr to the given
+// writers (either may be nil) while keeping the tail of both for the history.
+func Run(ctx context.Context, command string, stdout, stderr io.Writer) *Execution {
+	tail := &tailBuffer{max: OutputTailSize}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // G204: user-requested install command
+	cmd.Stdout = teeTo(tail, stdout)
+	cmd.Stderr = teeTo(tail, stderr)
+	cmd.WaitDelay = waitDelay
+
+	start := time.Now()
+	err := cmd.Run()
+
+	return &Execution{
+		Command:  command,
+		ExitCode: exitCode(err),
+		Duration: time.Since(start),
+		Output:   tail.String(),
+		Err:      err,
+	}
+}
+
+// Record converts the execution into an install history entry.
+func (e *Execution) Record(slug, platformID string) *db.InstallRecord {
+	return &db.InstallRecord{
+		Slug:     slug,
+		Platform: platformID,
+		Command:  e.Command,
+		ExitCode: e.ExitCode,
+		Duration: e.Duration,
+		Output:   e.Output,
+		User:     currentUser(),
+	}
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
+
+// currentUser names the account running troveler, preferring the invoking
+// user when troveler itself runs under sudo.
+func currentUser() string {
+	if name := os.Getenv("SUDO_USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	return os.Getenv("USER")
+}
+
+func teeTo(tail *tailBuffer, w io.Writer) io.Writer {
+	if w == nil {
+		return tail
+	}
+
+	return io.MultiWriter(w, tail)
+}
+
+// tailBuffer keeps the last max bytes written to it. Stdout and stderr are
+// copied from separate goroutines, so writes are serialized.
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return string(t.buf)
+}
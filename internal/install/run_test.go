@@ -0,0 +1,41 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var stdout bytes.Buffer
+	result := Run(context.Background(), "echo out; echo err >&2; exit 3", &stdout, nil)
+
+	if result.ExitCode != 3 || result.Err == nil {
+		t.Errorf("expected exit code 3 with error, got %d (%v)", result.ExitCode, result.Err)
+	}
+	if stdout.String() != "out\n" {
+		t.Errorf("stdout not forwarded: %q", stdout.String())
+	}
+	if !strings.Contains(result.Output, "out\n") || !strings.Contains(result.Output, "err\n") {
+		t.Errorf("output tail should hold stdout and stderr, got %q", result.Output)
+	}
+
+	rec := result.Record("ripgrep", "brew")
+	if rec.Slug != "ripgrep" || rec.Platform != "brew" || rec.ExitCode != 3 || rec.Command != result.Command {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	tail := &tailBuffer{max: 5}
+	for _, s := range []string{"abc", "defg", "h"} {
+		if _, err := tail.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := tail.String(); got != "defgh" {
+		t.Errorf("expected last 5 bytes %q, got %q", "defgh", got)
+	}
+}
@@ -0,0 +1,110 @@
+package install
+
+import "sort"
+
+// managerLocks maps package managers that take a global lock (on the dpkg or
+// rpm database, the Homebrew prefix, mise's global config, ...) to the lock
+// they hold. Two commands needing the same lock never run at once; managers
+// missing here (cargo, go, npm, pipx, ...) install side by side.
+var managerLocks = map[string]string{
+	"apt": "dpkg", "apt-get": "dpkg", "aptitude": "dpkg", "dpkg": "dpkg",
+	"dnf": "rpm", "yum": "rpm", "rpm": "rpm", "zypper": "rpm",
+	"pacman": "pacman", "yay": "pacman", "paru": "pacman",
+	"brew": "brew", "port": "port",
+	"apk": "apk", "emerge": "portage", "xbps-install": "xbps", "pkg": "pkg",
+	"snap": "snap", "flatpak": "flatpak", "nix-env": "nix",
+	"scoop": "scoop", "choco": "choco", "winget": "winget",
+	"mise": "mise", "asdf": "asdf",
+}
+
+// ManagerLocks returns the global locks command needs while it runs, sorted;
+// none for commands that can run in parallel with anything.
+func ManagerLocks(command string) []string {
+	seen := make(map[string]bool)
+	var locks []string
+	for _, program := range segmentPrograms(command) {
+		if lock, ok := managerLocks[program]; ok && !seen[lock] {
+			seen[lock] = true
+			locks = append(locks, lock)
+		}
+	}
+	sort.Strings(locks)
+
+	return locks
+}
+
+// Scheduler decides when the jobs of a batch install may start: at most
+// limit at once, and never two holding the same manager lock. Jobs start in
+// the order they were added unless a lock holds one back, in which case
+// later independent jobs go first.
+//
+// A Scheduler is not safe for concurrent use; callers report finished jobs
+// to it from a single goroutine.
+type Scheduler struct {
+	limit   int
+	locks   [][]string
+	pending []int
+	held    map[string]bool
+	running int
+}
+
+// NewScheduler creates a scheduler running up to limit jobs at once (at
+// least one).
+func NewScheduler(limit int) *Scheduler {
+	return &Scheduler{limit: max(limit, 1), held: make(map[string]bool)}
+}
+
+// Add queues a job needing locks and returns its index, counting from 0.
+func (s *Scheduler) Add(locks []string) int {
+	s.locks = append(s.locks, locks)
+	s.pending = append(s.pending, len(s.locks)-1)
+
+	return len(s.locks) - 1
+}
+
+// Next marks every job that may start now as running and returns them.
+func (s *Scheduler) Next() []int {
+	var started []int
+	remaining := s.pending[:0]
+	for _, job := range s.pending {
+		if s.running < s.limit && s.free(job) {
+			for _, lock := range s.locks[job] {
+				s.held[lock] = true
+			}
+			s.running++
+			started = append(started, job)
+
+			continue
+		}
+		remaining = append(remaining, job)
+	}
+	s.pending = remaining
+
+	return started
+}
+
+// Done releases the locks of a finished job.
+func (s *Scheduler) Done(job int) {
+	if job < 0 || job >= len(s.locks) {
+		return
+	}
+	for _, lock := range s.locks[job] {
+		delete(s.held, lock)
+	}
+	s.running--
+}
+
+// Idle reports whether no job is running or waiting.
+func (s *Scheduler) Idle() bool {
+	return s.running == 0 && len(s.pending) == 0
+}
+
+func (s *Scheduler) free(job int) bool {
+	for _, lock := range s.locks[job] {
+		if s.held[lock] {
+			return false
+		}
+	}
+
+	return true
+}
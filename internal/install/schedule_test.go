@@ -0,0 +1,80 @@
+package install
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestManagerLocks(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"cargo install ripgrep", nil},
+		{"go install github.com/jesseduffield/lazygit@latest", nil},
+		{"sudo apt install ripgrep", []string{"dpkg"}},
+		{"sudo apt update && sudo apt-get install -y bat", []string{"dpkg"}},
+		{"brew install fd", []string{"brew"}},
+		{"sudo dnf install bat", []string{"rpm"}},
+		{"mise use --global cargo:ripgrep", []string{"mise"}},
+		{"brew install mise && mise use -g node", []string{"brew", "mise"}},
+		{"curl -fsSL https://example.com/i.sh | sh", nil},
+	}
+
+	for _, tt := range tests {
+		if got := ManagerLocks(tt.command); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ManagerLocks(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	s := NewScheduler(3)
+	apt1 := s.Add([]string{"dpkg"})
+	apt2 := s.Add([]string{"dpkg"})
+	cargo := s.Add(nil)
+	goInstall := s.Add(nil)
+	brew := s.Add([]string{"brew"})
+
+	// apt2 waits for the dpkg lock; the limit holds back brew.
+	if got := s.Next(); !reflect.DeepEqual(got, []int{apt1, cargo, goInstall}) {
+		t.Fatalf("first wave = %v", got)
+	}
+	if got := s.Next(); got != nil {
+		t.Fatalf("nothing may start while the limit is reached, got %v", got)
+	}
+
+	s.Done(cargo)
+	if got := s.Next(); !reflect.DeepEqual(got, []int{brew}) {
+		t.Fatalf("after cargo = %v, want brew", got)
+	}
+
+	s.Done(apt1)
+	s.Done(goInstall)
+	if got := s.Next(); !reflect.DeepEqual(got, []int{apt2}) {
+		t.Fatalf("after apt1 = %v, want apt2", got)
+	}
+	if s.Idle() {
+		t.Fatal("scheduler idle while jobs run")
+	}
+
+	s.Done(apt2)
+	s.Done(brew)
+	if !s.Idle() {
+		t.Error("scheduler not idle after every job finished")
+	}
+}
+
+func TestSchedulerLimitOne(t *testing.T) {
+	s := NewScheduler(0)
+	for range 3 {
+		s.Add(nil)
+	}
+	for want := range 3 {
+		got := s.Next()
+		if !reflect.DeepEqual(got, []int{want}) {
+			t.Fatalf("Next() = %v, want [%d]", got, want)
+		}
+		s.Done(want)
+	}
+}
@@ -0,0 +1,122 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// SudoPrompt is the password prompt sudo shows for commands run by
+// RunTerminal. A distinct prompt tells sudo asking for the password apart
+// from anything else the command asks; the password is only typed when the
+// prompt shows with echo turned off, as sudo does, so it never shows up in
+// the output.
+const SudoPrompt = "[troveler] sudo password: "
+
+// errNoPTY reports that this platform has no pseudo-terminal support.
+var errNoPTY = errors.New("pseudo-terminals are not supported on this platform")
+
+// noTerminalNotice tells the user RunTerminal fell back to Run, where
+// replies and the sudo password cannot reach the command.
+const noTerminalNotice = "[troveler] %v; running without a terminal, so prompts cannot be answered\n"
+
+// TerminalOptions configure running an install command in a pseudo-terminal.
+type TerminalOptions struct {
+	Input    io.Reader // keystrokes for the command; nil sends none
+	Password string    // typed once when sudo asks for the password
+	Cols     uint16    // terminal width, 80 when zero
+	Rows     uint16    // terminal height, 24 when zero
+}
+
+// RunTerminal executes command through sh inside a pseudo-terminal, so
+// prompts, progress bars and sudo's password prompt behave as they do in a
+// terminal. Everything the terminal shows goes to out. Canceling ctx stops
+// the command and everything it started. Where no pseudo-terminal can be
+// opened it says so in out and falls back to Run, without input.
+func RunTerminal(ctx context.Context, command string, out io.Writer, opts TerminalOptions) *Execution {
+	cols, rows := opts.Cols, opts.Rows
+	if cols == 0 {
+		cols = 80
+	}
+	if rows == 0 {
+		rows = 24
+	}
+	ptmx, tty, err := openPTY(cols, rows)
+	if err != nil {
+		if out != nil {
+			_, _ = fmt.Fprintf(out, noTerminalNotice, err)
+		}
+
+		return Run(ctx, command, out, out)
+	}
+	defer func() { _ = ptmx.Close() }()
+
+	tail := &tailBuffer{max: OutputTailSize}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command) //nolint:gosec // G204: user-requested install command
+	cmd.Env = append(os.Environ(), "SUDO_PROMPT="+SudoPrompt, "TERM=dumb")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	cmd.WaitDelay = waitDelay
+	runInSession(cmd)
+
+	start := time.Now()
+	err = cmd.Start()
+	_ = tty.Close()
+	if err != nil {
+		return &Execution{Command: command, ExitCode: -1, Err: err}
+	}
+
+	if opts.Input != nil {
+		go func() { _, _ = io.Copy(ptmx, opts.Input) }()
+	}
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		w := &sudoAnswerer{w: teeTo(tail, out), ptmx: ptmx, password: opts.Password}
+		_, _ = io.Copy(w, ptmx)
+	}()
+
+	err = cmd.Wait()
+	// The output ends once every process holding the terminal exited; one
+	// left in the background must not keep the install running.
+	select {
+	case <-read:
+	case <-time.After(waitDelay):
+	}
+
+	return &Execution{
+		Command:  command,
+		ExitCode: exitCode(err),
+		Duration: time.Since(start),
+		Output:   tail.String(),
+		Err:      err,
+	}
+}
+
+// sudoAnswerer passes terminal output on to w, typing the password into the
+// terminal the first time sudo asks for it. Should sudo ask again, the
+// password was wrong and the prompt is left to the user.
+type sudoAnswerer struct {
+	w        io.Writer
+	ptmx     *os.File
+	password string
+	recent   []byte // end of the output, to find a prompt split across reads
+}
+
+func (s *sudoAnswerer) Write(p []byte) (int, error) {
+	if s.password != "" {
+		s.recent = append(s.recent, p...)
+		if bytes.Contains(s.recent, []byte(SudoPrompt)) && !echoing(s.ptmx) {
+			_, _ = io.WriteString(s.ptmx, s.password+"\n")
+			s.password, s.recent = "", nil
+		} else if keep := len(SudoPrompt) - 1; len(s.recent) > keep {
+			s.recent = s.recent[len(s.recent)-keep:]
+		}
+	}
+
+	return s.w.Write(p)
+}
@@ -0,0 +1,93 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunTerminal(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("pseudo-terminals are only supported on linux")
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		opts    TerminalOptions
+		want    []string
+	}{
+		{
+			name:    "runs in a terminal of the given size",
+			command: `[ -t 0 ] && [ -t 1 ] && echo "tty $(stty size)"`,
+			opts:    TerminalOptions{Cols: 100, Rows: 30},
+			want:    []string{"tty 30 100"},
+		},
+		{
+			name:    "forwards input",
+			command: `printf 'Continue? '; read -r answer; echo "answer=$answer"`,
+			opts:    TerminalOptions{Input: strings.NewReader("yes\n")},
+			want:    []string{"Continue?", "answer=yes"},
+		},
+		{
+			name: "types the password at sudo's prompt only",
+			command: `printf 'Name: '; read -r name; stty -echo; printf '%s' "$SUDO_PROMPT"; read -r pw; stty echo; ` +
+				`echo "name=$name pw=$pw"`,
+			opts: TerminalOptions{Input: strings.NewReader("me\n"), Password: "hunter2"},
+			want: []string{SudoPrompt, "name=me pw=hunter2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			result := RunTerminal(context.Background(), tt.command, &out, tt.opts)
+			if result.Err != nil {
+				t.Fatalf("RunTerminal failed: %v\n%s", result.Err, out.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) || !strings.Contains(result.Output, want) {
+					t.Errorf("expected %q in the output, got %q", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunTerminalEchoedPrompt(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("pseudo-terminals are only supported on linux")
+	}
+
+	// A command printing sudo's prompt without turning echo off is not sudo.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var out bytes.Buffer
+	result := RunTerminal(ctx, `printf '%s' "$SUDO_PROMPT"; read -r pw; echo "pw=$pw"`, &out,
+		TerminalOptions{Password: "hunter2"})
+	if result.Err == nil || strings.Contains(out.String(), "hunter2") {
+		t.Errorf("expected the password not to be typed, got %q (%v)", out.String(), result.Err)
+	}
+}
+
+func TestRunTerminalCancel(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("pseudo-terminals are only supported on linux")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	// sleep runs as a child of sh, which alone would not stop it.
+	result := RunTerminal(ctx, "sleep 30; echo done", &bytes.Buffer{}, TerminalOptions{})
+	if result.Err == nil || strings.Contains(result.Output, "done") {
+		t.Errorf("expected the command to be stopped, got %q (%v)", result.Output, result.Err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected the canceled command to end at once, took %v", elapsed)
+	}
+}
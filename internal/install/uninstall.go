@@ -0,0 +1,80 @@
+package install
+
+import (
+	"fmt"
+	"strings"
+
+	"troveler/db"
+	"troveler/internal/platform"
+)
+
+// UninstallPlan describes how to remove an installed tool. PlanUpgrade reuses
+// it with Command holding the upgrade command.
+type UninstallPlan struct {
+	InstallCommand string // the command the tool was (or is assumed to be) installed with
+	Platform       string
+	FromHistory    bool   // InstallCommand comes from the install history, not the catalog
+	Command        string // the uninstall (or upgrade) command, without sudo
+	Sudo           bool   // the install ran under sudo, so the removal likely needs it too
+}
+
+// PlanUninstall derives the uninstall command for slug from the last
+// successful install recorded in the history. When nothing was recorded it
+// assumes the tool came from fallback, the instruction an install would pick
+// on this machine (nil if there is none).
+func PlanUninstall(database *db.SQLiteDB, slug string, fallback *db.InstallInstruction) (*UninstallPlan, error) {
+	plan, err := installSource(database, slug, fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Command, err = UninstallCommand(plan.InstallCommand)
+	if err != nil {
+		return plan, err
+	}
+
+	return plan, nil
+}
+
+// PlanUpgrade derives the upgrade command for slug the same way PlanUninstall
+// derives the uninstall command.
+func PlanUpgrade(database *db.SQLiteDB, slug string, fallback *db.InstallInstruction) (*UninstallPlan, error) {
+	plan, err := installSource(database, slug, fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Command, err = platform.UpgradeCommand(plan.InstallCommand)
+	if err != nil {
+		return plan, err
+	}
+
+	return plan, nil
+}
+
+// installSource fills in the command slug was installed with: the last
+// successful install in the history, else fallback.
+func installSource(database *db.SQLiteDB, slug string, fallback *db.InstallInstruction) (*UninstallPlan, error) {
+	plan := &UninstallPlan{}
+
+	rec, err := database.LastSuccessfulInstall(slug)
+	if err != nil {
+		return nil, fmt.Errorf("read install history: %w", err)
+	}
+
+	switch {
+	case rec != nil:
+		plan.InstallCommand = rec.Command
+		plan.Platform = rec.Platform
+		plan.FromHistory = true
+	case fallback != nil:
+		plan.InstallCommand = fallback.Command
+		plan.Platform = fallback.Platform
+	default:
+		return nil, fmt.Errorf("no recorded install and no install method for this platform")
+	}
+
+	plan.Sudo = strings.HasPrefix(strings.TrimSpace(plan.InstallCommand), "sudo ")
+
+	return plan, nil
+}
@@ -0,0 +1,152 @@
+// Package manifest reads troveler.toml, the declarative list of tools a
+// machine should have installed.
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+
+	"troveler/db"
+)
+
+// DefaultPath is the manifest file sync reads when no path is given.
+const DefaultPath = "troveler.toml"
+
+// Install methods.
+const (
+	MethodAuto = "auto" // run the resolved install command as is
+	MethodMise = "mise" // rewrite the resolved command to "mise use"
+)
+
+// Manifest is the parsed troveler.toml:
+//
+//	[[tools]]
+//	slug = "ripgrep"
+//
+//	[[tools]]
+//	slug = "fd"
+//	platform = "brew"   # pin the install platform, like install --override
+//
+//	[[groups]]
+//	tag = "essentials"  # every tool carrying this tag
+//	method = "mise"     # install through mise
+type Manifest struct {
+	Tools  []Tool  `toml:"tools"`
+	Groups []Group `toml:"groups"`
+}
+
+// Tool is a single tool entry.
+type Tool struct {
+	Slug     string `toml:"slug"`
+	Platform string `toml:"platform"`
+	Method   string `toml:"method"`
+}
+
+// Group selects every tool carrying a user tag (see "troveler tag").
+type Group struct {
+	Tag      string `toml:"tag"`
+	Platform string `toml:"platform"`
+	Method   string `toml:"method"`
+}
+
+// Entry is a tool the manifest asks for, with its pins resolved.
+type Entry struct {
+	Slug     string
+	Platform string // "" lets config and OS detection decide
+	Method   string // MethodAuto or MethodMise
+	Group    string // tag of the group the entry came from, "" for [[tools]]
+}
+
+// Load reads and validates the manifest at path.
+func Load(path string) (*Manifest, error) {
+	var m Manifest
+	if _, err := toml.DecodeFile(path, &m); err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", path, err)
+	}
+
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("manifest %s: %w", path, err)
+	}
+
+	return &m, nil
+}
+
+func (m *Manifest) validate() error {
+	seen := make(map[string]bool, len(m.Tools))
+	for i, t := range m.Tools {
+		if t.Slug == "" {
+			return fmt.Errorf("tool #%d has no slug", i+1)
+		}
+		if seen[t.Slug] {
+			return fmt.Errorf("duplicate tool %q", t.Slug)
+		}
+		seen[t.Slug] = true
+		if err := validateMethod(t.Method); err != nil {
+			return fmt.Errorf("tool %q: %w", t.Slug, err)
+		}
+	}
+
+	for i, g := range m.Groups {
+		if g.Tag == "" {
+			return fmt.Errorf("group #%d has no tag", i+1)
+		}
+		if err := validateMethod(g.Method); err != nil {
+			return fmt.Errorf("group %q: %w", g.Tag, err)
+		}
+	}
+
+	return nil
+}
+
+func validateMethod(method string) error {
+	switch method {
+	case "", MethodAuto, MethodMise:
+		return nil
+	}
+
+	return fmt.Errorf("unknown method %q (use %s or %s)", method, MethodAuto, MethodMise)
+}
+
+// Entries expands the manifest into one entry per tool, in manifest order:
+// [[tools]] first, then each group's tagged tools by name. A tool listed
+// explicitly keeps its own pins even if a group also selects it.
+func (m *Manifest) Entries(database *db.SQLiteDB) ([]Entry, error) {
+	var entries []Entry
+	seen := make(map[string]bool)
+
+	for _, t := range m.Tools {
+		entries = append(entries, Entry{Slug: t.Slug, Platform: t.Platform, Method: methodOrAuto(t.Method)})
+		seen[t.Slug] = true
+	}
+
+	for _, g := range m.Groups {
+		tools, err := database.GetToolsByTag(g.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("group %q: %w", g.Tag, err)
+		}
+
+		for _, tool := range tools {
+			if seen[tool.Slug] {
+				continue
+			}
+			seen[tool.Slug] = true
+			entries = append(entries, Entry{
+				Slug:     tool.Slug,
+				Platform: g.Platform,
+				Method:   methodOrAuto(g.Method),
+				Group:    g.Tag,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+func methodOrAuto(method string) string {
+	if method == "" {
+		return MethodAuto
+	}
+
+	return method
+}
@@ -0,0 +1,98 @@
+package manifest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"troveler/db"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadAndEntries(t *testing.T) {
+	path := writeManifest(t, `
+[[tools]]
+slug = "ripgrep"
+
+[[tools]]
+slug = "fd"
+platform = "brew"
+
+[[groups]]
+tag = "Essentials"
+method = "mise"
+`)
+
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("db.New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	for _, slug := range []string{"ripgrep", "fd", "bat", "zoxide"} {
+		tool := &db.Tool{ID: "tool-" + slug, Slug: slug, Name: slug}
+		if err := database.UpsertTool(context.Background(), tool); err != nil {
+			t.Fatalf("UpsertTool failed: %v", err)
+		}
+	}
+	for _, slug := range []string{"fd", "zoxide", "bat"} {
+		if err := database.AddTag(slug, "essentials"); err != nil {
+			t.Fatalf("AddTag failed: %v", err)
+		}
+	}
+
+	entries, err := m.Entries(database)
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+
+	want := []Entry{
+		{Slug: "ripgrep", Method: MethodAuto},
+		{Slug: "fd", Platform: "brew", Method: MethodAuto},
+		{Slug: "bat", Method: MethodMise, Group: "Essentials"},
+		{Slug: "zoxide", Method: MethodMise, Group: "Essentials"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing slug":   "[[tools]]\nplatform = \"brew\"\n",
+		"duplicate slug": "[[tools]]\nslug = \"fd\"\n[[tools]]\nslug = \"fd\"\n",
+		"bad method":     "[[tools]]\nslug = \"fd\"\nmethod = \"curl\"\n",
+		"group no tag":   "[[groups]]\nmethod = \"mise\"\n",
+		"not toml":       "[[tools\n",
+	}
+
+	for name, content := range tests {
+		if _, err := Load(writeManifest(t, content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.toml")); err == nil {
+		t.Error("expected error for missing manifest")
+	}
+}
@@ -0,0 +1,208 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Package manager families recognized in install commands.
+const (
+	ManagerApt    = "apt"
+	ManagerDnf    = "dnf"
+	ManagerZypper = "zypper"
+	ManagerPacman = "pacman"
+	ManagerApk    = "apk"
+	ManagerEmerge = "emerge"
+	ManagerPkgin  = "pkgin"
+	ManagerBrew   = "brew"
+	ManagerPort   = "port"
+	ManagerSnap   = "snap"
+	ManagerScoop  = "scoop"
+	ManagerChoco  = "choco"
+	ManagerWinget = "winget"
+	ManagerNix    = "nix"
+	ManagerCargo  = "cargo"
+	ManagerGo     = "go"
+	ManagerNpm    = "npm"
+	ManagerPip    = "pip"
+	ManagerPipx   = "pipx"
+	ManagerUv     = "uv"
+	ManagerGem    = "gem"
+	ManagerMise   = "mise"
+	ManagerEget   = "eget"
+	ManagerScript = "script" // curl/wget install scripts
+)
+
+// InstallMethod describes how an install command installs a tool.
+type InstallMethod struct {
+	Manager string // one of the Manager* families, "" when unrecognized
+	Tool    string // the program invoked, e.g. "apt-get" or "python3 -m pip"
+	Verb    string // the install verb, e.g. "install", "use" or "-S"; "" for none
+	Package string // the package, crate, module or spec installed
+	Args    string // the arguments after the install verb
+}
+
+type methodPattern struct {
+	manager string
+	pattern *regexp.Regexp // group 1: tool, group 2: arguments
+	pkg     func(args string) string
+}
+
+// methodPatterns match commands with any leading sudo stripped.
+var methodPatterns = []methodPattern{
+	{ManagerApt, regexp.MustCompile(`(?i)^(apt|apt-get)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerDnf, regexp.MustCompile(`(?i)^(dnf|yum)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerZypper, regexp.MustCompile(`(?i)^(zypper)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerPacman, regexp.MustCompile(`(?i)^(pacman|yay|paru)\s+-S\w*\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerApk, regexp.MustCompile(`(?i)^(apk)\s+add\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerEmerge, regexp.MustCompile(`(?i)^(emerge)\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerPkgin, regexp.MustCompile(`(?i)^(pkgin)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerBrew, regexp.MustCompile(`(?i)^(brew|linuxbrew)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerPort, regexp.MustCompile(`(?i)^(port)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerSnap, regexp.MustCompile(`(?i)^(snap)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerScoop, regexp.MustCompile(`(?i)^(scoop)\s+install\s+(.+)$`), firstPackage(lastSegment)},
+	{ManagerChoco, regexp.MustCompile(`(?i)^(choco)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerWinget, regexp.MustCompile(`(?i)^(winget)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerNix, regexp.MustCompile(`(?i)^(nix-env)\s+-iA?\s+(.+)$`), firstPackage(nixAttrName)},
+	{ManagerNix, regexp.MustCompile(`(?i)^(nix\s+profile)\s+install\s+(.+)$`), firstPackage(nixAttrName)},
+	{ManagerCargo, regexp.MustCompile(`(?i)^(cargo)\s+(?:install|binstall)\s+(.+)$`), cargoCrate},
+	{ManagerGo, regexp.MustCompile(`(?i)^(go)\s+install\s+(\S+)$`), firstPackage(cleanPackage)},
+	{ManagerNpm, regexp.MustCompile(`(?i)^(npm)\s+(?:install|i)\s+(.+)$`), firstPackage(npmPackage)},
+	{ManagerNpm, regexp.MustCompile(`(?i)^(pnpm)\s+(?:add|install|i)\s+(.+)$`), firstPackage(npmPackage)},
+	{ManagerNpm, regexp.MustCompile(`(?i)^(yarn\s+global)\s+add\s+(.+)$`), firstPackage(npmPackage)},
+	{ManagerPip, regexp.MustCompile(`(?i)^((?:python\d*\s+-m\s+)?pip3?)\s+install\s+(.+)$`), firstPackage(pipPackage)},
+	{ManagerPipx, regexp.MustCompile(`(?i)^(pipx)\s+install\s+(.+)$`), firstPackage(pipPackage)},
+	{ManagerUv, regexp.MustCompile(`(?i)^(uv\s+tool)\s+install\s+(.+)$`), lastPackage(pipPackage)},
+	{ManagerGem, regexp.MustCompile(`(?i)^(gem)\s+install\s+(.+)$`), firstPackage(identityPkg)},
+	{ManagerMise, regexp.MustCompile(`(?i)^(mise)\s+(?:use|install)\s+(.+)$`), firstPackage(npmPackage)},
+	{ManagerEget, regexp.MustCompile(`(?i)^(eget)\s+(.+)$`), lastPackage(identityPkg)},
+	{ManagerScript, regexp.MustCompile(`(?i)^(curl|wget)\s+(.+)$`), func(string) string { return "" }},
+}
+
+// ParseInstall identifies the package manager and package of an install
+// command. Manager is empty when the command is not recognized. UninstallCommand
+// and UpgradeCommand build on it, so methodPatterns is the one table of
+// package managers.
+func ParseInstall(command string) InstallMethod {
+	command = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(command), "sudo "))
+
+	for _, mp := range methodPatterns {
+		m := mp.pattern.FindStringSubmatchIndex(command)
+		if m == nil {
+			continue
+		}
+		tool, args := command[m[2]:m[3]], command[m[4]:m[5]]
+
+		return InstallMethod{
+			Manager: mp.manager,
+			Tool:    tool,
+			Verb:    strings.TrimSpace(command[m[3]:m[4]]),
+			Package: mp.pkg(args),
+			Args:    args,
+		}
+	}
+
+	return InstallMethod{}
+}
+
+// ErrNoUpgrade is returned by UpgradeCommand for install methods without a
+// known upgrade path.
+var ErrNoUpgrade = errors.New("no known upgrade command")
+
+// UpgradeCommand returns the command that upgrades what installCommand
+// installed to the newest version its package manager offers. Like
+// UninstallCommand it drops a leading sudo. Commands chaining several
+// commands have no upgrade, except install scripts, which are run again.
+func UpgradeCommand(installCommand string) (string, error) {
+	method := ParseInstall(installCommand)
+	upgrade, ok := upgraders[method.Manager]
+	if !ok || (method.Manager != ManagerScript && isCompound(installCommand)) {
+		return "", fmt.Errorf("%w for %q", ErrNoUpgrade, installCommand)
+	}
+
+	command := upgrade(method)
+	if command == "" {
+		return "", fmt.Errorf("%w for %q", ErrNoUpgrade, installCommand)
+	}
+
+	return command, nil
+}
+
+// upgraders build the upgrade command for each package manager family.
+var upgraders = map[string]func(m InstallMethod) string{
+	ManagerApt:    withPackage("%s install --only-upgrade %s"),
+	ManagerDnf:    withPackage("%s upgrade %s"),
+	ManagerZypper: withPackage("%s update %s"),
+	ManagerPacman: withPackage("%s -S %s"),
+	ManagerApk:    withPackage("%s upgrade %s"),
+	ManagerBrew:   withPackage("%s upgrade %s"),
+	ManagerPort:   withPackage("%s upgrade %s"),
+	ManagerSnap:   withPackage("%s refresh %s"),
+	ManagerScoop:  withPackage("%s update %s"),
+	ManagerChoco:  withPackage("%s upgrade %s"),
+	ManagerWinget: withPackage("%s upgrade %s"),
+	ManagerNix: func(m InstallMethod) string {
+		if m.Tool == "nix-env" {
+			return withPackage("%s -u %s")(m)
+		}
+
+		return withPackage("%s upgrade %s")(m)
+	},
+	ManagerGo: withPackage("%s install %s@latest"),
+	ManagerNpm: func(m InstallMethod) string {
+		switch {
+		case strings.HasPrefix(m.Tool, "yarn"):
+			return withPackage("%s upgrade %s")(m)
+		case m.Tool == "pnpm":
+			return withPackage("%s add -g %s@latest")(m)
+		}
+
+		return withPackage("%s install -g %s@latest")(m)
+	},
+	ManagerPip:  withPackage("%s install --upgrade %s"),
+	ManagerPipx: withPackage("%s upgrade %s"),
+	ManagerUv:   withPackage("%s upgrade %s"),
+	ManagerGem:  withPackage("%s update %s"),
+	ManagerMise: withPackage("%s use --global %s@latest"),
+	// cargo install replaces an older version of the crate in place.
+	ManagerCargo: func(m InstallMethod) string { return m.Tool + " install " + m.Args },
+	ManagerEget:  func(m InstallMethod) string { return m.Tool + " --upgrade-only " + m.Args },
+	// Install scripts fetch the latest release; running one again upgrades.
+	ManagerScript: func(m InstallMethod) string { return m.Tool + " " + m.Args },
+}
+
+// withPackage formats an upgrade command from the tool and package, or
+// returns "" when the install command named no package.
+func withPackage(format string) func(m InstallMethod) string {
+	return func(m InstallMethod) string {
+		if m.Package == "" {
+			return ""
+		}
+
+		return fmt.Sprintf(format, m.Tool, m.Package)
+	}
+}
+
+// firstPackage returns the first non-flag argument mapped through name.
+func firstPackage(name func(string) string) func(string) string {
+	return func(args string) string {
+		if pkgs := packageArgs(args, name); len(pkgs) > 0 {
+			return pkgs[0]
+		}
+
+		return ""
+	}
+}
+
+// lastPackage returns the last non-flag argument mapped through name.
+func lastPackage(name func(string) string) func(string) string {
+	return func(args string) string {
+		if pkgs := packageArgs(args, name); len(pkgs) > 0 {
+			return pkgs[len(pkgs)-1]
+		}
+
+		return ""
+	}
+}
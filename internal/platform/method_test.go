@@ -0,0 +1,105 @@
+package platform
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseInstall(t *testing.T) {
+	tests := []struct {
+		install string
+		manager string
+		pkg     string
+	}{
+		{"sudo apt install -y fd-find", ManagerApt, "fd-find"},
+		{"brew install --cask wezterm", ManagerBrew, "wezterm"},
+		{"cargo install --locked ripgrep", ManagerCargo, "ripgrep"},
+		{"go install github.com/junegunn/fzf@latest", ManagerGo, "github.com/junegunn/fzf"},
+		{"npm install -g @ast-grep/cli@0.20", ManagerNpm, "@ast-grep/cli"},
+		{"python3 -m pip install httpie", ManagerPip, "httpie"},
+		{"uv tool install --with pip ruff", ManagerUv, "ruff"},
+		{"mise use --global cargo:ripgrep@14", ManagerMise, "cargo:ripgrep"},
+		{"curl -sSfL https://example.com/install.sh | sh", ManagerScript, ""},
+		{"make install", "", ""},
+	}
+
+	for _, tt := range tests {
+		got := ParseInstall(tt.install)
+		if got.Manager != tt.manager || got.Package != tt.pkg {
+			t.Errorf("ParseInstall(%q) = %q/%q, want %q/%q", tt.install, got.Manager, got.Package, tt.manager, tt.pkg)
+		}
+	}
+}
+
+func TestParseInstallVerb(t *testing.T) {
+	tests := []struct {
+		install, tool, verb, args string
+	}{
+		{"sudo apt-get install -y fd-find", "apt-get", "install", "-y fd-find"},
+		{"pacman -Syu --needed fzf", "pacman", "-Syu", "--needed fzf"},
+		{"nix-env -iA nixpkgs.ripgrep", "nix-env", "-iA", "nixpkgs.ripgrep"},
+		{"mise use -g node", "mise", "use", "-g node"},
+		{"eget zyedidia/micro", "eget", "", "zyedidia/micro"},
+	}
+
+	for _, tt := range tests {
+		got := ParseInstall(tt.install)
+		if got.Tool != tt.tool || got.Verb != tt.verb || got.Args != tt.args {
+			t.Errorf("ParseInstall(%q) = %q %q %q, want %q %q %q",
+				tt.install, got.Tool, got.Verb, got.Args, tt.tool, tt.verb, tt.args)
+		}
+	}
+}
+
+func TestUpgradeCommand(t *testing.T) {
+	tests := []struct {
+		install string
+		want    string
+	}{
+		{"sudo apt install -y fd-find", "apt install --only-upgrade fd-find"},
+		{"sudo dnf install bat", "dnf upgrade bat"},
+		{"zypper install jq", "zypper update jq"},
+		{"pacman -S --needed fzf", "pacman -S fzf"},
+		{"apk add jq", "apk upgrade jq"},
+		{"brew install --cask wezterm", "brew upgrade wezterm"},
+		{"snap install --classic nvim", "snap refresh nvim"},
+		{"scoop install extras/lazygit", "scoop update lazygit"},
+		{"nix-env -iA nixpkgs.ripgrep", "nix-env -u ripgrep"},
+		{"nix profile install nixpkgs#bat", "nix profile upgrade bat"},
+		{"cargo install --locked ripgrep", "cargo install --locked ripgrep"},
+		{"go install github.com/junegunn/fzf@v0.50.0", "go install github.com/junegunn/fzf@latest"},
+		{"npm install -g @ast-grep/cli@0.20", "npm install -g @ast-grep/cli@latest"},
+		{"pnpm add -g tldr", "pnpm add -g tldr@latest"},
+		{"yarn global add prettier", "yarn global upgrade prettier"},
+		{"pip3 install --user black[d]>=23", "pip3 install --upgrade black"},
+		{"pipx install poetry", "pipx upgrade poetry"},
+		{"uv tool install ruff", "uv tool upgrade ruff"},
+		{"gem install tmuxinator", "gem update tmuxinator"},
+		{"mise use --global cargo:ripgrep@14", "mise use --global cargo:ripgrep@latest"},
+		{"eget zyedidia/micro", "eget --upgrade-only zyedidia/micro"},
+		{"curl -sSfL https://example.com/install.sh | sh", "curl -sSfL https://example.com/install.sh | sh"},
+	}
+
+	for _, tt := range tests {
+		got, err := UpgradeCommand(tt.install)
+		if err != nil {
+			t.Errorf("UpgradeCommand(%q) error: %v", tt.install, err)
+
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("UpgradeCommand(%q) = %q, want %q", tt.install, got, tt.want)
+		}
+	}
+}
+
+func TestUpgradeCommandUnknown(t *testing.T) {
+	for _, install := range []string{
+		"make install", "npx cowsay", "",
+		"cargo install foo && foo init", "apt install -y foo; foo --setup", "eget foo/bar | tee log",
+	} {
+		if _, err := UpgradeCommand(install); !errors.Is(err, ErrNoUpgrade) {
+			t.Errorf("UpgradeCommand(%q) error = %v, want ErrNoUpgrade", install, err)
+		}
+	}
+}
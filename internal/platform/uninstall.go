@@ -0,0 +1,282 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrNotInvertible is returned by UninstallCommand for install commands that
+// have no inverse troveler can derive.
+var ErrNotInvertible = errors.New("cannot derive an uninstall command")
+
+// uninstallers build the uninstall command for each package manager family
+// ParseInstall recognizes.
+var uninstallers = map[string]func(m InstallMethod) string{
+	// --- System package managers: swap the verb, keep the package list ---
+	ManagerApt:    swapVerb("remove"),
+	ManagerDnf:    swapVerb("remove"),
+	ManagerZypper: swapVerb("remove"),
+	ManagerPkgin:  swapVerb("remove"),
+	ManagerApk:    swapVerb("del"),
+	ManagerBrew:   swapVerb("uninstall"),
+	ManagerPort:   swapVerb("uninstall"),
+	ManagerPacman: packagesOnly("-R", identityPkg),
+	ManagerSnap:   packagesOnly("remove", identityPkg),
+	ManagerScoop:  packagesOnly("uninstall", lastSegment),
+	ManagerChoco:  packagesOnly("uninstall", identityPkg),
+	ManagerWinget: packagesOnly("uninstall", identityPkg),
+	ManagerEmerge: packagesOnly("--depclean", identityPkg),
+	ManagerNix: func(m InstallMethod) string {
+		if m.Tool == "nix-env" {
+			return packagesOnly("-e", nixAttrName)(m)
+		}
+
+		return packagesOnly("remove", nixAttrName)(m)
+	},
+
+	// --- Language package managers ---
+	ManagerCargo: withPackage("%s uninstall %s"),
+	ManagerGo:    goUninstall,
+	ManagerNpm: func(m InstallMethod) string {
+		switch {
+		case strings.HasPrefix(m.Tool, "yarn"):
+			return packagesOnly("remove", npmPackage)(m)
+		case m.Tool == "pnpm":
+			return keepFlags("remove", "-g", "--global")(m)
+		}
+
+		return keepFlags("uninstall", "-g", "--global")(m)
+	},
+	ManagerPip:  packagesOnly("uninstall -y", pipPackage),
+	ManagerPipx: packagesOnly("uninstall", pipPackage),
+	ManagerUv:   withPackage("%s uninstall %s"),
+	ManagerGem:  packagesOnly("uninstall", identityPkg),
+
+	// --- mise: "use" edits the config, "install" only fetches ---
+	ManagerMise: func(m InstallMethod) string {
+		if strings.EqualFold(m.Verb, "use") {
+			return keepFlags("unuse", "-g", "--global")(m)
+		}
+
+		return packagesOnly("uninstall", identityPkg)(m)
+	},
+}
+
+// notInvertible explains why some install methods cannot be undone.
+var notInvertible = []struct {
+	pattern *regexp.Regexp
+	reason  string
+}{
+	{regexp.MustCompile(`(?i)^(?:curl|wget)\s`), "it runs a downloaded install script; remove the tool by hand"},
+	{regexp.MustCompile(`(?i)(?:^|\|\s*)(?:ba|z)?sh(?:\s|$)`), "it runs an install script; remove the tool by hand"},
+	{regexp.MustCompile(`(?i)^eget\s`), "eget downloads a release binary; delete it from where eget put it"},
+	{regexp.MustCompile(`(?i)^(?:npx|uvx|pnpx|bunx)\s`), "the command runs the tool without installing it"},
+	{regexp.MustCompile(`(?i)^nix-shell\s`), "nix-shell only provides the tool for the shell session"},
+	{regexp.MustCompile(`(?i)^cabal\s`), "cabal has no uninstall; delete the binary from ~/.cabal/bin"},
+}
+
+// UninstallCommand returns the command that removes what installCommand
+// installed. A leading sudo is dropped; callers decide on sudo the same way
+// they do for installs. Commands with no known inverse return an error
+// wrapping ErrNotInvertible.
+func UninstallCommand(installCommand string) (string, error) {
+	command := strings.TrimSpace(installCommand)
+	command = strings.TrimSpace(strings.TrimPrefix(command, "sudo "))
+
+	for _, n := range notInvertible {
+		if n.pattern.MatchString(command) {
+			return "", fmt.Errorf("%w: %s", ErrNotInvertible, n.reason)
+		}
+	}
+	if isCompound(command) {
+		return "", fmt.Errorf("%w: it runs more than one command; remove the tool by hand", ErrNotInvertible)
+	}
+
+	method := ParseInstall(command)
+	if uninstall, ok := uninstallers[method.Manager]; ok {
+		if result := uninstall(method); result != "" {
+			return result, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w from %q", ErrNotInvertible, installCommand)
+}
+
+// isCompound reports whether command chains or pipes several commands, so
+// that its arguments are not just packages.
+func isCompound(command string) bool {
+	return strings.ContainsAny(command, ";|") || strings.Contains(command, "&&")
+}
+
+// --- Uninstaller builders ----------------------------------------------------
+
+// swapVerb rebuilds "<tool> <verb> <args>", keeping the original arguments
+// (flags such as -y or --cask mean the same thing on removal).
+func swapVerb(newVerb string) func(InstallMethod) string {
+	return func(m InstallMethod) string {
+		return m.Tool + " " + newVerb + " " + m.Args
+	}
+}
+
+// packagesOnly rebuilds "<tool> <verb> <packages>", dropping install flags
+// and mapping each package argument through name.
+func packagesOnly(newVerb string, name func(string) string) func(InstallMethod) string {
+	return func(m InstallMethod) string {
+		pkgs := packageArgs(m.Args, name)
+		if len(pkgs) == 0 {
+			return ""
+		}
+
+		return m.Tool + " " + newVerb + " " + strings.Join(pkgs, " ")
+	}
+}
+
+// keepFlags is packagesOnly for npm-style tools, where a scope flag such as
+// -g must carry over to the removal.
+func keepFlags(newVerb string, flags ...string) func(InstallMethod) string {
+	return func(m InstallMethod) string {
+		pkgs := packageArgs(m.Args, npmPackage)
+		if len(pkgs) == 0 {
+			return ""
+		}
+
+		parts := []string{m.Tool, newVerb}
+		for _, field := range strings.Fields(m.Args) {
+			for _, flag := range flags {
+				if field == flag {
+					parts = append(parts, field)
+				}
+			}
+		}
+
+		return strings.Join(append(parts, pkgs...), " ")
+	}
+}
+
+// cargoCrate returns the crate a "cargo install" argument list installs,
+// taking it from the --git URL when no crate is named.
+func cargoCrate(args string) string {
+	crate := ""
+	gitRepo := ""
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case field == "--git" && i+1 < len(fields):
+			i++
+			gitRepo = fields[i]
+		case strings.HasPrefix(field, "--git="):
+			gitRepo = strings.TrimPrefix(field, "--git=")
+		case cargoValueFlags[field]:
+			i++
+		case strings.HasPrefix(field, "-"):
+		case crate == "":
+			crate = cleanPackage(field)
+		}
+	}
+
+	if crate == "" && gitRepo != "" {
+		crate = strings.TrimSuffix(lastSegment(gitRepo), ".git")
+	}
+
+	return crate
+}
+
+// cargoValueFlags are cargo install flags that take a separate value.
+var cargoValueFlags = map[string]bool{
+	"--branch": true, "--tag": true, "--rev": true, "--version": true, "--vers": true,
+	"--features": true, "-F": true, "--root": true, "--bin": true, "--registry": true, "--index": true,
+}
+
+// goUninstall removes the binary "go install" put in GOPATH/bin; Go has no
+// uninstall command of its own.
+func goUninstall(m InstallMethod) string {
+	path := m.Package
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/..."), "/cmd")
+	binary := lastSegment(path)
+	if isMajorVersion(binary) {
+		binary = lastSegment(strings.TrimSuffix(path, "/"+binary))
+	}
+	if binary == "" {
+		return ""
+	}
+
+	return `rm -f "$(go env GOPATH)/bin/` + binary + `"`
+}
+
+// --- Package name helpers ----------------------------------------------------
+
+// packageArgs returns the non-flag arguments of args mapped through name.
+func packageArgs(args string, name func(string) string) []string {
+	var pkgs []string
+	for _, field := range strings.Fields(args) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		if pkg := name(field); pkg != "" {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	return pkgs
+}
+
+func identityPkg(s string) string { return s }
+
+// npmPackage strips a version from an npm (or mise backend) spec, keeping a
+// leading scope: "@scope/pkg@1.2" -> "@scope/pkg", "cargo:rg@14" -> "cargo:rg".
+func npmPackage(s string) string {
+	start := strings.Index(s, ":") + 1
+	if idx := strings.LastIndex(s, "@"); idx > start {
+		return s[:idx]
+	}
+
+	return s
+}
+
+// pipPackage strips version specifiers and extras: "black[d]>=23" -> "black".
+func pipPackage(s string) string {
+	if idx := strings.IndexAny(s, "[=<>!~;@"); idx > 0 {
+		return s[:idx]
+	}
+
+	return s
+}
+
+// nixAttrName reduces an attribute path or flake reference to the package
+// name: "nixpkgs.ripgrep" and "nixpkgs#ripgrep" -> "ripgrep".
+func nixAttrName(s string) string {
+	if idx := strings.LastIndexAny(s, ".#"); idx >= 0 {
+		return s[idx+1:]
+	}
+
+	return s
+}
+
+// cleanPackage strips an "@version" suffix.
+func cleanPackage(s string) string {
+	if idx := strings.Index(s, "@"); idx > 0 {
+		return s[:idx]
+	}
+
+	return s
+}
+
+func lastSegment(s string) string {
+	return s[strings.LastIndex(s, "/")+1:]
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, r := range s[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
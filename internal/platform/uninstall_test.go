@@ -0,0 +1,78 @@
+package platform
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUninstallCommand(t *testing.T) {
+	tests := []struct {
+		install string
+		want    string
+	}{
+		{"sudo apt install -y fd-find", "apt remove -y fd-find"},
+		{"apt-get install ripgrep", "apt-get remove ripgrep"},
+		{"sudo dnf install bat", "dnf remove bat"},
+		{"pacman -S --needed fzf", "pacman -R fzf"},
+		{"yay -S lazygit", "yay -R lazygit"},
+		{"apk add jq", "apk del jq"},
+		{"brew install --cask wezterm", "brew uninstall --cask wezterm"},
+		{"snap install --classic nvim", "snap remove nvim"},
+		{"scoop install extras/lazygit", "scoop uninstall lazygit"},
+		{"nix-env -iA nixpkgs.ripgrep", "nix-env -e ripgrep"},
+		{"nix profile install nixpkgs#bat", "nix profile remove bat"},
+		{"cargo install --locked ripgrep", "cargo uninstall ripgrep"},
+		{"cargo install --git https://github.com/helix-editor/helix helix-term", "cargo uninstall helix-term"},
+		{"cargo install --git https://github.com/sharkdp/hyperfine.git", "cargo uninstall hyperfine"},
+		{"cargo binstall zoxide@0.9", "cargo uninstall zoxide"},
+		{"go install github.com/junegunn/fzf@latest", `rm -f "$(go env GOPATH)/bin/fzf"`},
+		{"go install github.com/charmbracelet/glow/v2@latest", `rm -f "$(go env GOPATH)/bin/glow"`},
+		{"go install github.com/jesseduffield/lazydocker/cmd/...@latest", `rm -f "$(go env GOPATH)/bin/lazydocker"`},
+		{"npm install -g @ast-grep/cli@0.20", "npm uninstall -g @ast-grep/cli"},
+		{"pnpm add -g tldr", "pnpm remove -g tldr"},
+		{"yarn global add prettier", "yarn global remove prettier"},
+		{"pip3 install --user black[d]>=23", "pip3 uninstall -y black"},
+		{"python3 -m pip install httpie", "python3 -m pip uninstall -y httpie"},
+		{"pipx install poetry", "pipx uninstall poetry"},
+		{"uv tool install --with pip ruff", "uv tool uninstall ruff"},
+		{"gem install tmuxinator", "gem uninstall tmuxinator"},
+		{"mise use --global cargo:ripgrep@14", "mise unuse --global cargo:ripgrep"},
+		{"mise use -g npm:@biomejs/biome", "mise unuse -g npm:@biomejs/biome"},
+		{"mise install node@20", "mise uninstall node@20"},
+		{"pkgin install -y htop", "pkgin remove -y htop"},
+		{"emerge --ask app-misc/jq", "emerge --depclean app-misc/jq"},
+		{"choco install -y bat", "choco uninstall bat"},
+	}
+
+	for _, tt := range tests {
+		got, err := UninstallCommand(tt.install)
+		if err != nil {
+			t.Errorf("UninstallCommand(%q) error: %v", tt.install, err)
+
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("UninstallCommand(%q) = %q, want %q", tt.install, got, tt.want)
+		}
+	}
+}
+
+func TestUninstallCommandNotInvertible(t *testing.T) {
+	for _, install := range []string{
+		"curl -sSfL https://example.com/install.sh | sh",
+		"eget zyedidia/micro",
+		"npx cowsay",
+		"nix-shell -p hello",
+		"make install",
+		"",
+		"npm install -g foo && foo init",
+		"apt install -y foo && foo --setup",
+		"brew install foo; foo setup",
+		"pip install foo || true",
+		"cargo install foo | tee log",
+	} {
+		if _, err := UninstallCommand(install); !errors.Is(err, ErrNotInvertible) {
+			t.Errorf("UninstallCommand(%q) error = %v, want ErrNotInvertible", install, err)
+		}
+	}
+}
@@ -2,9 +2,11 @@
 package search
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"troveler/db"
 )
@@ -13,6 +15,7 @@ import (
 type Parser struct {
 	tokens []token
 	pos    int
+	err    error // tokenizer error, reported by parseFromTokens
 }
 
 type token struct {
@@ -42,7 +45,7 @@ func isOperator(token string) bool {
 }
 
 func isFilterToken(t string) bool {
-	return strings.Contains(t, "=") || isOperator(t)
+	return strings.ContainsAny(t, "=<>") || strings.Index(t, "~/") > 0 || isOperator(t)
 }
 
 func extractFilterTokens(tokens []string) (filterTokens []string, searchTokens []string) {
@@ -64,7 +67,11 @@ func joinTokens(tokens []string) string {
 func (p *Parser) parseFromTokens(tokens []string) (*db.Filter, error) {
 	p.tokens = nil
 	p.pos = 0
+	p.err = nil
 	p.tokenize(joinTokens(tokens))
+	if p.err != nil {
+		return nil, p.err
+	}
 	if len(p.tokens) == 0 {
 		return nil, fmt.Errorf("empty filter expression")
 	}
@@ -74,11 +81,11 @@ func (p *Parser) parseFromTokens(tokens []string) (*db.Filter, error) {
 
 // ParseFilters parses a query string into a filter tree, remaining search terms, and warnings.
 func ParseFilters(query string) (*db.Filter, string, string, error) {
-	if !strings.Contains(query, "=") && !strings.ContainsAny(query, "&|!()") {
+	if !strings.ContainsAny(query, "=<>&|!()") && !strings.Contains(query, "~/") {
 		return nil, query, "", nil
 	}
 
-	rawTokens := strings.Fields(query)
+	rawTokens := queryFields(query)
 	filterTokens, searchTokens := extractFilterTokens(rawTokens)
 
 	if len(filterTokens) == 0 {
@@ -96,6 +103,9 @@ func ParseFilters(query string) (*db.Filter, string, string, error) {
 			return nil, query, //nolint:nilerr // graceful degradation
 				fmt.Sprintf("Malformed filter \"%s\" - using filter expression as search term", filterExpr), nil
 		}
+		if err := validateFilter(ast); err != nil {
+			return nil, "", "", err
+		}
 
 		return ast, "", "", nil
 	}
@@ -110,10 +120,85 @@ func ParseFilters(query string) (*db.Filter, string, string, error) {
 		// Intentionally swallow parse error: fall back to search term.
 		return nil, searchTerm, warn, nil //nolint:nilerr // graceful degradation
 	}
+	if err := validateFilter(ast); err != nil {
+		return nil, "", "", err
+	}
 
 	return ast, joinTokens(searchTokens), "", nil
 }
 
+// queryFields splits query at whitespace like strings.Fields, except inside
+// the /pattern/ of a field~/pattern/ filter, which may contain spaces.
+func queryFields(query string) []string {
+	var fields []string
+	var field strings.Builder
+	for i := 0; i < len(query); {
+		if strings.HasPrefix(query[i:], "~/") {
+			_, n, _ := scanRegex(query[i+2:])
+			field.WriteString(query[i : i+2+n])
+			i += 2 + n
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(query[i:])
+		if unicode.IsSpace(r) {
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		} else {
+			field.WriteString(query[i : i+size])
+		}
+		i += size
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	return fields
+}
+
+// validateFilter rejects unknown fields and values a field cannot take, so a
+// typo in a saved query fails loudly instead of silently matching everything.
+func validateFilter(filter *db.Filter) error {
+	if filter == nil {
+		return nil
+	}
+
+	if filter.Type == db.FilterField {
+		err := db.ValidateFilterField(filter.Field, filter.Op, filter.Value)
+		if errors.Is(err, db.ErrUnknownFilterField) {
+			if suggestion := suggestField(filter.Field); suggestion != "" {
+				return fmt.Errorf("%w (did you mean %q?)", err, suggestion)
+			}
+
+			return fmt.Errorf("%w (available: %s)", err, strings.Join(db.FilterFields(), ", "))
+		}
+
+		return err
+	}
+
+	if err := validateFilter(filter.Left); err != nil {
+		return err
+	}
+
+	return validateFilter(filter.Right)
+}
+
+// suggestField returns the filter field closest to field, or "" if none is
+// close enough to be a likely typo.
+func suggestField(field string) string {
+	best, bestScore := "", 0.5
+	for _, name := range db.FilterFields() {
+		if score := closeness(normalizeFuzzy(field), name); score > bestScore {
+			best, bestScore = name, score
+		}
+	}
+
+	return best
+}
+
 func (p *Parser) tokenize(query string) {
 	// Simple tokenizer - scan for patterns in order
 	var tokens []token
@@ -160,15 +245,33 @@ func (p *Parser) tokenize(query string) {
 		case ')':
 			tokens = append(tokens, token{Type: tokenRParen})
 			i++
-		case '=':
-			tokens = append(tokens, token{Type: tokenOperator, Value: "="})
+		case '=', '<', '>':
+			op := string(r)
+			i++
+			if i < len(query) && query[i] == '=' {
+				op += "="
+				i++
+			}
+			tokens = append(tokens, token{Type: tokenOperator, Value: op})
+		case '~':
+			// field~/regex/: the pattern may contain any operator character,
+			// so it is read up to the closing unescaped slash.
+			tokens = append(tokens, token{Type: tokenOperator, Value: db.OpMatch})
 			i++
+			if i < len(query) && query[i] == '/' {
+				pattern, n, ok := scanRegex(query[i+1:])
+				if !ok {
+					p.err = fmt.Errorf("unterminated regular expression")
+				}
+				tokens = append(tokens, token{Type: tokenValue, Value: pattern})
+				i += 1 + n
+			}
 		default:
 			// Collect field or value
 			var value strings.Builder
 			for i < len(query) {
 				r := rune(query[i])
-				if unicode.IsSpace(r) || r == '&' || r == '|' || r == '(' || r == ')' || r == '=' {
+				if unicode.IsSpace(r) || strings.ContainsRune("&|()=<>~", r) {
 					break
 				}
 				value.WriteRune(r)
@@ -176,13 +279,8 @@ func (p *Parser) tokenize(query string) {
 			}
 
 			// Determine if this is a field or value
-			if len(tokens) > 0 {
-				lastToken := tokens[len(tokens)-1]
-				if lastToken.Type == tokenOperator && lastToken.Value == "=" {
-					tokens = append(tokens, token{Type: tokenValue, Value: value.String()})
-				} else {
-					tokens = append(tokens, token{Type: tokenField, Value: value.String()})
-				}
+			if len(tokens) > 0 && isComparison(tokens[len(tokens)-1]) {
+				tokens = append(tokens, token{Type: tokenValue, Value: value.String()})
 			} else {
 				tokens = append(tokens, token{Type: tokenField, Value: value.String()})
 			}
@@ -192,6 +290,38 @@ func (p *Parser) tokenize(query string) {
 	p.tokens = tokens
 }
 
+// scanRegex reads a regex body up to its closing '/', unescaping "\/".
+// It returns the pattern, the number of bytes consumed including the
+// closing slash, and whether the slash was found.
+func scanRegex(s string) (string, int, bool) {
+	var pattern strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '/':
+			pattern.WriteByte('/')
+			i++
+		case s[i] == '/':
+			return pattern.String(), i + 1, true
+		default:
+			pattern.WriteByte(s[i])
+		}
+	}
+
+	return pattern.String(), len(s), false
+}
+
+func isComparison(t token) bool {
+	if t.Type != tokenOperator {
+		return false
+	}
+	switch t.Value {
+	case db.OpContains, db.OpEquals, db.OpMatch, db.OpBefore, db.OpAfter, db.OpOnBefore, db.OpOnAfter:
+		return true
+	}
+
+	return false
+}
+
 func (p *Parser) parseExpression() (*db.Filter, error) {
 	return p.parseOr()
 }
@@ -277,22 +407,38 @@ func (p *Parser) parseTerm() (*db.Filter, error) {
 		return expr, nil
 	}
 
-	// Handle field=value
+	// Handle field=value, field==value, field~/regex/, field>value, ...
 	if p.pos+2 < len(p.tokens) &&
 		p.tokens[p.pos].Type == tokenField &&
-		p.tokens[p.pos+1].Type == tokenOperator &&
-		p.tokens[p.pos+1].Value == "=" &&
+		isComparison(p.tokens[p.pos+1]) &&
 		p.tokens[p.pos+2].Type == tokenValue {
 		field := p.tokens[p.pos].Value
+		op := p.tokens[p.pos+1].Value
 		value := p.tokens[p.pos+2].Value
 		p.pos += 3
 
 		return &db.Filter{
 			Type:  db.FilterField,
 			Field: field,
+			Op:    op,
 			Value: value,
 		}, nil
 	}
 
 	return nil, fmt.Errorf("expected field=value or parenthesis at position %d", p.pos)
 }
+
+// parseFuzzyTerm strips the "~" that requests fuzzy matching from the words of
+// a search term, e.g. "~ripgerp". Reports whether any word carried it.
+func parseFuzzyTerm(term string) (string, bool) {
+	words := strings.Fields(term)
+	fuzzy := false
+	for i, w := range words {
+		if strings.HasPrefix(w, "~") {
+			words[i] = strings.TrimPrefix(w, "~")
+			fuzzy = true
+		}
+	}
+
+	return strings.TrimSpace(joinTokens(words)), fuzzy
+}
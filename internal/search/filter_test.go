@@ -1,6 +1,7 @@
 package search
 
 import (
+	"strings"
 	"testing"
 
 	"troveler/db"
@@ -512,3 +513,118 @@ func TestParseFiltersWarningForMultiOperator(t *testing.T) {
 		t.Errorf("unexpected warning: %s", warning)
 	}
 }
+
+func TestParseFiltersOperators(t *testing.T) {
+	tests := []struct {
+		query string
+		field string
+		op    string
+		value string
+	}{
+		{"name==bat", testFilterName, db.OpEquals, testToolBat},
+		{"published>2024-01-01", "published", db.OpAfter, "2024-01-01"},
+		{"published<=2024", "published", db.OpOnBefore, "2024"},
+		{"name~/^(rg|fd)$/", testFilterName, db.OpMatch, "^(rg|fd)$"},
+		{`repo~/github\.com\/sharkdp/`, "repo", db.OpMatch, `github\.com/sharkdp`},
+		{"totw=true", "totw", db.OpContains, filterValueTrue},
+		{"license=mit", "license", db.OpContains, "mit"},
+	}
+
+	for _, tt := range tests {
+		ast, searchTerm, warning, err := ParseFilters(tt.query)
+		if err != nil {
+			t.Fatalf("ParseFilters(%q) failed: %v", tt.query, err)
+		}
+		if searchTerm != "" || warning != "" {
+			t.Errorf("ParseFilters(%q) left term %q, warning %q", tt.query, searchTerm, warning)
+		}
+		if ast == nil || ast.Type != db.FilterField || ast.Field != tt.field || ast.Op != tt.op || ast.Value != tt.value {
+			t.Errorf("ParseFilters(%q) = %+v, want %s %s %s", tt.query, ast, tt.field, tt.op, tt.value)
+		}
+	}
+}
+
+func TestParseFiltersRegexWithSearchTerm(t *testing.T) {
+	ast, searchTerm, _, err := ParseFilters("grep name~/^(rg|ripgrep)$/&language=rust")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if searchTerm != "grep" {
+		t.Errorf("expected searchTerm 'grep', got '%s'", searchTerm)
+	}
+	if ast == nil || ast.Type != db.FilterAnd || ast.Left.Value != "^(rg|ripgrep)$" {
+		t.Errorf("unexpected AST: %+v", ast)
+	}
+}
+
+func TestParseFiltersRegexWithSpace(t *testing.T) {
+	tests := []struct {
+		query      string
+		pattern    string
+		searchTerm string
+	}{
+		{query: "name~/foo bar/", pattern: "foo bar"},
+		{query: "grep  tagline~/a  (b|c)/ more", pattern: "a  (b|c)", searchTerm: "grep more"},
+	}
+
+	for _, tt := range tests {
+		ast, searchTerm, warning, err := ParseFilters(tt.query)
+		if err != nil || warning != "" {
+			t.Fatalf("ParseFilters(%q): err=%v warning=%q", tt.query, err, warning)
+		}
+		if ast == nil || ast.Op != db.OpMatch || ast.Value != tt.pattern {
+			t.Errorf("ParseFilters(%q) = %+v, want pattern %q", tt.query, ast, tt.pattern)
+		}
+		if searchTerm != tt.searchTerm {
+			t.Errorf("ParseFilters(%q) search term = %q, want %q", tt.query, searchTerm, tt.searchTerm)
+		}
+	}
+}
+
+func TestParseFiltersUnknownField(t *testing.T) {
+	_, _, _, err := ParseFilters("lang=go")
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if !strings.Contains(err.Error(), `did you mean "language"?`) {
+		t.Errorf("expected suggestion in error, got: %v", err)
+	}
+
+	_, _, _, err = ParseFilters("model zzz=1")
+	if err == nil || !strings.Contains(err.Error(), "available:") {
+		t.Errorf("expected list of fields in error, got: %v", err)
+	}
+}
+
+func TestParseFiltersInvalidValues(t *testing.T) {
+	for _, query := range []string{
+		"published>yesterday",
+		"totw=maybe",
+		"name~/(/",
+		"name>bat",
+	} {
+		if _, _, _, err := ParseFilters(query); err == nil {
+			t.Errorf("ParseFilters(%q): expected error", query)
+		}
+	}
+}
+
+func TestParseFiltersUnterminatedRegex(t *testing.T) {
+	_, _, warning, err := ParseFilters("name~/^rg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if warning == "" {
+		t.Error("expected malformed filter warning for unterminated regex")
+	}
+}
+
+func TestParseFiltersTildeWordIsSearchTerm(t *testing.T) {
+	ast, searchTerm, _, err := ParseFilters("~ripgerp a~b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast != nil || searchTerm != "~ripgerp a~b" {
+		t.Errorf("expected plain search term, got ast=%+v term=%q", ast, searchTerm)
+	}
+}
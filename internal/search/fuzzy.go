@@ -0,0 +1,119 @@
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+// SortFieldCloseness is reported as the sort field of fuzzy results, which
+// are ordered by how closely name or slug resembles the search term.
+const SortFieldCloseness = "closeness"
+
+const (
+	// fuzzyThreshold is the minimum closeness for a tool to count as a match.
+	fuzzyThreshold = 0.6
+	// maxFuzzyCandidates bounds the slugs handed back to the database.
+	maxFuzzyCandidates = 200
+)
+
+type fuzzyMatch struct {
+	slug  string
+	name  string
+	score float64
+}
+
+// rankFuzzy scores every tool in names (slug -> name) against term and returns
+// those above fuzzyThreshold, closest first.
+func rankFuzzy(term string, names map[string]string) []fuzzyMatch {
+	query := normalizeFuzzy(term)
+	if query == "" {
+		return nil
+	}
+
+	var matches []fuzzyMatch
+	for slug, name := range names {
+		score := max(closeness(query, normalizeFuzzy(name)), closeness(query, normalizeFuzzy(slug)))
+		if score >= fuzzyThreshold {
+			matches = append(matches, fuzzyMatch{slug: slug, name: name, score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+
+		return strings.ToLower(matches[i].name) < strings.ToLower(matches[j].name)
+	})
+
+	if len(matches) > maxFuzzyCandidates {
+		matches = matches[:maxFuzzyCandidates]
+	}
+
+	return matches
+}
+
+// normalizeFuzzy lowercases s and drops separators, so "fd-find", "fd_find"
+// and "FD Find" all compare equal to "fdfind".
+func normalizeFuzzy(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', '.', ' ', '\t':
+			return -1
+		}
+
+		return r
+	}, strings.ToLower(s))
+}
+
+// closeness returns a similarity in [0, 1] between two normalized strings:
+// 1 minus the edit distance (with transpositions) over the longer length.
+// When one is a prefix of the other, the score is at least 0.5 plus half the
+// length ratio, so "fdfind" still finds "fd". A prefix of two characters only
+// counts when it is at least a third of the longer string, so "fdclone" does
+// not.
+func closeness(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	longest := max(len(ra), len(rb))
+	score := 1 - float64(editDistance(ra, rb))/float64(longest)
+
+	shortest := min(len(ra), len(rb))
+	prefixCounts := shortest >= 3 || (shortest == 2 && 3*shortest >= longest)
+	if prefixCounts && (strings.HasPrefix(a, b) || strings.HasPrefix(b, a)) {
+		score = max(score, 0.5+0.5*float64(shortest)/float64(longest))
+	}
+
+	return score
+}
+
+// editDistance is the optimal string alignment distance: insertions,
+// deletions, substitutions and adjacent transpositions each cost one.
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(b)]
+}
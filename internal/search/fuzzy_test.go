@@ -0,0 +1,147 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"troveler/db"
+)
+
+func TestCloseness(t *testing.T) {
+	tests := []struct {
+		query     string
+		candidate string
+		minScore  float64
+		maxScore  float64
+	}{
+		{query: "ripgrep", candidate: "ripgrep", minScore: 1, maxScore: 1},
+		{query: "ripgerp", candidate: "ripgrep", minScore: 0.8, maxScore: 0.9},
+		{query: "fdfind", candidate: "fd", minScore: 0.6, maxScore: 0.7},
+		{query: "batcat", candidate: "bat", minScore: 0.7, maxScore: 0.8},
+		// A two-character prefix of a much longer term is not close.
+		{query: "fdclone", candidate: "fd", minScore: 0, maxScore: 0.3},
+		{query: "fd-tui-manager", candidate: "fd", minScore: 0, maxScore: 0.2},
+		{query: "fzf", candidate: "ripgrep", minScore: 0, maxScore: 0.2},
+		{query: "", candidate: "fd", minScore: 0, maxScore: 0},
+	}
+
+	for _, tt := range tests {
+		got := closeness(normalizeFuzzy(tt.query), normalizeFuzzy(tt.candidate))
+		if got < tt.minScore || got > tt.maxScore {
+			t.Errorf("closeness(%q, %q) = %.3f, want in [%.2f, %.2f]",
+				tt.query, tt.candidate, got, tt.minScore, tt.maxScore)
+		}
+	}
+}
+
+func TestRankFuzzy(t *testing.T) {
+	names := map[string]string{
+		"ripgrep":  "ripgrep",
+		"ripgrep2": "ripgrep-all",
+		"fd":       "fd",
+		"fzf":      "fzf",
+		"bat":      "bat",
+	}
+
+	matches := rankFuzzy("ripgerp", names)
+	if len(matches) == 0 || matches[0].slug != "ripgrep" {
+		t.Fatalf("rankFuzzy(ripgerp) = %+v, want ripgrep first", matches)
+	}
+	for _, m := range matches {
+		if m.slug == "bat" || m.slug == "fzf" {
+			t.Errorf("rankFuzzy(ripgerp) unexpectedly matched %q", m.slug)
+		}
+	}
+
+	matches = rankFuzzy("fd_find", names)
+	if len(matches) != 1 || matches[0].slug != "fd" {
+		t.Errorf("rankFuzzy(fd_find) = %+v, want only fd", matches)
+	}
+
+	if matches = rankFuzzy("fdclone", names); len(matches) != 0 {
+		t.Errorf("rankFuzzy(fdclone) = %+v, want no matches", matches)
+	}
+}
+
+func TestParseFuzzyTerm(t *testing.T) {
+	tests := []struct {
+		term      string
+		want      string
+		wantFuzzy bool
+	}{
+		{term: "ripgrep", want: "ripgrep"},
+		{term: "~ripgerp", want: "ripgerp", wantFuzzy: true},
+		{term: "json ~parsr", want: "json parsr", wantFuzzy: true},
+		{term: "a~b", want: "a~b"},
+		{term: "~", want: "", wantFuzzy: true},
+	}
+
+	for _, tt := range tests {
+		got, fuzzy := parseFuzzyTerm(tt.term)
+		if got != tt.want || fuzzy != tt.wantFuzzy {
+			t.Errorf("parseFuzzyTerm(%q) = (%q, %v), want (%q, %v)", tt.term, got, fuzzy, tt.want, tt.wantFuzzy)
+		}
+	}
+}
+
+func TestSearchFuzzy(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	for _, tool := range []db.Tool{
+		{ID: "1", Slug: "ripgrep", Name: "ripgrep", Tagline: "Recursively search directories", Language: "rust"},
+		{ID: "2", Slug: "ripgrep-all", Name: "ripgrep-all", Tagline: "ripgrep, but also in PDFs", Language: "rust"},
+		{ID: "3", Slug: "fd", Name: "fd", Tagline: "A simple alternative to find", Language: "rust"},
+		{ID: "4", Slug: "grip", Name: "grip", Tagline: "Preview markdown", Language: "python"},
+	} {
+		if err := database.UpsertTool(ctx, &tool); err != nil {
+			t.Fatalf("UpsertTool(%s) failed: %v", tool.Slug, err)
+		}
+	}
+
+	svc := NewService(database)
+	tests := []struct {
+		name      string
+		query     string
+		wantFuzzy bool
+		want      []string
+	}{
+		{name: "exact match stays exact", query: "ripgrep", want: []string{"ripgrep", "ripgrep-all"}},
+		{name: "typo falls back", query: "ripgerp", wantFuzzy: true, want: []string{"ripgrep", "ripgrep-all"}},
+		{name: "debian name", query: "fdfind", wantFuzzy: true, want: []string{"fd"}},
+		{name: "explicit", query: "~ripgrp", wantFuzzy: true, want: []string{"ripgrep", "ripgrep-all"}},
+		{name: "filter applies", query: "~ripgerp language=python", wantFuzzy: true, want: nil},
+		{name: "nothing close", query: "zzzzzz", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := svc.Search(ctx, Options{Query: tt.query})
+			if err != nil {
+				t.Fatalf("Search(%q) failed: %v", tt.query, err)
+			}
+			if result.Fuzzy != tt.wantFuzzy {
+				t.Errorf("Search(%q).Fuzzy = %v, want %v", tt.query, result.Fuzzy, tt.wantFuzzy)
+			}
+
+			var got []string
+			for _, r := range result.Tools {
+				got = append(got, r.Slug)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+
+					break
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package search
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"unicode"
 
 	"troveler/db"
 )
@@ -21,7 +24,7 @@ func NewService(database *db.SQLiteDB) *Service {
 type Options struct {
 	Query     string
 	Limit     int
-	SortField string
+	SortField string // "" picks relevance for free-text queries, name otherwise
 	SortOrder string // ASC or DESC
 }
 
@@ -33,28 +36,58 @@ type Result struct {
 	SortField     string
 	SortOrder     string
 	FilterWarning string
+	Fuzzy         bool // Tools are fuzzy matches ranked by closeness
+	// InstalledStale is set when an installed= filter was answered from
+	// installed state older than db.InstalledStateMaxAge or missing tools.
+	InstalledStale bool
 }
 
 // ValidSortFields defines allowed sort fields
 var ValidSortFields = map[string]bool{
-	"name":     true,
-	"tagline":  true,
-	"language": true,
+	"name":                true,
+	"tagline":             true,
+	"language":            true,
+	db.SortFieldRelevance: true,
 }
 
 // Search performs a tool search with:: given options
 func (s *Service) Search(ctx context.Context, opts Options) (*Result, error) {
-	filter, searchTerm, filterWarning, err := ParseFilters(opts.Query)
+	query, err := s.expandSavedQueries(opts.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, searchTerm, filterWarning, err := ParseFilters(query)
 	if err != nil {
 		return nil, fmt.Errorf("invalid filter syntax: %w", err)
 	}
 
+	result, err := s.search(ctx, opts, filter, searchTerm, filterWarning)
+	if err != nil || !db.FilterUsesInstalled(filter) {
+		return result, err
+	}
+	if result.InstalledStale, err = s.db.InstalledStateStale(ctx, db.InstalledStateMaxAge); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// search runs a parsed query.
+func (s *Service) search(
+	ctx context.Context, opts Options, filter *db.Filter, searchTerm, filterWarning string,
+) (*Result, error) {
+	searchTerm, fuzzy := parseFuzzyTerm(searchTerm)
+
 	// Apply defaults
 	if opts.Limit <= 0 {
 		opts.Limit = 50
 	}
 
 	// Validate and default sort field
+	if opts.SortField == "" && searchTerm != "" {
+		opts.SortField = db.SortFieldRelevance
+	}
 	if !ValidSortFields[opts.SortField] {
 		opts.SortField = "name"
 	}
@@ -64,6 +97,10 @@ func (s *Service) Search(ctx context.Context, opts Options) (*Result, error) {
 		opts.SortOrder = "ASC"
 	}
 
+	if fuzzy && searchTerm != "" {
+		return s.fuzzySearch(ctx, searchTerm, filter, opts.Limit, filterWarning)
+	}
+
 	// Perform search
 	dbOpts := db.SearchOptions{
 		Query:     searchTerm,
@@ -78,6 +115,14 @@ func (s *Service) Search(ctx context.Context, opts Options) (*Result, error) {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
+	// Nothing matched verbatim: the term may be a typo.
+	if len(tools) == 0 && searchTerm != "" {
+		result, err := s.fuzzySearch(ctx, searchTerm, filter, opts.Limit, filterWarning)
+		if err != nil || len(result.Tools) > 0 {
+			return result, err
+		}
+	}
+
 	return &Result{
 		Tools:         tools,
 		TotalCount:    len(tools),
@@ -88,6 +133,138 @@ func (s *Service) Search(ctx context.Context, opts Options) (*Result, error) {
 	}, nil
 }
 
+// expandSavedQueries replaces every "@name" reference in query with the saved
+// expression of that name, in parentheses so it binds as one operand. Saved
+// expressions are not expanded again.
+func (s *Service) expandSavedQueries(query string) (string, error) {
+	return replaceSavedQueryRefs(query, func(name string) (string, error) {
+		saved, err := s.db.GetSavedQuery(name)
+		if err != nil {
+			return "", err
+		}
+
+		return "(" + saved.Expression + ")", nil
+	})
+}
+
+// SavedQueryRefs returns the names of the saved queries query references, in
+// the order they appear.
+func SavedQueryRefs(query string) []string {
+	var names []string
+	_, _ = replaceSavedQueryRefs(query, func(name string) (string, error) {
+		names = append(names, name)
+
+		return "", nil
+	})
+
+	return names
+}
+
+// replaceSavedQueryRefs replaces every "@name" reference in query with what
+// replace returns for name. A reference starts a word or follows "(" or "!";
+// "@" inside quoted values is left alone.
+func replaceSavedQueryRefs(query string, replace func(name string) (string, error)) (string, error) {
+	if !strings.Contains(query, "@") {
+		return query, nil
+	}
+
+	var out strings.Builder
+	var quote rune
+	prev := ' '
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '@' && (unicode.IsSpace(prev) || prev == '(' || prev == '!'):
+			end := i + 1
+			for end < len(runes) && !isQueryNameEnd(runes[end]) {
+				end++
+			}
+			if end == i+1 {
+				break
+			}
+			replacement, err := replace(string(runes[i+1 : end]))
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(replacement)
+			i, prev = end-1, ')'
+
+			continue
+		}
+		out.WriteRune(r)
+		prev = r
+	}
+
+	return out.String(), nil
+}
+
+// isQueryNameEnd reports whether r ends a saved query reference.
+func isQueryNameEnd(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("()&|!\"'", r)
+}
+
+// fuzzySearch returns the tools matching filter whose name or slug is close
+// to term, closest first.
+func (s *Service) fuzzySearch(
+	ctx context.Context, term string, filter *db.Filter, limit int, filterWarning string,
+) (*Result, error) {
+	names, err := s.db.ListToolNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fuzzy search failed: %w", err)
+	}
+
+	result := &Result{
+		Query:         term,
+		SortField:     SortFieldCloseness,
+		SortOrder:     "DESC",
+		FilterWarning: filterWarning,
+		Fuzzy:         true,
+	}
+
+	matches := rankFuzzy(term, names)
+	if len(matches) == 0 {
+		return result, nil
+	}
+
+	slugs := make([]string, len(matches))
+	rank := make(map[string]int, len(matches))
+	for i, m := range matches {
+		slugs[i] = m.slug
+		rank[m.slug] = i
+	}
+
+	// The database applies the filter; closeness decides the order.
+	tools, err := s.db.Search(ctx, db.SearchOptions{
+		Limit:     len(slugs),
+		SortField: "name",
+		SortOrder: "ASC",
+		Filter:    filter,
+		Slugs:     slugs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fuzzy search failed: %w", err)
+	}
+
+	sort.SliceStable(tools, func(i, j int) bool {
+		return rank[tools[i].Slug] < rank[tools[j].Slug]
+	})
+	if len(tools) > limit {
+		tools = tools[:limit]
+	}
+
+	result.Tools = tools
+	result.TotalCount = len(tools)
+
+	return result, nil
+}
+
 // SearchAll returns all tools (for initial TUI load)
 func (s *Service) SearchAll(ctx context.Context, limit int) (*Result, error) {
 	return s.Search(ctx, Options{
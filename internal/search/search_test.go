@@ -1,7 +1,11 @@
 package search
 
 import (
+	"context"
+	"strings"
 	"testing"
+
+	"troveler/db"
 )
 
 const (
@@ -9,7 +13,7 @@ const (
 )
 
 func TestValidSortFields(t *testing.T) {
-	validFields := []string{sortFieldName, "tagline", "language"}
+	validFields := []string{sortFieldName, "tagline", "language", db.SortFieldRelevance}
 
 	for _, field := range validFields {
 		if !ValidSortFields[field] {
@@ -80,3 +84,181 @@ func TestOptionsDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchDefaultSortField(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	svc := NewService(database)
+	tests := []struct {
+		query string
+		sort  string
+		want  string
+	}{
+		{query: "json", want: db.SortFieldRelevance},
+		{query: "language=go", want: sortFieldName},
+		{query: "json", sort: "language", want: "language"},
+	}
+
+	for _, tt := range tests {
+		result, err := svc.Search(context.Background(), Options{Query: tt.query, SortField: tt.sort})
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", tt.query, err)
+		}
+		if result.SortField != tt.want {
+			t.Errorf("Search(%q, sort=%q) sorted by %q, want %q", tt.query, tt.sort, result.SortField, tt.want)
+		}
+	}
+}
+
+func TestSearchSavedQuery(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	for _, tool := range []db.Tool{
+		{ID: "1", Slug: "ripgrep", Name: "ripgrep", Language: "rust"},
+		{ID: "2", Slug: "fzf", Name: "fzf", Language: "go"},
+		{ID: "3", Slug: "bat", Name: "bat", Language: "rust"},
+	} {
+		if err := database.UpsertTool(ctx, &tool); err != nil {
+			t.Fatalf("UpsertTool(%s) failed: %v", tool.Slug, err)
+		}
+	}
+	if err := database.SaveQuery("rusty", "language=rust"); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+
+	svc := NewService(database)
+	result, err := svc.Search(ctx, Options{Query: "@rusty"})
+	if err != nil {
+		t.Fatalf("Search(@rusty) failed: %v", err)
+	}
+	if len(result.Tools) != 2 || result.Tools[0].Slug != "bat" || result.Tools[1].Slug != "ripgrep" {
+		t.Errorf("Search(@rusty) = %+v, want bat and ripgrep", result.Tools)
+	}
+
+	result, err = svc.Search(ctx, Options{Query: "rip @rusty"})
+	if err != nil {
+		t.Fatalf("Search(rip @rusty) failed: %v", err)
+	}
+	if len(result.Tools) != 1 || result.Tools[0].Slug != "ripgrep" {
+		t.Errorf("Search(rip @rusty) = %+v, want ripgrep", result.Tools)
+	}
+
+	if _, err := svc.Search(ctx, Options{Query: "@missing"}); err == nil {
+		t.Error("expected error for unknown saved query")
+	}
+}
+
+func TestSearchSavedQueryGrouping(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	for _, tool := range []db.Tool{
+		{ID: "1", Slug: "ripgrep", Name: "ripgrep", Language: "rust", License: "mit"},
+		{ID: "2", Slug: "fzf", Name: "fzf", Language: "go", License: "mit"},
+		{ID: "3", Slug: "lazygit", Name: "lazygit", Language: "go", License: "apache"},
+		{ID: "4", Slug: "vim", Name: "vim", Language: "c", License: "mit"},
+	} {
+		if err := database.UpsertTool(ctx, &tool); err != nil {
+			t.Fatalf("UpsertTool(%s) failed: %v", tool.Slug, err)
+		}
+	}
+	if err := database.SaveQuery("langs", "language=go | language=rust"); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+
+	svc := NewService(database)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "@langs & license=mit", want: "fzf,ripgrep"},
+		{query: "license=mit & @langs", want: "fzf,ripgrep"},
+		{query: "(@langs)", want: "fzf,lazygit,ripgrep"},
+		{query: "(@langs) & license=apache", want: "lazygit"},
+		{query: "!@langs", want: "vim"},
+	}
+
+	for _, tt := range tests {
+		result, err := svc.Search(ctx, Options{Query: tt.query})
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", tt.query, err)
+		}
+		slugs := make([]string, len(result.Tools))
+		for i, tool := range result.Tools {
+			slugs[i] = tool.Slug
+		}
+		if got := strings.Join(slugs, ","); got != tt.want || result.FilterWarning != "" {
+			t.Errorf("Search(%q) = %s (warning %q), want %s", tt.query, got, result.FilterWarning, tt.want)
+		}
+	}
+
+	expanded, err := svc.expandSavedQueries(`tagline="@langs  here" & @langs`)
+	if err != nil {
+		t.Fatalf("expandSavedQueries failed: %v", err)
+	}
+	if want := `tagline="@langs  here" & (language=go | language=rust)`; expanded != want {
+		t.Errorf("expandSavedQueries = %q, want %q", expanded, want)
+	}
+}
+
+func TestSearchInstalledStale(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	tool := db.Tool{ID: "1", Slug: "ripgrep", Name: "ripgrep", Language: "rust"}
+	if err := database.UpsertTool(ctx, &tool); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+
+	svc := NewService(database)
+	result, err := svc.Search(ctx, Options{Query: "installed=false"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if !result.InstalledStale {
+		t.Error("never scanned: expected InstalledStale")
+	}
+	state, err := database.GetInstalledState(ctx)
+	if err != nil {
+		t.Fatalf("GetInstalledState failed: %v", err)
+	}
+	if len(state) != 0 {
+		t.Errorf("Search rescanned PATH: installed state = %+v", state)
+	}
+
+	if _, err := database.ScanInstalled(ctx); err != nil {
+		t.Fatalf("ScanInstalled failed: %v", err)
+	}
+	result, err = svc.Search(ctx, Options{Query: "installed=false"})
+	if err != nil {
+		t.Fatalf("Search after scan failed: %v", err)
+	}
+	if result.InstalledStale {
+		t.Error("just scanned: expected fresh installed state")
+	}
+
+	result, err = svc.Search(ctx, Options{Query: "language=rust"})
+	if err != nil {
+		t.Fatalf("Search without installed filter failed: %v", err)
+	}
+	if result.InstalledStale {
+		t.Error("InstalledStale set without an installed filter")
+	}
+}
@@ -0,0 +1,161 @@
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/google/uuid"
+
+	"troveler/db"
+)
+
+// File is a source backed by a local TOML catalog, typically listing a
+// team's internal tools:
+//
+//	[[tools]]
+//	name = "deployctl"
+//	tagline = "Deploy services to the internal cluster"
+//	language = "go"
+//	repository = "https://git.example.com/platform/deployctl"
+//	executable = "deployctl"
+//
+//	[tools.install]
+//	go = "go install git.example.com/platform/deployctl@latest"
+//	brew = "brew install example/tap/deployctl"
+type File struct {
+	name  string
+	path  string
+	mu    sync.RWMutex
+	tools map[string]catalogTool
+}
+
+type catalogFile struct {
+	Tools []catalogTool `toml:"tools"`
+}
+
+type catalogTool struct {
+	Slug        string            `toml:"slug" json:"slug"`
+	Name        string            `toml:"name" json:"name"`
+	Tagline     string            `toml:"tagline" json:"tagline"`
+	Description string            `toml:"description" json:"description"`
+	Language    string            `toml:"language" json:"language"`
+	License     string            `toml:"license" json:"license"`
+	Repository  string            `toml:"repository" json:"repository"`
+	Published   string            `toml:"published" json:"published"`
+	Executable  string            `toml:"executable" json:"executable"`
+	Install     map[string]string `toml:"install" json:"install"`
+}
+
+// NewFile creates a source that reads the catalog at path. The file is read
+// on every List, so edits are picked up by the next update.
+func NewFile(name, path string) *File {
+	return &File{name: name, path: path, tools: make(map[string]catalogTool)}
+}
+
+// Name returns the configured catalog name.
+func (s *File) Name() string {
+	return s.name
+}
+
+// List parses the catalog file and returns one ref per tool, in file order.
+func (s *File) List(_ context.Context, limit int) ([]Ref, int, error) {
+	data, err := os.ReadFile(s.path) //nolint:gosec // G304: catalog path comes from the user config
+	if err != nil {
+		return nil, 0, fmt.Errorf("read catalog %s: %w", s.name, err)
+	}
+
+	var catalog catalogFile
+	if err := toml.Unmarshal(data, &catalog); err != nil {
+		return nil, 0, fmt.Errorf("parse catalog %s: %w", s.name, err)
+	}
+
+	tools := make(map[string]catalogTool, len(catalog.Tools))
+	refs := make([]Ref, 0, len(catalog.Tools))
+
+	for i, t := range catalog.Tools {
+		if t.Slug == "" {
+			t.Slug = strings.ToLower(strings.ReplaceAll(t.Name, " ", "-"))
+		}
+		if t.Slug == "" {
+			return nil, 0, fmt.Errorf("catalog %s: tool #%d has neither slug nor name", s.name, i+1)
+		}
+		if _, dup := tools[t.Slug]; dup {
+			return nil, 0, fmt.Errorf("catalog %s: duplicate slug %q", s.name, t.Slug)
+		}
+		if t.Name == "" {
+			t.Name = t.Slug
+		}
+
+		tools[t.Slug] = t
+		if limit == 0 || len(refs) < limit {
+			refs = append(refs, Ref{Slug: t.Slug, Fingerprint: t.fingerprint()})
+		}
+	}
+
+	s.mu.Lock()
+	s.tools = tools
+	s.mu.Unlock()
+
+	return refs, len(catalog.Tools), nil
+}
+
+// Fetch returns the tool with the given slug from the last List.
+func (s *File) Fetch(_ context.Context, slug string) (*Entry, error) {
+	s.mu.RLock()
+	t, ok := s.tools[slug]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("catalog %s: unknown tool %q", s.name, slug)
+	}
+
+	tool := &db.Tool{
+		ID:             uuid.New().String(),
+		Slug:           t.Slug,
+		Name:           t.Name,
+		Tagline:        t.Tagline,
+		Description:    t.Description,
+		Language:       t.Language,
+		License:        t.License,
+		DatePublished:  t.Published,
+		CodeRepository: t.Repository,
+		SourceHash:     t.fingerprint(),
+		Source:         s.name,
+	}
+
+	platforms := make([]string, 0, len(t.Install))
+	for platform := range t.Install {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	installs := make([]db.InstallInstruction, 0, len(platforms))
+	for _, platform := range platforms {
+		installs = append(installs, db.InstallInstruction{
+			ID:             uuid.New().String(),
+			ToolID:         tool.ID,
+			Platform:       platform,
+			Command:        t.Install[platform],
+			ExecutableName: t.Executable,
+		})
+	}
+
+	return &Entry{Tool: tool, Installs: installs}, nil
+}
+
+// fingerprint hashes every field of the catalog entry. encoding/json sorts
+// map keys, so the result is stable across runs.
+func (t catalogTool) fingerprint() string {
+	data, _ := json.Marshal(t) // strings and a string map always encode
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,99 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testCatalog = `[[tools]]
+name = "deployctl"
+tagline = "Deploy services to the internal cluster"
+language = "go"
+executable = "deployctl"
+
+[tools.install]
+go = "go install git.example.com/platform/deployctl@latest"
+brew = "brew install example/tap/deployctl"
+
+[[tools]]
+slug = "vault-login"
+name = "Vault Login"
+`
+
+func writeCatalog(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "team.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write catalog: %v", err)
+	}
+
+	return path
+}
+
+func TestFileSource(t *testing.T) {
+	ctx := context.Background()
+	src := NewFile("team", writeCatalog(t, testCatalog))
+
+	refs, total, err := src.List(ctx, 0)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 2 || len(refs) != 2 {
+		t.Fatalf("expected 2 refs, got %d (total %d)", len(refs), total)
+	}
+	if refs[0].Slug != "deployctl" || refs[1].Slug != "vault-login" {
+		t.Errorf("unexpected slugs: %v", refs)
+	}
+	if refs[0].Fingerprint == "" || refs[0].Fingerprint == refs[1].Fingerprint {
+		t.Errorf("expected distinct fingerprints, got %v", refs)
+	}
+
+	entry, err := src.Fetch(ctx, "deployctl")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if entry.Tool.Source != "team" || entry.Tool.Language != "go" {
+		t.Errorf("unexpected tool: %+v", entry.Tool)
+	}
+	if entry.Tool.SourceHash != refs[0].Fingerprint {
+		t.Errorf("SourceHash = %q, want ref fingerprint", entry.Tool.SourceHash)
+	}
+	if len(entry.Installs) != 2 || entry.Installs[0].Platform != "brew" {
+		t.Fatalf("unexpected installs: %+v", entry.Installs)
+	}
+	if entry.Installs[0].ExecutableName != "deployctl" || entry.Installs[0].ToolID != entry.Tool.ID {
+		t.Errorf("unexpected install: %+v", entry.Installs[0])
+	}
+
+	if _, err := src.Fetch(ctx, "missing"); err == nil {
+		t.Error("expected error for unknown slug")
+	}
+}
+
+func TestFileSourceDuplicateSlug(t *testing.T) {
+	src := NewFile("team", writeCatalog(t, "[[tools]]\nname = \"a\"\n[[tools]]\nslug = \"a\"\n"))
+
+	if _, _, err := src.List(context.Background(), 0); err == nil {
+		t.Error("expected error for duplicate slug")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	sources := []Source{NewTerminalTrove(nil), NewFile("team", "team.toml")}
+
+	all, err := Select(sources, "")
+	if err != nil || len(all) != 2 {
+		t.Fatalf("Select(\"\") = %d sources, err %v", len(all), err)
+	}
+
+	team, err := Select(sources, "team")
+	if err != nil || len(team) != 1 || team[0].Name() != "team" {
+		t.Fatalf("Select(team) = %v, err %v", team, err)
+	}
+
+	if _, err := Select(sources, "nope"); err == nil {
+		t.Error("expected error for unknown source")
+	}
+}
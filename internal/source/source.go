@@ -0,0 +1,67 @@
+// Package source defines the catalogs that update pulls tools from.
+package source
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"troveler/config"
+	"troveler/crawler"
+	"troveler/db"
+)
+
+// Ref identifies a tool in a source listing. Fingerprint changes whenever the
+// tool's full record may have changed, so incremental updates can skip
+// fetching refs whose fingerprint matches the stored one.
+type Ref struct {
+	Slug        string
+	Fingerprint string
+}
+
+// Entry is a fully loaded tool together with its install instructions.
+type Entry struct {
+	Tool     *db.Tool
+	Installs []db.InstallInstruction
+}
+
+// Source is a catalog of tools that update can refresh.
+type Source interface {
+	// Name identifies the source; it is recorded on every tool it yields.
+	Name() string
+	// List returns refs for up to limit tools (0 = all) and the total number
+	// of tools the source offers.
+	List(ctx context.Context, limit int) ([]Ref, int, error)
+	// Fetch loads the tool with the given slug from a previous List call.
+	Fetch(ctx context.Context, slug string) (*Entry, error)
+}
+
+// Configured returns the terminaltrove source followed by one File source per
+// configured catalog. Catalog names are validated by config.Load.
+func Configured(fetcher *crawler.Fetcher, catalogs []config.CatalogConfig) []Source {
+	sources := []Source{NewTerminalTrove(fetcher)}
+	for _, cat := range catalogs {
+		sources = append(sources, NewFile(cat.Name, cat.Path))
+	}
+
+	return sources
+}
+
+// Select returns the source called name, or all sources when name is empty.
+func Select(sources []Source, name string) ([]Source, error) {
+	if name == "" {
+		return sources, nil
+	}
+
+	names := make([]string, 0, len(sources))
+	for _, src := range sources {
+		if src.Name() == name {
+			return []Source{src}, nil
+		}
+		names = append(names, src.Name())
+	}
+	sort.Strings(names)
+
+	return nil, fmt.Errorf("unknown source %q (available: %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,128 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"troveler/crawler"
+	"troveler/db"
+)
+
+// TerminalTrove is the source backed by terminaltrove.com's search API and
+// tool detail pages.
+type TerminalTrove struct {
+	fetcher *crawler.Fetcher
+	mu      sync.RWMutex
+	hits    map[string]crawler.HitDocument
+}
+
+// NewTerminalTrove creates a terminaltrove.com source using fetcher.
+func NewTerminalTrove(fetcher *crawler.Fetcher) *TerminalTrove {
+	return &TerminalTrove{
+		fetcher: fetcher,
+		hits:    make(map[string]crawler.HitDocument),
+	}
+}
+
+// Name returns db.DefaultSource.
+func (s *TerminalTrove) Name() string {
+	return db.DefaultSource
+}
+
+// List fetches the search pages and returns one ref per hit, fingerprinted by
+// the hit's search-result fields. A page that is missing or fails to parse,
+// or pages holding well under the tools the search reported, fail the whole
+// listing: an incomplete listing would make incremental updates mark the
+// missing tools as removed.
+func (s *TerminalTrove) List(ctx context.Context, limit int) ([]Ref, int, error) {
+	initialData, err := s.fetcher.FetchSearchPage(ctx, 1)
+	if err != nil {
+		return nil, 0, fmt.Errorf("initial fetch: %w", err)
+	}
+
+	initialResp, err := crawler.ParseSearchResponse(initialData)
+	if err != nil {
+		return nil, 0, fmt.Errorf("parse initial: %w", err)
+	}
+
+	totalTools := int(initialResp.Found)
+	if limit > 0 && limit < totalTools {
+		totalTools = limit
+	}
+
+	pageResults, err := s.fetcher.FetchSearchPagesConcurrently(ctx, (totalTools+99)/100)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fetch pages: %w", err)
+	}
+
+	var refs []Ref
+	hits := make(map[string]crawler.HitDocument)
+
+	for i := 1; i <= (totalTools+99)/100; i++ {
+		data, ok := pageResults[i]
+		if !ok {
+			return nil, 0, fmt.Errorf("search page %d missing", i)
+		}
+
+		resp, err := crawler.ParseSearchResponse(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("parse page %d: %w", i, err)
+		}
+
+		for _, item := range resp.Hits {
+			if item.Document.Slug != "" {
+				refs = append(refs, Ref{Slug: item.Document.Slug, Fingerprint: item.Document.Fingerprint()})
+				hits[item.Document.Slug] = item.Document
+				if limit > 0 && len(refs) >= limit {
+					break
+				}
+			}
+		}
+		if limit > 0 && len(refs) >= limit {
+			break
+		}
+	}
+
+	// Allow for hits without a slug; anything more is a broken listing.
+	if len(refs) < totalTools*9/10 {
+		return nil, 0, fmt.Errorf("search listed %d of %d tools", len(refs), totalTools)
+	}
+
+	s.mu.Lock()
+	s.hits = hits
+	s.mu.Unlock()
+
+	return refs, int(initialResp.Found), nil
+}
+
+// Fetch downloads and parses the tool's detail page.
+func (s *TerminalTrove) Fetch(ctx context.Context, slug string) (*Entry, error) {
+	data, err := s.fetcher.FetchDetailPage(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	detail, err := crawler.ParseDetailPage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	hit, ok := s.hits[slug]
+	s.mu.RUnlock()
+	if ok {
+		applyHit(&detail.Tool, hit)
+	}
+
+	tool := detail.ToTool()
+	tool.Source = db.DefaultSource
+
+	return &Entry{Tool: tool, Installs: detail.ToInstallInstructions()}, nil
+}
+
+// applyHit copies the search-hit-only fields onto a parsed detail page's tool.
+func applyHit(tool *db.Tool, hit crawler.HitDocument) {
+	tool.ToolOfTheWeek = hit.ToolOfTheWeek
+	tool.SourceHash = hit.Fingerprint()
+}
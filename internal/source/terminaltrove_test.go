@@ -0,0 +1,22 @@
+package source
+
+import (
+	"testing"
+
+	"troveler/crawler"
+	"troveler/db"
+)
+
+func TestApplyHit(t *testing.T) {
+	hit := crawler.HitDocument{Slug: "bat", ToolOfTheWeek: true}
+	tool := &db.Tool{Slug: "bat"}
+
+	applyHit(tool, hit)
+
+	if !tool.ToolOfTheWeek {
+		t.Error("expected ToolOfTheWeek to be copied from the hit")
+	}
+	if tool.SourceHash != hit.Fingerprint() {
+		t.Errorf("SourceHash = %q, want %q", tool.SourceHash, hit.Fingerprint())
+	}
+}
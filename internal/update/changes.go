@@ -0,0 +1,100 @@
+package update
+
+import (
+	"fmt"
+	"strings"
+
+	"troveler/db"
+)
+
+// changeLabels names each kind of catalog change in summaries, singular and
+// plural, in the order summaries list them.
+var changeLabels = []struct{ kind, one, many string }{
+	{db.ChangeAdded, "added", "added"},
+	{db.ChangeRemoved, "removed", "removed"},
+	{db.ChangeRenamed, "renamed", "renamed"},
+	{db.ChangeTagline, "tagline changed", "taglines changed"},
+	{db.ChangeDescription, "description changed", "descriptions changed"},
+	{db.ChangeInstallAdded, "install method added", "install methods added"},
+	{db.ChangeInstallRemoved, "install method removed", "install methods removed"},
+	{db.ChangeInstallChanged, "install command changed", "install commands changed"},
+}
+
+// changeValueWidth caps the old and new values DescribeChange shows.
+const changeValueWidth = 60
+
+// SummarizeChanges counts changes by kind, e.g. "2 added, 1 renamed,
+// 3 install methods added".
+func SummarizeChanges(changes []db.CatalogChange) string {
+	if len(changes) == 0 {
+		return "no changes"
+	}
+
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+
+	var parts []string
+	for _, label := range changeLabels {
+		switch n := counts[label.kind]; n {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+label.one)
+		default:
+			parts = append(parts, fmt.Sprintf("%d %s", n, label.many))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// DescribeChange renders a change on one line.
+func DescribeChange(c db.CatalogChange) string {
+	switch c.Kind {
+	case db.ChangeAdded:
+		return c.Slug + ": added"
+	case db.ChangeRemoved:
+		return c.Slug + ": removed upstream"
+	case db.ChangeRenamed:
+		return fmt.Sprintf("%s: renamed %q → %q", c.Slug, c.Old, c.New)
+	case db.ChangeTagline:
+		return fmt.Sprintf("%s: tagline %q → %q", c.Slug, shorten(c.Old), shorten(c.New))
+	case db.ChangeDescription:
+		return c.Slug + ": description changed"
+	case db.ChangeInstallAdded:
+		return fmt.Sprintf("%s: %s install added: %s", c.Slug, c.Platform, shorten(c.New))
+	case db.ChangeInstallRemoved:
+		return fmt.Sprintf("%s: %s install removed", c.Slug, c.Platform)
+	case db.ChangeInstallChanged:
+		return fmt.Sprintf("%s: %s install changed: %s", c.Slug, c.Platform, shorten(c.New))
+	default:
+		return fmt.Sprintf("%s: %s", c.Slug, c.Kind)
+	}
+}
+
+// DescribeChanges describes changes one per line, listing at most limit of
+// them.
+func DescribeChanges(changes []db.CatalogChange, limit int) string {
+	var b strings.Builder
+	for i, c := range changes {
+		if i == limit {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(changes)-limit)
+
+			break
+		}
+		fmt.Fprintf(&b, "  %s\n", DescribeChange(c))
+	}
+
+	return b.String()
+}
+
+// shorten cuts s to changeValueWidth runes, ending in "..." when cut.
+func shorten(s string) string {
+	runes := []rune(s)
+	if len(runes) <= changeValueWidth {
+		return s
+	}
+
+	return string(runes[:changeValueWidth-3]) + "..."
+}
@@ -0,0 +1,64 @@
+package update
+
+import (
+	"strings"
+	"testing"
+
+	"troveler/db"
+)
+
+func TestSummarizeChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []db.CatalogChange
+		want    string
+	}{
+		{"none", nil, "no changes"},
+		{
+			"counted in order",
+			[]db.CatalogChange{
+				{Kind: db.ChangeInstallAdded}, {Kind: db.ChangeAdded}, {Kind: db.ChangeInstallAdded},
+				{Kind: db.ChangeTagline}, {Kind: db.ChangeAdded},
+			},
+			"2 added, 1 tagline changed, 2 install methods added",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SummarizeChanges(tt.changes); got != tt.want {
+				t.Errorf("SummarizeChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeChange(t *testing.T) {
+	tests := []struct {
+		change db.CatalogChange
+		want   string
+	}{
+		{db.CatalogChange{Slug: "fd", Kind: db.ChangeAdded, New: "fd"}, "fd: added"},
+		{db.CatalogChange{Slug: "fd", Kind: db.ChangeRemoved}, "fd: removed upstream"},
+		{db.CatalogChange{Slug: "fd", Kind: db.ChangeRenamed, Old: "fd", New: "fd-find"}, `fd: renamed "fd" → "fd-find"`},
+		{db.CatalogChange{Slug: "fd", Kind: db.ChangeDescription, Old: "a", New: "b"}, "fd: description changed"},
+		{
+			db.CatalogChange{Slug: "fd", Kind: db.ChangeInstallAdded, Platform: "brew", New: "brew install fd"},
+			"fd: brew install added: brew install fd",
+		},
+		{
+			db.CatalogChange{Slug: "fd", Kind: db.ChangeInstallRemoved, Platform: "apt", Old: "apt install fd-find"},
+			"fd: apt install removed",
+		},
+		{
+			db.CatalogChange{Slug: "fd", Kind: db.ChangeTagline, New: strings.Repeat("x", 100)},
+			`fd: tagline "" → "` + strings.Repeat("x", changeValueWidth-3) + `..."`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := DescribeChange(tt.change); got != tt.want {
+			t.Errorf("DescribeChange(%+v) = %q, want %q", tt.change, got, tt.want)
+		}
+	}
+}
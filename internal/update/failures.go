@@ -0,0 +1,166 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"troveler/crawler"
+	"troveler/db"
+	"troveler/internal/source"
+)
+
+// Classify returns the class of a fetch error, one of the db.Failure*
+// constants.
+func Classify(err error) string {
+	var status *crawler.StatusError
+	var parseErr *crawler.ParseError
+	var timeout interface{ Timeout() bool }
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &status):
+		return db.FailureHTTP
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &timeout) && timeout.Timeout()):
+		return db.FailureTimeout
+	case errors.Is(err, crawler.ErrNoJSONLD):
+		return db.FailureNoJSONLD
+	case errors.As(err, &parseErr):
+		return db.FailureParse
+	case errors.As(err, &netErr):
+		return db.FailureNetwork
+	default:
+		return db.FailureOther
+	}
+}
+
+// Job is a tool to fetch from a source.
+type Job struct {
+	Source source.Source
+	Slug   string
+}
+
+// PlanTargets returns the jobs that refetch just the given slugs and, with
+// retryFailed, the tools recorded as failed by earlier updates. Only sources
+// that one of the tools may come from are listed; a given slug no source
+// lists is an error, while a failed tool that is no longer listed is staged
+// for removal, which also clears its failure.
+func PlanTargets(
+	ctx context.Context, database *db.SQLiteDB, sources []source.Source, slugs []string, retryFailed bool,
+	staging *Staging,
+) ([]Job, error) {
+	wanted, err := wantedTargets(ctx, database, sources, slugs, retryFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []Job
+	for _, src := range sources {
+		if !wantsSource(wanted, src.Name()) {
+			continue
+		}
+		refs, _, err := src.List(ctx, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src.Name(), err)
+		}
+		for _, ref := range refs {
+			from := wanted[ref.Slug]
+			switch {
+			case from[""]:
+				delete(wanted, ref.Slug)
+			case from[src.Name()]:
+				delete(from, src.Name())
+			default:
+				continue
+			}
+			jobs = append(jobs, Job{Source: src, Slug: ref.Slug})
+		}
+	}
+
+	if err := stageUnlisted(wanted, staging); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// wantedTargets maps each slug to refetch to the sources it failed in, ""
+// standing for any source. A failure in a source not given is left alone.
+func wantedTargets(
+	ctx context.Context, database *db.SQLiteDB, sources []source.Source, slugs []string, retryFailed bool,
+) (map[string]map[string]bool, error) {
+	wanted := make(map[string]map[string]bool)
+	for _, slug := range slugs {
+		wanted[slug] = map[string]bool{"": true}
+	}
+	if !retryFailed {
+		return wanted, nil
+	}
+
+	failures, err := database.ListUpdateFailures(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range failures {
+		if wanted[f.Slug][""] || !hasSource(sources, f.Source) {
+			continue
+		}
+		if wanted[f.Slug] == nil {
+			wanted[f.Slug] = make(map[string]bool)
+		}
+		wanted[f.Slug][f.Source] = true
+	}
+
+	return wanted, nil
+}
+
+// stageUnlisted handles the wanted tools no source listed: a given slug is an
+// error, a failed tool is staged for removal from the source it failed in.
+func stageUnlisted(wanted map[string]map[string]bool, staging *Staging) error {
+	var unknown []string
+	vanished := make(map[string][]string) // source -> slugs
+	for slug, from := range wanted {
+		for src := range from {
+			if src == "" {
+				unknown = append(unknown, slug)
+			} else {
+				vanished[src] = append(vanished[src], slug)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		return fmt.Errorf("not listed by any source: %s", strings.Join(unknown, ", "))
+	}
+	for src, slugs := range vanished {
+		sort.Strings(slugs)
+		staging.Remove(src, slugs)
+	}
+
+	return nil
+}
+
+func hasSource(sources []source.Source, name string) bool {
+	for _, src := range sources {
+		if src.Name() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wantsSource reports whether a wanted slug may come from the source name.
+func wantsSource(wanted map[string]map[string]bool, name string) bool {
+	for _, from := range wanted {
+		if from[""] || from[name] {
+			return true
+		}
+	}
+
+	return false
+}
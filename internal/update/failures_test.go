@@ -0,0 +1,137 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"troveler/crawler"
+	"troveler/db"
+	"troveler/internal/source"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"http status", fmt.Errorf("fetch: %w", &crawler.StatusError{StatusCode: 404}), db.FailureHTTP},
+		{"deadline", fmt.Errorf("fetch: %w", context.DeadlineExceeded), db.FailureTimeout},
+		{"timeout", os.ErrDeadlineExceeded, db.FailureTimeout},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, db.FailureNetwork},
+		{"no json-ld", &crawler.ParseError{Inner: crawler.ErrNoJSONLD}, db.FailureNoJSONLD},
+		{"parse", crawler.NewParseError("no SoftwareApplication in JSON-LD"), db.FailureParse},
+		{"other", errors.New("boom"), db.FailureOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchAndUpdateRetryFailed(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	ctx := context.Background()
+
+	var fetched []string
+	healthy := false
+	src := &stubSource{
+		slugs: []string{"bat", "fd", "jq"},
+		fetch: func(_ context.Context, slug string) (*source.Entry, error) {
+			fetched = append(fetched, slug)
+			if slug != "bat" && !healthy {
+				return nil, &crawler.StatusError{StatusCode: 503}
+			}
+
+			return stubEntry(slug), nil
+		},
+	}
+	svc := NewServiceWithSources(database, src)
+	if err := svc.FetchAndUpdate(ctx, Options{}); err != nil {
+		t.Fatalf("FetchAndUpdate failed: %v", err)
+	}
+
+	failures, err := database.ListUpdateFailures(ctx)
+	if err != nil || len(failures) != 2 || failures[0].Class != db.FailureHTTP || failures[0].Source != "stub" {
+		t.Fatalf("expected fd and jq recorded as http failures, got %+v (%v)", failures, err)
+	}
+
+	// jq vanished upstream: retrying drops it instead of fetching it.
+	healthy = true
+	src.slugs = []string{"bat", "fd"}
+	fetched = nil
+	if err := svc.FetchAndUpdate(ctx, Options{RetryFailed: true}); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if strings.Join(fetched, ",") != "fd" {
+		t.Errorf("retry fetched %v, want only fd", fetched)
+	}
+	if failures, err = database.ListUpdateFailures(ctx); err != nil || len(failures) != 0 {
+		t.Errorf("retry must clear failures, got %+v (%v)", failures, err)
+	}
+
+	fetched = nil
+	if err := svc.FetchAndUpdate(ctx, Options{Slugs: []string{"bat"}}); err != nil {
+		t.Fatalf("refetching bat failed: %v", err)
+	}
+	if strings.Join(fetched, ",") != "bat" {
+		t.Errorf("targeted update fetched %v, want only bat", fetched)
+	}
+	if err := svc.FetchAndUpdate(ctx, Options{Slugs: []string{"nope"}}); err == nil ||
+		!strings.Contains(err.Error(), "nope") {
+		t.Errorf("expected an error naming the unknown slug, got %v", err)
+	}
+}
+
+// namedSource is a stubSource under another name.
+type namedSource struct {
+	*stubSource
+	name string
+}
+
+func (s namedSource) Name() string { return s.name }
+
+func TestPlanTargetsRetriesEachSource(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	ctx := context.Background()
+
+	if _, _, err := database.ApplyCatalogRefresh(ctx, &db.CatalogRefresh{Failed: []db.FailedRow{
+		{Slug: "bat", Source: "alpha", Class: db.FailureHTTP},
+		{Slug: "bat", Source: "beta", Class: db.FailureHTTP},
+	}}); err != nil {
+		t.Fatalf("ApplyCatalogRefresh failed: %v", err)
+	}
+
+	sources := []source.Source{
+		namedSource{&stubSource{slugs: []string{"bat"}}, "alpha"},
+		namedSource{&stubSource{slugs: []string{"bat"}}, "beta"},
+	}
+	jobs, err := PlanTargets(ctx, database, sources, nil, true, &Staging{})
+	if err != nil {
+		t.Fatalf("PlanTargets failed: %v", err)
+	}
+	var got []string
+	for _, job := range jobs {
+		got = append(got, job.Source.Name()+"/"+job.Slug)
+	}
+	if strings.Join(got, ",") != "alpha/bat,beta/bat" {
+		t.Errorf("jobs = %v, want bat retried in alpha and beta", got)
+	}
+}
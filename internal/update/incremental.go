@@ -0,0 +1,96 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"troveler/db"
+	"troveler/internal/source"
+)
+
+// ChangeSet partitions a source listing against the local catalog.
+type ChangeSet struct {
+	New       []string // slugs not yet in the database
+	Changed   []string // slugs whose fingerprint differs (or that were removed and came back)
+	Unchanged []string // slugs that can be skipped
+	Removed   []string // slugs in the database that no longer appear upstream
+}
+
+// ToFetch returns the slugs whose detail pages must be fetched.
+func (c ChangeSet) ToFetch() []string {
+	slugs := make([]string, 0, len(c.New)+len(c.Changed))
+	slugs = append(slugs, c.New...)
+
+	return append(slugs, c.Changed...)
+}
+
+// Summary returns a short human-readable description of the change set.
+func (c ChangeSet) Summary() string {
+	return fmt.Sprintf("%d new, %d changed, %d unchanged, %d removed",
+		len(c.New), len(c.Changed), len(c.Unchanged), len(c.Removed))
+}
+
+// DiffCatalog compares a source listing with the stored catalog state of that
+// source. complete must be false when refs is only a subset of the source
+// (e.g. --limit); removal detection is skipped then, since absent slugs may
+// simply lie beyond the limit.
+func DiffCatalog(refs []source.Ref, state map[string]db.CatalogEntry, complete bool) ChangeSet {
+	var cs ChangeSet
+	seen := make(map[string]bool, len(refs))
+
+	for _, ref := range refs {
+		if ref.Slug == "" || seen[ref.Slug] {
+			continue
+		}
+		seen[ref.Slug] = true
+
+		entry, ok := state[ref.Slug]
+		switch {
+		case !ok:
+			cs.New = append(cs.New, ref.Slug)
+		case entry.Removed || entry.SourceHash != ref.Fingerprint:
+			cs.Changed = append(cs.Changed, ref.Slug)
+		default:
+			cs.Unchanged = append(cs.Unchanged, ref.Slug)
+		}
+	}
+
+	if complete {
+		for slug, entry := range state {
+			if !seen[slug] && !entry.Removed {
+				cs.Removed = append(cs.Removed, slug)
+			}
+		}
+		sort.Strings(cs.Removed)
+	}
+
+	return cs
+}
+
+// PlanIncremental loads the stored catalog state of sourceName and diffs refs
+// against it. A complete listing without a single tool, while the source has
+// tools stored, is refused: it is far likelier a broken response than the
+// source emptied, and would mark the whole catalog removed.
+func PlanIncremental(
+	ctx context.Context, database *db.SQLiteDB, sourceName string, refs []source.Ref, complete bool,
+) (ChangeSet, error) {
+	state, err := database.GetCatalogState(ctx, sourceName)
+	if err != nil {
+		return ChangeSet{}, fmt.Errorf("load catalog state: %w", err)
+	}
+
+	if complete && len(refs) == 0 {
+		stored := 0
+		for _, entry := range state {
+			if !entry.Removed {
+				stored++
+			}
+		}
+		if stored > 0 {
+			return ChangeSet{}, fmt.Errorf("listed no tools while %d are stored, refusing to mark them removed", stored)
+		}
+	}
+
+	return DiffCatalog(refs, state, complete), nil
+}
@@ -0,0 +1,54 @@
+package update
+
+import (
+	"reflect"
+	"testing"
+
+	"troveler/db"
+	"troveler/internal/source"
+)
+
+func TestDiffCatalog(t *testing.T) {
+	same := source.Ref{Slug: "same", Fingerprint: "h-same"}
+	changed := source.Ref{Slug: "changed", Fingerprint: "h-changed"}
+	revived := source.Ref{Slug: "revived", Fingerprint: "h-revived"}
+	fresh := source.Ref{Slug: "fresh", Fingerprint: "h-fresh"}
+
+	state := map[string]db.CatalogEntry{
+		"same":    {ID: "1", SourceHash: same.Fingerprint},
+		"changed": {ID: "2", SourceHash: "stale"},
+		"revived": {ID: "3", SourceHash: revived.Fingerprint, Removed: true},
+		"gone":    {ID: "4", SourceHash: "whatever"},
+		"ghost":   {ID: "5", SourceHash: "whatever", Removed: true},
+	}
+
+	refs := []source.Ref{same, changed, revived, fresh, fresh}
+
+	cs := DiffCatalog(refs, state, true)
+
+	if !reflect.DeepEqual(cs.New, []string{"fresh"}) {
+		t.Errorf("New = %v, want [fresh]", cs.New)
+	}
+	if !reflect.DeepEqual(cs.Changed, []string{"changed", "revived"}) {
+		t.Errorf("Changed = %v, want [changed revived]", cs.Changed)
+	}
+	if !reflect.DeepEqual(cs.Unchanged, []string{"same"}) {
+		t.Errorf("Unchanged = %v, want [same]", cs.Unchanged)
+	}
+	if !reflect.DeepEqual(cs.Removed, []string{"gone"}) {
+		t.Errorf("Removed = %v, want [gone]", cs.Removed)
+	}
+	if got := cs.ToFetch(); !reflect.DeepEqual(got, []string{"fresh", "changed", "revived"}) {
+		t.Errorf("ToFetch() = %v", got)
+	}
+}
+
+func TestDiffCatalog_IncompleteSkipsRemoval(t *testing.T) {
+	state := map[string]db.CatalogEntry{"gone": {ID: "1"}}
+
+	cs := DiffCatalog(nil, state, false)
+
+	if len(cs.Removed) != 0 {
+		t.Errorf("expected no removals for a limited crawl, got %v", cs.Removed)
+	}
+}
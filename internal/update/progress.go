@@ -0,0 +1,64 @@
+package update
+
+import (
+	"encoding/json"
+
+	"troveler/db"
+)
+
+// progressJSON is the JSON form of a ProgressUpdate. Counts are only set on
+// "progress" and "complete" events, the rest only on "complete".
+type progressJSON struct {
+	Type      string              `json:"type"`
+	Slug      string              `json:"slug,omitempty"`
+	Processed *int                `json:"processed,omitempty"`
+	Total     *int                `json:"total,omitempty"`
+	Message   string              `json:"message,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	ElapsedMS *int64              `json:"elapsed_ms,omitempty"`
+	Failed    *[]failedJSON       `json:"failed,omitempty"`
+	Changes   *[]db.CatalogChange `json:"changes,omitempty"`
+}
+
+type failedJSON struct {
+	Slug   string `json:"slug"`
+	Source string `json:"source"`
+	Class  string `json:"class"`
+	Error  string `json:"error"`
+}
+
+// MarshalJSON encodes u as a line of `troveler update --progress=jsonl`. On
+// "complete", processed is the number of tools saved and total the number
+// found; failed and changes are always present.
+func (u ProgressUpdate) MarshalJSON() ([]byte, error) {
+	out := progressJSON{Type: u.Type, Slug: u.Slug, Message: u.Message}
+	if u.Error != nil {
+		out.Error = u.Error.Error()
+	}
+
+	switch u.Type {
+	case "progress":
+		out.Processed, out.Total = &u.Processed, &u.Total
+	case "complete":
+		out.Processed, out.Total = &u.Processed, &u.Total
+		elapsed := u.Elapsed.Milliseconds()
+		out.ElapsedMS = &elapsed
+
+		failed := make([]failedJSON, len(u.Failed))
+		for i, row := range u.Failed {
+			failed[i] = failedJSON{Slug: row.Slug, Source: row.Source, Class: row.Class}
+			if row.Err != nil {
+				failed[i].Error = row.Err.Error()
+			}
+		}
+		out.Failed = &failed
+
+		changes := u.Changes
+		if changes == nil {
+			changes = []db.CatalogChange{}
+		}
+		out.Changes = &changes
+	}
+
+	return json.Marshal(out)
+}
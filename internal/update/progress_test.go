@@ -0,0 +1,64 @@
+package update
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"troveler/db"
+)
+
+func TestProgressUpdateMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		upd  ProgressUpdate
+		want string
+	}{
+		{
+			name: "start",
+			upd:  ProgressUpdate{Type: "start", Message: "Fetching tools from terminaltrove..."},
+			want: `{"type":"start","message":"Fetching tools from terminaltrove..."}`,
+		},
+		{
+			name: "slug",
+			upd:  ProgressUpdate{Type: "slug", Slug: "bat"},
+			want: `{"type":"slug","slug":"bat"}`,
+		},
+		{
+			name: "progress",
+			upd:  ProgressUpdate{Type: "progress", Processed: 0, Total: 3},
+			want: `{"type":"progress","processed":0,"total":3}`,
+		},
+		{
+			name: "error",
+			upd:  ProgressUpdate{Type: "error", Error: errors.New("terminaltrove: status 503")},
+			want: `{"type":"error","error":"terminaltrove: status 503"}`,
+		},
+		{
+			name: "complete",
+			upd: ProgressUpdate{
+				Type: "complete", Processed: 1, Total: 2, Message: "Update complete! Saved 1 tools, 1 failed.",
+				Failed: []db.FailedRow{
+					{Slug: "fd", Source: "terminaltrove", Class: db.FailureHTTP, Err: errors.New("status 500")},
+				},
+				Elapsed: 1500 * time.Millisecond,
+			},
+			want: `{"type":"complete","processed":1,"total":2,` +
+				`"message":"Update complete! Saved 1 tools, 1 failed.","elapsed_ms":1500,` +
+				`"failed":[{"slug":"fd","source":"terminaltrove","class":"http","error":"status 500"}],"changes":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.upd)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,205 +4,233 @@ package update
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"troveler/crawler"
 	"troveler/db"
+	"troveler/internal/source"
 )
 
 // Service handles database updates
 type Service struct {
 	db      *db.SQLiteDB
-	fetcher *crawler.Fetcher
+	sources []source.Source
 }
 
-// NewService creates a new update service
-func NewService(database *db.SQLiteDB) *Service {
+// NewService creates a new update service for terminaltrove.com. fetcherOpts
+// configure the underlying crawler.Fetcher (e.g. crawler.WithCacheDir).
+func NewService(database *db.SQLiteDB, fetcherOpts ...crawler.FetcherOption) *Service {
+	return NewServiceWithSources(database, source.NewTerminalTrove(crawler.NewFetcher(fetcherOpts...)))
+}
+
+// NewServiceWithSources creates an update service that refreshes sources.
+func NewServiceWithSources(database *db.SQLiteDB, sources ...source.Source) *Service {
 	return &Service{
 		db:      database,
-		fetcher: crawler.NewFetcher(),
+		sources: sources,
 	}
 }
 
 // ProgressUpdate represents an update progress event
 type ProgressUpdate struct {
-	Type      string // "start", "slug", "progress", "complete", "error"
-	Slug      string // Tool slug being processed
-	Processed int    // Number of tools processed
-	Total     int    // Total number of tools
-	Message   string // Status message
-	Error     error  // Error if any
+	Type      string             // "start", "slug", "progress", "complete", "error"
+	Slug      string             // Tool slug being processed
+	Processed int                // Number of tools processed
+	Total     int                // Total number of tools
+	Message   string             // Status message
+	Error     error              // Error if any
+	Failed    []db.FailedRow     // On "complete", the tools that could not be refreshed
+	Changes   []db.CatalogChange // On "complete", what the update changed in the catalog
+	Elapsed   time.Duration      // On "complete", how long the update took
 }
 
 // Options configures the update
 type Options struct {
-	Limit    int                   // Limit number of tools (0 = all)
-	Progress chan<- ProgressUpdate // Channel for progress updates
+	Limit       int                   // Limit number of tools per source (0 = all)
+	Incremental bool                  // Only fetch detail pages for new or changed tools
+	Source      string                // Only refresh the source with this name ("" = all)
+	Slugs       []string              // Only refetch these tools
+	RetryFailed bool                  // Only refetch the tools earlier updates failed to refresh
+	Progress    chan<- ProgressUpdate // Channel for progress updates
 }
 
-// FetchAndUpdate fetches all tools and updates the database
+// FetchAndUpdate fetches all tools and updates the database. Fetched tools
+// are staged and written in a single transaction once every fetch has
+// finished, so a canceled or failed update leaves the database untouched.
+// Tools that could not be fetched or written are reported on the "complete"
+// update instead of failing the whole refresh, together with the catalog
+// changes the update made. opts.Progress, if set, is closed on return.
 func (s *Service) FetchAndUpdate(ctx context.Context, opts Options) error {
-	// Send start message
+	started := time.Now()
 	if opts.Progress != nil {
-		select {
-		case opts.Progress <- ProgressUpdate{Type: "start", Message: "Fetching tools from terminaltrove.com..."}:
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+		defer close(opts.Progress)
 	}
-
-	// Fetch all slugs
-	slugs, total, err := s.fetchSlugs(ctx, opts.Limit)
-	if err != nil {
-		if opts.Progress != nil {
-			opts.Progress <- ProgressUpdate{Type: "error", Error: err}
-		}
+	fail := func(err error) error {
+		sendProgress(ctx, opts.Progress, ProgressUpdate{Type: "error", Error: err})
 
 		return err
 	}
 
-	if len(slugs) == 0 {
-		if opts.Progress != nil {
-			opts.Progress <- ProgressUpdate{Type: "complete", Message: "No tools found"}
-		}
-
-		return nil
+	sources, err := source.Select(s.sources, opts.Source)
+	if err != nil {
+		return fail(err)
 	}
 
-	if opts.Progress != nil {
-		select {
-		case opts.Progress <- ProgressUpdate{
-			Type:    "progress",
-			Total:   len(slugs),
-			Message: fmt.Sprintf("Found %d tools", len(slugs)),
-		}:
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+	names := make([]string, len(sources))
+	for i, src := range sources {
+		names[i] = src.Name()
+	}
+	if !sendProgress(ctx, opts.Progress, ProgressUpdate{
+		Type:    "start",
+		Message: fmt.Sprintf("Fetching tools from %s...", strings.Join(names, ", ")),
+	}) {
+		return ctx.Err()
 	}
 
-	// Fetch and process details
-	detailChan, errChan := s.fetchDetailsConcurrently(ctx, slugs, opts.Progress)
+	staging := &Staging{}
+	jobs, found, summaries, err := s.planJobs(ctx, sources, opts, staging)
+	if err != nil {
+		return fail(err)
+	}
 
-	// Process details and write to database
-	processed := 0
-	for detail := range detailChan {
-		tool := detail.ToTool()
-		if err := s.db.UpsertTool(ctx, tool); err != nil {
-			continue
+	if found == 0 {
+		if _, err := staging.Apply(ctx, s.db); err != nil {
+			return fail(err)
 		}
+		sendProgress(ctx, opts.Progress, ProgressUpdate{
+			Type: "complete", Message: "No tools found", Elapsed: time.Since(started),
+		})
 
-		installs := detail.ToInstallInstructions()
-		for _, inst := range installs {
-			if err := s.db.UpsertInstallInstruction(ctx, &inst); err != nil {
-				continue
-			}
-		}
+		return nil
+	}
 
-		processed++
-		if opts.Progress != nil {
-			select {
-			case opts.Progress <- ProgressUpdate{
-				Type:      "progress",
-				Processed: processed,
-				Total:     len(slugs),
-			}:
-			case <-ctx.Done():
-				return ctx.Err()
-			}
-		}
+	foundMsg := fmt.Sprintf("Found %d tools", found)
+	if len(summaries) > 0 {
+		foundMsg = fmt.Sprintf("Found %d tools (%s)", found, strings.Join(summaries, "; "))
+	}
+	if !sendProgress(ctx, opts.Progress, ProgressUpdate{Type: "progress", Total: len(jobs), Message: foundMsg}) {
+		return ctx.Err()
 	}
 
-	// Check for errors
-	select {
-	case err := <-errChan:
-		if err != nil && opts.Progress != nil {
-			select {
-			case opts.Progress <- ProgressUpdate{Type: "error", Error: err}:
-			case <-ctx.Done():
-			}
-		}
-	default:
+	// Fetch details into the staging set. The entry channel is closed once
+	// every worker has stopped, so none sends progress after we return.
+	entryChan := s.fetchDetailsConcurrently(ctx, jobs, opts.Progress, staging)
+	for entry := range entryChan {
+		staging.Add(entry)
+		sendProgress(ctx, opts.Progress, ProgressUpdate{
+			Type:      "progress",
+			Processed: staging.Staged(),
+			Total:     len(jobs),
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	if opts.Progress != nil {
-		select {
-		case opts.Progress <- ProgressUpdate{
-			Type:      "complete",
-			Processed: processed,
-			Total:     total,
-			Message:   fmt.Sprintf("Update complete! Saved %d tools.", processed),
-		}:
-		case <-ctx.Done():
-		}
-		close(opts.Progress) // Close channel when done
+	result, err := staging.Apply(ctx, s.db)
+	if err != nil {
+		return fail(err)
+	}
+	// Saved tools may have new install commands, so their installed state is
+	// rescanned here rather than left for a search to find stale.
+	if err := s.db.RefreshInstalled(ctx, staging.Slugs()...); err != nil {
+		return fail(fmt.Errorf("rescan installed tools: %w", err))
 	}
 
+	saved, failed := result.Run.Saved, result.Failed
+	message := fmt.Sprintf("Update complete! Saved %d tools.", saved)
+	if len(failed) > 0 {
+		message = fmt.Sprintf("Update complete! Saved %d tools, %d failed.", saved, len(failed))
+	}
+	sendProgress(ctx, opts.Progress, ProgressUpdate{
+		Type:      "complete",
+		Processed: saved,
+		Total:     found,
+		Message:   message,
+		Failed:    failed,
+		Changes:   result.Run.Changes,
+		Elapsed:   time.Since(started),
+	})
+
 	return nil
 }
 
-// fetchSlugs fetches all tool slugs from search pages
-func (s *Service) fetchSlugs(ctx context.Context, limit int) ([]string, int, error) {
-	initialData, err := s.fetcher.FetchSearchPage(ctx, 1)
-	if err != nil {
-		return nil, 0, fmt.Errorf("initial fetch: %w", err)
+// sendProgress sends upd on progress, if set, unless ctx is done first. It
+// reports whether upd was sent or there was nowhere to send it.
+func sendProgress(ctx context.Context, progress chan<- ProgressUpdate, upd ProgressUpdate) bool {
+	if progress == nil {
+		return true
 	}
 
-	initialResp, err := crawler.ParseSearchResponse(initialData)
-	if err != nil {
-		return nil, 0, fmt.Errorf("parse initial: %w", err)
+	select {
+	case progress <- upd:
+		return true
+	case <-ctx.Done():
+		return false
 	}
+}
 
-	totalTools := int(initialResp.Found)
-	if limit > 0 && limit < totalTools {
-		totalTools = limit
+// planJobs lists every source and returns the tools to fetch, the number of
+// tools found and, for incremental updates, a change summary per source.
+// Tools that vanished upstream are staged for removal. With Slugs or
+// RetryFailed set, only those tools are fetched; see PlanTargets.
+func (s *Service) planJobs(
+	ctx context.Context, sources []source.Source, opts Options, staging *Staging,
+) ([]Job, int, []string, error) {
+	if len(opts.Slugs) > 0 || opts.RetryFailed {
+		jobs, err := PlanTargets(ctx, s.db, sources, opts.Slugs, opts.RetryFailed, staging)
+
+		return jobs, len(jobs), nil, err
 	}
 
-	pageResults, err := s.fetcher.FetchSearchPagesConcurrently(ctx, (totalTools+99)/100)
-	if err != nil {
-		return nil, 0, fmt.Errorf("fetch pages: %w", err)
-	}
+	var jobs []Job
+	var summaries []string
+	found := 0
 
-	var allSlugs []string
-	for i := 1; i <= (totalTools+99)/100; i++ {
-		data, ok := pageResults[i]
-		if !ok {
-			continue
+	for _, src := range sources {
+		refs, _, err := src.List(ctx, opts.Limit)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("%s: %w", src.Name(), err)
 		}
+		found += len(refs)
 
-		resp, err := crawler.ParseSearchResponse(data)
-		if err != nil {
-			continue
+		slugs := make([]string, len(refs))
+		for i, ref := range refs {
+			slugs[i] = ref.Slug
 		}
 
-		for _, item := range resp.Hits {
-			if item.Document.Slug != "" {
-				allSlugs = append(allSlugs, item.Document.Slug)
-				if limit > 0 && len(allSlugs) >= limit {
-					break
-				}
+		if opts.Incremental {
+			changes, err := PlanIncremental(ctx, s.db, src.Name(), refs, opts.Limit == 0)
+			if err != nil {
+				return nil, 0, nil, err
 			}
+			staging.Remove(src.Name(), changes.Removed)
+			slugs = changes.ToFetch()
+			summaries = append(summaries, fmt.Sprintf("%s: %s", src.Name(), changes.Summary()))
 		}
-		if limit > 0 && len(allSlugs) >= limit {
-			break
+
+		for _, slug := range slugs {
+			jobs = append(jobs, Job{Source: src, Slug: slug})
 		}
 	}
 
-	return allSlugs, int(initialResp.Found), nil
+	return jobs, found, summaries, nil
 }
 
-// fetchDetailsConcurrently fetches tool details concurrently
+// fetchDetailsConcurrently fetches tool details concurrently. Tools that
+// fail to fetch are recorded in staging.
 func (s *Service) fetchDetailsConcurrently(
-	ctx context.Context, slugs []string, progress chan<- ProgressUpdate,
-) (<-chan crawler.DetailPage, <-chan error) {
-	detailChan := make(chan crawler.DetailPage, 100)
-	errChan := make(chan error, 1)
+	ctx context.Context, jobs []Job, progress chan<- ProgressUpdate, staging *Staging,
+) <-chan *source.Entry {
+	entryChan := make(chan *source.Entry, 100)
 
-	slugChan := make(chan string, len(slugs))
-	for _, slug := range slugs {
-		slugChan <- slug
+	jobChan := make(chan Job, len(jobs))
+	for _, j := range jobs {
+		jobChan <- j
 	}
-	close(slugChan)
+	close(jobChan)
 
 	workerCount := 5
 	var wg sync.WaitGroup
@@ -211,31 +239,22 @@ func (s *Service) fetchDetailsConcurrently(
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for slug := range slugChan {
-				data, err := s.fetcher.FetchDetailPage(ctx, slug)
+			for j := range jobChan {
+				entry, err := j.Source.Fetch(ctx, j.Slug)
 				if err != nil {
-					continue
-				}
+					if ctx.Err() == nil {
+						staging.Fail(j.Source.Name(), j.Slug, err)
+					}
 
-				detail, err := crawler.ParseDetailPage(data)
-				if err != nil {
 					continue
 				}
 
-				// Send slug progress update (safely)
-				if progress != nil {
-					select {
-					case progress <- ProgressUpdate{
-						Type: "slug",
-						Slug: slug,
-					}:
-					case <-ctx.Done():
-						return
-					}
+				if !sendProgress(ctx, progress, ProgressUpdate{Type: "slug", Slug: j.Slug}) {
+					return
 				}
 
 				select {
-				case detailChan <- *detail:
+				case entryChan <- entry:
 				case <-ctx.Done():
 					return
 				}
@@ -245,9 +264,8 @@ func (s *Service) fetchDetailsConcurrently(
 
 	go func() {
 		wg.Wait()
-		close(detailChan)
-		close(errChan)
+		close(entryChan)
 	}()
 
-	return detailChan, errChan
+	return entryChan
 }
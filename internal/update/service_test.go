@@ -0,0 +1,361 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"troveler/crawler"
+	"troveler/db"
+	"troveler/internal/source"
+)
+
+func writeFixture(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+}
+
+func TestFetchAndUpdateFromFixtures(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	dir := t.TempDir()
+	writeFixture(t, dir, "search/page-1.json",
+		`{"found":1,"page":1,"hits":[{"document":{"slug":"bat","name":"bat","tool_of_the_week":true}}]}`)
+	writeFixture(t, dir, "detail/bat.html", `<html><head>
+<script type="application/ld+json">{"@graph":[{"@type":"SoftwareApplication","name":"bat","programmingLanguage":"rust"}]}</script>
+</head><body>
+<p id="tagline">A cat clone with wings</p>
+<div id="install" data-install="{&quot;brew&quot;: &quot;brew install bat&quot;}"></div>
+</body></html>`)
+
+	svc := NewService(database, crawler.WithReplayDir(dir))
+	if err := svc.FetchAndUpdate(context.Background(), Options{}); err != nil {
+		t.Fatalf("FetchAndUpdate failed: %v", err)
+	}
+
+	tools, err := database.GetToolBySlug("bat")
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("GetToolBySlug: got %d tools, err %v", len(tools), err)
+	}
+	tool := tools[0]
+	if tool.Tagline != "A cat clone with wings" || !tool.ToolOfTheWeek {
+		t.Errorf("unexpected tool: tagline=%q totw=%v", tool.Tagline, tool.ToolOfTheWeek)
+	}
+
+	insts, err := database.GetInstallInstructions(tool.ID)
+	if err != nil {
+		t.Fatalf("GetInstallInstructions failed: %v", err)
+	}
+	if len(insts) != 1 || insts[0].Command != "brew install bat" {
+		t.Errorf("unexpected install instructions: %+v", insts)
+	}
+}
+
+func TestFetchAndUpdateSingleSourceIncremental(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	ctx := context.Background()
+
+	bat := &db.Tool{ID: "tool-bat", Slug: "bat", Name: "bat"}
+	if err := database.UpsertTool(ctx, bat); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	catalog := filepath.Join(dir, "team.toml")
+	writeFixture(t, dir, "team.toml", "[[tools]]\nname = \"deployctl\"\n[[tools]]\nname = \"vault-login\"\n")
+
+	// The terminaltrove source replays an empty fixture dir, so any attempt
+	// to refresh it would fail the update.
+	svc := NewServiceWithSources(database,
+		source.NewTerminalTrove(crawler.NewFetcher(crawler.WithReplayDir(t.TempDir()))),
+		source.NewFile("team", catalog),
+	)
+
+	if err := svc.FetchAndUpdate(ctx, Options{Source: "team", Incremental: true}); err != nil {
+		t.Fatalf("FetchAndUpdate failed: %v", err)
+	}
+
+	tools, err := database.GetToolBySlug("deployctl")
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("GetToolBySlug: got %d tools, err %v", len(tools), err)
+	}
+	if tools[0].Source != "team" {
+		t.Errorf("Source = %q, want team", tools[0].Source)
+	}
+
+	writeFixture(t, dir, "team.toml", "[[tools]]\nname = \"deployctl\"\n")
+	if err := svc.FetchAndUpdate(ctx, Options{Source: "team", Incremental: true}); err != nil {
+		t.Fatalf("second FetchAndUpdate failed: %v", err)
+	}
+
+	state, err := database.GetCatalogState(ctx, "team")
+	if err != nil {
+		t.Fatalf("GetCatalogState failed: %v", err)
+	}
+	if !state["vault-login"].Removed || state["deployctl"].Removed {
+		t.Errorf("expected only vault-login removed, got %+v", state)
+	}
+
+	tools, err = database.GetToolBySlug("bat")
+	if err != nil || len(tools) != 1 || tools[0].Removed {
+		t.Errorf("terminaltrove tool must be untouched by a team refresh: %+v (err %v)", tools, err)
+	}
+
+	if err := svc.FetchAndUpdate(ctx, Options{Source: "nope"}); err == nil {
+		t.Error("expected error for unknown source")
+	}
+}
+
+// stubSource lists slugs and fetches them with fetch.
+type stubSource struct {
+	slugs []string
+	fetch func(ctx context.Context, slug string) (*source.Entry, error)
+}
+
+func (s *stubSource) Name() string { return "stub" }
+
+func (s *stubSource) List(_ context.Context, _ int) ([]source.Ref, int, error) {
+	refs := make([]source.Ref, len(s.slugs))
+	for i, slug := range s.slugs {
+		refs[i] = source.Ref{Slug: slug, Fingerprint: slug}
+	}
+
+	return refs, len(refs), nil
+}
+
+func (s *stubSource) Fetch(ctx context.Context, slug string) (*source.Entry, error) {
+	return s.fetch(ctx, slug)
+}
+
+func stubEntry(slug string) *source.Entry {
+	return &source.Entry{
+		Tool:     &db.Tool{ID: "new-" + slug, Slug: slug, Name: slug, Tagline: "refreshed", Source: "stub"},
+		Installs: []db.InstallInstruction{{ID: "inst-" + slug, Platform: "brew", Command: "brew install " + slug}},
+	}
+}
+
+func TestFetchAndUpdateReportsFailedTools(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	src := &stubSource{
+		slugs: []string{"bat", "broken"},
+		fetch: func(_ context.Context, slug string) (*source.Entry, error) {
+			if slug == "broken" {
+				return nil, errors.New("HTTP 500")
+			}
+
+			return stubEntry(slug), nil
+		},
+	}
+	progress := make(chan ProgressUpdate, 100)
+	if err := NewServiceWithSources(database, src).FetchAndUpdate(context.Background(),
+		Options{Progress: progress}); err != nil {
+		t.Fatalf("FetchAndUpdate failed: %v", err)
+	}
+
+	var complete ProgressUpdate
+	for upd := range progress {
+		if upd.Type == "complete" {
+			complete = upd
+		}
+	}
+	if complete.Processed != 1 || len(complete.Failed) != 1 || complete.Failed[0].Slug != "broken" {
+		t.Fatalf("unexpected complete update: %+v", complete)
+	}
+	if msg := complete.Failed[0].Err.Error(); msg != "HTTP 500" {
+		t.Errorf("failure reason = %q", msg)
+	}
+	if len(complete.Changes) != 1 || complete.Changes[0].Slug != "bat" || complete.Changes[0].Kind != db.ChangeAdded {
+		t.Errorf("expected bat reported as added, got %+v", complete.Changes)
+	}
+
+	if tools, err := database.GetToolBySlug("bat"); err != nil || len(tools) != 1 {
+		t.Errorf("expected bat to be saved, got %+v (%v)", tools, err)
+	}
+}
+
+func TestFetchAndUpdateCanceledLeavesDatabase(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bat := &db.Tool{ID: "tool-bat", Slug: "bat", Name: "bat", Source: "stub"}
+	if err := database.UpsertTool(ctx, bat); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+
+	src := &stubSource{
+		slugs: []string{"bat", "fd"},
+		fetch: func(ctx context.Context, slug string) (*source.Entry, error) {
+			if slug == "fd" {
+				cancel()
+
+				return nil, ctx.Err()
+			}
+
+			return stubEntry(slug), nil
+		},
+	}
+	err = NewServiceWithSources(database, src).FetchAndUpdate(ctx, Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	tools, err := database.GetToolBySlug("bat")
+	if err != nil || len(tools) != 1 || tools[0].Tagline != "" {
+		t.Errorf("canceled update must not write bat: %+v (%v)", tools, err)
+	}
+}
+
+func TestFetchAndUpdateMalformedSearchPageRemovesNothing(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	ctx := context.Background()
+
+	fd := &db.Tool{ID: "tool-fd", Slug: "fd", Name: "fd", Source: db.DefaultSource}
+	if err := database.UpsertTool(ctx, fd); err != nil {
+		t.Fatalf("UpsertTool failed: %v", err)
+	}
+
+	// fd is listed on page 2, which is truncated.
+	dir := t.TempDir()
+	writeFixture(t, dir, "search/page-1.json",
+		`{"found":101,"page":1,"hits":[{"document":{"slug":"bat","name":"bat"}}]}`)
+	writeFixture(t, dir, "search/page-2.json", `{"found":101,"page":2,"hits":[{"docu`)
+
+	svc := NewService(database, crawler.WithReplayDir(dir))
+	if err := svc.FetchAndUpdate(ctx, Options{Incremental: true}); err == nil {
+		t.Fatal("expected the malformed search page to fail the update")
+	}
+
+	state, err := database.GetCatalogState(ctx, db.DefaultSource)
+	if err != nil {
+		t.Fatalf("GetCatalogState failed: %v", err)
+	}
+	if state["fd"].Removed {
+		t.Error("fd must not be marked removed after an incomplete listing")
+	}
+}
+
+func TestFetchAndUpdateEmptyListingRemovesNothing(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	ctx := context.Background()
+
+	for _, tool := range []*db.Tool{
+		{ID: "tool-bat", Slug: "bat", Name: "bat", Source: db.DefaultSource},
+		{ID: "tool-fd", Slug: "fd", Name: "fd", Source: db.DefaultSource},
+	} {
+		if err := database.UpsertTool(ctx, tool); err != nil {
+			t.Fatalf("UpsertTool failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		pages map[string]string
+	}{
+		{name: "found nothing", pages: map[string]string{
+			"search/page-1.json": `{"found":0,"page":1,"hits":[]}`,
+		}},
+		{name: "pages without hits", pages: map[string]string{
+			"search/page-1.json": `{"found":150,"page":1,"hits":[{"document":{"slug":"bat","name":"bat"}}]}`,
+			"search/page-2.json": `{"found":150,"page":2,"hits":[]}`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for rel, content := range tt.pages {
+				writeFixture(t, dir, rel, content)
+			}
+
+			svc := NewService(database, crawler.WithReplayDir(dir))
+			if err := svc.FetchAndUpdate(ctx, Options{Incremental: true}); err == nil {
+				t.Fatal("expected the empty listing to fail the update")
+			}
+
+			state, err := database.GetCatalogState(ctx, db.DefaultSource)
+			if err != nil {
+				t.Fatalf("GetCatalogState failed: %v", err)
+			}
+			for _, slug := range []string{"bat", "fd"} {
+				if state[slug].Removed {
+					t.Errorf("%s must not be marked removed after an empty listing", slug)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchAndUpdateClosesProgress(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	svc := NewServiceWithSources(database, &stubSource{})
+	tests := []struct {
+		name    string
+		opts    Options
+		want    string
+		wantErr bool
+	}{
+		{name: "unknown source", opts: Options{Source: "nope"}, want: "error", wantErr: true},
+		{name: "no tools", want: "start,complete"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := make(chan ProgressUpdate)
+			tt.opts.Progress = progress
+			done := make(chan error, 1)
+			go func() { done <- svc.FetchAndUpdate(context.Background(), tt.opts) }()
+
+			// Ranging ends only if FetchAndUpdate closes the channel.
+			var types []string
+			for upd := range progress {
+				types = append(types, upd.Type)
+			}
+			if err := <-done; (err != nil) != tt.wantErr {
+				t.Errorf("FetchAndUpdate error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.Join(types, ","); got != tt.want {
+				t.Errorf("events = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
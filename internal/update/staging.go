@@ -0,0 +1,115 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"troveler/db"
+	"troveler/internal/source"
+)
+
+// Staging collects the result of an update before anything is written:
+// fetched tools, slugs that vanished upstream and tools that failed. Apply
+// then swaps it into the database at once, so an interrupted update leaves
+// the catalog as it was. Staging is safe for concurrent use.
+type Staging struct {
+	mu      sync.Mutex
+	refresh db.CatalogRefresh
+}
+
+// Result is how an applied update went.
+type Result struct {
+	Run    *db.UpdateRun  // the recorded run: counts and catalog changes
+	Failed []db.FailedRow // tools not refreshed, at fetch or write time, by slug
+}
+
+// Add stages a fetched tool.
+func (s *Staging) Add(entry *source.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refresh.Tools = append(s.refresh.Tools, db.StagedTool{Tool: entry.Tool, Installs: entry.Installs})
+}
+
+// Remove stages slugs of src to mark as removed upstream.
+func (s *Staging) Remove(src string, slugs []string) {
+	if len(slugs) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.refresh.Removed == nil {
+		s.refresh.Removed = make(map[string][]string)
+	}
+	s.refresh.Removed[src] = append(s.refresh.Removed[src], slugs...)
+}
+
+// Fail records that slug could not be fetched from src, classified by
+// Classify.
+func (s *Staging) Fail(src, slug string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refresh.Failed = append(s.refresh.Failed, db.FailedRow{
+		Slug: slug, Source: src, Class: Classify(err), Err: err,
+	})
+}
+
+// Staged returns the number of tools staged so far.
+func (s *Staging) Staged() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.refresh.Tools)
+}
+
+// Slugs returns the slugs of the tools staged so far.
+func (s *Staging) Slugs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slugs := make([]string, len(s.refresh.Tools))
+	for i, staged := range s.refresh.Tools {
+		slugs[i] = staged.Tool.Slug
+	}
+
+	return slugs
+}
+
+// Apply writes the staged tools and removals in one transaction and records
+// the update with the changes it made.
+func (s *Staging) Apply(ctx context.Context, database *db.SQLiteDB) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, writeFailed, err := database.ApplyCatalogRefresh(ctx, &s.refresh)
+	if err != nil {
+		return nil, fmt.Errorf("apply update: %w", err)
+	}
+
+	failed := append(append([]db.FailedRow(nil), s.refresh.Failed...), writeFailed...)
+	sort.SliceStable(failed, func(i, j int) bool { return failed[i].Slug < failed[j].Slug })
+
+	return &Result{Run: run, Failed: failed}, nil
+}
+
+// FailureSummary describes failed tools one per line, listing at most limit
+// of them.
+func FailureSummary(failed []db.FailedRow, limit int) string {
+	var b strings.Builder
+	for i, row := range failed {
+		if i == limit {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(failed)-limit)
+
+			break
+		}
+		fmt.Fprintf(&b, "  %s (%s): %v\n", row.Slug, row.Class, row.Err)
+	}
+
+	return b.String()
+}
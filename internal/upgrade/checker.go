@@ -0,0 +1,114 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"troveler/db"
+	"troveler/internal/platform"
+)
+
+// DefaultMaxAge is how long a stored version check is reused before the
+// tool is probed and GitHub asked again.
+const DefaultMaxAge = 24 * time.Hour
+
+// defaultWorkers bounds concurrent checks; each one runs local commands and
+// makes one GitHub request.
+const defaultWorkers = 8
+
+// Target is an installed tool to check.
+type Target struct {
+	Tool           db.Tool
+	Installs       []db.InstallInstruction
+	InstallCommand string // the command the tool was installed with, "" if unknown
+}
+
+// Result is the outcome of checking one tool.
+type Result struct {
+	db.ToolVersion
+	Probe  Probe // zero for results reused from the database
+	Cached bool  // reused from a previous check
+	Err    error // upstream lookup failure; a missing release feed is not an error
+}
+
+// Checker compares installed versions with upstream releases.
+type Checker struct {
+	Prober   *Prober
+	Releases *Releases
+	MaxAge   time.Duration // 0 always checks again
+	Workers  int
+}
+
+// NewChecker returns a Checker that probes real commands and asks GitHub,
+// reusing checks younger than DefaultMaxAge.
+func NewChecker() *Checker {
+	return &Checker{Prober: NewProber(), Releases: NewReleases(), MaxAge: DefaultMaxAge, Workers: defaultWorkers}
+}
+
+// Check probes the installed version of t and looks up its latest release.
+func (c *Checker) Check(ctx context.Context, t Target) Result {
+	result := Result{ToolVersion: db.ToolVersion{ToolID: t.Tool.ID, Slug: t.Tool.Slug, CheckedAt: time.Now()}}
+
+	if path := db.InstalledExecutable(t.Installs); path != "" {
+		result.Probe = c.Prober.Installed(ctx, path, platform.ParseInstall(t.InstallCommand))
+		result.Installed = result.Probe.Version
+	}
+
+	latest, err := c.Releases.Latest(ctx, t.Tool.CodeRepository)
+	switch {
+	case errors.Is(err, ErrNoUpstream):
+	case err != nil:
+		result.Err = err
+	default:
+		result.Latest = latest
+	}
+
+	result.Outdated = IsNewer(result.Latest, result.Installed)
+
+	return result
+}
+
+// CheckAll checks targets concurrently, reusing checks from database younger
+// than MaxAge unless refresh is set, and stores every new check. Results are
+// in target order.
+func (c *Checker) CheckAll(ctx context.Context, database *db.SQLiteDB, targets []Target, refresh bool) []Result {
+	cached := map[string]db.ToolVersion{}
+	if !refresh && c.MaxAge > 0 {
+		if versions, err := database.GetToolVersions(); err == nil {
+			cached = versions
+		}
+	}
+
+	results := make([]Result, len(targets))
+	sem := make(chan struct{}, max(c.Workers, 1))
+	var wg sync.WaitGroup
+
+	for i, t := range targets {
+		if v, ok := cached[t.Tool.ID]; ok && time.Since(v.CheckedAt) < c.MaxAge {
+			results[i] = Result{ToolVersion: v, Cached: true}
+
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = c.Check(ctx, t)
+		}(i, t)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if results[i].Cached || results[i].Err != nil {
+			continue
+		}
+		_ = database.SaveToolVersion(&results[i].ToolVersion)
+	}
+
+	return results
+}
@@ -0,0 +1,108 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// githubAPI is the default GitHub REST endpoint.
+const githubAPI = "https://api.github.com"
+
+// ErrNoUpstream is returned for tools whose code repository has no release
+// feed troveler can read.
+var ErrNoUpstream = errors.New("no upstream release information")
+
+// Releases looks up the latest release of a tool's code repository.
+type Releases struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// NewReleases returns a GitHub release client. GITHUB_TOKEN, when set, is
+// sent to raise the API rate limit.
+func NewReleases() *Releases {
+	return &Releases{
+		client:  &http.Client{Timeout: 15 * time.Second},
+		baseURL: githubAPI,
+		token:   os.Getenv("GITHUB_TOKEN"),
+	}
+}
+
+// NewReleasesWithBaseURL returns a client talking to baseURL instead of
+// api.github.com, for tests and GitHub Enterprise.
+func NewReleasesWithBaseURL(baseURL string) *Releases {
+	r := NewReleases()
+	r.baseURL = strings.TrimRight(baseURL, "/")
+
+	return r
+}
+
+var githubRepoPattern = regexp.MustCompile(`(?i)^(?:https?://|git@)?(?:www\.)?github\.com[/:]([^/]+)/([^/#?]+)`)
+
+// GitHubRepo returns the owner and name of a GitHub repository URL.
+func GitHubRepo(repoURL string) (string, string, bool) {
+	m := githubRepoPattern.FindStringSubmatch(strings.TrimSpace(repoURL))
+	if m == nil {
+		return "", "", false
+	}
+
+	return m[1], strings.TrimSuffix(m[2], ".git"), true
+}
+
+// Latest returns the version of the newest published release of repoURL.
+func (r *Releases) Latest(ctx context.Context, repoURL string) (string, error) {
+	owner, name, ok := GitHubRepo(repoURL)
+	if !ok {
+		return "", ErrNoUpstream
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", r.baseURL, owner, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch latest release of %s/%s: %w", owner, name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", ErrNoUpstream
+	default:
+		return "", fmt.Errorf("fetch latest release of %s/%s: %s", owner, name, resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		Name    string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("parse latest release of %s/%s: %w", owner, name, err)
+	}
+
+	version := ExtractVersion(release.TagName)
+	if version == "" {
+		version = ExtractVersion(release.Name)
+	}
+	if version == "" {
+		return "", ErrNoUpstream
+	}
+
+	return version, nil
+}
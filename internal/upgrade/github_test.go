@@ -0,0 +1,67 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubRepo(t *testing.T) {
+	tests := []struct {
+		url         string
+		owner, name string
+		ok          bool
+	}{
+		{"https://github.com/BurntSushi/ripgrep", "BurntSushi", "ripgrep", true},
+		{"https://github.com/sharkdp/fd.git", "sharkdp", "fd", true},
+		{"git@github.com:junegunn/fzf.git", "junegunn", "fzf", true},
+		{"https://github.com/helix-editor/helix/tree/master", "helix-editor", "helix", true},
+		{"https://gitlab.com/foo/bar", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		owner, name, ok := GitHubRepo(tt.url)
+		if owner != tt.owner || name != tt.name || ok != tt.ok {
+			t.Errorf("GitHubRepo(%q) = %q, %q, %v", tt.url, owner, name, ok)
+		}
+	}
+}
+
+func TestReleasesLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/BurntSushi/ripgrep/releases/latest":
+			_, _ = w.Write([]byte(`{"tag_name": "14.1.0", "name": "14.1.0"}`))
+		case "/repos/junegunn/fzf/releases/latest":
+			_, _ = w.Write([]byte(`{"tag_name": "v0.50.0"}`))
+		case "/repos/rate/limited/releases/latest":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	releases := NewReleasesWithBaseURL(srv.URL)
+	ctx := context.Background()
+
+	if v, err := releases.Latest(ctx, "https://github.com/BurntSushi/ripgrep"); err != nil || v != "14.1.0" {
+		t.Errorf("ripgrep: got %q (%v)", v, err)
+	}
+	if v, err := releases.Latest(ctx, "https://github.com/junegunn/fzf"); err != nil || v != "0.50.0" {
+		t.Errorf("fzf: got %q (%v)", v, err)
+	}
+	if _, err := releases.Latest(ctx, "https://github.com/no/releases"); !errors.Is(err, ErrNoUpstream) {
+		t.Errorf("expected ErrNoUpstream for a repository without releases, got %v", err)
+	}
+	if _, err := releases.Latest(ctx, "https://gitlab.com/foo/bar"); !errors.Is(err, ErrNoUpstream) {
+		t.Errorf("expected ErrNoUpstream for a non-GitHub repository, got %v", err)
+	}
+	_, err := releases.Latest(ctx, "https://github.com/rate/limited")
+	if err == nil || errors.Is(err, ErrNoUpstream) {
+		t.Errorf("expected an error for a rate-limited request, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package upgrade
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"troveler/db"
+	"troveler/internal/platform"
+)
+
+// Runner runs a command and returns its combined output.
+type Runner = db.CommandRunner
+
+// probeTimeout bounds each version query so a hanging tool cannot stall a
+// whole outdated report.
+const probeTimeout = 10 * time.Second
+
+// ExecRunner runs commands with os/exec.
+func ExecRunner(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+
+	return string(out), err
+}
+
+// Prober determines the installed version of an executable.
+type Prober struct {
+	Run Runner
+}
+
+// NewProber returns a Prober that runs real commands.
+func NewProber() *Prober {
+	return &Prober{Run: ExecRunner}
+}
+
+// Probe is an installed version and where it came from.
+type Probe struct {
+	Owner   string // package manager that owns the executable, "" if unknown
+	Version string // "" when no version could be determined
+	Source  string // how the version was found: the owner, "path" or "--version"
+}
+
+// Installed returns the version of the executable at path. The package
+// manager owning it (see db.DetectOwner) is asked first, for the package it
+// reports or the one named in method when that is the manager that installed
+// it. Without an answer it falls back to running "<exe> --version".
+func (p *Prober) Installed(ctx context.Context, path string, method platform.InstallMethod) Probe {
+	owner := db.DetectOwner(ctx, path, p.Run)
+	probe := Probe{Owner: owner.Owner}
+	if probe.Owner == "" && method.Manager != "" && isSystemPath(owner.Path) && isSystemManager(method.Manager) {
+		probe.Owner = method.Manager
+	}
+
+	if v := versionFromPath(probe.Owner, owner.Path); v != "" {
+		probe.Version, probe.Source = v, "path"
+
+		return probe
+	}
+
+	pkg := filepath.Base(path)
+	switch {
+	case method.Manager == probe.Owner && method.Package != "":
+		pkg = method.Package
+	case owner.Package != "":
+		pkg = owner.Package
+	}
+
+	if probe.Owner != "" {
+		if v := p.queryManager(ctx, probe.Owner, pkg, path); v != "" {
+			probe.Version, probe.Source = v, probe.Owner
+
+			return probe
+		}
+	}
+
+	if out, err := p.Run(ctx, path, "--version"); err == nil {
+		if v := ExtractVersion(firstLine(out)); v != "" {
+			probe.Version, probe.Source = v, "--version"
+		}
+	}
+
+	return probe
+}
+
+func isSystemPath(path string) bool {
+	for _, dir := range []string{"/usr/bin/", "/usr/sbin/", "/bin/", "/sbin/"} {
+		if strings.HasPrefix(path, dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSystemManager(manager string) bool {
+	switch manager {
+	case platform.ManagerApt, platform.ManagerDnf, platform.ManagerZypper, platform.ManagerPacman,
+		platform.ManagerApk:
+		return true
+	}
+
+	return false
+}
+
+// versionFromPath reads the version from package managers that install each
+// version into its own directory, e.g. ".../Cellar/ripgrep/14.1.0/bin/rg".
+func versionFromPath(owner, path string) string {
+	var marker string
+	switch owner {
+	case platform.ManagerBrew:
+		marker = "/Cellar/"
+	case platform.ManagerMise:
+		marker = "/mise/installs/"
+	case platform.ManagerNix:
+		// /nix/store/<hash>-ripgrep-14.1.0/bin/rg
+		rest := strings.SplitN(strings.TrimPrefix(filepath.ToSlash(path), "/nix/store/"), "/", 2)[0]
+
+		return ExtractVersion(rest)
+	default:
+		return ""
+	}
+
+	_, rest, ok := strings.Cut(filepath.ToSlash(path), marker)
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return ExtractVersion(parts[1])
+}
+
+// versionQuery is how a package manager reports the installed version of a
+// package: the command to run and the prefix of the output line holding it
+// ("" for the first line). {pkg} and {path} in args are substituted.
+type versionQuery struct {
+	prefix string
+	args   []string
+}
+
+var versionQueries = map[string]versionQuery{
+	platform.ManagerBrew:   {"", []string{"brew", "list", "--versions", "{pkg}"}},
+	platform.ManagerCargo:  {"{pkg} v", []string{"cargo", "install", "--list"}},
+	platform.ManagerGo:     {"\tmod\t", []string{"go", "version", "-m", "{path}"}},
+	platform.ManagerNpm:    {"{pkg}@", []string{"npm", "ls", "-g", "--depth=0", "{pkg}"}},
+	platform.ManagerPipx:   {"{pkg} ", []string{"pipx", "list", "--short"}},
+	platform.ManagerUv:     {"{pkg} v", []string{"uv", "tool", "list"}},
+	platform.ManagerPip:    {"Version:", []string{"pip", "show", "{pkg}"}},
+	platform.ManagerGem:    {"{pkg} (", []string{"gem", "list", "--local", "--exact", "{pkg}"}},
+	platform.ManagerApt:    {"", []string{"dpkg-query", "-W", "-f=${Version}", "{pkg}"}},
+	platform.ManagerDnf:    {"", []string{"rpm", "-q", "--qf", "%{VERSION}", "{pkg}"}},
+	platform.ManagerZypper: {"", []string{"rpm", "-q", "--qf", "%{VERSION}", "{pkg}"}},
+	db.OwnerRPM:            {"", []string{"rpm", "-q", "--qf", "%{VERSION}", "{pkg}"}},
+	platform.ManagerPacman: {"", []string{"pacman", "-Q", "{pkg}"}},
+	platform.ManagerApk:    {"", []string{"apk", "info", "-v", "{pkg}"}},
+	platform.ManagerSnap:   {"{pkg} ", []string{"snap", "list", "{pkg}"}},
+}
+
+// queryManager asks owner for the installed version of pkg.
+func (p *Prober) queryManager(ctx context.Context, owner, pkg, path string) string {
+	q, ok := versionQueries[owner]
+	if !ok {
+		return ""
+	}
+
+	replacer := strings.NewReplacer("{pkg}", pkg, "{path}", path)
+	args := make([]string, len(q.args))
+	for i, arg := range q.args {
+		args[i] = replacer.Replace(arg)
+	}
+
+	return p.query(ctx, replacer.Replace(q.prefix), args[0], args[1:]...)
+}
+
+// query runs a command and extracts the version from the first output line
+// starting with prefix (the first line when prefix is empty).
+func (p *Prober) query(ctx context.Context, prefix, name string, args ...string) string {
+	out, err := p.Run(ctx, name, args...)
+	if err != nil {
+		return ""
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if prefix == "" {
+			return ExtractVersion(line)
+		}
+		// npm ls prints a tree; drop the drawing before the package.
+		trimmed := strings.TrimLeft(line, "├└─│ ")
+		if strings.HasPrefix(trimmed, prefix) || strings.HasPrefix(line, prefix) {
+			return ExtractVersion(strings.TrimPrefix(trimmed, pkgPrefixName(prefix)))
+		}
+	}
+
+	return ""
+}
+
+// pkgPrefixName strips the separator from a query prefix so package names
+// with digits ("python3-foo@1.0") don't shadow the version.
+func pkgPrefixName(prefix string) string {
+	return strings.TrimRight(prefix, " v@(")
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+
+	return line
+}
+
+// String describes the probe for reports, e.g. "14.1.0 (cargo)".
+func (p Probe) String() string {
+	if p.Version == "" {
+		return "unknown"
+	}
+	if p.Source == "" {
+		return p.Version
+	}
+
+	return fmt.Sprintf("%s (%s)", p.Version, p.Source)
+}
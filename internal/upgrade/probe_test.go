@@ -0,0 +1,68 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"troveler/db"
+	"troveler/internal/platform"
+)
+
+// fakeRunner answers commands from a table keyed by the full command line.
+func fakeRunner(outputs map[string]string) Runner {
+	return func(_ context.Context, name string, args ...string) (string, error) {
+		out, ok := outputs[strings.Join(append([]string{name}, args...), " ")]
+		if !ok {
+			return "", errors.New("command not found")
+		}
+
+		return out, nil
+	}
+}
+
+func TestProberInstalled(t *testing.T) {
+	prober := &Prober{Run: fakeRunner(map[string]string{
+		"cargo install --list":                 "bat v0.24.0:\n    bat\nripgrep-all v0.10.6:\n    rga\nripgrep v14.1.0:\n    rg\n",
+		"npm ls -g --depth=0 @ast-grep/cli":    "/usr/lib\n└── @ast-grep/cli@0.20.1\n",
+		"dpkg-query -W -f=${Version} fd-find":  "8.7.0-3",
+		"dpkg -S /usr/bin/fdfind":              "fd-find: /usr/bin/fdfind",
+		"rpm -qf --qf %{NAME} /usr/bin/jq":     "jq",
+		"rpm -q --qf %{VERSION} jq":            "1.7.1",
+		"/home/me/go/bin/glow --version":       "glow version 1.5.1",
+		"go version -m /home/me/go/bin/glow":   "",
+		"/usr/local/bin/unknown --version":     "",
+		"/home/me/.local/bin/mytool --version": "mytool 3.2.1\nmore text",
+		"brew list --versions ripgrep":         "ripgrep 14.1.0",
+	})}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		path    string
+		install string
+		version string
+		source  string
+	}{
+		{"cargo list", "/home/me/.cargo/bin/rg", "cargo install ripgrep", "14.1.0", platform.ManagerCargo},
+		{"npm tree", "/usr/lib/node_modules/@ast-grep/cli/sg", "npm install -g @ast-grep/cli", "0.20.1",
+			platform.ManagerNpm},
+		{"system package", "/usr/bin/fdfind", "sudo apt install fd-find", "8.7.0", platform.ManagerApt},
+		{"rpm owner", "/usr/bin/jq", "", "1.7.1", db.OwnerRPM},
+		{"brew path", "/opt/homebrew/Cellar/ripgrep/14.1.0/bin/rg", "brew install ripgrep", "14.1.0", "path"},
+		{"go falls back to --version", "/home/me/go/bin/glow", "go install github.com/charmbracelet/glow@latest",
+			"1.5.1", "--version"},
+		{"unowned", "/home/me/.local/bin/mytool", "", "3.2.1", "--version"},
+		{"unknown", "/usr/local/bin/unknown", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := prober.Installed(ctx, tt.path, platform.ParseInstall(tt.install))
+			if probe.Version != tt.version || probe.Source != tt.source {
+				t.Errorf("got %+v, want version %q from %q", probe, tt.version, tt.source)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package upgrade finds out which version of a tool is installed, compares it
+// with the latest upstream release and reports tools that are outdated.
+package upgrade
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionPattern matches a dotted version with an optional pre-release
+// suffix, e.g. "14.1.0", "v0.9.4" or "2.0.0-rc.1".
+var versionPattern = regexp.MustCompile(
+	`(?i)\bv?(\d+(?:\.\d+)+)((?:-|\.)?(?:alpha|beta|rc|pre|dev)[.\-]?\d*)?`)
+
+// ExtractVersion returns the first version number in s without a leading "v",
+// or "" when s contains none. Single numbers are only accepted when s is
+// nothing else, so "ripgrep 14" yields "" but "14" yields "14".
+func ExtractVersion(s string) string {
+	if m := versionPattern.FindStringSubmatch(s); m != nil {
+		return m[1] + m[2]
+	}
+
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if _, err := strconv.Atoi(trimmed); err == nil {
+		return trimmed
+	}
+
+	return ""
+}
+
+// CompareVersions compares two versions numerically segment by segment and
+// returns -1, 0 or 1. Missing segments count as zero and a pre-release sorts
+// before the release it precedes: 1.2 == 1.2.0 and 2.0.0-rc.1 < 2.0.0.
+func CompareVersions(a, b string) int {
+	aNum, aPre := splitPrerelease(a)
+	bNum, bPre := splitPrerelease(b)
+
+	aParts := strings.Split(aNum, ".")
+	bParts := strings.Split(bNum, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		if c := compareInts(segment(aParts, i), segment(bParts, i)); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	}
+
+	return 1
+}
+
+// IsNewer reports whether latest is a newer version than installed. Unknown
+// versions are never newer.
+func IsNewer(latest, installed string) bool {
+	if latest == "" || installed == "" {
+		return false
+	}
+
+	return CompareVersions(latest, installed) > 0
+}
+
+func splitPrerelease(v string) (string, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if idx := strings.IndexFunc(v, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); idx >= 0 {
+		return strings.TrimRight(v[:idx], "."), strings.ToLower(strings.TrimLeft(v[idx:], "-."))
+	}
+
+	return v, ""
+}
+
+func segment(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n, err := strconv.Atoi(parts[i])
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,55 @@
+package upgrade
+
+import "testing"
+
+func TestExtractVersion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"ripgrep 14.1.0 (rev e50df40a19)", "14.1.0"},
+		{"v0.9.4", "0.9.4"},
+		{"fd 10.1.0", "10.1.0"},
+		{"1:13.0.0-2ubuntu1", "13.0.0"},
+		{"jq-1.7.1-r0", "1.7.1"},
+		{"helix 24.03 (2cadec0b)", "24.03"},
+		{"v2.0.0-rc.1", "2.0.0-rc.1"},
+		{"42", "42"},
+		{"version unknown", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ExtractVersion(tt.input); got != tt.want {
+			t.Errorf("ExtractVersion(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"14.1.0", "14.1.0", 0},
+		{"1.2", "1.2.0", 0},
+		{"v1.10.0", "1.9.9", 1},
+		{"0.9.4", "0.10.0", -1},
+		{"2.0.0-rc.1", "2.0.0", -1},
+		{"2.0.0", "2.0.0-beta", 1},
+		{"2.0.0-alpha", "2.0.0-beta", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	if IsNewer("14.1.0", "") || IsNewer("", "14.1.0") {
+		t.Error("expected unknown versions never to be newer")
+	}
+	if !IsNewer("14.1.0", "13.0.0") {
+		t.Error("expected 14.1.0 to be newer than 13.0.0")
+	}
+}
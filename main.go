@@ -99,7 +99,15 @@ func init() {
 	RootCmd.AddCommand(commands.SearchCmd)
 	RootCmd.AddCommand(commands.InfoCmd)
 	RootCmd.AddCommand(commands.InstallCmd)
+	RootCmd.AddCommand(commands.UninstallCmd)
+	RootCmd.AddCommand(commands.OutdatedCmd)
+	RootCmd.AddCommand(commands.UpgradeCmd)
 	RootCmd.AddCommand(commands.TagCmd)
+	RootCmd.AddCommand(commands.QueryCmd)
+	RootCmd.AddCommand(commands.SyncCmd)
+	RootCmd.AddCommand(commands.HistoryCmd)
+	RootCmd.AddCommand(commands.ChangelogCmd)
+	RootCmd.AddCommand(commands.ScanCmd)
 	RootCmd.AddCommand(commands.NewestCmd)
 	RootCmd.AddCommand(completionCmd)
 }
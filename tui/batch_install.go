@@ -2,6 +2,8 @@
 package tui
 
 import (
+	"time"
+
 	"troveler/db"
 )
 
@@ -19,13 +21,35 @@ type BatchInstallConfig struct {
 // BatchInstallProgress tracks batch install progress
 type BatchInstallProgress struct {
 	Tools         []db.SearchResult
-	CurrentIndex  int
-	Completed     []string // Tool IDs that completed successfully
-	Failed        []string // Tool IDs that failed
-	Skipped       []string // Tool IDs that were skipped
-	CurrentOutput string
+	Jobs          []BatchJobStatus // one row per tool, in Tools order
+	Completed     []string         // Tool IDs that completed successfully
+	Failed        []string         // Tool IDs that failed
+	Skipped       []string         // Tool IDs that were skipped
+	CurrentOutput string           // output of the last finished tool
 	CurrentError  error
 	IsComplete    bool
+	Focus         int // job whose log is shown; -1 follows the latest started
+}
+
+// BatchJobState is where one tool of a batch install stands.
+type BatchJobState int
+
+// Batch job states.
+const (
+	JobQueued BatchJobState = iota
+	JobRunning
+	JobCompleted
+	JobFailed
+	JobSkipped
+)
+
+// BatchJobStatus is the progress row of one tool of a batch install.
+type BatchJobStatus struct {
+	State   BatchJobState
+	Command string    // resolved install command, once known
+	Started time.Time // when the job started running
+	Elapsed time.Duration
+	Log     *InstallLog // output of the install, once it started
 }
 
 // NewBatchInstallConfig creates a new batch install configuration
@@ -43,13 +67,60 @@ func NewBatchInstallConfig() *BatchInstallConfig {
 // NewBatchInstallProgress creates a new batch install progress tracker
 func NewBatchInstallProgress(tools []db.SearchResult) *BatchInstallProgress {
 	return &BatchInstallProgress{
-		Tools:        tools,
-		CurrentIndex: 0,
-		Completed:    []string{},
-		Failed:       []string{},
-		Skipped:      []string{},
-		IsComplete:   false,
+		Tools:      tools,
+		Jobs:       make([]BatchJobStatus, len(tools)),
+		Completed:  []string{},
+		Failed:     []string{},
+		Skipped:    []string{},
+		IsComplete: false,
+		Focus:      -1,
+	}
+}
+
+// Finished returns how many tools completed, failed or were skipped.
+func (p *BatchInstallProgress) Finished() int {
+	return len(p.Completed) + len(p.Failed) + len(p.Skipped)
+}
+
+// FocusedJob returns the job whose log is shown: the one picked, else the
+// running job started last, else the last one that ran. It is -1 while no
+// job has a log.
+func (p *BatchInstallProgress) FocusedJob() int {
+	if p.Focus >= 0 && p.Focus < len(p.Jobs) {
+		return p.Focus
 	}
+
+	focus := -1
+	for i, job := range p.Jobs {
+		if job.Log != nil && (focus < 0 || p.showsFirst(i, focus)) {
+			focus = i
+		}
+	}
+
+	return focus
+}
+
+// showsFirst reports whether job a's log is shown rather than job b's:
+// running jobs first, then the one started last.
+func (p *BatchInstallProgress) showsFirst(a, b int) bool {
+	ja, jb := p.Jobs[a], p.Jobs[b]
+	if (ja.State == JobRunning) != (jb.State == JobRunning) {
+		return ja.State == JobRunning
+	}
+
+	return !ja.Started.Before(jb.Started)
+}
+
+// Running returns how many tools are installing right now.
+func (p *BatchInstallProgress) Running() int {
+	running := 0
+	for _, job := range p.Jobs {
+		if job.State == JobRunning {
+			running++
+		}
+	}
+
+	return running
 }
 
 // ConfigStepCount returns the total number of config steps
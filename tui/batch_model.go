@@ -2,7 +2,7 @@ package tui
 
 import (
 	"fmt"
-	"os/exec"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -15,6 +15,8 @@ import (
 
 type batchInstallStartMsg struct {
 	tools []db.SearchResult
+	jobs  []batchJob   // resolved install of each tool, in tools order
+	run   *batchRunRef // nil when the batch is not persisted
 }
 
 type batchInstallProgressMsg struct {
@@ -26,13 +28,38 @@ type batchInstallProgressMsg struct {
 
 type batchInstallCompleteMsg struct{}
 
+// batchTickMsg refreshes the elapsed times and logs of running batch jobs.
+type batchTickMsg struct{}
+
+// batchRunRef ties a batch install to its persisted run, so it can be
+// resumed after the TUI was closed mid-batch.
+type batchRunRef struct {
+	id        int64
+	positions []int // position in the run of each tool of the batch
+}
+
+// batchJob is the resolved install of one tool of a batch: the command to
+// run, or the outcome when there is nothing to run (skipped, no method,
+// blocked by the risk policy).
+type batchJob struct {
+	platform string
+	command  string
+	result   *batchInstallProgressMsg
+}
+
 // --- BatchInstallModel ------------------------------------------------------
 
 // BatchInstallModel manages batch install configuration and progress.
 type BatchInstallModel struct {
-	db       *db.SQLiteDB
-	config   *BatchInstallConfig
-	progress *BatchInstallProgress
+	db        *db.SQLiteDB
+	config    *BatchInstallConfig
+	progress  *BatchInstallProgress
+	policy    *install.RiskPolicy // install risk policy; nil allows everything
+	policyErr error               // invalid install risk policy; every job fails with it
+	limit     int                 // installs run at once ([install] jobs)
+	jobs      []batchJob
+	sched     *install.Scheduler
+	run       *batchRunRef
 }
 
 // NewBatchInstallModel creates a new BatchInstallModel.
@@ -89,126 +116,350 @@ func (bm *BatchInstallModel) AdvanceStep() bool {
 	return bm.config.NextStep()
 }
 
-// StartInstall begins batch installation of the marked tools.
+// StartInstall begins batch installation of the marked tools. The returned
+// Cmd records the batch for resuming and resolves the install command of
+// every tool in the background.
 func (bm *BatchInstallModel) StartInstall(markedTools []db.SearchResult) tea.Cmd {
-	bm.progress = NewBatchInstallProgress(markedTools)
+	return bm.startRun(markedTools, nil, 0)
+}
 
-	return func() tea.Msg {
-		return batchInstallStartMsg{tools: markedTools}
+// ResumeInstall continues an interrupted batch run with the choices made
+// when it started.
+func (bm *BatchInstallModel) ResumeInstall(run *db.BatchRun) tea.Cmd {
+	bm.config = &BatchInstallConfig{
+		ReuseConfig:    run.Options.ReuseConfig,
+		UseSudo:        run.Options.UseSudo,
+		SudoOnlySystem: run.Options.SudoOnlySystem,
+		SkipIfBlind:    run.Options.SkipIfBlind,
+		UseMise:        run.Options.UseMise,
+		ConfigStep:     NewBatchInstallConfig().ConfigStepCount(),
 	}
-}
 
-// ProcessTool processes the batch tool at the given index, returning a Cmd
-// that runs the install command in a background goroutine.
-func (bm *BatchInstallModel) ProcessTool(index int) tea.Cmd {
-	if bm.progress == nil || index >= len(bm.progress.Tools) {
-		return func() tea.Msg { return batchInstallCompleteMsg{} }
+	pins := run.Unfinished()
+	tools := make([]db.SearchResult, len(pins))
+	for i, pin := range pins {
+		// A tool gone from the catalog stays a stub and fails to resolve.
+		tools[i] = db.SearchResult{Tool: db.Tool{Slug: pin.Slug, Name: pin.Slug}}
+		if bm.db == nil {
+			continue
+		}
+		if found, err := bm.db.GetToolBySlug(pin.Slug); err == nil && len(found) > 0 {
+			tools[i].Tool = found[0]
+		}
 	}
 
-	tool := bm.progress.Tools[index]
+	return bm.startRun(tools, pins, run.ID)
+}
+
+// startRun starts a batch over tools. A new batch (runID 0) is persisted as
+// it starts; a resumed one keeps updating its run, with pins giving the
+// persisted entry of each tool.
+func (bm *BatchInstallModel) startRun(tools []db.SearchResult, pins []db.BatchRunTool, runID int64) tea.Cmd {
+	bm.progress = NewBatchInstallProgress(tools)
+	bm.jobs, bm.sched, bm.run = nil, nil, nil
 	cfg := bm.config
 	database := bm.db
+	policy, policyErr := bm.policy, bm.policyErr
 
 	return func() tea.Msg {
-		installs, err := database.GetInstallInstructions(tool.ID)
-		if err != nil || len(installs) == 0 {
-			if cfg != nil && cfg.SkipIfBlind {
-				return batchInstallProgressMsg{
-					toolID:  tool.ID,
-					skipped: true,
-				}
-			}
+		msg := batchInstallStartMsg{tools: tools, jobs: make([]batchJob, len(tools))}
+		for i, tool := range tools {
+			if policyErr != nil {
+				msg.jobs[i] = batchJob{result: &batchInstallProgressMsg{toolID: tool.ID, err: policyErr}}
 
-			return batchInstallProgressMsg{
-				toolID: tool.ID,
-				err:    fmt.Errorf("no install instructions found"),
+				continue
 			}
+			var pin db.BatchRunTool
+			if pins != nil {
+				pin = pins[i]
+			}
+			msg.jobs[i] = resolveBatchJob(database, tool, pin, cfg, policy)
 		}
 
-		selector := install.NewPlatformSelector("", "", "", tool.Language)
-		osInfo, _ := platform.DetectOS()
-		detectedOS := ""
-		if osInfo != nil {
-			detectedOS = osInfo.ID
-		}
-
-		result := install.ResolvePlatform(selector, installs, detectedOS, tool.Language)
-		filtered := result.Installs
-		if result.UsedFallback || len(filtered) == 0 {
-			synthMatched, platformID := install.TryResolveLangFallback(installs, result.PlatformID)
-			if len(synthMatched) > 0 {
-				filtered = synthMatched
-			} else if result.UsedFallback && len(filtered) > 0 {
-				// Fallback returned candidate installs from other platforms;
-				// use them as-is since TryResolveLangFallback couldn't refine.
-				_ = platformID
-			} else {
-				if cfg != nil && cfg.SkipIfBlind {
-					return batchInstallProgressMsg{
-						toolID:  tool.ID,
-						skipped: true,
-					}
-				}
-
-				return batchInstallProgressMsg{
-					toolID: tool.ID,
-					err:    fmt.Errorf("no compatible install method"),
-				}
+		if runID != 0 {
+			msg.run = &batchRunRef{id: runID}
+			for _, pin := range pins {
+				msg.run.positions = append(msg.run.positions, pin.Position)
 			}
+		} else if database != nil {
+			msg.run = recordBatchRun(database, tools, cfg)
 		}
 
-		defaultCmd := install.SelectDefaultCommand(filtered, result.UsedFallback, detectedOS)
-		cmd := filtered[0].Command
-		if defaultCmd != nil {
-			cmd = defaultCmd.Command
-		}
+		return msg
+	}
+}
 
-		if cfg != nil && cfg.UseMise {
-			cmd = install.TransformToMise(cmd)
+// recordBatchRun persists a new batch. Persisting is best effort: without
+// it the batch runs but cannot be resumed.
+func recordBatchRun(database *db.SQLiteDB, tools []db.SearchResult, cfg *BatchInstallConfig) *batchRunRef {
+	run := &db.BatchRun{}
+	if cfg != nil {
+		run.Options = db.BatchOptions{
+			ReuseConfig:    true,
+			UseSudo:        cfg.UseSudo,
+			SudoOnlySystem: cfg.SudoOnlySystem,
+			SkipIfBlind:    cfg.SkipIfBlind,
+			UseMise:        cfg.UseMise,
 		}
+	}
+	ref := &batchRunRef{}
+	for i, tool := range tools {
+		run.Tools = append(run.Tools, db.BatchRunTool{Slug: tool.Slug})
+		ref.positions = append(ref.positions, i)
+	}
+	if err := database.CreateBatchRun(run); err != nil {
+		return nil
+	}
+	ref.id = run.ID
 
-		if cfg != nil && cfg.UseSudo {
-			isSystemPM := isSystemPackageManager(filtered[0].Platform)
-			if !cfg.SudoOnlySystem || isSystemPM {
-				cmd = "sudo " + cmd
-			}
+	return ref
+}
+
+// Schedule queues the resolved jobs of a started batch and starts as many
+// as the concurrency limit and the package manager locks allow.
+func (bm *BatchInstallModel) Schedule(msg batchInstallStartMsg) tea.Cmd {
+	if bm.progress == nil {
+		return nil
+	}
+
+	bm.jobs = msg.jobs
+	bm.run = msg.run
+	bm.sched = install.NewScheduler(bm.limit)
+	for i, job := range msg.jobs {
+		bm.progress.Jobs[i].Command = job.command
+		var locks []string
+		if job.result == nil {
+			locks = install.ManagerLocks(job.command)
 		}
+		bm.sched.Add(locks)
+	}
 
-		execCmd := exec.Command("sh", "-c", cmd) //nolint:noctx,gosec
-		output, err := execCmd.CombinedOutput()
+	return tea.Batch(bm.startNext(), batchTick())
+}
+
+// startNext starts every queued job the scheduler lets run now.
+func (bm *BatchInstallModel) startNext() tea.Cmd {
+	next := bm.sched.Next()
+	cmds := make([]tea.Cmd, 0, len(next))
+	for _, index := range next {
+		bm.progress.Jobs[index].State = JobRunning
+		bm.progress.Jobs[index].Started = time.Now()
+		bm.saveStatus(index, db.BatchRunning, "")
+		cmds = append(cmds, bm.ProcessTool(index))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// ProcessTool runs the scheduled job at the given index in a background
+// goroutine.
+func (bm *BatchInstallModel) ProcessTool(index int) tea.Cmd {
+	if bm.progress == nil || index >= len(bm.progress.Tools) || index >= len(bm.jobs) {
+		return func() tea.Msg { return batchInstallCompleteMsg{} }
+	}
+
+	tool := bm.progress.Tools[index]
+	job := bm.jobs[index]
+	database := bm.db
+	if job.result != nil {
+		return func() tea.Msg { return *job.result }
+	}
+
+	ctx, log := newLoggedRun()
+	bm.progress.Jobs[index].Log = log
+	password := ""
+	if bm.config != nil {
+		password = bm.config.SudoPassword
+	}
+
+	return func() tea.Msg {
+		output, err := runInstallCommand(ctx, database, db.ActionInstall, tool.Slug, job.platform, job.command, log,
+			password)
+		log.finish()
 
 		return batchInstallProgressMsg{
 			toolID: tool.ID,
-			output: string(output),
+			output: output,
 			err:    err,
 		}
 	}
 }
 
+// resolveBatchJob picks the install command of tool for a batch, applying
+// the batch config and the install risk policy.
+func resolveBatchJob(
+	database *db.SQLiteDB, tool db.SearchResult, pin db.BatchRunTool, cfg *BatchInstallConfig,
+	policy *install.RiskPolicy,
+) batchJob {
+	blind := func(err error) batchJob {
+		if cfg != nil && cfg.SkipIfBlind {
+			return batchJob{result: &batchInstallProgressMsg{toolID: tool.ID, skipped: true}}
+		}
+
+		return batchJob{result: &batchInstallProgressMsg{toolID: tool.ID, err: err}}
+	}
+
+	if database == nil {
+		return blind(fmt.Errorf("no install instructions found"))
+	}
+	installs, err := database.GetInstallInstructions(tool.ID)
+	if err != nil || len(installs) == 0 {
+		return blind(fmt.Errorf("no install instructions found"))
+	}
+
+	selector := install.NewPlatformSelector(pin.Platform, "", "", tool.Language)
+	osInfo, _ := platform.DetectOS()
+	detectedOS := ""
+	if osInfo != nil {
+		detectedOS = osInfo.ID
+	}
+
+	result := install.ResolvePlatform(selector, installs, detectedOS, tool.Language)
+	filtered := result.Installs
+	if result.UsedFallback || len(filtered) == 0 {
+		synthMatched, _ := install.TryResolveLangFallback(installs, result.PlatformID)
+		if len(synthMatched) > 0 {
+			filtered = synthMatched
+		} else if !result.UsedFallback || len(filtered) == 0 {
+			// A fallback with candidates from other platforms is used as-is
+			// since TryResolveLangFallback couldn't refine it.
+			return blind(fmt.Errorf("no compatible install method"))
+		}
+	}
+
+	defaultCmd := install.SelectDefaultCommand(filtered, result.UsedFallback, detectedOS)
+	cmd := filtered[0].Command
+	if defaultCmd != nil {
+		cmd = defaultCmd.Command
+	}
+
+	if pin.Mise {
+		cmd = install.TransformToMise(cmd)
+	}
+	job := batchJob{platform: filtered[0].Platform, command: applyBatchConfig(cmd, filtered[0].Platform, cfg)}
+	job.result = riskGate(policy, tool.ID, job.command)
+
+	return job
+}
+
+// applyBatchConfig rewrites cmd for mise and prepends sudo as cfg asks.
+func applyBatchConfig(cmd, platformID string, cfg *BatchInstallConfig) string {
+	if cfg == nil {
+		return cmd
+	}
+
+	if cfg.UseMise {
+		cmd = install.TransformToMise(cmd)
+	}
+
+	if cfg.UseSudo && (!cfg.SudoOnlySystem || isSystemPackageManager(platformID)) {
+		cmd = "sudo " + cmd
+	}
+
+	return cmd
+}
+
+// riskGate applies the install risk policy to a batch command. A batch cannot
+// stop to ask, so commands needing confirmation are skipped and left for a
+// single install. It returns nil when cmd may run.
+func riskGate(policy *install.RiskPolicy, toolID, cmd string) *batchInstallProgressMsg {
+	switch decision, classes := policy.Decide(install.AnalyzeCommand(cmd)); decision {
+	case install.RiskBlock:
+		return &batchInstallProgressMsg{toolID: toolID, output: cmd, err: &install.RiskBlockedError{Classes: classes}}
+	case install.RiskConfirm:
+		return &batchInstallProgressMsg{
+			toolID:  toolID,
+			output:  "needs confirmation for " + install.FormatRiskClasses(classes) + "; install it on its own",
+			skipped: true,
+		}
+	}
+
+	return nil
+}
+
 // HandleProgress applies a progress update and reports whether the batch is
-// finished. When not finished, nextIndex gives the next tool to process.
-func (bm *BatchInstallModel) HandleProgress(msg batchInstallProgressMsg) (finished bool, nextIndex int) {
+// finished. When not finished, next starts the jobs the finished one made
+// room for.
+func (bm *BatchInstallModel) HandleProgress(msg batchInstallProgressMsg) (finished bool, next tea.Cmd) {
 	if bm.progress == nil {
-		return true, 0
+		return true, nil
 	}
 
+	state, status, detail := JobCompleted, db.BatchCompleted, ""
 	if msg.skipped {
 		bm.progress.Skipped = append(bm.progress.Skipped, msg.toolID)
+		state, status, detail = JobSkipped, db.BatchSkipped, msg.output
 	} else if msg.err != nil {
 		bm.progress.Failed = append(bm.progress.Failed, msg.toolID)
+		state, status, detail = JobFailed, db.BatchFailed, msg.err.Error()
 	} else {
 		bm.progress.Completed = append(bm.progress.Completed, msg.toolID)
 	}
 	bm.progress.CurrentOutput = msg.output
 	bm.progress.CurrentError = msg.err
-	bm.progress.CurrentIndex++
 
-	if bm.progress.CurrentIndex < len(bm.progress.Tools) {
-		return false, bm.progress.CurrentIndex
+	for i, tool := range bm.progress.Tools {
+		// Tools missing from the catalog share an empty ID; take the first
+		// one still open.
+		if tool.ID != msg.toolID || bm.progress.Jobs[i].State > JobRunning {
+			continue
+		}
+		job := &bm.progress.Jobs[i]
+		job.State = state
+		bm.saveStatus(i, status, detail)
+		if !job.Started.IsZero() {
+			job.Elapsed = time.Since(job.Started)
+		}
+		if bm.sched != nil {
+			bm.sched.Done(i)
+		}
+
+		break
+	}
+
+	if bm.progress.Finished() < len(bm.progress.Tools) {
+		if bm.sched == nil {
+			return false, nil
+		}
+
+		return false, bm.startNext()
 	}
 	bm.progress.IsComplete = true
+	bm.dropRun()
+
+	return true, nil
+}
+
+// saveStatus records the status of the batch's index-th tool in its run.
+// Like recording the run, this is best effort.
+func (bm *BatchInstallModel) saveStatus(index int, status, detail string) {
+	if bm.run == nil || bm.db == nil || index >= len(bm.run.positions) {
+		return
+	}
+	_ = bm.db.SetBatchToolStatus(bm.run.id, bm.run.positions[index], status, detail)
+}
+
+// dropRun forgets the run of a batch that got through every tool.
+func (bm *BatchInstallModel) dropRun() {
+	if bm.run == nil || bm.db == nil {
+		return
+	}
+	_ = bm.db.DeleteBatchRun(bm.run.id)
+	bm.run = nil
+}
+
+// Tick keeps the elapsed times and logs of running jobs current while a
+// batch runs.
+func (bm *BatchInstallModel) Tick() tea.Cmd {
+	if bm.progress == nil || bm.progress.IsComplete {
+		return nil
+	}
+
+	return batchTick()
+}
 
-	return true, 0
+func batchTick() tea.Cmd {
+	return tea.Tick(installLogRefresh, func(time.Time) tea.Msg { return batchTickMsg{} })
 }
 
 // HandleComplete marks the batch install as finished.
@@ -2,10 +2,13 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"troveler/db"
+	"troveler/internal/install"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -146,13 +149,10 @@ func TestBatchModel_Progress_IsRunning(t *testing.T) {
 
 func TestBatchModel_HandleProgress_Completed(t *testing.T) {
 	batch := NewBatchInstallModel(nil)
-	batch.progress = &BatchInstallProgress{
-		Tools:        []db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}},
-		CurrentIndex: 0,
-	}
+	batch.progress = NewBatchInstallProgress([]db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}})
 
 	// Process the single tool as completed
-	finished, nextIdx := batch.HandleProgress(batchInstallProgressMsg{
+	finished, next := batch.HandleProgress(batchInstallProgressMsg{
 		toolID: "t1",
 		output: "done",
 	})
@@ -160,8 +160,11 @@ func TestBatchModel_HandleProgress_Completed(t *testing.T) {
 	if !finished {
 		t.Error("expected finished true after processing the only tool")
 	}
-	if nextIdx != 0 {
-		t.Errorf("expected nextIndex 0 when finished, got %d", nextIdx)
+	if next != nil {
+		t.Error("expected no next Cmd when finished")
+	}
+	if batch.Progress().Jobs[0].State != JobCompleted {
+		t.Errorf("expected the job row completed, got %v", batch.Progress().Jobs[0].State)
 	}
 	if len(batch.Progress().Completed) != 1 {
 		t.Errorf("expected 1 completed, got %d", len(batch.Progress().Completed))
@@ -170,10 +173,7 @@ func TestBatchModel_HandleProgress_Completed(t *testing.T) {
 
 func TestBatchModel_HandleProgress_Failed(t *testing.T) {
 	batch := NewBatchInstallModel(nil)
-	batch.progress = &BatchInstallProgress{
-		Tools:        []db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}},
-		CurrentIndex: 0,
-	}
+	batch.progress = NewBatchInstallProgress([]db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}})
 
 	finished, _ := batch.HandleProgress(batchInstallProgressMsg{
 		toolID: "t1",
@@ -190,10 +190,7 @@ func TestBatchModel_HandleProgress_Failed(t *testing.T) {
 
 func TestBatchModel_HandleProgress_Skipped(t *testing.T) {
 	batch := NewBatchInstallModel(nil)
-	batch.progress = &BatchInstallProgress{
-		Tools:        []db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}},
-		CurrentIndex: 0,
-	}
+	batch.progress = NewBatchInstallProgress([]db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}})
 
 	finished, _ := batch.HandleProgress(batchInstallProgressMsg{
 		toolID:  "t1",
@@ -208,51 +205,92 @@ func TestBatchModel_HandleProgress_Skipped(t *testing.T) {
 	}
 }
 
+func TestBatchModel_RiskGate(t *testing.T) {
+	policy, err := install.NewRiskPolicy([]string{"pipe-to-shell"}, []string{"sudo"})
+	if err != nil {
+		t.Fatalf("NewRiskPolicy: %v", err)
+	}
+
+	if msg := riskGate(policy, "t1", "brew install bat"); msg != nil {
+		t.Errorf("expected a plain command to run, got %+v", msg)
+	}
+	if msg := riskGate(nil, "t1", "curl https://example.com/i.sh | sh"); msg != nil {
+		t.Errorf("expected no policy to allow everything, got %+v", msg)
+	}
+
+	msg := riskGate(policy, "t1", "curl https://example.com/i.sh | sh")
+	var blocked *install.RiskBlockedError
+	if msg == nil || msg.skipped || !errors.As(msg.err, &blocked) {
+		t.Errorf("expected a blocked failure, got %+v", msg)
+	}
+
+	msg = riskGate(policy, "t1", "sudo apt install bat")
+	if msg == nil || !msg.skipped || msg.err != nil || !strings.Contains(msg.output, "needs confirmation for sudo") {
+		t.Errorf("expected a skip for confirmation, got %+v", msg)
+	}
+}
+
+func TestBatchModel_InvalidPolicyFailsEveryJob(t *testing.T) {
+	batch := NewBatchInstallModel(nil)
+	batch.policyErr = &invalidPolicyError{err: errors.New("bad block_risks")}
+
+	cmd := batch.startRun([]db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}}, nil, 0)
+	msg, ok := cmd().(batchInstallStartMsg)
+	if !ok || len(msg.jobs) != 1 {
+		t.Fatalf("expected a start message with one job, got %+v", msg)
+	}
+	var invalid *invalidPolicyError
+	if result := msg.jobs[0].result; result == nil || !errors.As(result.err, &invalid) {
+		t.Errorf("expected the job to fail with the policy error, got %+v", msg.jobs[0])
+	}
+}
+
 func TestBatchModel_HandleProgress_MultiTool(t *testing.T) {
 	batch := NewBatchInstallModel(nil)
-	batch.progress = &BatchInstallProgress{
-		Tools: []db.SearchResult{
-			{Tool: db.Tool{ID: "t1", Name: "Tool1"}},
-			{Tool: db.Tool{ID: "t2", Name: "Tool2"}},
-			{Tool: db.Tool{ID: "t3", Name: "Tool3"}},
-		},
-		CurrentIndex: 0,
+	batch.progress = NewBatchInstallProgress([]db.SearchResult{
+		{Tool: db.Tool{ID: "t1", Name: "Tool1"}},
+		{Tool: db.Tool{ID: "t2", Name: "Tool2"}},
+		{Tool: db.Tool{ID: "t3", Name: "Tool3"}},
+	})
+	batch.Schedule(batchInstallStartMsg{jobs: []batchJob{{command: "echo 1"}, {command: "echo 2"}, {command: "echo 3"}}})
+	if batch.Progress().Running() != 1 {
+		t.Fatalf("expected one job running with limit 1, got %d", batch.Progress().Running())
 	}
 
 	// First tool completes
-	finished, nextIdx := batch.HandleProgress(batchInstallProgressMsg{
+	finished, next := batch.HandleProgress(batchInstallProgressMsg{
 		toolID: "t1",
 		output: "ok",
 	})
 	if finished {
 		t.Error("expected not finished after first tool of 3")
 	}
-	if nextIdx != 1 {
-		t.Errorf("expected nextIndex 1, got %d", nextIdx)
+	if next == nil || batch.Progress().Jobs[1].State != JobRunning {
+		t.Error("expected the second tool to start")
 	}
 
 	// Second tool fails
-	finished, nextIdx = batch.HandleProgress(batchInstallProgressMsg{
+	finished, next = batch.HandleProgress(batchInstallProgressMsg{
 		toolID: "t2",
 		err:    &testError{msg: "fail"},
 	})
 	if finished {
 		t.Error("expected not finished after second tool of 3")
 	}
-	if nextIdx != 2 {
-		t.Errorf("expected nextIndex 2, got %d", nextIdx)
+	if next == nil || batch.Progress().Jobs[2].State != JobRunning {
+		t.Error("expected the third tool to start")
 	}
 
 	// Third tool skipped
-	finished, nextIdx = batch.HandleProgress(batchInstallProgressMsg{
+	finished, next = batch.HandleProgress(batchInstallProgressMsg{
 		toolID:  "t3",
 		skipped: true,
 	})
 	if !finished {
 		t.Error("expected finished after last tool")
 	}
-	if nextIdx != 0 {
-		t.Errorf("expected nextIndex 0 when done, got %d", nextIdx)
+	if next != nil {
+		t.Error("expected no next Cmd when done")
 	}
 
 	// Final state
@@ -271,18 +309,77 @@ func TestBatchModel_HandleProgress_MultiTool(t *testing.T) {
 	}
 }
 
+func TestBatchModel_Schedule_SharedManagers(t *testing.T) {
+	batch := NewBatchInstallModel(nil)
+	batch.limit = 3
+	batch.progress = NewBatchInstallProgress([]db.SearchResult{
+		{Tool: db.Tool{ID: "bat", Name: "bat"}},
+		{Tool: db.Tool{ID: "fd", Name: "fd"}},
+		{Tool: db.Tool{ID: "rg", Name: "ripgrep"}},
+		{Tool: db.Tool{ID: "jq", Name: "jq"}},
+	})
+	batch.Schedule(batchInstallStartMsg{jobs: []batchJob{
+		{command: "sudo apt install bat"},
+		{command: "sudo apt install fd-find"},
+		{command: "cargo install ripgrep"},
+		{command: "go install example.com/jq@latest"},
+	}})
+
+	// fd waits for the dpkg lock bat holds; cargo and go run alongside.
+	want := []BatchJobState{JobRunning, JobQueued, JobRunning, JobRunning}
+	for i, job := range batch.Progress().Jobs {
+		if job.State != want[i] {
+			t.Errorf("job %d state = %v, want %v", i, job.State, want[i])
+		}
+	}
+
+	rows := viewBatchJobs(batch.Progress(), 10)
+	if !strings.Contains(rows, "fd") || !strings.Contains(rows, "queued") || !strings.Contains(rows, "running") {
+		t.Errorf("expected queued and running rows:\n%s", rows)
+	}
+
+	_, next := batch.HandleProgress(batchInstallProgressMsg{toolID: "bat"})
+	if next == nil || batch.Progress().Jobs[1].State != JobRunning {
+		t.Error("expected fd to start once bat released the dpkg lock")
+	}
+}
+
+func TestBatchInstallProgress_FocusedJob(t *testing.T) {
+	bp := NewBatchInstallProgress(make([]db.SearchResult, 3))
+	if bp.FocusedJob() != -1 {
+		t.Fatal("expected no focus before any job ran")
+	}
+
+	start := time.Now()
+	bp.Jobs[0] = BatchJobStatus{State: JobRunning, Started: start, Log: NewInstallLog(func() {})}
+	bp.Jobs[1] = BatchJobStatus{State: JobCompleted, Started: start.Add(time.Second), Log: NewInstallLog(func() {})}
+	if got := bp.FocusedJob(); got != 0 {
+		t.Errorf("FocusedJob() = %d, want the running job 0", got)
+	}
+
+	bp.Jobs[0].State = JobFailed
+	if got := bp.FocusedJob(); got != 1 {
+		t.Errorf("FocusedJob() = %d, want job 1, started last", got)
+	}
+
+	bp.Focus = 0
+	if got := bp.FocusedJob(); got != 0 {
+		t.Errorf("FocusedJob() = %d, want the picked job 0", got)
+	}
+}
+
 func TestBatchModel_HandleProgress_NilProgress(t *testing.T) {
 	batch := NewBatchInstallModel(nil)
 
-	finished, nextIdx := batch.HandleProgress(batchInstallProgressMsg{
+	finished, next := batch.HandleProgress(batchInstallProgressMsg{
 		toolID: "t1",
 		output: "ok",
 	})
 	if !finished {
 		t.Error("expected finished true when progress is nil (nothing to track)")
 	}
-	if nextIdx != 0 {
-		t.Errorf("expected nextIndex 0, got %d", nextIdx)
+	if next != nil {
+		t.Error("expected no next Cmd when progress is nil")
 	}
 }
 
@@ -330,8 +427,8 @@ func TestBatchModel_StartInstall_SetsProgress(t *testing.T) {
 	if len(prog.Tools) != 2 {
 		t.Errorf("expected 2 tools, got %d", len(prog.Tools))
 	}
-	if prog.CurrentIndex != 0 {
-		t.Errorf("expected CurrentIndex 0, got %d", prog.CurrentIndex)
+	if len(prog.Jobs) != 2 || prog.Finished() != 0 {
+		t.Errorf("expected 2 queued job rows, got %+v", prog.Jobs)
 	}
 }
 
@@ -372,10 +469,7 @@ func TestBatchModel_ProcessTool_NilProgress(t *testing.T) {
 
 func TestBatchModel_ProcessTool_IndexOutOfBounds(t *testing.T) {
 	batch := NewBatchInstallModel(nil)
-	batch.progress = &BatchInstallProgress{
-		Tools:        []db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}},
-		CurrentIndex: 0,
-	}
+	batch.progress = NewBatchInstallProgress([]db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}})
 
 	cmd := batch.ProcessTool(5) // out of bounds
 	msg := cmd()
@@ -404,10 +498,11 @@ func TestBatchModel_ProcessTool_HappyPath(t *testing.T) {
 	}
 
 	batch := NewBatchInstallModel(database)
-	batch.progress = &BatchInstallProgress{
-		Tools:        []db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}},
-		CurrentIndex: 0,
+	start, ok := batch.StartInstall([]db.SearchResult{{Tool: db.Tool{ID: "t1", Slug: "tool1", Name: "Tool1"}}})().(batchInstallStartMsg)
+	if !ok || len(start.jobs) != 1 || start.jobs[0].command != "echo ok" {
+		t.Fatalf("expected the resolved echo command, got %+v", start)
 	}
+	batch.Schedule(start)
 
 	cmd := batch.ProcessTool(0)
 	msg := cmd()
@@ -424,6 +519,63 @@ func TestBatchModel_ProcessTool_HappyPath(t *testing.T) {
 	}
 }
 
+func TestBatchModel_PersistsAndResumesRun(t *testing.T) {
+	database, err := db.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create in-memory db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	ctx := context.Background()
+	var tools []db.SearchResult
+	for _, slug := range []string{"one", "two"} {
+		tool := db.Tool{ID: "id-" + slug, Slug: slug, Name: slug}
+		if err := database.UpsertTool(ctx, &tool); err != nil {
+			t.Fatalf("failed to seed tool: %v", err)
+		}
+		inst := &db.InstallInstruction{ID: "inst-" + slug, ToolID: tool.ID, Platform: "linux", Command: "echo " + slug}
+		if err := database.UpsertInstallInstruction(ctx, inst); err != nil {
+			t.Fatalf("failed to seed install instruction: %v", err)
+		}
+		tools = append(tools, db.SearchResult{Tool: tool})
+	}
+
+	batch := NewBatchInstallModel(database)
+	batch.limit = 1
+	start, ok := batch.StartInstall(tools)().(batchInstallStartMsg)
+	if !ok || start.run == nil {
+		t.Fatalf("expected the batch to be recorded, got %+v", start)
+	}
+	batch.Schedule(start)
+	batch.HandleProgress(batchInstallProgressMsg{toolID: "id-one", output: "one"})
+
+	// The TUI closes while two is installing.
+	run, err := database.LatestBatchRun()
+	if err != nil || run == nil {
+		t.Fatalf("expected the batch run, got %+v (%v)", run, err)
+	}
+	if run.Tools[0].Status != db.BatchCompleted || run.Tools[1].Status != db.BatchRunning {
+		t.Fatalf("unexpected statuses: %+v", run.Tools)
+	}
+
+	resumed := NewBatchInstallModel(database)
+	start, ok = resumed.ResumeInstall(run)().(batchInstallStartMsg)
+	if !ok || len(start.jobs) != 1 || start.jobs[0].command != "echo two" {
+		t.Fatalf("expected only two to be resumed, got %+v", start)
+	}
+	if start.run == nil || start.run.id != run.ID || start.run.positions[0] != 1 {
+		t.Fatalf("expected the resumed batch to update its run, got %+v", start.run)
+	}
+	resumed.Schedule(start)
+	if finished, _ := resumed.HandleProgress(batchInstallProgressMsg{toolID: "id-two"}); !finished {
+		t.Fatal("expected the resumed batch to finish")
+	}
+
+	if run, err := database.LatestBatchRun(); err != nil || run != nil {
+		t.Errorf("expected the finished run to be dropped, got %+v (%v)", run, err)
+	}
+}
+
 func TestBatchModel_ProcessTool_SkipIfBlind_NoInstalls(t *testing.T) {
 	database, err := db.New(":memory:")
 	if err != nil {
@@ -442,12 +594,11 @@ func TestBatchModel_ProcessTool_SkipIfBlind_NoInstalls(t *testing.T) {
 	batch.StartBatchConfig(false)
 	batch.Config().SkipIfBlind = true
 
-	batch.progress = &BatchInstallProgress{
-		Tools: []db.SearchResult{
-			{Tool: db.Tool{ID: "t1", Name: "Tool1"}},
-		},
-		CurrentIndex: 0,
+	start, ok := batch.StartInstall([]db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}})().(batchInstallStartMsg)
+	if !ok {
+		t.Fatal("expected batchInstallStartMsg")
 	}
+	batch.Schedule(start)
 
 	cmd := batch.ProcessTool(0)
 	msg := cmd()
@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"troveler/internal/install"
+)
+
+// installTermCols is the width of the terminal install commands run in, the
+// widest the install modal shows their output.
+const installTermCols = 96
+
+// installLogRefresh is how often the install modal redraws while a command
+// runs, picking up the output logged since.
+const installLogRefresh = 100 * time.Millisecond
+
+// InstallLog collects the output of an install command line by line while it
+// runs and keeps all of it once it ends; replies typed in the install modal
+// go the other way, to the command's terminal. The command writes its
+// output from another goroutine while the TUI renders, so access is
+// serialized.
+type InstallLog struct {
+	mu       sync.Mutex
+	lines    []string
+	partial  []byte // output after the last newline
+	started  time.Time
+	finished time.Time
+	cancel   context.CancelFunc
+	canceled bool
+	input    *io.PipeReader
+	replies  *io.PipeWriter
+}
+
+// NewInstallLog starts the log of a command that cancel stops.
+func NewInstallLog(cancel context.CancelFunc) *InstallLog {
+	input, replies := io.Pipe()
+
+	return &InstallLog{started: time.Now(), cancel: cancel, input: input, replies: replies}
+}
+
+// newLoggedRun returns the context to run an install command in and the log
+// to write its output to.
+func newLoggedRun() (context.Context, *InstallLog) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return ctx, NewInstallLog(cancel)
+}
+
+func (l *InstallLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		l.lines = append(l.lines, logLine(l.partial[:i]))
+		l.partial = l.partial[i+1:]
+	}
+}
+
+// logLine is what a terminal would show of line: escape sequences (colors,
+// cursor movement) are dropped, progress bars redraw themselves after a
+// carriage return, so only the text after the last one remains, and
+// backspaces erase.
+func logLine(line []byte) string {
+	text := strings.TrimRight(ansi.Strip(string(line)), "\r")
+	if i := strings.LastIndexByte(text, '\r'); i >= 0 {
+		text = text[i+1:]
+	}
+
+	shown := make([]rune, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\b':
+			if len(shown) > 0 {
+				shown = shown[:len(shown)-1]
+			}
+		case r == '\t':
+			shown = append(shown, ' ', ' ', ' ', ' ')
+		case r >= ' ' && r != 0x7f:
+			shown = append(shown, r)
+		}
+	}
+
+	return string(shown)
+}
+
+// Lines returns the output so far, including a line still being written.
+func (l *InstallLog) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lines := append([]string(nil), l.lines...)
+	if len(l.partial) > 0 {
+		lines = append(lines, logLine(l.partial))
+	}
+
+	return lines
+}
+
+// String returns the whole output.
+func (l *InstallLog) String() string {
+	return strings.Join(l.Lines(), "\n")
+}
+
+// Elapsed is how long the command ran, or has been running.
+func (l *InstallLog) Elapsed() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.finished.IsZero() {
+		return time.Since(l.started)
+	}
+
+	return l.finished.Sub(l.started)
+}
+
+// Running reports whether the command has not ended yet.
+func (l *InstallLog) Running() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.finished.IsZero()
+}
+
+// Cancel stops the command if it still runs and reports whether it did.
+func (l *InstallLog) Cancel() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.finished.IsZero() || l.canceled {
+		return false
+	}
+	l.canceled = true
+	l.cancel()
+
+	return true
+}
+
+// Canceled reports whether the command was canceled.
+func (l *InstallLog) Canceled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.canceled
+}
+
+// Input is what the command reads as typed into its terminal.
+func (l *InstallLog) Input() io.Reader {
+	return l.input
+}
+
+// Reply types text and Enter into the terminal of the running command and
+// reports whether it still ran.
+func (l *InstallLog) Reply(text string) bool {
+	if !l.Running() {
+		return false
+	}
+	// The command may not read its input; it must not block the TUI.
+	go func() { _, _ = io.WriteString(l.replies, text+"\n") }()
+
+	return true
+}
+
+// AwaitsSudo reports whether the command waits for sudo's password.
+func (l *InstallLog) AwaitsSudo() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.finished.IsZero() && bytes.HasSuffix(l.partial, []byte(install.SudoPrompt))
+}
+
+// AsksSecret reports whether the command seems to wait for a password, so
+// a reply is better not shown.
+func (l *InstallLog) AsksSecret() bool {
+	l.mu.Lock()
+	prompt := strings.ToLower(string(l.partial))
+	l.mu.Unlock()
+
+	return strings.Contains(prompt, "password") || strings.Contains(prompt, "passphrase")
+}
+
+// finish records that the command ended.
+func (l *InstallLog) finish() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.finished = time.Now()
+	l.cancel()
+	_ = l.input.Close()
+}
+
+type installLogTickMsg struct{}
+
+func installLogTick() tea.Cmd {
+	return tea.Tick(installLogRefresh, func(time.Time) tea.Msg { return installLogTickMsg{} })
+}
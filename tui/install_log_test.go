@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"troveler/internal/install"
+)
+
+func TestInstallLog(t *testing.T) {
+	canceled := 0
+	log := NewInstallLog(func() { canceled++ })
+
+	_, _ = log.Write([]byte("Compiling foo\r\nDownloading  10%\rDownloading 100%\nhalf"))
+	_, _ = log.Write([]byte(" a line"))
+
+	want := []string{"Compiling foo", "Downloading 100%", "half a line"}
+	if got := log.Lines(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+	if !log.Running() {
+		t.Error("expected the log of a running command")
+	}
+
+	if !log.Cancel() || !log.Canceled() || canceled != 1 {
+		t.Fatal("expected Cancel to stop the running command")
+	}
+	if log.Cancel() {
+		t.Error("expected a second Cancel to do nothing")
+	}
+
+	log.finish()
+	if log.Running() || log.Elapsed() <= 0 {
+		t.Errorf("expected a finished log, got running=%v elapsed=%v", log.Running(), log.Elapsed())
+	}
+}
+
+func TestLogLine(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Compiling foo", "Compiling foo"},
+		{"colors", "\x1b[1;32m   Compiling\x1b[0m foo", "   Compiling foo"},
+		{"line ending", "done\r", "done"},
+		{"progress redraw", "\x1b[2K 10%\r\x1b[2K 55%\r\x1b[2K100%", "100%"},
+		{"backspace", "Pass\b\b\bxy", "Pxy"},
+		{"tab and bell", "a\tb\a", "a    b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logLine([]byte(tt.in)); got != tt.want {
+				t.Errorf("logLine(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallLog_Reply(t *testing.T) {
+	log := NewInstallLog(func() {})
+	_, _ = log.Write([]byte("Reading\n" + install.SudoPrompt))
+	if !log.AwaitsSudo() || !log.AsksSecret() {
+		t.Fatal("expected the log to wait for sudo's password")
+	}
+
+	if !log.Reply("s3cret") {
+		t.Fatal("expected a reply to a running command")
+	}
+	buf := make([]byte, 32)
+	n, _ := log.Input().Read(buf)
+	if string(buf[:n]) != "s3cret\n" {
+		t.Errorf("typed %q, want the reply and Enter", buf[:n])
+	}
+
+	log.finish()
+	if log.Reply("late") || log.AwaitsSudo() {
+		t.Error("expected no replies once the command ended")
+	}
+}
+
+func TestViewLog(t *testing.T) {
+	lines := []string{"one", "two", "three", "four", "five"}
+
+	tests := []struct {
+		name   string
+		scroll int
+		want   []string
+		hidden []string
+	}{
+		{"follows the end", 0, []string{"four", "five", "lines 4-5 of 5"}, []string{"three"}},
+		{"scrolled up", 2, []string{"two", "three", "lines 2-3 of 5"}, []string{"four"}},
+		{"clamped at the top", 10, []string{"one", "two", "lines 1-2 of 5"}, []string{"three"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := viewLog(lines, 2, 40, tt.scroll)
+			for _, want := range tt.want {
+				if !strings.Contains(view, want) {
+					t.Errorf("expected %q in:\n%s", want, view)
+				}
+			}
+			for _, hidden := range tt.hidden {
+				if strings.Contains(view, hidden) {
+					t.Errorf("expected %q to be scrolled out of:\n%s", hidden, view)
+				}
+			}
+		})
+	}
+
+	if view := viewLog(lines[:2], 5, 40, 0); strings.Contains(view, "lines") {
+		t.Errorf("expected no position line when the log fits:\n%s", view)
+	}
+}
@@ -23,6 +23,10 @@ type KeyMap struct {
 	Sort        key.Binding // Alt+s
 	OpenRepo    key.Binding // Alt+r
 	InfoModal   key.Binding // i for full-screen info modal
+	Queries     key.Binding // Ctrl+R for the saved query picker
+	History     key.Binding // Alt+h for the install history
+	Uninstall   key.Binding // Alt+x to uninstall the selected tool
+	Cancel      key.Binding // x to cancel the running install
 	Help        key.Binding // ?
 }
 
@@ -85,6 +89,22 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("i"),
 			key.WithHelp("i", "full info"),
 		),
+		Queries: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "saved queries"),
+		),
+		History: key.NewBinding(
+			key.WithKeys("alt+h"),
+			key.WithHelp("alt+h", "install history"),
+		),
+		Uninstall: key.NewBinding(
+			key.WithKeys("alt+x"),
+			key.WithHelp("alt+x", "uninstall"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "cancel install"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -102,7 +122,7 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right},
 		{k.Tab, k.Enter, k.Escape},
-		{k.Install, k.InstallMise, k.Update, k.Sort, k.InfoModal},
+		{k.Install, k.InstallMise, k.Uninstall, k.Update, k.Sort, k.InfoModal, k.Queries, k.History},
 		{k.Help, k.Quit},
 	}
 }
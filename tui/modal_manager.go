@@ -1,14 +1,18 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"troveler/db"
+	"troveler/internal/install"
 	"troveler/internal/update"
+	"troveler/tui/panels"
 	"troveler/tui/styles"
 )
 
@@ -20,6 +24,11 @@ type ModalManager struct {
 	showUpdateModal      bool
 	showInstallModal     bool
 	showBatchConfigModal bool
+	showQueryPicker      bool
+	showHistory          bool
+	showUninstall        bool
+	showRiskConfirm      bool
+	showResumeBatch      bool
 }
 
 // NewModalManager creates a ModalManager.
@@ -37,6 +46,11 @@ const (
 	ModalUpdate
 	ModalInstall
 	ModalBatchConfig
+	ModalQueryPicker
+	ModalHistory
+	ModalUninstall
+	ModalRiskConfirm
+	ModalResumeBatch
 )
 
 // ActiveModalType returns the type of the currently active modal.
@@ -52,6 +66,16 @@ func (mm *ModalManager) ActiveModalType() ModalType {
 		return ModalInstall
 	case mm.showBatchConfigModal:
 		return ModalBatchConfig
+	case mm.showQueryPicker:
+		return ModalQueryPicker
+	case mm.showHistory:
+		return ModalHistory
+	case mm.showUninstall:
+		return ModalUninstall
+	case mm.showRiskConfirm:
+		return ModalRiskConfirm
+	case mm.showResumeBatch:
+		return ModalResumeBatch
 	}
 	return ModalNone
 }
@@ -62,6 +86,11 @@ func (mm *ModalManager) ShowInfo()    { mm.showInfoModal = true }
 func (mm *ModalManager) ShowUpdate()  { mm.showUpdateModal = true }
 func (mm *ModalManager) ShowInstall() { mm.showInstallModal = true }
 func (mm *ModalManager) ShowBatchConfig() { mm.showBatchConfigModal = true }
+func (mm *ModalManager) ShowQueryPicker() { mm.showQueryPicker = true }
+func (mm *ModalManager) ShowHistory()     { mm.showHistory = true }
+func (mm *ModalManager) ShowUninstall()   { mm.showUninstall = true }
+func (mm *ModalManager) ShowRiskConfirm() { mm.showRiskConfirm = true }
+func (mm *ModalManager) ShowResumeBatch() { mm.showResumeBatch = true }
 
 // Getters for Model-level access (e.g. handleKeyPress checks these).
 func (mm *ModalManager) IsHelpShown()          bool { return mm.showHelp }
@@ -69,6 +98,11 @@ func (mm *ModalManager) IsInfoShown()          bool { return mm.showInfoModal }
 func (mm *ModalManager) IsUpdateShown()        bool { return mm.showUpdateModal }
 func (mm *ModalManager) IsInstallShown()       bool { return mm.showInstallModal }
 func (mm *ModalManager) IsBatchConfigShown()   bool { return mm.showBatchConfigModal }
+func (mm *ModalManager) IsQueryPickerShown()   bool { return mm.showQueryPicker }
+func (mm *ModalManager) IsHistoryShown()       bool { return mm.showHistory }
+func (mm *ModalManager) IsUninstallShown()     bool { return mm.showUninstall }
+func (mm *ModalManager) IsRiskConfirmShown()   bool { return mm.showRiskConfirm }
+func (mm *ModalManager) IsResumeBatchShown()   bool { return mm.showResumeBatch }
 
 // ToggleHelp toggles the help modal.
 func (mm *ModalManager) ToggleHelp() {
@@ -110,9 +144,54 @@ func (mm *ModalManager) HandleEscape(
 		mm.showBatchConfigModal = false
 		return ModalBatchConfig, nil
 	}
+	if mm.showQueryPicker {
+		mm.showQueryPicker = false
+		return ModalQueryPicker, nil
+	}
+	if mm.showHistory {
+		mm.showHistory = false
+		return ModalHistory, nil
+	}
+	if mm.showUninstall {
+		mm.showUninstall = false
+		return ModalUninstall, nil
+	}
+	if mm.showRiskConfirm {
+		mm.showRiskConfirm = false
+		return ModalRiskConfirm, nil
+	}
+	if mm.showResumeBatch {
+		mm.showResumeBatch = false
+		return ModalResumeBatch, nil
+	}
 	return ModalNone, nil
 }
 
+// CloseQueryPicker hides the saved query picker.
+func (mm *ModalManager) CloseQueryPicker() {
+	mm.showQueryPicker = false
+}
+
+// CloseHistory hides the install history modal.
+func (mm *ModalManager) CloseHistory() {
+	mm.showHistory = false
+}
+
+// CloseUninstall hides the uninstall confirmation.
+func (mm *ModalManager) CloseUninstall() {
+	mm.showUninstall = false
+}
+
+// CloseRiskConfirm hides the risky install confirmation.
+func (mm *ModalManager) CloseRiskConfirm() {
+	mm.showRiskConfirm = false
+}
+
+// CloseResumeBatch hides the interrupted batch prompt.
+func (mm *ModalManager) CloseResumeBatch() {
+	mm.showResumeBatch = false
+}
+
 // CloseBatchConfig marks the batch config as done and clears its reference
 // (the caller is responsible for clearing m.batch via ClearConfig).
 func (mm *ModalManager) CloseBatchConfig() {
@@ -136,13 +215,22 @@ Selection:
   m            Mark/unmark tool for batch install
   Alt+I        Install (single or batch if marked)
   Alt+M        Install via mise (single or batch)
+  Alt+X        Uninstall the selected tool
 
 Actions:
+  Ctrl+R       Pick a saved query (search panel)
+  Alt+H        Browse the install history
   Alt+R        Open repository URL in browser
   Alt+U        Update database
   Alt+S        Toggle sort order
   i            Show full info modal
 
+Install output:
+  ↑/k, ↓/j     Scroll (PgUp/PgDn by page, End to follow)
+  ←/h, →/l     Show another tool's output (batch)
+  Enter        Reply to a prompt of the running install
+  x            Cancel the running install
+
 Other:
   ?            Show/hide this help
   Esc          Cancel / close modal
@@ -221,14 +309,22 @@ func (mm *ModalManager) ViewInfo(width, height int, tool *db.Tool, installs []db
 	)
 }
 
-// ViewUpdate renders the database update progress modal.
-func (mm *ModalManager) ViewUpdate(width, height int, updating bool, slugWave *update.SlugWave) string {
+// ViewUpdate renders the database update progress modal, and once the update
+// finished, its summary.
+func (mm *ModalManager) ViewUpdate(
+	width, height int, updating bool, slugWave *update.SlugWave, summary string,
+) string {
 	var content string
-	if updating && slugWave != nil {
+	switch {
+	case updating && slugWave != nil:
 		content = styles.TitleStyle.Render("Database Update") + "\n\n"
 		content += slugWave.RenderWithProgress() + "\n\n"
 		content += styles.HelpStyle.Render("Press Esc to cancel")
-	} else {
+	case !updating && summary != "":
+		content = styles.TitleStyle.Render("Database Update") + "\n\n"
+		content += summary + "\n\n"
+		content += styles.HelpStyle.Render("Press Esc to close")
+	default:
 		content = styles.TitleStyle.Render("Database Update") + "\n\n"
 		content += "Updating database...\n\n"
 		content += styles.HelpStyle.Render("Press Esc to close")
@@ -248,41 +344,68 @@ func (mm *ModalManager) ViewUpdate(width, height int, updating bool, slugWave *u
 	)
 }
 
-// ViewInstall renders the install execution modal.
-func (mm *ModalManager) ViewInstall(width, height int, executing bool, output string, err error, batchProgress *BatchInstallProgress) string {
+// installLogView is the state of the install log shown in the install modal.
+type installLogView struct {
+	log      *InstallLog // of a single install; a batch shows its focused job's
+	scroll   int         // lines scrolled up from the end
+	replying bool
+	reply    string
+}
+
+// ViewInstall renders the install execution modal. While the command runs
+// it shows its log live.
+func (mm *ModalManager) ViewInstall(
+	width, height int, executing bool, output string, err error, batchProgress *BatchInstallProgress,
+	lv installLogView,
+) string {
 	if batchProgress != nil {
-		return mm.viewBatchInstall(width, height, batchProgress)
+		return mm.viewBatchInstall(width, height, batchProgress, lv)
 	}
+	log, scroll := lv.log, lv.scroll
 
+	boxWidth, boxHeight := min(100, width-4), min(30, height-4)
+	logRows := max(boxHeight-10, 3)
 	var content string
 
 	if executing {
 		content = styles.TitleStyle.Render("Executing Install Command") + "\n\n"
-		content += "Running install command...\n\n"
-		content += styles.MutedStyle.Render("This may take a moment depending on your package manager") + "\n\n"
-		content += styles.HelpStyle.Render("Please wait...")
+		if log == nil {
+			content += "Running install command...\n\n"
+			content += styles.MutedStyle.Render("This may take a moment depending on your package manager") + "\n\n"
+			content += styles.HelpStyle.Render("Please wait...")
+		} else {
+			content += styles.HighlightStyle.Render("Running "+log.Elapsed().Round(time.Second).String()) + "\n\n"
+			content += viewLog(log.Lines(), logRows, boxWidth-4, scroll) + "\n"
+			content += viewReply(log, lv) + "\n\n"
+			content += styles.HelpStyle.Render(installLogHelp(lv, false))
+		}
 	} else {
-		content = styles.TitleStyle.Render("Install Complete") + "\n\n"
+		title := "Install Complete"
+		var blocked *install.RiskBlockedError
+		var invalid *invalidPolicyError
+		if errors.As(err, &blocked) || errors.As(err, &invalid) {
+			title = "Install Blocked"
+		}
+		content = styles.TitleStyle.Render(title) + "\n\n"
 
 		if err != nil {
 			content += styles.ErrorStyle.Render(fmt.Sprintf("Error: %v\n\n", err))
-			content += styles.HighlightStyle.Render("Output:\n")
-			content += styles.MutedStyle.Render(output)
 		} else {
 			content += styles.HighlightStyle.Render("Command executed successfully\n\n")
-			if output != "" {
-				content += styles.HighlightStyle.Render("Output:\n")
-				content += styles.MutedStyle.Render(output)
-			}
 		}
-		content += "\n\n" + styles.HelpStyle.Render("Press Esc to close")
+		content += viewInstallOutput(output, log, logRows-2, boxWidth-4, scroll, err != nil)
+		help := "Press Esc to close"
+		if log != nil {
+			help = "↑/↓ PgUp/PgDn to scroll | " + help
+		}
+		content += "\n\n" + styles.HelpStyle.Render(help)
 	}
 
 	modalBox := styles.BorderStyle.
 		BorderForeground(lipgloss.Color("#00FFFF")).
 		Padding(1, 2).
-		Width(min(100, width-4)).
-		Height(min(30, height-4)).
+		Width(boxWidth).
+		Height(boxHeight).
 		Render(content)
 
 	return lipgloss.Place(
@@ -292,10 +415,59 @@ func (mm *ModalManager) ViewInstall(width, height int, executing bool, output st
 	)
 }
 
-// viewBatchInstall renders the batch install progress modal.
-func (mm *ModalManager) viewBatchInstall(width, height int, bp *BatchInstallProgress) string {
+// viewInstallOutput renders the output of a finished command: all of it,
+// scrollable, when it was logged, else its tail. Without an error an empty
+// output is left out.
+func viewInstallOutput(output string, log *InstallLog, rows, width, scroll int, failed bool) string {
+	if log == nil {
+		if output == "" && !failed {
+			return ""
+		}
+
+		return styles.HighlightStyle.Render("Output:\n") + styles.MutedStyle.Render(output)
+	}
+
+	lines := log.Lines()
+	if len(lines) == 0 && !failed {
+		return styles.MutedStyle.Render("Finished in " + log.Elapsed().Round(time.Second).String())
+	}
+
+	return styles.HighlightStyle.Render(fmt.Sprintf("Output (finished in %s):\n", log.Elapsed().Round(time.Second))) +
+		viewLog(lines, rows, width, scroll)
+}
+
+// viewLog renders the rows lines of an install log ending scroll lines
+// before its end, cut to width. A scrolled or overflowing log gets a
+// position line.
+func viewLog(lines []string, rows, width, scroll int) string {
+	end := len(lines) - min(scroll, max(len(lines)-rows, 0))
+	start := max(0, end-rows)
+
+	shown := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		shown = append(shown, truncate(line, max(width, 10)))
+	}
+	content := styles.MutedStyle.Render(strings.Join(shown, "\n"))
+	if start > 0 || end < len(lines) {
+		content += "\n" + styles.SubtitleStyle.Render(fmt.Sprintf("lines %d-%d of %d", start+1, end, len(lines)))
+	}
+
+	return content
+}
+
+// viewBatchInstall renders the batch install progress modal with the log of
+// the focused job.
+func (mm *ModalManager) viewBatchInstall(width, height int, bp *BatchInstallProgress, lv installLogView) string {
 	var content string
 
+	boxWidth := min(100, width-4)
+	focus := bp.FocusedJob()
+	jobRows := max(height-16, 5)
+	if focus >= 0 {
+		jobRows = max(min(len(bp.Jobs)+1, (height-16)/3), 3)
+	}
+	logRows := max(height-20-jobRows, 3)
+
 	if bp.IsComplete {
 		content = styles.TitleStyle.Render("Batch Install Complete") + "\n\n"
 
@@ -308,33 +480,40 @@ func (mm *ModalManager) viewBatchInstall(width, height int, bp *BatchInstallProg
 		if len(bp.Skipped) > 0 {
 			content += styles.MutedStyle.Render(fmt.Sprintf("Skipped: %d\n", len(bp.Skipped)))
 		}
+		if focus >= 0 {
+			content += "\n" + viewBatchJobs(bp, jobRows) + "\n\n" + viewBatchLog(bp, focus, logRows, boxWidth-4, lv.scroll)
+		}
 
-		content += "\n" + styles.HelpStyle.Render("Press Esc to close")
+		help := "Press Esc to close"
+		if focus >= 0 {
+			help = "←/→ job | ↑/↓ PgUp/PgDn to scroll | " + help
+		}
+		content += "\n" + styles.HelpStyle.Render(help)
 	} else {
-		current := bp.CurrentIndex + 1
 		total := len(bp.Tools)
-		content = styles.TitleStyle.Render(fmt.Sprintf("Batch Install (%d/%d)", current, total)) + "\n\n"
-
-		if bp.CurrentIndex < len(bp.Tools) {
-			tool := bp.Tools[bp.CurrentIndex]
-			content += styles.HighlightStyle.Render("Installing: ") + tool.Name + "\n\n"
+		content = styles.TitleStyle.Render(fmt.Sprintf("Batch Install (%d/%d, %d running)",
+			bp.Finished(), total, bp.Running())) + "\n\n"
+		content += viewBatchJobs(bp, jobRows) + "\n\n"
+		if focus >= 0 {
+			content += viewBatchLog(bp, focus, logRows, boxWidth-4, lv.scroll) + "\n"
+			content += viewReply(bp.Jobs[focus].Log, lv) + "\n\n"
 		}
 
-		content += styles.MutedStyle.Render("Please wait...") + "\n"
-
 		progressWidth := 40
-		completed := bp.CurrentIndex
-		pct := float64(completed) / float64(total)
+		pct := float64(bp.Finished()) / float64(total)
 		filled := int(pct * float64(progressWidth))
 		empty := progressWidth - filled
 		bar := "[" + strings.Repeat("=", filled) + strings.Repeat(" ", empty) + "]"
 		content += styles.SubtitleStyle.Render(bar) + "\n"
+		if focus >= 0 {
+			content += styles.HelpStyle.Render(installLogHelp(lv, true))
+		}
 	}
 
 	modalBox := styles.BorderStyle.
 		BorderForeground(lipgloss.Color("#00FFFF")).
 		Padding(1, 2).
-		Width(min(70, width-4)).
+		Width(boxWidth).
 		Render(content)
 
 	return lipgloss.Place(
@@ -344,6 +523,107 @@ func (mm *ModalManager) viewBatchInstall(width, height int, bp *BatchInstallProg
 	)
 }
 
+// viewReply renders the reply being typed to the running command, hidden
+// when it asks for a password.
+func viewReply(log *InstallLog, lv installLogView) string {
+	if !lv.replying {
+		return ""
+	}
+
+	reply := lv.reply
+	if log != nil && log.AsksSecret() {
+		reply = strings.Repeat("•", len([]rune(reply)))
+	}
+
+	return styles.HighlightStyle.Render("> ") + reply + "█"
+}
+
+// installLogHelp lists the keys of the install modal while a command runs.
+func installLogHelp(lv installLogView, batch bool) string {
+	if lv.replying {
+		return "Enter to send | Esc to stop replying"
+	}
+	help := "↑/↓ PgUp/PgDn to scroll | Enter to reply | x to cancel"
+	if batch {
+		help = "←/→ job | " + help + " the job"
+	}
+
+	return help
+}
+
+// viewBatchLog renders the log of the focused batch job under a header
+// naming it.
+func viewBatchLog(bp *BatchInstallProgress, focus, rows, width, scroll int) string {
+	job := bp.Jobs[focus]
+	header := fmt.Sprintf("── %s: %s", bp.Tools[focus].Name, batchJobStatus(job))
+	if job.Log.Canceled() {
+		header += styles.ErrorStyle.Render(" (canceled)")
+	}
+
+	return header + "\n" + viewLog(job.Log.Lines(), rows, width, scroll)
+}
+
+// batchJobIcons marks the state of each batch job row.
+var batchJobIcons = map[BatchJobState]string{
+	JobQueued: "·", JobRunning: "▶", JobCompleted: "✓", JobFailed: "✗", JobSkipped: "○",
+}
+
+// viewBatchJobs renders one row per batch tool, at most maxRows, starting
+// just before the first unfinished one so running installs stay in view.
+func viewBatchJobs(bp *BatchInstallProgress, maxRows int) string {
+	first := 0
+	for first < len(bp.Jobs) && bp.Jobs[first].State > JobRunning {
+		first++
+	}
+	first = max(0, min(first-1, len(bp.Jobs)-maxRows))
+
+	focus := bp.FocusedJob()
+	var rows []string
+	for i := first; i < len(bp.Jobs) && len(rows) < maxRows; i++ {
+		job := bp.Jobs[i]
+		marker := " "
+		if i == focus {
+			marker = "›"
+		}
+		row := fmt.Sprintf("%s%s %-16s %s", marker, batchJobIcons[job.State], truncate(bp.Tools[i].Name, 16),
+			batchJobStatus(job))
+		if job.Command != "" && job.State <= JobRunning {
+			row += "  " + styles.MutedStyle.Render(truncate(job.Command, 36))
+		}
+		rows = append(rows, row)
+	}
+	if hidden := len(bp.Jobs) - len(rows); hidden > 0 {
+		rows = append(rows, styles.MutedStyle.Render(fmt.Sprintf("… %d more", hidden)))
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func batchJobStatus(job BatchJobStatus) string {
+	switch job.State {
+	case JobQueued:
+		return styles.MutedStyle.Render("queued")
+	case JobRunning:
+		return styles.HighlightStyle.Render("running " + time.Since(job.Started).Round(time.Second).String())
+	case JobFailed:
+		return styles.ErrorStyle.Render("failed")
+	case JobSkipped:
+		return styles.MutedStyle.Render("skipped")
+	default:
+		return "done in " + job.Elapsed.Round(time.Second).String()
+	}
+}
+
+// truncate shortens s to at most n runes, ending in "..." when cut.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n-3]) + "..."
+}
+
 // ViewBatchConfig renders the batch install configuration wizard modal.
 func (mm *ModalManager) ViewBatchConfig(width, height int, config *BatchInstallConfig, markedCount int) string {
 	if config == nil {
@@ -379,3 +659,234 @@ func (mm *ModalManager) ViewBatchConfig(width, height int, config *BatchInstallC
 		modalBox,
 	)
 }
+
+// ViewQueryPicker renders the saved query picker.
+func (mm *ModalManager) ViewQueryPicker(width, height int, queries []db.SavedQuery, cursor int) string {
+	content := styles.TitleStyle.Render("Saved Queries") + "\n\n"
+
+	if len(queries) == 0 {
+		content += styles.MutedStyle.Render("No saved queries yet.") + "\n\n"
+		content += styles.MutedStyle.Render(`Save one with: troveler query save <name> "<expression>"`) + "\n"
+	}
+
+	for i, q := range queries {
+		line := fmt.Sprintf("@%-16s %s", q.Name, q.Expression)
+		if i == cursor {
+			content += styles.SelectedStyle.Render("> "+line) + "\n"
+		} else {
+			content += styles.UnselectedStyle.Render("  "+line) + "\n"
+		}
+	}
+
+	content += "\n" + styles.HelpStyle.Render("↑/↓ to move | Enter to search | Esc to cancel")
+
+	modalBox := styles.BorderStyle.
+		BorderForeground(lipgloss.Color("#00FFFF")).
+		Padding(1, 2).
+		Width(min(80, width-4)).
+		Render(content)
+
+	return lipgloss.Place(
+		width, height,
+		lipgloss.Center, lipgloss.Center,
+		modalBox,
+	)
+}
+
+// ViewHistory renders the install history browser: a list of installs with
+// the details and output tail of the one under the cursor.
+func (mm *ModalManager) ViewHistory(width, height int, records []db.InstallRecord, cursor int) string {
+	content := styles.TitleStyle.Render("Install History") + "\n\n"
+
+	if len(records) == 0 {
+		content += styles.MutedStyle.Render("No installs recorded yet.") + "\n"
+	}
+
+	// Show a window of entries around the cursor.
+	const visible = 8
+	start := max(0, min(cursor-visible/2, len(records)-visible))
+	end := min(len(records), start+visible)
+
+	for i := start; i < end; i++ {
+		rec := records[i]
+		status := "✓"
+		if !rec.Succeeded() {
+			status = "✗"
+		}
+		line := fmt.Sprintf("%s %s  %-9s %-18s %s", status, rec.InstalledAt.Local().Format("2006-01-02 15:04"),
+			rec.Action, rec.Slug, rec.Platform)
+		if i == cursor {
+			content += styles.SelectedStyle.Render("> "+line) + "\n"
+		} else {
+			content += styles.UnselectedStyle.Render("  "+line) + "\n"
+		}
+	}
+
+	if cursor >= 0 && cursor < len(records) {
+		content += "\n" + viewHistoryDetails(&records[cursor])
+	}
+
+	content += "\n" + styles.HelpStyle.Render("↑/↓ to move | Esc to close")
+
+	modalBox := styles.BorderStyle.
+		BorderForeground(lipgloss.Color("#00FFFF")).
+		Padding(1, 2).
+		Width(min(100, width-4)).
+		Render(content)
+
+	return lipgloss.Place(
+		width, height,
+		lipgloss.Center, lipgloss.Center,
+		modalBox,
+	)
+}
+
+func viewHistoryDetails(rec *db.InstallRecord) string {
+	content := styles.HighlightStyle.Render("Command: ") + rec.Command + "\n"
+
+	result := fmt.Sprintf("exit %d after %s", rec.ExitCode, rec.Duration.Round(time.Second/10))
+	if rec.Succeeded() {
+		content += styles.MutedStyle.Render("Result:  ") + result + "\n"
+	} else {
+		content += styles.MutedStyle.Render("Result:  ") + styles.ErrorStyle.Render(result) + "\n"
+	}
+	if rec.User != "" {
+		content += styles.MutedStyle.Render("User:    ") + rec.User + "\n"
+	}
+
+	// Only the last few lines of output fit below the list.
+	const outputLines = 8
+	lines := strings.Split(strings.TrimRight(rec.Output, "\n"), "\n")
+	if len(lines) > outputLines {
+		lines = lines[len(lines)-outputLines:]
+	}
+	if output := strings.Join(lines, "\n"); strings.TrimSpace(output) != "" {
+		content += "\n" + styles.MutedStyle.Render(output) + "\n"
+	}
+
+	return content
+}
+
+// ViewUninstall renders the uninstall confirmation for the selected tool.
+// When no uninstall command could be derived, err explains why.
+func (mm *ModalManager) ViewUninstall(
+	width, height int, tool *db.Tool, plan *install.UninstallPlan, command string, err error,
+) string {
+	name := ""
+	if tool != nil {
+		name = tool.Name
+	}
+	content := styles.TitleStyle.Render("Uninstall "+name) + "\n\n"
+
+	if plan != nil {
+		source := "catalog, no install recorded"
+		if plan.FromHistory {
+			source = "install history"
+		}
+		content += styles.MutedStyle.Render("Installed with: ") + plan.InstallCommand + "\n"
+		content += styles.MutedStyle.Render("  ("+source+")") + "\n\n"
+	}
+
+	if err != nil {
+		content += styles.ErrorStyle.Render(fmt.Sprintf("Cannot uninstall: %v", err)) + "\n"
+		content += "\n" + styles.HelpStyle.Render("Press Esc to close")
+	} else {
+		content += styles.HighlightStyle.Render("Uninstall with: ") + command + "\n"
+		content += "\n" + styles.HelpStyle.Render("Enter to run | Esc to cancel")
+	}
+
+	modalBox := styles.BorderStyle.
+		BorderForeground(lipgloss.Color("#00FFFF")).
+		Padding(1, 2).
+		Width(min(90, width-4)).
+		Render(content)
+
+	return lipgloss.Place(
+		width, height,
+		lipgloss.Center, lipgloss.Center,
+		modalBox,
+	)
+}
+
+// ViewRiskConfirm renders the confirmation the install risk policy requires
+// before running command.
+func (mm *ModalManager) ViewRiskConfirm(width, height int, command string, classes []install.RiskClass) string {
+	content := styles.TitleStyle.Render("Confirm Risky Install") + "\n\n"
+	content += styles.HighlightStyle.Render("Command: ") + command + "\n"
+	content += styles.MutedStyle.Render("Risk: ") + panels.RiskBadge(command) + "\n\n"
+	content += styles.ErrorStyle.Render("The install risk policy requires confirmation for: "+
+		install.FormatRiskClasses(classes)) + "\n"
+	content += "\n" + styles.HelpStyle.Render("Enter to run | Esc to cancel")
+
+	modalBox := styles.BorderStyle.
+		BorderForeground(lipgloss.Color("#FFA500")).
+		Padding(1, 2).
+		Width(min(90, width-4)).
+		Render(content)
+
+	return lipgloss.Place(
+		width, height,
+		lipgloss.Center, lipgloss.Center,
+		modalBox,
+	)
+}
+
+// ViewResumeBatch renders the prompt to continue the batch install run was
+// interrupted in.
+func (mm *ModalManager) ViewResumeBatch(width, height int, run *db.BatchRun) string {
+	left := run.Unfinished()
+	content := styles.TitleStyle.Render("Resume Batch Install?") + "\n\n"
+	content += fmt.Sprintf("A batch install started %s was interrupted with %d of %d tools left:\n\n",
+		run.StartedAt.Local().Format("2006-01-02 15:04"), len(left), len(run.Tools))
+
+	const maxListed = 8
+	for i, tool := range left {
+		if i == maxListed {
+			content += styles.MutedStyle.Render(fmt.Sprintf("  … %d more", len(left)-maxListed)) + "\n"
+
+			break
+		}
+		line := "  " + tool.Slug
+		if tool.Status == db.BatchRunning {
+			line += styles.MutedStyle.Render(" (was installing)")
+		}
+		content += line + "\n"
+	}
+
+	if opts := batchOptionLabels(run.Options); opts != "" {
+		content += "\n" + styles.MutedStyle.Render("Options: ") + opts + "\n"
+	}
+	content += "\n" + styles.HelpStyle.Render("Enter to resume | d to discard | Esc to decide later")
+
+	modalBox := styles.BorderStyle.
+		BorderForeground(lipgloss.Color("#FFA500")).
+		Padding(1, 2).
+		Width(min(90, width-4)).
+		Render(content)
+
+	return lipgloss.Place(
+		width, height,
+		lipgloss.Center, lipgloss.Center,
+		modalBox,
+	)
+}
+
+// batchOptionLabels lists the batch install options a run was started with.
+func batchOptionLabels(opts db.BatchOptions) string {
+	var labels []string
+	if opts.UseMise {
+		labels = append(labels, "mise")
+	}
+	if opts.UseSudo {
+		label := "sudo"
+		if opts.SudoOnlySystem {
+			label += " (system packages only)"
+		}
+		labels = append(labels, label)
+	}
+	if opts.SkipIfBlind {
+		labels = append(labels, "skip blind installs")
+	}
+
+	return strings.Join(labels, ", ")
+}
@@ -7,6 +7,7 @@ import (
 
 	"troveler/config"
 	"troveler/db"
+	"troveler/internal/install"
 	"troveler/internal/search"
 	"troveler/tui/panels"
 )
@@ -28,6 +29,8 @@ type Model struct {
 	db            *db.SQLiteDB
 	config        *config.Config
 	searchService *search.Service
+	riskPolicy    *install.RiskPolicy
+	riskPolicyErr error // invalid install risk policy; installs are refused
 
 	// Terminal size
 	width  int
@@ -51,10 +54,15 @@ type Model struct {
 	selectedTool *db.Tool
 	installs     []db.InstallInstruction
 	searching    bool
+	fuzzy        bool // m.tools are fuzzy matches
 
 	// Install execution state
 	executing     bool
 	executeOutput string
+	installLog    *InstallLog // output of the last single install or uninstall
+	logScroll     int         // lines the install log is scrolled up from its end
+	replying      bool        // typing a reply to the running install
+	reply         string
 
 	// Batch install state
 	batch *BatchInstallModel
@@ -62,6 +70,25 @@ type Model struct {
 	// Update state
 	update *UpdateModel
 
+	// Saved query picker state
+	savedQueries []db.SavedQuery
+	queryCursor  int
+
+	// Install history browser state
+	history       []db.InstallRecord
+	historyCursor int
+
+	// Uninstall confirmation state
+	uninstallTool *db.Tool
+	uninstallPlan *install.UninstallPlan
+	uninstallErr  error
+
+	// Install awaiting the confirmation the risk policy requires
+	riskPending *pendingInstall
+
+	// Interrupted batch install offered for resuming
+	pendingBatch *db.BatchRun
+
 	// Error state
 	err error
 }
@@ -79,14 +106,25 @@ func NewModel(database *db.SQLiteDB, cfg *config.Config) *Model {
 		cfg.Install.FallbackPlatform,
 	)
 
+	// An invalid policy refuses every install rather than allowing them all.
+	policy, policyErr := install.NewRiskPolicy(cfg.Install.BlockRisks, cfg.Install.ConfirmRisks)
+	if policyErr != nil {
+		policyErr = &invalidPolicyError{err: policyErr}
+	}
+	batch := NewBatchInstallModel(database)
+	batch.policy, batch.policyErr = policy, policyErr
+	batch.limit = cfg.Install.Jobs
+
 	m := &Model{
 		db:            database,
 		config:        cfg,
 		searchService: search.NewService(database),
+		riskPolicy:    policy,
+		riskPolicyErr: policyErr,
 		modals:        NewModalManager(),
 		keys:          DefaultKeyMap(),
-		batch:         NewBatchInstallModel(database),
-		update:        NewUpdateModel(database),
+		batch:         batch,
+		update:        NewUpdateModel(database, cfg),
 		activePanel:   PanelSearch,
 		searchPanel:   searchPanel,
 		toolsPanel:    toolsPanel,
@@ -100,8 +138,10 @@ func NewModel(database *db.SQLiteDB, cfg *config.Config) *Model {
 
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
-	// Load initial tools (empty query = all tools)
-	return m.performSearch("")
+	// Load initial tools (empty query = all tools) and rescan PATH in the
+	// background; the search reruns if the scan changed anything. An
+	// interrupted batch install is offered for resuming.
+	return tea.Batch(m.performSearch(""), m.scanInstalled(), m.loadUnfinishedBatch())
 }
 
 // SetSize sets the terminal size
@@ -116,7 +156,6 @@ func (m *Model) performSearch(query string) tea.Cmd {
 		opts := search.Options{
 			Query:     query,
 			Limit:     1000,
-			SortField: "name",
 			SortOrder: "ASC",
 		}
 
@@ -126,16 +165,20 @@ func (m *Model) performSearch(query string) tea.Cmd {
 		}
 
 		return searchResultMsg{
-			tools: result.Tools,
-			query: query,
+			tools:     result.Tools,
+			query:     query,
+			sortField: result.SortField,
+			fuzzy:     result.Fuzzy,
 		}
 	}
 }
 
 // searchResultMsg contains search results
 type searchResultMsg struct {
-	tools []db.SearchResult
-	query string
+	tools     []db.SearchResult
+	query     string
+	sortField string
+	fuzzy     bool
 }
 
 // searchErrorMsg contains search errors
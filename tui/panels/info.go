@@ -39,6 +39,15 @@ func (p *InfoPanel) SetTool(tool *db.Tool, installs []db.InstallInstruction) {
 	p.updateContent()
 }
 
+// SetOwner shows which package manager owns the displayed tool's executable.
+func (p *InfoPanel) SetOwner(owner *db.ToolOwner) {
+	if p.tool == nil {
+		return
+	}
+	p.tool.SetOwner(owner)
+	p.updateContent()
+}
+
 // Clear clears the displayed tool
 func (p *InfoPanel) Clear() {
 	p.tool = nil
@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"troveler/db"
 	"troveler/internal/install"
@@ -27,12 +28,14 @@ type InstallPanel struct {
 
 // InstallExecuteMsg is sent when user wants to execute install
 type InstallExecuteMsg struct {
-	Command string
+	Command  string
+	Platform string
 }
 
 // InstallExecuteMiseMsg is sent when user wants to execute install via mise
 type InstallExecuteMiseMsg struct {
-	Command string
+	Command  string
+	Platform string
 }
 
 // NewInstallPanel creates a new install panel
@@ -152,10 +155,10 @@ func (p *InstallPanel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case msg.Alt && (msg.Type == tea.KeyRunes && len(msg.Runes) > 0 && msg.Runes[0] == 'i'):
 			if p.cursor >= 0 && p.cursor < len(p.commands) {
-				cmd := p.commands[p.cursor].Command
+				selected := p.commands[p.cursor]
 
 				return p, func() tea.Msg {
-					return InstallExecuteMsg{Command: cmd}
+					return InstallExecuteMsg{Command: selected.Command, Platform: selected.Platform}
 				}
 			}
 
@@ -164,9 +167,10 @@ func (p *InstallPanel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case msg.Alt && (msg.Type == tea.KeyRunes && len(msg.Runes) > 0 && msg.Runes[0] == 'm'):
 			if p.cursor >= 0 && p.cursor < len(p.commands) {
 				cmd := install.TransformToMise(p.commands[p.cursor].Command)
+				platformID := p.commands[p.cursor].Platform
 
 				return p, func() tea.Msg {
-					return InstallExecuteMiseMsg{Command: cmd}
+					return InstallExecuteMiseMsg{Command: cmd, Platform: platformID}
 				}
 			}
 
@@ -177,6 +181,24 @@ func (p *InstallPanel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return p, nil
 }
 
+var riskStyles = map[install.RiskLevel]lipgloss.Style{
+	install.RiskLow:    styles.RiskLowStyle,
+	install.RiskMedium: styles.RiskMediumStyle,
+	install.RiskHigh:   styles.RiskHighStyle,
+}
+
+// RiskBadge renders the risk badge of command colored by its level, or ""
+// when the analyzer found nothing.
+func RiskBadge(command string) string {
+	risk := install.AnalyzeCommand(command)
+	style, ok := riskStyles[risk.Level]
+	if !ok {
+		return ""
+	}
+
+	return style.Render(risk.Badge())
+}
+
 // View renders the install panel
 func (p *InstallPanel) View() string {
 	if len(p.commands) == 0 {
@@ -206,6 +228,9 @@ func (p *InstallPanel) View() string {
 		}
 
 		b.WriteString(line)
+		if badge := RiskBadge(cmd.Command); badge != "" {
+			b.WriteString("  " + badge)
+		}
 		b.WriteString("\n")
 	}
 
@@ -148,6 +148,15 @@ func (p *SearchPanel) GetQuery() string {
 	return p.textInput.Value()
 }
 
+// SetQuery replaces the search text and searches for it immediately.
+func (p *SearchPanel) SetQuery(query string) tea.Cmd {
+	p.textInput.SetValue(query)
+	p.textInput.CursorEnd()
+	p.lastQuery = query
+
+	return p.triggerSearch(query)
+}
+
 // SetStyles updates the input style based on focus
 func (p *SearchPanel) SetStyles() {
 	if p.focused {
@@ -19,14 +19,15 @@ type ToolsPanel struct {
 	tools         []db.SearchResult
 	cursor        int
 	selectedCol   int // 0=slug, 1=tagline, 2=language, 3=installed
-	sortCol       int
+	sortCol       int // -1 while tools are ranked by search relevance
 	sortAscending bool
 	focused       bool
 	width         int
 	height        int
 	scrollOffset  int
-	installedMap  map[string]bool // Cache of installed status by tool ID
-	markedTools   map[string]bool // Set of marked tool IDs for batch install
+	installedMap  map[string]bool   // Cache of installed status by tool ID
+	outdatedMap   map[string]string // Latest version of outdated tools by tool ID
+	markedTools   map[string]bool   // Set of marked tool IDs for batch install
 }
 
 // ToolSelectedMsg is sent when a tool is selected (Enter pressed)
@@ -55,6 +56,7 @@ func NewToolsPanel() *ToolsPanel {
 		sortAscending: true,
 		focused:       false,
 		installedMap:  make(map[string]bool),
+		outdatedMap:   make(map[string]string),
 		markedTools:   make(map[string]bool),
 	}
 }
@@ -66,6 +68,18 @@ func (p *ToolsPanel) SetTools(tools []db.SearchResult) {
 	p.scrollOffset = 0
 }
 
+// SetRanked marks the tools as ordered by search relevance, which hides the
+// column sort indicator until the user sorts by a column again.
+func (p *ToolsPanel) SetRanked(ranked bool) {
+	switch {
+	case ranked:
+		p.sortCol = -1
+	case p.sortCol < 0:
+		p.sortCol = 0
+		p.sortAscending = true
+	}
+}
+
 // Update handles messages
 func (p *ToolsPanel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if !p.focused {
@@ -235,22 +249,22 @@ func (p *ToolsPanel) View() string {
 
 	var b strings.Builder
 
-	installedWidth := 9
-	nameWidth := 25
-	taglineWidth := p.width - nameWidth - installedWidth - 15 - 10
-	langWidth := 10
+	widths := columnWidths{name: 25, lang: 10, installed: 9, outdated: 10}
+	widths.tagline = p.width - widths.name - widths.installed - widths.outdated - 15 - 13
 
 	// Ensure taglineWidth doesn't become negative or too small
-	if taglineWidth < 10 {
-		taglineWidth = 10
+	if widths.tagline < 10 {
+		widths.tagline = 10
 	}
 
-	// Render header
+	// Render header. The outdated marker is informational and cannot be
+	// selected or sorted by.
 	headers := []string{
-		p.renderHeader("Name", 0, nameWidth),
-		p.renderHeader("Tagline", 1, taglineWidth),
-		p.renderHeader("Language", 2, langWidth),
-		p.renderHeader("Installed", 3, installedWidth),
+		p.renderHeader("Name", 0, widths.name),
+		p.renderHeader("Tagline", 1, widths.tagline),
+		p.renderHeader("Language", 2, widths.lang),
+		p.renderHeader("Installed", 3, widths.installed),
+		p.renderHeader("Outdated", outdatedCol, widths.outdated),
 	}
 	b.WriteString(strings.Join(headers, " │ "))
 	b.WriteString("\n")
@@ -270,7 +284,7 @@ func (p *ToolsPanel) View() string {
 
 	for i := p.scrollOffset; i < end; i++ {
 		tool := p.tools[i]
-		row := p.renderRow(i, tool, nameWidth, taglineWidth, langWidth, installedWidth)
+		row := p.renderRow(i, tool, widths)
 		b.WriteString(row)
 		b.WriteString("\n")
 	}
@@ -278,6 +292,15 @@ func (p *ToolsPanel) View() string {
 	return b.String()
 }
 
+// outdatedCol is the header index of the outdated marker, past the columns
+// the cursor can select.
+const outdatedCol = 4
+
+// columnWidths holds the rendered width of each table column.
+type columnWidths struct {
+	name, tagline, lang, installed, outdated int
+}
+
 // renderHeader renders a column header with sort indicator
 func (p *ToolsPanel) renderHeader(title string, col int, width int) string {
 	// Ensure width is positive
@@ -310,23 +333,21 @@ func (p *ToolsPanel) renderHeader(title string, col int, width int) string {
 }
 
 // renderRow renders a single tool row
-func (p *ToolsPanel) renderRow(
-	idx int, tool db.SearchResult, nameWidth, taglineWidth, langWidth, installedWidth int,
-) string {
+func (p *ToolsPanel) renderRow(idx int, tool db.SearchResult, widths columnWidths) string {
 	// Truncate fields to fit
 	name := tool.Name
-	if len(name) > nameWidth {
-		name = name[:nameWidth-3] + "..."
+	if len(name) > widths.name {
+		name = name[:widths.name-3] + "..."
 	}
 
 	tagline := tool.Tagline
-	if len(tagline) > taglineWidth {
-		tagline = tagline[:taglineWidth-3] + "..."
+	if len(tagline) > widths.tagline {
+		tagline = tagline[:widths.tagline-3] + "..."
 	}
 
 	lang := tool.Language
-	if len(lang) > langWidth {
-		lang = lang[:langWidth-3] + "..."
+	if len(lang) > widths.lang {
+		lang = lang[:widths.lang-3] + "..."
 	}
 
 	installed := ""
@@ -334,6 +355,14 @@ func (p *ToolsPanel) renderRow(
 		installed = "✓"
 	}
 
+	outdated := ""
+	if latest, ok := p.outdatedMap[tool.ID]; ok && tool.Installed {
+		outdated = "↑ " + latest
+		if len(outdated) > widths.outdated {
+			outdated = outdated[:widths.outdated]
+		}
+	}
+
 	// Check if tool is marked for batch install
 	isMarked := p.markedTools[tool.ID]
 	markIndicator := "  "
@@ -344,46 +373,25 @@ func (p *ToolsPanel) renderRow(
 	// Apply gradient color
 	gradient := styles.GetGradientColor(idx)
 
-	// Highlight if selected (cursor on this row)
-	if idx == p.cursor && p.focused {
-		var baseStyle lipgloss.Style
-		if isMarked {
-			baseStyle = styles.MarkedSelectedStyle.Foreground(gradient)
-		} else {
-			baseStyle = styles.SelectedStyle.Foreground(gradient)
-		}
-
-		return fmt.Sprintf("%s%s │ %s │ %s │ %s",
-			baseStyle.Width(2).Render(markIndicator),
-			baseStyle.Width(nameWidth-2).Render(name),
-			baseStyle.Width(taglineWidth).Render(tagline),
-			baseStyle.Width(langWidth).Render(lang),
-			baseStyle.Width(installedWidth).Render(installed),
-		)
-	}
-
-	// Marked but not selected
-	if isMarked {
-		markedStyle := styles.MarkedStyle.Foreground(gradient)
-
-		return fmt.Sprintf("%s%s │ %s │ %s │ %s",
-			markedStyle.Width(2).Render(markIndicator),
-			markedStyle.Width(nameWidth-2).Render(name),
-			markedStyle.Width(taglineWidth).Render(tagline),
-			markedStyle.Width(langWidth).Render(lang),
-			markedStyle.Width(installedWidth).Render(installed),
-		)
-	}
-
-	// Normal style with gradient
+	// Normal style with gradient, highlighted if selected (cursor on this
+	// row) or marked
 	style := lipgloss.NewStyle().Foreground(gradient)
+	switch {
+	case idx == p.cursor && p.focused && isMarked:
+		style = styles.MarkedSelectedStyle.Foreground(gradient)
+	case idx == p.cursor && p.focused:
+		style = styles.SelectedStyle.Foreground(gradient)
+	case isMarked:
+		style = styles.MarkedStyle.Foreground(gradient)
+	}
 
-	return fmt.Sprintf("%s%s │ %s │ %s │ %s",
+	return fmt.Sprintf("%s%s │ %s │ %s │ %s │ %s",
 		style.Width(2).Render(markIndicator),
-		style.Width(nameWidth-2).Render(name),
-		style.Width(taglineWidth).Render(tagline),
-		style.Width(langWidth).Render(lang),
-		style.Width(installedWidth).Render(installed),
+		style.Width(widths.name-2).Render(name),
+		style.Width(widths.tagline).Render(tagline),
+		style.Width(widths.lang).Render(lang),
+		style.Width(widths.installed).Render(installed),
+		style.Width(widths.outdated).Render(outdated),
 	)
 }
 
@@ -426,6 +434,9 @@ func (p *ToolsPanel) UpdateInstalledStatus(installs []db.InstallInstruction) {
 // UpdateToolInstalledStatus updates the installed status for a specific tool
 func (p *ToolsPanel) UpdateToolInstalledStatus(toolID string, isInstalled bool) {
 	p.installedMap[toolID] = isInstalled
+	if !isInstalled {
+		delete(p.outdatedMap, toolID)
+	}
 	for i := range p.tools {
 		if p.tools[i].ID == toolID {
 			p.tools[i].Installed = isInstalled
@@ -435,26 +446,37 @@ func (p *ToolsPanel) UpdateToolInstalledStatus(toolID string, isInstalled bool)
 	}
 }
 
-// UpdateAllInstalledStatus updates the installed status for all tools using
-// batch-fetched install instructions and a deduplicated LookPath cache.
+// UpdateAllInstalledStatus updates the installed status for all tools from
+// the installed state the last PATH scan stored.
 func (p *ToolsPanel) UpdateAllInstalledStatus(database *db.SQLiteDB) {
-	toolIDs := make([]string, len(p.tools))
-	for i, t := range p.tools {
-		toolIDs[i] = t.ID
+	if database == nil {
+		return
 	}
 
-	installsByTool, err := database.GetInstallInstructionsBatch(context.Background(), toolIDs)
+	states, err := database.GetInstalledState(context.Background())
 	if err != nil {
 		return
 	}
 
-	pathCache := db.BuildLookPathCache(installsByTool)
-
 	for i := range p.tools {
-		installs := installsByTool[p.tools[i].ID]
-		p.tools[i].Installed = db.IsInstalledCached(&p.tools[i].Tool, installs, pathCache)
+		p.tools[i].Installed = states[p.tools[i].ID].Installed
 		p.installedMap[p.tools[i].ID] = p.tools[i].Installed
 	}
+
+	if versions, err := database.GetToolVersions(); err == nil {
+		p.SetOutdated(versions)
+	}
+}
+
+// SetOutdated replaces the outdated markers with the stored version checks
+// that found a newer release.
+func (p *ToolsPanel) SetOutdated(versions map[string]db.ToolVersion) {
+	p.outdatedMap = make(map[string]string)
+	for id, v := range versions {
+		if v.Outdated {
+			p.outdatedMap[id] = v.Latest
+		}
+	}
 }
 
 // GetTool returns a tool by index
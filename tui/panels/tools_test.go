@@ -1,6 +1,7 @@
 package panels
 
 import (
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -237,3 +238,30 @@ func TestToolsPanelNarrowTerminal(t *testing.T) {
 		t.Error("Expected view to contain tool name with adequate width")
 	}
 }
+
+func TestToolsPanelOutdatedMarker(t *testing.T) {
+	panel := NewToolsPanel()
+	panel.SetTools([]db.SearchResult{
+		{Tool: db.Tool{ID: "1", Name: "ripgrep", Installed: true}},
+		{Tool: db.Tool{ID: "2", Name: "fd", Installed: true}},
+	})
+	panel.SetSize(120, 20)
+	panel.SetOutdated(map[string]db.ToolVersion{
+		"1": {ToolID: "1", Latest: "14.1.0", Outdated: true},
+		"2": {ToolID: "2", Latest: "10.1.0"},
+	})
+
+	view := panel.View()
+	if !strings.Contains(view, "Outdated") || !strings.Contains(view, "↑ 14.1.0") {
+		t.Errorf("expected outdated column with ripgrep's latest version, got:\n%s", view)
+	}
+	if strings.Contains(view, "↑ 10.1.0") {
+		t.Error("expected no marker for an up-to-date tool")
+	}
+
+	// Uninstalling a tool drops its marker.
+	panel.UpdateToolInstalledStatus("1", false)
+	if strings.Contains(panel.View(), "↑") {
+		t.Error("expected no marker after the tool was uninstalled")
+	}
+}
@@ -40,6 +40,11 @@ var (
 	StatusBarStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA"))
 	HelpStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
 
+	// Risk badges of install commands, by level
+	RiskLowStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#87CEEB"))
+	RiskMediumStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFA500"))
+	RiskHighStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FF5555"))
+
 	// Gradient colors (reuse from existing gradient)
 	GradientColors = ui.GradientColors
 )
@@ -36,6 +36,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case panels.ToolCursorChangedMsg:
 		return m.handleToolCursorChanged(msg)
 
+	case toolOwnerMsg:
+		return m.handleToolOwner(msg)
+
 	case panels.ToolSelectedMsg:
 		return m.handleToolSelected()
 
@@ -48,8 +51,24 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case installCompleteMsg:
 		return m.handleInstallComplete(msg)
 
+	case uninstallCompleteMsg:
+		return m.handleUninstallComplete(msg)
+
 	case batchInstallStartMsg:
-		return m, m.batch.ProcessTool(0)
+		return m, m.batch.Schedule(msg)
+
+	case unfinishedBatchMsg:
+		return m.handleUnfinishedBatch(msg)
+
+	case installLogTickMsg:
+		if !m.executing {
+			return m, nil
+		}
+
+		return m, installLogTick()
+
+	case batchTickMsg:
+		return m, m.batch.Tick()
 
 	case batchInstallProgressMsg:
 		return m.handleBatchInstallProgress(msg)
@@ -62,6 +81,12 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case slugTickMsg:
 		return m.handleSlugTick()
+
+	case installedScanTickMsg:
+		return m, m.scanInstalled()
+
+	case installedScannedMsg:
+		return m.handleInstalledScanned(msg)
 	}
 
 	return m.delegateToActivePanel(msg)
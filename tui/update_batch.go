@@ -14,6 +14,7 @@ func (m *Model) startBatchInstall() tea.Cmd {
 	}
 
 	m.executing = true
+	m.logScroll = 0
 	m.modals.ShowInstall()
 	return m.batch.StartInstall(markedTools)
 }
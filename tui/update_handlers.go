@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"context"
+
 	tea "github.com/charmbracelet/bubbletea"
 
 	"troveler/db"
@@ -17,7 +19,9 @@ func (m *Model) handleSearchTriggered(msg panels.SearchTriggeredMsg) (tea.Model,
 func (m *Model) handleSearchResult(msg searchResultMsg) (tea.Model, tea.Cmd) {
 	m.tools = msg.tools
 	m.toolsPanel.SetTools(msg.tools)
+	m.toolsPanel.SetRanked(msg.sortField == db.SortFieldRelevance || msg.fuzzy)
 	m.searching = false
+	m.fuzzy = msg.fuzzy
 
 	m.toolsPanel.UpdateAllInstalledStatus(m.db)
 
@@ -31,6 +35,8 @@ func (m *Model) handleSearchResult(msg searchResultMsg) (tea.Model, tea.Cmd) {
 			m.installs = installs
 			m.infoPanel.SetTool(firstTool, installs)
 			m.installPanel.SetTool(firstTool, installs)
+
+			return m, m.resolveOwner(*firstTool, installs)
 		}
 	}
 
@@ -54,6 +60,36 @@ func (m *Model) handleToolCursorChanged(msg panels.ToolCursorChangedMsg) (tea.Mo
 		m.installs = installs
 		m.infoPanel.SetTool(m.selectedTool, installs)
 		m.installPanel.SetTool(m.selectedTool, installs)
+
+		return m, m.resolveOwner(*m.selectedTool, installs)
+	}
+
+	return m, nil
+}
+
+// toolOwnerMsg carries the owner of a tool's executable, resolved off the
+// UI goroutine since it may query the system package manager.
+type toolOwnerMsg struct {
+	toolID string
+	owner  *db.ToolOwner
+}
+
+func (m *Model) resolveOwner(tool db.Tool, installs []db.InstallInstruction) tea.Cmd {
+	database := m.db
+
+	return func() tea.Msg {
+		owner, err := database.ResolveToolOwner(context.Background(), &tool, installs)
+		if err != nil {
+			return nil
+		}
+
+		return toolOwnerMsg{toolID: tool.ID, owner: owner}
+	}
+}
+
+func (m *Model) handleToolOwner(msg toolOwnerMsg) (tea.Model, tea.Cmd) {
+	if m.selectedTool != nil && m.selectedTool.ID == msg.toolID {
+		m.infoPanel.SetOwner(msg.owner)
 	}
 
 	return m, nil
@@ -68,19 +104,11 @@ func (m *Model) handleToolSelected() (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) handleInstallExecute(msg panels.InstallExecuteMsg) (tea.Model, tea.Cmd) {
-	m.modals.ShowInstall()
-	m.executing = true
-	m.executeOutput = ""
-
-	return m, m.executeInstallCommand(msg.Command)
+	return m.startInstall(msg.Platform, msg.Command)
 }
 
 func (m *Model) handleInstallExecuteMise(msg panels.InstallExecuteMiseMsg) (tea.Model, tea.Cmd) {
-	m.modals.ShowInstall()
-	m.executing = true
-	m.executeOutput = ""
-
-	return m, m.executeInstallCommand(msg.Command)
+	return m.startInstall(msg.Platform, msg.Command)
 }
 
 func (m *Model) handleInstallComplete(msg installCompleteMsg) (tea.Model, tea.Cmd) {
@@ -89,24 +117,27 @@ func (m *Model) handleInstallComplete(msg installCompleteMsg) (tea.Model, tea.Cm
 	if msg.err != nil {
 		m.err = msg.err
 	}
+	m.toolsPanel.UpdateAllInstalledStatus(m.db)
 
 	return m, nil
 }
 
 func (m *Model) handleBatchInstallProgress(msg batchInstallProgressMsg) (tea.Model, tea.Cmd) {
-	finished, nextIndex := m.batch.HandleProgress(msg)
+	finished, next := m.batch.HandleProgress(msg)
 	if finished {
 		m.executing = false
 		m.toolsPanel.ClearMarks()
+		m.toolsPanel.UpdateAllInstalledStatus(m.db)
 		return m, nil
 	}
-	return m, m.batch.ProcessTool(nextIndex)
+	return m, next
 }
 
 func (m *Model) handleBatchInstallComplete() (tea.Model, tea.Cmd) {
 	m.executing = false
 	m.toolsPanel.ClearMarks()
 	m.batch.HandleComplete()
+	m.toolsPanel.UpdateAllInstalledStatus(m.db)
 
 	return m, nil
 }
@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"troveler/db"
+)
+
+// historyModalLimit bounds how many installs the history modal loads.
+const historyModalLimit = 200
+
+// handleHistoryKey opens the install history browser.
+func (m *Model) handleHistoryKey() (tea.Model, tea.Cmd, bool) {
+	records, err := m.db.ListInstallHistory(db.HistoryFilter{Limit: historyModalLimit})
+	if err != nil {
+		m.err = err
+
+		return m, nil, true
+	}
+
+	m.history = records
+	m.historyCursor = 0
+	m.modals.ShowHistory()
+
+	return m, nil, true
+}
+
+// handleHistoryInput moves through the install history.
+func (m *Model) handleHistoryInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Escape):
+		m.modals.CloseHistory()
+	case key.Matches(msg, m.keys.Up):
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.historyCursor < len(m.history)-1 {
+			m.historyCursor++
+		}
+	}
+
+	return m, nil
+}
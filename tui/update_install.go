@@ -1,24 +1,71 @@
 package tui
 
 import (
+	"context"
+	"fmt"
 	"os/exec"
 	"runtime"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"troveler/db"
+	"troveler/internal/install"
 	"troveler/internal/update"
 )
 
-func (m *Model) executeInstallCommand(command string) tea.Cmd {
-	return func() tea.Msg {
-		cmd := exec.Command("sh", "-c", command) //nolint:noctx,gosec // G204: user install command
-		output, err := cmd.CombinedOutput()
+func (m *Model) executeInstallCommand(platformID, command string) tea.Cmd {
+	slug := ""
+	if m.selectedTool != nil {
+		slug = m.selectedTool.Slug
+	}
+	database := m.db
+	ctx, log := newLoggedRun()
+	m.installLog, m.logScroll = log, 0
+
+	return tea.Batch(func() tea.Msg {
+		output, err := runInstallCommand(ctx, database, db.ActionInstall, slug, platformID, command, log, "")
+		log.finish()
 
 		return installCompleteMsg{
-			output: string(output),
+			output: output,
 			err:    err,
 		}
+	}, installLogTick())
+}
+
+// runInstallCommand runs command in a terminal of the install modal's width,
+// streaming its output to log and taking replies from it, records it in the
+// install history under action, rescans the tool's installed state and
+// returns the tail of its output. A sudo password, if known, answers sudo's
+// prompt. A database write failure is appended to the output rather than
+// failing the command.
+func runInstallCommand(
+	ctx context.Context, database *db.SQLiteDB, action, slug, platformID, command string, log *InstallLog,
+	sudoPassword string,
+) (string, error) {
+	result := install.RunTerminal(ctx, command, log, install.TerminalOptions{
+		Input:    log.Input(),
+		Password: sudoPassword,
+		Cols:     installTermCols,
+	})
+	output := result.Output
+	if ctx.Err() != nil && result.Err != nil {
+		result.Err = fmt.Errorf("canceled: %w", result.Err)
+	}
+
+	if database != nil && slug != "" {
+		rec := result.Record(slug, platformID)
+		rec.Action = action
+		if err := database.RecordInstall(rec); err != nil {
+			output += fmt.Sprintf("\nwarning: %v\n", err)
+		}
+		if err := database.RefreshInstalled(context.Background(), slug); err != nil {
+			output += fmt.Sprintf("\nwarning: %v\n", err)
+		}
 	}
+
+	return output, result.Err
 }
 
 func (m *Model) openRepositoryURL() tea.Cmd {
@@ -46,3 +93,105 @@ func (m *Model) openRepositoryURL() tea.Cmd {
 }
 
 type updateProgressMsg update.ProgressUpdate
+
+// logPageSize is how many lines PgUp and PgDown scroll the install log.
+const logPageSize = 10
+
+// handleInstallLogKeys scrolls the install log, starts a reply to the
+// running install, cancels it and, in a batch, picks the job whose log is
+// shown.
+func (m *Model) handleInstallLogKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	log := m.shownLog()
+	switch {
+	case key.Matches(msg, m.keys.Enter):
+		if log != nil && log.Running() {
+			m.replying, m.reply = true, ""
+		}
+	case key.Matches(msg, m.keys.Cancel):
+		if log != nil {
+			log.Cancel()
+		}
+	case key.Matches(msg, m.keys.Up):
+		m.scrollLog(log, 1)
+	case key.Matches(msg, m.keys.Down):
+		m.scrollLog(log, -1)
+	case msg.Type == tea.KeyPgUp:
+		m.scrollLog(log, logPageSize)
+	case msg.Type == tea.KeyPgDown:
+		m.scrollLog(log, -logPageSize)
+	case msg.Type == tea.KeyEnd:
+		m.logScroll = 0
+	case key.Matches(msg, m.keys.Left):
+		m.focusBatchJob(-1)
+	case key.Matches(msg, m.keys.Right):
+		m.focusBatchJob(1)
+	default:
+		return m, nil, false
+	}
+
+	return m, nil, true
+}
+
+// handleReplyInput edits the reply to the running install and types it into
+// its terminal on Enter. A reply to sudo's password prompt in a batch is
+// kept to answer the prompts of the installs still to come.
+func (m *Model) handleReplyInput(msg tea.KeyMsg, log *InstallLog) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.replying, m.reply = false, ""
+	case tea.KeyEnter:
+		if log != nil {
+			if log.AwaitsSudo() && m.batch.Progress() != nil && m.batch.Config() != nil {
+				m.batch.Config().SudoPassword = m.reply
+			}
+			log.Reply(m.reply)
+		}
+		m.replying, m.reply = false, ""
+	case tea.KeyBackspace:
+		if runes := []rune(m.reply); len(runes) > 0 {
+			m.reply = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		m.reply += " "
+	case tea.KeyRunes:
+		m.reply += string(msg.Runes)
+	}
+}
+
+// shownLog is the log the install modal shows: the focused job's in a
+// batch, else the last single install's.
+func (m *Model) shownLog() *InstallLog {
+	if bp := m.batch.Progress(); bp != nil {
+		if i := bp.FocusedJob(); i >= 0 {
+			return bp.Jobs[i].Log
+		}
+
+		return nil
+	}
+
+	return m.installLog
+}
+
+func (m *Model) scrollLog(log *InstallLog, lines int) {
+	if log == nil {
+		return
+	}
+	m.logScroll = max(0, min(m.logScroll+lines, len(log.Lines())-1))
+}
+
+// focusBatchJob shows the log of the previous or next batch job that has
+// one.
+func (m *Model) focusBatchJob(step int) {
+	bp := m.batch.Progress()
+	if bp == nil {
+		return
+	}
+	for i := bp.FocusedJob() + step; i >= 0 && i < len(bp.Jobs); i += step {
+		if bp.Jobs[i].Log != nil {
+			bp.Focus = i
+			m.logScroll = 0
+
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"troveler/db"
+)
+
+// installedScanInterval is how often the TUI rescans PATH in the
+// background, after a first scan at startup. It stays well below
+// db.InstalledStateMaxAge so installed= filters do not go stale.
+const installedScanInterval = 5 * time.Minute
+
+type installedScanTickMsg struct{}
+
+type installedScannedMsg struct {
+	result *db.ScanResult
+	err    error
+}
+
+// scheduleInstalledScan starts the next background scan after the interval.
+func scheduleInstalledScan() tea.Cmd {
+	return tea.Tick(installedScanInterval, func(_ time.Time) tea.Msg {
+		return installedScanTickMsg{}
+	})
+}
+
+// scanInstalled rescans PATH off the UI goroutine.
+func (m *Model) scanInstalled() tea.Cmd {
+	database := m.db
+
+	return func() tea.Msg {
+		result, err := database.ScanInstalled(context.Background())
+
+		return installedScannedMsg{result: result, err: err}
+	}
+}
+
+// handleInstalledScanned refreshes the installed column and, when tools
+// appeared or disappeared, reruns the search so installed= filters see
+// them. A failed scan is retried at the next interval.
+func (m *Model) handleInstalledScanned(msg installedScannedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil || !msg.result.Changed() {
+		return m, scheduleInstalledScan()
+	}
+
+	m.toolsPanel.UpdateAllInstalledStatus(m.db)
+
+	return m, tea.Batch(m.performSearch(m.searchPanel.GetQuery()), scheduleInstalledScan())
+}
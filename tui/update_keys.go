@@ -8,6 +8,13 @@ import (
 )
 
 func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Layer 0: Reply typed to the running install (every key but quit)
+	if m.replying && !key.Matches(msg, m.keys.Quit) {
+		m.handleReplyInput(msg, m.shownLog())
+
+		return m, nil
+	}
+
 	// Layer 1: Global keys (quit, help, tab)
 	if result, cmd, handled := m.handleGlobalKeys(msg); handled {
 		return result, cmd
@@ -18,6 +25,38 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleBatchConfigInput(msg)
 	}
 
+	// Layer 2b: Saved query picker input
+	if m.modals.IsQueryPickerShown() {
+		return m.handleQueryPickerInput(msg)
+	}
+
+	// Layer 2c: Install history browser input
+	if m.modals.IsHistoryShown() {
+		return m.handleHistoryInput(msg)
+	}
+
+	// Layer 2d: Uninstall confirmation input
+	if m.modals.IsUninstallShown() {
+		return m.handleUninstallInput(msg)
+	}
+
+	// Layer 2e: Risky install confirmation input
+	if m.modals.IsRiskConfirmShown() {
+		return m.handleRiskConfirmInput(msg)
+	}
+
+	// Layer 2f: Interrupted batch install prompt input
+	if m.modals.IsResumeBatchShown() {
+		return m.handleResumeBatchInput(msg)
+	}
+
+	// Layer 2g: Install log scrolling and cancelling
+	if m.modals.IsInstallShown() {
+		if result, cmd, handled := m.handleInstallLogKeys(msg); handled {
+			return result, cmd
+		}
+	}
+
 	// Layer 3: Search panel text input (unmodified rune keys)
 	if m.activePanel == PanelSearch && !msg.Alt && msg.Type == tea.KeyRunes {
 		return m.delegateToSearchPanel(msg)
@@ -92,6 +131,12 @@ func (m *Model) handleActionKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
 		return m.handleUpdateKey()
 	case key.Matches(msg, m.keys.InfoModal):
 		return m.handleInfoModalKey()
+	case key.Matches(msg, m.keys.Queries) && m.activePanel == PanelSearch:
+		return m.handleQueriesKey()
+	case key.Matches(msg, m.keys.History):
+		return m.handleHistoryKey()
+	case key.Matches(msg, m.keys.Uninstall):
+		return m.handleUninstallKey()
 	case msg.Alt && msg.Type == tea.KeyRunes && len(msg.Runes) > 0 && msg.Runes[0] == 'i':
 		return m.handleAltIKey()
 	case msg.Alt && msg.Type == tea.KeyRunes && len(msg.Runes) > 0 && msg.Runes[0] == 'm':
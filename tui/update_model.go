@@ -6,10 +6,19 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"troveler/config"
+	"troveler/crawler"
 	"troveler/db"
+	"troveler/internal/source"
 	"troveler/internal/update"
 )
 
+// Caps on the changes and failed tools listed in the update modal.
+const (
+	maxSummaryChanges  = 6
+	maxSummaryFailures = 5
+)
+
 // UpdateModel manages database update state and the slug wave animation.
 type UpdateModel struct {
 	service  *update.Service
@@ -17,12 +26,16 @@ type UpdateModel struct {
 	slugWave *update.SlugWave
 	progress chan update.ProgressUpdate
 	cancel   context.CancelFunc
+	summary  string // how the last update ended
 }
 
-// NewUpdateModel creates a new UpdateModel.
-func NewUpdateModel(database *db.SQLiteDB) *UpdateModel {
+// NewUpdateModel creates a new UpdateModel that refreshes terminaltrove.com
+// and every catalog configured in cfg, using cfg's HTTP cache directory.
+func NewUpdateModel(database *db.SQLiteDB, cfg *config.Config) *UpdateModel {
+	fetcher := crawler.NewFetcher(crawler.WithCacheDir(cfg.CacheDir))
+
 	return &UpdateModel{
-		service: update.NewService(database),
+		service: update.NewServiceWithSources(database, source.Configured(fetcher, cfg.Catalogs)...),
 	}
 }
 
@@ -36,6 +49,13 @@ func (um *UpdateModel) SlugWave() *update.SlugWave {
 	return um.slugWave
 }
 
+// Summary describes how the last update ended: what it changed in the
+// catalog and the tools it could not refresh. It is empty until an update
+// completes.
+func (um *UpdateModel) Summary() string {
+	return um.summary
+}
+
 // Start begins the database update process.
 func (um *UpdateModel) Start() tea.Cmd {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -87,6 +107,15 @@ func (um *UpdateModel) HandleProgress(upd update.ProgressUpdate) {
 		um.slugWave.IncProcessed()
 	}
 
+	if upd.Type == "complete" && upd.Message != "" {
+		um.summary = upd.Message + "\n\nChanges: " + update.SummarizeChanges(upd.Changes) + "\n" +
+			update.DescribeChanges(upd.Changes, maxSummaryChanges)
+		if len(upd.Failed) > 0 {
+			um.summary += "\n\nNot refreshed, previous data kept:\n" +
+				update.FailureSummary(upd.Failed, maxSummaryFailures)
+		}
+	}
+
 	if upd.Type == "complete" || upd.Type == "error" {
 		um.running = false
 	}
@@ -114,5 +143,6 @@ func (um *UpdateModel) Close() {
 // StartProgress prepares the progress channel and returns start commands.
 func (um *UpdateModel) StartProgress() tea.Cmd {
 	um.progress = make(chan update.ProgressUpdate, 100)
+	um.summary = ""
 	return tea.Batch(um.Start(), um.tick())
 }
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleQueriesKey opens the saved query picker.
+func (m *Model) handleQueriesKey() (tea.Model, tea.Cmd, bool) {
+	queries, err := m.db.ListSavedQueries()
+	if err != nil {
+		m.err = err
+
+		return m, nil, true
+	}
+
+	m.savedQueries = queries
+	m.queryCursor = 0
+	m.modals.ShowQueryPicker()
+
+	return m, nil, true
+}
+
+// handleQueryPickerInput moves through the picker and runs the chosen query
+// by putting "@name" into the search panel.
+func (m *Model) handleQueryPickerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Escape):
+		m.modals.CloseQueryPicker()
+	case key.Matches(msg, m.keys.Up):
+		if m.queryCursor > 0 {
+			m.queryCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.queryCursor < len(m.savedQueries)-1 {
+			m.queryCursor++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		m.modals.CloseQueryPicker()
+		if m.queryCursor < len(m.savedQueries) {
+			return m, m.searchPanel.SetQuery("@" + m.savedQueries[m.queryCursor].Name)
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"troveler/db"
+)
+
+// unfinishedBatchMsg carries the batch run left behind by an interrupted
+// batch install, if any.
+type unfinishedBatchMsg struct {
+	run *db.BatchRun
+}
+
+// loadUnfinishedBatch looks for a batch install to offer resuming.
+func (m *Model) loadUnfinishedBatch() tea.Cmd {
+	database := m.db
+	if database == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		run, err := database.LatestBatchRun()
+		if err != nil {
+			return nil
+		}
+
+		return unfinishedBatchMsg{run: run}
+	}
+}
+
+// handleUnfinishedBatch asks whether to continue an interrupted batch. A
+// run with nothing left is dropped silently.
+func (m *Model) handleUnfinishedBatch(msg unfinishedBatchMsg) (tea.Model, tea.Cmd) {
+	if msg.run == nil {
+		return m, nil
+	}
+	if len(msg.run.Unfinished()) == 0 {
+		_ = m.db.DeleteBatchRun(msg.run.ID)
+
+		return m, nil
+	}
+
+	m.pendingBatch = msg.run
+	m.modals.ShowResumeBatch()
+
+	return m, nil
+}
+
+// handleResumeBatchInput resumes the interrupted batch on Enter, discards it
+// on d and leaves it for later on Escape.
+func (m *Model) handleResumeBatchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	run := m.pendingBatch
+	switch {
+	case key.Matches(msg, m.keys.Escape):
+	case key.Matches(msg, m.keys.Enter):
+		if run != nil {
+			m.modals.CloseResumeBatch()
+			m.pendingBatch = nil
+			m.executing = true
+			m.logScroll = 0
+			m.modals.ShowInstall()
+
+			return m, m.batch.ResumeInstall(run)
+		}
+	case msg.String() == "d":
+		if run != nil {
+			if err := m.db.DeleteBatchRun(run.ID); err != nil {
+				m.SetError(err)
+			}
+		}
+	default:
+		return m, nil
+	}
+
+	m.modals.CloseResumeBatch()
+	m.pendingBatch = nil
+
+	return m, nil
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"troveler/internal/install"
+)
+
+// invalidPolicyError refuses installs while the configured install risk
+// policy is invalid.
+type invalidPolicyError struct {
+	err error
+}
+
+func (e *invalidPolicyError) Error() string {
+	return "invalid install risk policy, installs are disabled: [install] " + e.err.Error()
+}
+
+func (e *invalidPolicyError) Unwrap() error {
+	return e.err
+}
+
+// pendingInstall is an install held back until the user confirms it.
+type pendingInstall struct {
+	platformID string
+	command    string
+	classes    []install.RiskClass // the classes the policy wants confirmed
+}
+
+// startInstall runs command unless the install risk policy is invalid or
+// blocks it, in which case the install modal shows why, or requires a
+// confirmation, which opens first.
+func (m *Model) startInstall(platformID, command string) (tea.Model, tea.Cmd) {
+	decision, classes := m.riskPolicy.Decide(install.AnalyzeCommand(command))
+	switch {
+	case m.riskPolicyErr != nil:
+		m.refuseInstall(command, m.riskPolicyErr)
+
+		return m, nil
+	case decision == install.RiskBlock:
+		m.refuseInstall(command, &install.RiskBlockedError{Classes: classes})
+
+		return m, nil
+	case decision == install.RiskConfirm:
+		m.riskPending = &pendingInstall{platformID: platformID, command: command, classes: classes}
+		m.modals.ShowRiskConfirm()
+
+		return m, nil
+	}
+
+	return m, m.runInstall(platformID, command)
+}
+
+// refuseInstall shows command in the install modal with why it did not run.
+func (m *Model) refuseInstall(command string, err error) {
+	m.modals.ShowInstall()
+	m.executeOutput = command
+	m.installLog = nil
+	m.err = err
+}
+
+func (m *Model) runInstall(platformID, command string) tea.Cmd {
+	m.modals.ShowInstall()
+	m.executing = true
+	m.executeOutput = ""
+
+	return m.executeInstallCommand(platformID, command)
+}
+
+// handleRiskConfirmInput runs the held back install on Enter or drops it on
+// Escape.
+func (m *Model) handleRiskConfirmInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Escape):
+		m.modals.CloseRiskConfirm()
+		m.riskPending = nil
+	case key.Matches(msg, m.keys.Enter):
+		m.modals.CloseRiskConfirm()
+		pending := m.riskPending
+		m.riskPending = nil
+		if pending == nil {
+			return m, nil
+		}
+
+		return m, m.runInstall(pending.platformID, pending.command)
+	}
+
+	return m, nil
+}
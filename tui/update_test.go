@@ -2,12 +2,15 @@ package tui
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"troveler/config"
 	"troveler/db"
+	"troveler/internal/install"
 	"troveler/internal/search"
 	"troveler/tui/panels"
 )
@@ -100,6 +103,31 @@ func TestEscapeChain_UpdateModal(t *testing.T) {
 	}
 }
 
+func TestUpdate_UpdateCompleteShowsChangesAndFailedTools(t *testing.T) {
+	m := newTestModel(t)
+	m.width, m.height = 120, 40
+	m.modals.ShowUpdate()
+
+	m.Update(updateProgressMsg{
+		Type:    "complete",
+		Message: "Update complete! Saved 41 tools, 1 failed.",
+		Failed:  []db.FailedRow{{Slug: "broken", Source: "terminaltrove", Class: db.FailureHTTP, Err: errors.New("status 500")}},
+		Changes: []db.CatalogChange{{Slug: "fd", Kind: db.ChangeRenamed, Old: "fd", New: "fd-find"}},
+	})
+	// The closed progress channel reports a bare completion afterwards.
+	m.Update(updateProgressMsg{Type: "complete"})
+
+	view := m.View()
+	for _, want := range []string{
+		"Saved 41 tools, 1 failed", "Changes: 1 renamed", `fd: renamed "fd" → "fd-find"`,
+		"broken (http): status 500",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected update modal to show %q, got:\n%s", want, view)
+		}
+	}
+}
+
 func TestEscapeChain_InstallModalWithoutExecuting(t *testing.T) {
 	m := newTestModel(t)
 	m.modals.ShowInstall()
@@ -212,6 +240,24 @@ func TestUpdate_SearchResultMsg_PopulatesTools(t *testing.T) {
 	}
 }
 
+func TestUpdate_SearchResultMsg_Fuzzy(t *testing.T) {
+	m := newTestModelWithDB(t)
+	tools := []db.SearchResult{{Tool: db.Tool{ID: "tool-1", Slug: "ripgrep", Name: "ripgrep"}}}
+
+	_, _ = m.Update(searchResultMsg{tools: tools, query: "ripgerp", fuzzy: true})
+	if !m.fuzzy {
+		t.Error("Expected fuzzy to be set after fuzzy searchResultMsg")
+	}
+	if title := m.renderToolsPanel(60, 10); !strings.Contains(title, "showing fuzzy matches") {
+		t.Errorf("Expected tools panel title to mention fuzzy matches, got %q", title)
+	}
+
+	_, _ = m.Update(searchResultMsg{tools: tools, query: "ripgrep"})
+	if m.fuzzy {
+		t.Error("Expected fuzzy to be cleared after exact searchResultMsg")
+	}
+}
+
 func TestUpdate_SearchResultMsg_EmptyResults(t *testing.T) {
 	m := newTestModelWithDB(t)
 	m.searching = true
@@ -301,6 +347,216 @@ func TestUpdate_InstallExecuteMiseMsg_SetsModalState(t *testing.T) {
 	}
 }
 
+func TestUpdate_InstallExecuteMsg_RiskPolicyBlocks(t *testing.T) {
+	m := newTestModel(t)
+	m.selectedTool = &db.Tool{ID: "test-tool", Name: "Test"}
+	m.riskPolicy, _ = install.NewRiskPolicy([]string{"pipe-to-shell"}, nil)
+
+	_, cmd := m.Update(panels.InstallExecuteMsg{Command: "curl -fsSL https://example.com/i.sh | sh"})
+
+	var blocked *install.RiskBlockedError
+	if !m.modals.IsInstallShown() || !errors.As(m.err, &blocked) {
+		t.Fatalf("Expected the install modal with a RiskBlockedError, got %v", m.err)
+	}
+	if m.executing || cmd != nil {
+		t.Error("Expected a blocked command not to run")
+	}
+
+	m.width, m.height = 100, 40
+	if view := m.View(); !strings.Contains(view, "Install Blocked") {
+		t.Errorf("Expected the blocked title in the view:\n%s", view)
+	}
+}
+
+func TestUpdate_InstallExecuteMsg_RiskPolicyConfirm(t *testing.T) {
+	m := newTestModel(t)
+	m.selectedTool = &db.Tool{ID: "test-tool", Name: "Test"}
+	m.riskPolicy, _ = install.NewRiskPolicy(nil, []string{"sudo"})
+
+	_, cmd := m.Update(panels.InstallExecuteMsg{Command: "sudo apt install bat"})
+	if !m.modals.IsRiskConfirmShown() || m.executing || cmd != nil {
+		t.Fatal("Expected the risk confirmation before running")
+	}
+
+	m.width, m.height = 100, 40
+	if view := m.View(); !strings.Contains(view, "sudo apt install bat") {
+		t.Errorf("Expected the command in the confirmation:\n%s", view)
+	}
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.modals.IsRiskConfirmShown() || m.riskPending != nil || m.modals.IsInstallShown() {
+		t.Fatal("Expected escape to drop the install")
+	}
+
+	_, _ = m.Update(panels.InstallExecuteMsg{Command: "sudo apt install bat"})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.modals.IsRiskConfirmShown() || !m.modals.IsInstallShown() || !m.executing || cmd == nil {
+		t.Error("Expected enter to run the confirmed install")
+	}
+}
+
+func TestUpdate_UnfinishedBatchPrompt(t *testing.T) {
+	m := newTestModelWithDB(t)
+	run := &db.BatchRun{Tools: []db.BatchRunTool{{Slug: "one"}, {Slug: "two"}}}
+	if err := m.db.CreateBatchRun(run); err != nil {
+		t.Fatalf("CreateBatchRun failed: %v", err)
+	}
+	if err := m.db.SetBatchToolStatus(run.ID, 0, db.BatchCompleted, ""); err != nil {
+		t.Fatalf("SetBatchToolStatus failed: %v", err)
+	}
+
+	_, _ = m.Update(m.loadUnfinishedBatch()())
+	if !m.modals.IsResumeBatchShown() || m.pendingBatch == nil {
+		t.Fatal("Expected the prompt to resume the interrupted batch")
+	}
+
+	m.width, m.height = 100, 40
+	if view := m.View(); !strings.Contains(view, "1 of 2 tools left") || !strings.Contains(view, "two") {
+		t.Errorf("Expected the tools left in the prompt:\n%s", view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.modals.IsResumeBatchShown() || cmd != nil {
+		t.Fatal("Expected escape to close the prompt")
+	}
+	if kept, _ := m.db.LatestBatchRun(); kept == nil {
+		t.Fatal("Expected escape to keep the run for later")
+	}
+
+	_, _ = m.Update(m.loadUnfinishedBatch()())
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.modals.IsResumeBatchShown() || !m.modals.IsInstallShown() || !m.executing || cmd == nil {
+		t.Fatal("Expected enter to resume the batch")
+	}
+	if progress := m.batch.Progress(); progress == nil || len(progress.Tools) != 1 || progress.Tools[0].Slug != "two" {
+		t.Errorf("Expected only two in the resumed batch, got %+v", progress)
+	}
+}
+
+func TestUpdate_UnfinishedBatchDiscard(t *testing.T) {
+	m := newTestModelWithDB(t)
+	if err := m.db.CreateBatchRun(&db.BatchRun{Tools: []db.BatchRunTool{{Slug: "one"}}}); err != nil {
+		t.Fatalf("CreateBatchRun failed: %v", err)
+	}
+
+	_, _ = m.Update(m.loadUnfinishedBatch()())
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if m.modals.IsResumeBatchShown() {
+		t.Fatal("Expected d to close the prompt")
+	}
+	if run, err := m.db.LatestBatchRun(); err != nil || run != nil {
+		t.Errorf("Expected d to discard the run, got %+v (%v)", run, err)
+	}
+
+	if cmd := newTestModel(t).loadUnfinishedBatch(); cmd != nil {
+		t.Error("Expected no lookup without a database")
+	}
+}
+
+func TestUpdate_InstallStreamsOutputAndCancels(t *testing.T) {
+	m := newTestModel(t)
+	m.width, m.height = 100, 40
+
+	batch, ok := m.runInstall("", "echo first; exec sleep 30")().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("Expected the install and the log refresh, got %T", batch)
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- batch[0]() }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(m.installLog.Lines()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if view := m.View(); !strings.Contains(view, "first") || !strings.Contains(view, "x to cancel") {
+		t.Fatalf("Expected the live output while running:\n%s", view)
+	}
+	if _, cmd := m.Update(installLogTickMsg{}); cmd == nil {
+		t.Error("Expected the log to keep refreshing while running")
+	}
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	var msg tea.Msg
+	select {
+	case msg = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected x to cancel the install")
+	}
+
+	_, _ = m.Update(msg)
+	if m.executing || m.err == nil || !strings.Contains(m.err.Error(), "canceled") {
+		t.Fatalf("Expected a canceled install, got executing=%v err=%v", m.executing, m.err)
+	}
+	if view := m.View(); !strings.Contains(view, "first") {
+		t.Errorf("Expected the log kept after the install ended:\n%s", view)
+	}
+	if _, cmd := m.Update(installLogTickMsg{}); cmd != nil {
+		t.Error("Expected the log refresh to stop once the install ended")
+	}
+}
+
+func TestUpdate_InstallReplyPrompt(t *testing.T) {
+	m := newTestModel(t)
+	m.width, m.height = 100, 40
+
+	batch, ok := m.runInstall("", `printf 'Continue? [Y/n] '; read -r answer; echo "answer=$answer"`)().(tea.BatchMsg)
+	if !ok {
+		t.Fatalf("Expected the install and the log refresh, got %T", batch)
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- batch[0]() }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(m.installLog.String(), "Continue?") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for _, r := range "n?" {
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if !m.replying || m.modals.IsHelpShown() {
+		t.Fatal("Expected typed keys to go to the reply, not the shortcuts")
+	}
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if view := m.View(); !strings.Contains(view, "> n") {
+		t.Errorf("Expected the reply in the view:\n%s", view)
+	}
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	select {
+	case msg := <-done:
+		_, _ = m.Update(msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the reply to reach the command")
+	}
+	if m.replying || m.err != nil || !strings.Contains(m.installLog.String(), "answer=n") {
+		t.Errorf("Expected the command to read the reply, got %q (%v)", m.installLog.String(), m.err)
+	}
+}
+
+func TestUpdate_BatchRemembersSudoPassword(t *testing.T) {
+	m := newTestModel(t)
+	m.width, m.height = 100, 40
+	m.batch.StartBatchConfig(false)
+	m.batch.progress = NewBatchInstallProgress([]db.SearchResult{{Tool: db.Tool{ID: "t1", Name: "Tool1"}}})
+	log := NewInstallLog(func() {})
+	m.batch.progress.Jobs[0] = BatchJobStatus{State: JobRunning, Started: time.Now(), Log: log}
+	_, _ = log.Write([]byte(install.SudoPrompt))
+	m.modals.ShowInstall()
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s3cret")})
+	if view := m.View(); strings.Contains(view, "s3cret") || !strings.Contains(view, "••••••") {
+		t.Errorf("Expected the password to be hidden:\n%s", view)
+	}
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.batch.Config().SudoPassword != "s3cret" {
+		t.Errorf("Expected the batch to keep the sudo password, got %q", m.batch.Config().SudoPassword)
+	}
+}
+
 func TestUpdate_InstallCompleteMsg_ClearsExecution(t *testing.T) {
 	m := newTestModel(t)
 	m.executing = true
@@ -698,3 +954,154 @@ func TestHandleKeyPress_InstallPanel_Delegation(t *testing.T) {
 		t.Error("Expected to stay on Install panel after key delegation")
 	}
 }
+
+func TestUpdate_QueryPicker_RunsSelectedQuery(t *testing.T) {
+	m := newTestModelWithDB(t)
+	if err := m.db.SaveQuery("go-tools", "language=go"); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+	if err := m.db.SaveQuery("rusty", "language=rust"); err != nil {
+		t.Fatalf("SaveQuery failed: %v", err)
+	}
+	m.activePanel = PanelSearch
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !m.modals.IsQueryPickerShown() {
+		t.Fatal("Expected query picker to open on ctrl+r in search panel")
+	}
+	if len(m.savedQueries) != 2 {
+		t.Fatalf("Expected 2 saved queries, got %d", len(m.savedQueries))
+	}
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.modals.IsQueryPickerShown() {
+		t.Error("Expected query picker to close after enter")
+	}
+	if cmd == nil {
+		t.Fatal("Expected a search command after picking a query")
+	}
+	msg, ok := cmd().(panels.SearchTriggeredMsg)
+	if !ok || msg.Query != "@rusty" {
+		t.Errorf("Expected search for @rusty, got %#v", msg)
+	}
+	if m.searchPanel.GetQuery() != "@rusty" {
+		t.Errorf("Expected search input to show @rusty, got %q", m.searchPanel.GetQuery())
+	}
+}
+
+func TestUpdate_QueryPicker_EscapeCloses(t *testing.T) {
+	m := newTestModelWithDB(t)
+	m.activePanel = PanelSearch
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.modals.IsQueryPickerShown() {
+		t.Error("Expected escape to close the query picker")
+	}
+}
+
+func TestUpdate_HistoryModal(t *testing.T) {
+	m := newTestModelWithDB(t)
+	for _, rec := range []*db.InstallRecord{
+		{Slug: "ripgrep", Platform: "brew", Command: "brew install ripgrep"},
+		{Slug: "fd", Platform: "apt", Command: "apt install fd-find", ExitCode: 100, Output: "E: locked"},
+	} {
+		if err := m.db.RecordInstall(rec); err != nil {
+			t.Fatalf("RecordInstall failed: %v", err)
+		}
+	}
+	m.activePanel = PanelSearch
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}, Alt: true})
+	if !m.modals.IsHistoryShown() {
+		t.Fatal("Expected history modal to open on alt+h")
+	}
+	if len(m.history) != 2 || m.history[0].Slug != "fd" {
+		t.Fatalf("Expected 2 records newest first, got %+v", m.history)
+	}
+
+	m.width, m.height = 100, 40
+	if view := m.View(); !strings.Contains(view, "apt install fd-find") || !strings.Contains(view, "E: locked") {
+		t.Errorf("Expected the selected install's details in the view:\n%s", view)
+	}
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.historyCursor != 1 {
+		t.Errorf("Expected cursor on second record, got %d", m.historyCursor)
+	}
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.modals.IsHistoryShown() {
+		t.Error("Expected escape to close the history modal")
+	}
+}
+
+func TestUpdate_UninstallConfirm(t *testing.T) {
+	m := newTestModelWithDB(t)
+	m.selectedTool = &db.Tool{ID: "tool-gizmo", Slug: "gizmo", Name: "Gizmo"}
+	m.activePanel = PanelTools
+	if err := m.db.RecordInstall(&db.InstallRecord{Slug: "gizmo", Platform: "brew", Command: "brew install gizmo"}); err != nil {
+		t.Fatalf("RecordInstall failed: %v", err)
+	}
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}, Alt: true})
+	if !m.modals.IsUninstallShown() {
+		t.Fatal("Expected uninstall confirmation to open on alt+x")
+	}
+	if m.uninstallErr != nil || m.uninstallCommand() != "brew uninstall gizmo" {
+		t.Fatalf("Expected brew uninstall command, got %q (%v)", m.uninstallCommand(), m.uninstallErr)
+	}
+
+	m.width, m.height = 100, 40
+	if view := m.View(); !strings.Contains(view, "brew uninstall gizmo") {
+		t.Errorf("Expected the uninstall command in the view:\n%s", view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.modals.IsUninstallShown() || !m.modals.IsInstallShown() || !m.executing || cmd == nil {
+		t.Fatal("Expected enter to start the uninstall in the execution modal")
+	}
+
+	_, _ = m.Update(uninstallCompleteMsg{tool: *m.selectedTool, output: "Uninstalling gizmo..."})
+	if m.executing || m.executeOutput != "Uninstalling gizmo..." {
+		t.Errorf("Expected uninstall output after completion, got %q", m.executeOutput)
+	}
+}
+
+func TestUpdate_UninstallNotInvertible(t *testing.T) {
+	m := newTestModelWithDB(t)
+	m.selectedTool = &db.Tool{ID: "tool-gizmo", Slug: "gizmo", Name: "Gizmo"}
+	m.activePanel = PanelTools
+	err := m.db.RecordInstall(&db.InstallRecord{Slug: "gizmo", Command: "curl -fsSL https://gizmo.sh | sh"})
+	if err != nil {
+		t.Fatalf("RecordInstall failed: %v", err)
+	}
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}, Alt: true})
+	if m.uninstallErr == nil {
+		t.Fatal("Expected an error for an install script")
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.executing {
+		t.Error("Expected enter not to run anything when the install cannot be inverted")
+	}
+}
+
+func TestUpdate_InstallExecuteMsg_InvalidRiskPolicyRefuses(t *testing.T) {
+	m := newTestModel(t)
+	m.selectedTool = &db.Tool{ID: "test-tool", Name: "Test"}
+	m.riskPolicyErr = &invalidPolicyError{err: errors.New(`unknown risk class "pipe-to-shel" in block_risks`)}
+
+	_, cmd := m.Update(panels.InstallExecuteMsg{Command: "brew install bat"})
+	if !m.modals.IsInstallShown() || m.executing || cmd != nil {
+		t.Fatal("Expected an invalid risk policy to refuse the install")
+	}
+
+	m.width, m.height = 100, 40
+	view := m.View()
+	if !strings.Contains(view, "Install Blocked") || !strings.Contains(view, "installs are disabled") {
+		t.Errorf("Expected the policy error in the view:\n%s", view)
+	}
+}
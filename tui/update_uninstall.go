@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"context"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"troveler/db"
+	"troveler/internal/install"
+	"troveler/internal/platform"
+)
+
+type uninstallCompleteMsg struct {
+	tool   db.Tool
+	output string
+	err    error
+}
+
+// handleUninstallKey derives the uninstall command for the selected tool and
+// opens the confirmation.
+func (m *Model) handleUninstallKey() (tea.Model, tea.Cmd, bool) {
+	if m.selectedTool == nil || m.activePanel == PanelSearch {
+		return m, nil, true
+	}
+
+	tool := *m.selectedTool
+	m.uninstallTool = &tool
+	m.uninstallPlan, m.uninstallErr = install.PlanUninstall(m.db, tool.Slug, m.defaultInstall())
+	m.modals.ShowUninstall()
+
+	return m, nil, true
+}
+
+// handleUninstallInput runs the uninstall on Enter or cancels on Escape.
+func (m *Model) handleUninstallInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Escape):
+		m.modals.CloseUninstall()
+	case key.Matches(msg, m.keys.Enter):
+		m.modals.CloseUninstall()
+		if m.uninstallErr != nil || m.uninstallPlan == nil || m.uninstallTool == nil {
+			return m, nil
+		}
+
+		m.modals.ShowInstall()
+		m.executing = true
+		m.executeOutput = ""
+
+		return m, m.executeUninstallCommand(*m.uninstallTool, m.uninstallPlan.Platform, m.uninstallCommand())
+	}
+
+	return m, nil
+}
+
+func (m *Model) executeUninstallCommand(tool db.Tool, platformID, command string) tea.Cmd {
+	database := m.db
+	ctx, log := newLoggedRun()
+	m.installLog, m.logScroll = log, 0
+
+	return tea.Batch(func() tea.Msg {
+		output, err := runInstallCommand(ctx, database, db.ActionUninstall, tool.Slug, platformID, command, log, "")
+		log.finish()
+
+		return uninstallCompleteMsg{tool: tool, output: output, err: err}
+	}, installLogTick())
+}
+
+// handleUninstallComplete shows the result and refreshes the tool's
+// Installed column.
+func (m *Model) handleUninstallComplete(msg uninstallCompleteMsg) (tea.Model, tea.Cmd) {
+	m.executing = false
+	m.executeOutput = msg.output
+	if msg.err != nil {
+		m.err = msg.err
+	}
+
+	states, err := m.db.GetInstalledState(context.Background())
+	if err == nil {
+		installed := states[msg.tool.ID].Installed
+		if !installed {
+			_ = m.db.DeleteToolVersion(msg.tool.ID)
+		}
+		m.toolsPanel.UpdateToolInstalledStatus(msg.tool.ID, installed)
+	}
+
+	return m, nil
+}
+
+// uninstallCommand is the planned uninstall command with sudo applied when
+// the install used it or use_sudo is "true" (the TUI cannot ask).
+func (m *Model) uninstallCommand() string {
+	if m.uninstallPlan == nil {
+		return ""
+	}
+
+	command := m.uninstallPlan.Command
+	if m.uninstallPlan.Sudo || m.config.Install.UseSudo == "true" {
+		command = "sudo " + command
+	}
+
+	return command
+}
+
+// defaultInstall is the instruction an install of the selected tool would
+// pick on this machine, or nil when there is none.
+func (m *Model) defaultInstall() *db.InstallInstruction {
+	if m.selectedTool == nil {
+		return nil
+	}
+
+	osInfo, _ := platform.DetectOS()
+	detectedOS := ""
+	if osInfo != nil {
+		detectedOS = osInfo.ID
+	}
+
+	selector := install.NewPlatformSelector("", m.config.Install.PlatformOverride, m.config.Install.FallbackPlatform,
+		m.selectedTool.Language)
+	result := install.ResolvePlatform(selector, m.installs, detectedOS, m.selectedTool.Language)
+	if result.UsedFallback || len(result.Installs) == 0 {
+		return nil
+	}
+
+	if defaultCmd := install.SelectDefaultCommand(result.Installs, false, detectedOS); defaultCmd != nil {
+		return defaultCmd
+	}
+
+	return &result.Installs[0]
+}
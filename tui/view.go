@@ -28,11 +28,23 @@ func (m *Model) View() string {
 	case ModalInfo:
 		return m.modals.ViewInfo(m.width, m.height, m.selectedTool, m.installs)
 	case ModalUpdate:
-		return m.modals.ViewUpdate(m.width, m.height, m.update.IsRunning(), m.update.SlugWave())
+		return m.modals.ViewUpdate(m.width, m.height, m.update.IsRunning(), m.update.SlugWave(), m.update.Summary())
 	case ModalInstall:
-		return m.modals.ViewInstall(m.width, m.height, m.executing, m.executeOutput, m.err, m.batch.Progress())
+		return m.modals.ViewInstall(m.width, m.height, m.executing, m.executeOutput, m.err, m.batch.Progress(),
+			installLogView{log: m.installLog, scroll: m.logScroll, replying: m.replying, reply: m.reply})
 	case ModalBatchConfig:
 		return m.modals.ViewBatchConfig(m.width, m.height, m.batch.Config(), m.toolsPanel.GetMarkedCount())
+	case ModalQueryPicker:
+		return m.modals.ViewQueryPicker(m.width, m.height, m.savedQueries, m.queryCursor)
+	case ModalHistory:
+		return m.modals.ViewHistory(m.width, m.height, m.history, m.historyCursor)
+	case ModalUninstall:
+		return m.modals.ViewUninstall(m.width, m.height, m.uninstallTool, m.uninstallPlan,
+			m.uninstallCommand(), m.uninstallErr)
+	case ModalRiskConfirm:
+		return m.modals.ViewRiskConfirm(m.width, m.height, m.riskPending.command, m.riskPending.classes)
+	case ModalResumeBatch:
+		return m.modals.ViewResumeBatch(m.width, m.height, m.pendingBatch)
 	}
 
 	return m.renderMainLayout()
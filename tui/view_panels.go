@@ -45,6 +45,8 @@ func (m *Model) renderToolsPanel(width, height int) string {
 	var title string
 	if m.searching {
 		title = titleStyle.Render(" Tools (searching...) ")
+	} else if m.fuzzy {
+		title = titleStyle.Render(fmt.Sprintf(" Tools (%d, showing fuzzy matches) ", len(m.tools)))
 	} else {
 		title = titleStyle.Render(fmt.Sprintf(" Tools (%d) ", len(m.tools)))
 	}